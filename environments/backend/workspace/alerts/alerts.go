@@ -0,0 +1,125 @@
+// Package alerts はトレーニングのドメインイベントをユーザーの通知先へ届ける
+package alerts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"workout/domain"
+	"workout/notifier"
+)
+
+// チャネル名。ユーザーの宛先はnotifier.Preferences.Addressesにこの名前で保存する
+const (
+	ChannelEmail = "email"
+	ChannelSlack = "slack"
+)
+
+// TopicPrefix はイベントのトピックの接頭辞（"workout.personal_record"）
+// 特定のイベントを受け取らない場合は、Preferences.MutedTopicsにトピックを入れる
+const TopicPrefix = "workout."
+
+// Topic はイベントの種類のトピックを返す
+func Topic(t domain.EventType) string {
+	return TopicPrefix + string(t)
+}
+
+// NewRouter はイベントをユーザーのメール・Slackに送るRouterを作る
+// emailとslackはDispatcher.Notifierのようにアウトボックスに書くNotifierを渡す（Publishが送信を待たないように）
+// nilのチャネルは登録しない
+func NewRouter(prefs notifier.PreferenceStore, email, slack notifier.Notifier) (*notifier.Router, error) {
+	r := notifier.NewRouter(prefs)
+	var channels []string
+	for _, ch := range []struct {
+		name string
+		n    notifier.Notifier
+	}{{ChannelEmail, email}, {ChannelSlack, slack}} {
+		if ch.n == nil {
+			continue
+		}
+		if err := r.AddRecipientChannel(ch.name, ch.n, "{{.Title}}"); err != nil {
+			return nil, err
+		}
+		channels = append(channels, ch.name)
+	}
+	if err := r.AddRule(notifier.Rule{Topics: []string{TopicPrefix + "*"}, Channels: channels}); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Publisher はdomain.EventPublisherの実装
+// イベントをnotifier.Messageにして、Routerでユーザーの設定に合うチャネルへ振り分ける
+type Publisher struct {
+	router    *notifier.Router
+	exercises domain.ExerciseRepository
+}
+
+func NewPublisher(router *notifier.Router, exercises domain.ExerciseRepository) *Publisher {
+	return &Publisher{router: router, exercises: exercises}
+}
+
+// Publish はeをユーザーのチャネルに送る。宛先のないユーザーや、受け取りを拒否しているイベントは送らない
+// EventKeyを冪等キーにするので、同じイベントを2回Publishしてもアウトボックスには1件だけ入る
+// 配送はat-least-once（notifier.Dispatcherを参照）で、送り直しは受信側が冪等キーで捨てる
+func (p *Publisher) Publish(e domain.Event) error {
+	msg := notifier.Message{
+		Severity:  notifier.SeverityInfo,
+		Topic:     Topic(e.Type),
+		Recipient: strconv.Itoa(e.UserID),
+		Title:     p.Render(e),
+	}
+	ctx := notifier.WithIdempotencyKey(context.Background(), EventKey(e))
+	results, err := p.router.Dispatch(ctx, msg, notifier.SendOptions{})
+	if errors.Is(err, notifier.ErrNoRoute) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return errors.Join(results.Errors()...)
+}
+
+// EventKey はイベントの冪等キーを返す
+// セッションを更新して同じイベントがもう一度検出されても同じキーになり、記録が変われば別のキーになる
+func EventKey(e domain.Event) string {
+	key := fmt.Sprintf("%s:user=%d:session=%d", e.Type, e.UserID, e.SessionID)
+	switch e.Type {
+	case domain.EventPersonalRecord:
+		key += fmt.Sprintf(":exercise=%d:record=%s", e.ExerciseID, formatSet(*e.Record))
+	case domain.EventStreakExtended, domain.EventStreakBroken:
+		key += fmt.Sprintf(":streak=%d", e.Streak)
+	}
+	return key
+}
+
+// Render はイベントを通知の本文にする
+func (p *Publisher) Render(e domain.Event) string {
+	switch e.Type {
+	case domain.EventPersonalRecord:
+		return fmt.Sprintf("自己ベスト更新！ %s %s（これまで %s）",
+			p.exerciseName(e.ExerciseID), formatSet(*e.Record), formatSet(*e.Previous))
+	case domain.EventStreakExtended:
+		return fmt.Sprintf("%d日連続でトレーニングしました！", e.Streak)
+	case domain.EventStreakBroken:
+		return fmt.Sprintf("%d日続いた連続記録が途切れました。今日からまた積み上げましょう", e.Streak)
+	default:
+		return string(e.Type)
+	}
+}
+
+// exerciseName は種目名を返す。取得できなければIDで表示する
+func (p *Publisher) exerciseName(id int) string {
+	if p.exercises != nil {
+		if ex, err := p.exercises.FindByID(id); err == nil {
+			return ex.Name
+		}
+	}
+	return "種目#" + strconv.Itoa(id)
+}
+
+func formatSet(s domain.Set) string {
+	return fmt.Sprintf("%skg × %d回", strconv.FormatFloat(s.WeightKg, 'f', -1, 64), s.Reps)
+}
@@ -0,0 +1,226 @@
+package alerts_test
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"workout/alerts"
+	"workout/domain"
+	"workout/notifier"
+)
+
+type sent struct {
+	recipient string
+	message   string
+}
+
+// recorder は送信された宛先とメッセージを記録するNotifier
+type recorder struct {
+	mu   sync.Mutex
+	sent []sent
+}
+
+func (r *recorder) Notify(message string) error {
+	return r.NotifyContext(context.Background(), message)
+}
+
+func (r *recorder) NotifyContext(ctx context.Context, message string) error {
+	recipient, _ := notifier.RecipientFromContext(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent = append(r.sent, sent{recipient, message})
+	return nil
+}
+
+func (r *recorder) Sent() []sent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.sent)
+}
+
+type fixture struct {
+	prefs        *notifier.MemoryPreferenceStore
+	outbox       *notifier.FileOutbox
+	dispatcher   *notifier.Dispatcher
+	email, slack *recorder
+	publisher    *alerts.Publisher
+	sessions     *domain.SessionService
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	f := &fixture{prefs: notifier.NewMemoryPreferenceStore(), email: &recorder{}, slack: &recorder{}}
+	var err error
+	f.outbox, err = notifier.OpenFileOutbox(filepath.Join(t.TempDir(), "outbox.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.dispatcher = notifier.NewDispatcher(f.outbox, map[string]notifier.Notifier{
+		alerts.ChannelEmail: f.email,
+		alerts.ChannelSlack: f.slack,
+	}, notifier.DispatcherOptions{})
+	router, err := alerts.NewRouter(f.prefs,
+		f.dispatcher.Notifier(alerts.ChannelEmail), f.dispatcher.Notifier(alerts.ChannelSlack))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exercises := domain.NewInMemoryExerciseRepository(domain.DefaultExercises()...)
+	f.publisher = alerts.NewPublisher(router, exercises)
+	f.sessions = domain.NewSessionService(domain.NewInMemorySessionRepository(), exercises)
+	f.sessions.SetEventPublisher(f.publisher)
+	return f
+}
+
+func (f *fixture) pending(t *testing.T) []notifier.OutboxEntry {
+	t.Helper()
+	entries, err := f.outbox.List(notifier.OutboxPending)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+func session(userID int, day int, weight float64) *domain.Session {
+	return &domain.Session{
+		UserID:      userID,
+		PerformedAt: time.Date(2024, 5, day, 7, 0, 0, 0, time.UTC),
+		Sets:        []domain.Set{{ExerciseID: 1, Reps: 5, WeightKg: weight}},
+	}
+}
+
+// CreateとUpdateはアウトボックスに書くだけで戻り、同じイベントは1回しか積まない
+func TestSessionEventsAreEnqueuedOnce(t *testing.T) {
+	f := newFixture(t)
+	f.prefs.SavePreferences("1", notifier.Preferences{
+		Addresses: map[string]string{alerts.ChannelEmail: "a@example.com", alerts.ChannelSlack: "#alice"},
+	})
+
+	if err := f.sessions.Create(context.Background(), session(1, 1, 100)); err != nil {
+		t.Fatal(err)
+	}
+	second := session(1, 2, 105)
+	if err := f.sessions.Create(context.Background(), second); err != nil {
+		t.Fatal(err)
+	}
+	// 自己ベスト更新と連続記録が、メールとSlackにそれぞれ1件ずつ
+	if got := len(f.pending(t)); got != 4 {
+		t.Fatalf("pending = %d, want 4", got)
+	}
+	if len(f.email.Sent()) != 0 || len(f.slack.Sent()) != 0 {
+		t.Fatal("Create sent notifications synchronously")
+	}
+
+	// メモだけの更新では同じイベントが検出されるが、冪等キーで重複は積まれない
+	second.Note = "調子が良い"
+	if err := f.sessions.Update(context.Background(), second); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(f.pending(t)); got != 4 {
+		t.Fatalf("pending after note update = %d, want 4", got)
+	}
+	// 重量を直すと自己ベストは別の記録になるので、新たに通知する
+	second.Sets[0].WeightKg = 110
+	if err := f.sessions.Update(context.Background(), second); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(f.pending(t)); got != 6 {
+		t.Fatalf("pending after weight update = %d, want 6", got)
+	}
+
+	if _, err := f.dispatcher.ProcessReady(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range f.email.Sent() {
+		if s.recipient != "a@example.com" {
+			t.Errorf("email sent to %q", s.recipient)
+		}
+	}
+	for _, s := range f.slack.Sent() {
+		if s.recipient != "#alice" {
+			t.Errorf("slack sent to %q", s.recipient)
+		}
+	}
+	if got := len(f.email.Sent()) + len(f.slack.Sent()); got != 6 {
+		t.Errorf("sent %d notifications, want 6", got)
+	}
+}
+
+func TestPublishFollowsPreferences(t *testing.T) {
+	record := domain.Event{Type: domain.EventPersonalRecord, UserID: 2, SessionID: 5, ExerciseID: 1,
+		Previous: &domain.Set{Reps: 5, WeightKg: 100}, Record: &domain.Set{Reps: 5, WeightKg: 105}}
+	streak := domain.Event{Type: domain.EventStreakBroken, UserID: 2, SessionID: 5, Streak: 3}
+
+	tests := []struct {
+		name      string
+		prefs     notifier.Preferences
+		wantEmail []string
+		wantSlack []string
+	}{
+		{
+			name:  "no addresses",
+			prefs: notifier.Preferences{},
+		},
+		{
+			name:      "slack only",
+			prefs:     notifier.Preferences{Addresses: map[string]string{alerts.ChannelSlack: "#bob"}},
+			wantSlack: []string{"自己ベスト更新！ ベンチプレス 105kg × 5回（これまで 100kg × 5回）", "3日続いた"},
+		},
+		{
+			name: "muted streaks and opted out of email",
+			prefs: notifier.Preferences{
+				Addresses:   map[string]string{alerts.ChannelEmail: "b@example.com", alerts.ChannelSlack: "#bob"},
+				OptOut:      []string{alerts.ChannelEmail},
+				MutedTopics: []string{alerts.Topic(domain.EventStreakBroken)},
+			},
+			wantSlack: []string{"自己ベスト更新！"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			f.prefs.SavePreferences("2", tt.prefs)
+			for _, e := range []domain.Event{record, streak} {
+				if err := f.publisher.Publish(e); err != nil {
+					t.Fatalf("Publish %s: %v", e.Type, err)
+				}
+			}
+			if _, err := f.dispatcher.ProcessReady(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			assertSent(t, "email", f.email.Sent(), tt.wantEmail)
+			assertSent(t, "slack", f.slack.Sent(), tt.wantSlack)
+		})
+	}
+}
+
+// assertSent はwantの各要素で始まるメッセージが順不同で1件ずつ送られたかを確かめる
+func assertSent(t *testing.T, channel string, got []sent, want []string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s sent %+v, want %d messages", channel, got, len(want))
+	}
+	for _, prefix := range want {
+		if !slices.ContainsFunc(got, func(s sent) bool { return strings.HasPrefix(s.message, prefix) }) {
+			t.Errorf("%s sent %+v, want a message starting with %q", channel, got, prefix)
+		}
+	}
+}
+
+func TestEventKey(t *testing.T) {
+	a := domain.Event{Type: domain.EventPersonalRecord, UserID: 1, SessionID: 2, ExerciseID: 3,
+		Previous: &domain.Set{Reps: 5, WeightKg: 100}, Record: &domain.Set{Reps: 5, WeightKg: 105}}
+	b := a
+	b.OccurredAt = time.Now()
+	if alerts.EventKey(a) != alerts.EventKey(b) {
+		t.Error("key depends on OccurredAt")
+	}
+	b.Record = &domain.Set{Reps: 6, WeightKg: 105}
+	if alerts.EventKey(a) == alerts.EventKey(b) {
+		t.Error("different records share a key")
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"workout/lessons/logger"
+)
+
+// ErrorBody はエラーレスポンスの統一フォーマット
+// {"error": {"code": "not_found", "message": "..."}}
+type ErrorBody struct {
+	Error ErrorDetail `json:"error"`
+}
+
+// ErrorDetail はエラーの中身。Fieldsはバリデーションエラーの項目ごとの理由
+type ErrorDetail struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// WriteJSON はvをJSONにしてstatusで返す
+func WriteJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if v == nil {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		// ヘッダー送信後なのでステータスは変えられない。ログにだけ残す
+		logger.Default().Warn("write json response", logger.Err(err))
+	}
+}
+
+// WriteError はErrorBody形式のエラーレスポンスを返す
+func WriteError(w http.ResponseWriter, status int, code, message string) {
+	WriteJSON(w, status, ErrorBody{Error: ErrorDetail{Code: code, Message: message}})
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"workout/lessons/logger"
+)
+
+// Middleware はhttp.Handlerを包んで処理を追加する
+type Middleware func(http.Handler) http.Handler
+
+// Chain はmwsを先頭から順に外側になるように重ねる
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Recoverer はハンドラー内のpanicをrecoverし、スタックトレースをログに書いてJSONの500を返す
+// 1つのリクエストのpanicでサーバー全体が落ちるのを防ぐ
+// logger.Middlewareの内側に置くと、リクエストIDの付いたログになる
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				logger.FromContext(r.Context()).Error("panic",
+					logger.F("panic", fmt.Sprint(rec)), logger.F("stack", string(debug.Stack())))
+				WriteError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
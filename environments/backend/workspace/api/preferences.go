@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"workout/notifier"
+)
+
+func (s *Server) handleGetPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	prefs, err := s.preferences.Preferences(strconv.Itoa(userID))
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, prefs)
+}
+
+// handlePutPreferences は設定を丸ごと置き換える
+// 宛先はaddresses（"email"、"slack"）、特定のイベントを受け取らない場合はmuted_topics（"workout.streak_broken"）
+func (s *Server) handlePutPreferences(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var prefs notifier.Preferences
+	if !decodeJSON(w, r, &prefs) {
+		return
+	}
+	if fields := prefs.Validate(); fields != nil {
+		writeValidationError(w, fields)
+		return
+	}
+	if err := s.preferences.SavePreferences(strconv.Itoa(userID), prefs); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, prefs)
+}
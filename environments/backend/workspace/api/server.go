@@ -0,0 +1,81 @@
+// Package api はworkoutサービスのHTTP APIを提供する
+package api
+
+import (
+	"net/http"
+
+	"workout/domain"
+	"workout/lessons/logger"
+	"workout/notifier"
+)
+
+// Services はハンドラーが使うドメイン層の依存
+type Services struct {
+	Sessions    *domain.SessionService
+	Exercises   domain.ExerciseRepository
+	Preferences PreferenceStore
+	// Logger はアクセスログとエラーの出力先（nilならlogger.Default()）
+	Logger *logger.Logger
+}
+
+// PreferenceStore はユーザーの通知設定を読み書きする
+// 受信者はユーザーIDの10進表記で、alertsが通知を送るときと同じ設定を使う
+type PreferenceStore interface {
+	notifier.PreferenceStore
+	SavePreferences(recipient string, p notifier.Preferences) error
+}
+
+// Server はルーティングを持つhttp.Handler
+type Server struct {
+	mux         *http.ServeMux
+	handler     http.Handler
+	sessions    *domain.SessionService
+	exercises   domain.ExerciseRepository
+	preferences PreferenceStore
+}
+
+// NewServer はルートを登録したServerを作る
+func NewServer(svc Services) *Server {
+	s := &Server{
+		mux:         http.NewServeMux(),
+		sessions:    svc.Sessions,
+		exercises:   svc.Exercises,
+		preferences: svc.Preferences,
+	}
+	s.routes()
+	log := svc.Logger
+	if log == nil {
+		log = logger.Default()
+	}
+	// logger.Middlewareがリクエストごとのロガーをcontextに入れ、ハンドラーのログにもリクエストIDが付く
+	s.handler = Chain(s.mux, logger.Middleware(log), Recoverer)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("GET /healthz", s.handleHealthz)
+
+	s.mux.HandleFunc("GET /exercises", s.handleListExercises)
+
+	s.mux.HandleFunc("GET /sessions", s.handleListSessions)
+	s.mux.HandleFunc("POST /sessions", s.handleCreateSession)
+	s.mux.HandleFunc("GET /sessions/{id}", s.handleGetSession)
+	s.mux.HandleFunc("PUT /sessions/{id}", s.handleUpdateSession)
+	s.mux.HandleFunc("DELETE /sessions/{id}", s.handleDeleteSession)
+
+	s.mux.HandleFunc("GET /users/{id}/notification-preferences", s.handleGetPreferences)
+	s.mux.HandleFunc("PUT /users/{id}/notification-preferences", s.handlePutPreferences)
+
+	// どのルートにも一致しない場合もJSONで返す
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		WriteError(w, http.StatusNotFound, "not_found", "route not found: "+r.Method+" "+r.URL.Path)
+	})
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
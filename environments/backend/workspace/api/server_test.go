@@ -0,0 +1,65 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"workout/api"
+	"workout/domain"
+	"workout/lessons/logger"
+	"workout/notifier"
+)
+
+// アクセスログはServices.Loggerに、リクエストIDの付いたJSONで書かれる
+func TestServerAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	exercises := domain.NewInMemoryExerciseRepository(domain.DefaultExercises()...)
+	srv := api.NewServer(api.Services{
+		Sessions:    domain.NewSessionService(domain.NewInMemorySessionRepository(), exercises),
+		Exercises:   exercises,
+		Preferences: notifier.NewMemoryPreferenceStore(),
+		Logger:      logger.New(&buf, logger.Options{Encoder: logger.JSONEncoder{}}),
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		requestID  string
+		wantStatus int
+	}{
+		{name: "given request id", path: "/healthz", requestID: "req-1", wantStatus: http.StatusOK},
+		{name: "generated request id", path: "/healthz", wantStatus: http.StatusOK},
+		{name: "not found", path: "/nope", requestID: "req-2", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.requestID != "" {
+				req.Header.Set(logger.RequestIDHeader, tt.requestID)
+			}
+			rec := httptest.NewRecorder()
+			srv.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			id := rec.Header().Get(logger.RequestIDHeader)
+			if id == "" || (tt.requestID != "" && id != tt.requestID) {
+				t.Errorf("%s = %q, want %q", logger.RequestIDHeader, id, tt.requestID)
+			}
+			var access map[string]any
+			if err := json.Unmarshal([]byte(strings.TrimSpace(buf.String())), &access); err != nil {
+				t.Fatalf("access log %q: %v", buf.String(), err)
+			}
+			if access["msg"] != "request" || access["request_id"] != id || access["path"] != tt.path ||
+				access["status"] != float64(tt.wantStatus) {
+				t.Errorf("access log = %v", access)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"workout/domain"
+	"workout/lessons/logger"
+)
+
+// maxBodyBytes はリクエストボディの上限（巨大なボディでメモリを使い切られないように）
+const maxBodyBytes = 1 << 20
+
+// sessionRequest はPOST/PUTで受け付ける項目
+// id や created_at をクライアントに書き換えさせないため、domain.Sessionとは別に定義する
+type sessionRequest struct {
+	UserID      int          `json:"user_id"`
+	PerformedAt time.Time    `json:"performed_at"`
+	Note        string       `json:"note"`
+	Sets        []domain.Set `json:"sets"`
+}
+
+func (req sessionRequest) toSession(id int) *domain.Session {
+	return &domain.Session{
+		ID:          id,
+		UserID:      req.UserID,
+		PerformedAt: req.PerformedAt,
+		Note:        req.Note,
+		Sets:        req.Sets,
+	}
+}
+
+func (s *Server) handleListExercises(w http.ResponseWriter, r *http.Request) {
+	exercises, err := s.exercises.List()
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"exercises": exercises})
+}
+
+func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(r.URL.Query().Get("user_id"))
+	if err != nil || userID <= 0 {
+		writeValidationError(w, map[string]string{"user_id": "query parameter must be a positive integer"})
+		return
+	}
+	sessions, err := s.sessions.ListByUser(userID)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, map[string]any{"sessions": sessions, "count": len(sessions)})
+}
+
+func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
+	var req sessionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	session := req.toSession(0)
+	if err := s.sessions.Create(r.Context(), session); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/sessions/%d", session.ID))
+	WriteJSON(w, http.StatusCreated, session)
+}
+
+func (s *Server) handleGetSession(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	session, err := s.sessions.Get(id)
+	if err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, session)
+}
+
+func (s *Server) handleUpdateSession(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var req sessionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+	session := req.toSession(id)
+	if err := s.sessions.Update(r.Context(), session); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	WriteJSON(w, http.StatusOK, session)
+}
+
+func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	if err := s.sessions.Delete(id); err != nil {
+		writeDomainError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// pathID はパスパラメータ{id}を正の整数として取り出す。不正なら400を書いてfalseを返す
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		WriteError(w, http.StatusBadRequest, "invalid_id", "id must be a positive integer")
+		return 0, false
+	}
+	return id, true
+}
+
+// decodeJSON はボディをvに読み込む。失敗したら400を書いてfalseを返す
+// 未知のフィールドを拒否して、"reps"を"rep"と書き間違えたような入力に気付けるようにする
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		WriteError(w, http.StatusBadRequest, "invalid_json", err.Error())
+		return false
+	}
+	return true
+}
+
+func writeValidationError(w http.ResponseWriter, fields map[string]string) {
+	WriteJSON(w, http.StatusUnprocessableEntity, ErrorBody{Error: ErrorDetail{
+		Code:    "validation_failed",
+		Message: "request has invalid fields",
+		Fields:  fields,
+	}})
+}
+
+// writeDomainError はドメイン層のエラーをHTTPステータスに変換する
+// 内部エラーはリクエストIDの付いたLoggerで書く
+func writeDomainError(w http.ResponseWriter, r *http.Request, err error) {
+	var verr *domain.ValidationError
+	switch {
+	case errors.As(err, &verr):
+		writeValidationError(w, verr.Fields)
+	case errors.Is(err, domain.ErrNotFound):
+		WriteError(w, http.StatusNotFound, "not_found", err.Error())
+	default:
+		// 内部エラーの詳細はクライアントに返さずログにだけ残す
+		logger.FromContext(r.Context()).Error("internal error", logger.Err(err))
+		WriteError(w, http.StatusInternalServerError, "internal_error", "internal server error")
+	}
+}
@@ -0,0 +1,34 @@
+// Command 01-struct-and-embedding はlessons/productのデモ
+package main
+
+import (
+	"fmt"
+
+	"workout/lessons/product"
+)
+
+func main() {
+	// 正常な商品作成
+	p, err := product.NewProduct("Goの本", 3000, 10)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println(p)
+	fmt.Println("在庫あり:", p.IsInStock())
+
+	// BaseModelのフィールドに直接アクセス（埋め込みによる昇格）
+	fmt.Println("作成日時:", p.CreatedAt.Format("2006-01-02 15:04:05"))
+
+	// Touchメソッドも直接呼べる
+	p.Touch()
+	fmt.Println("更新日時:", p.UpdatedAt.Format("2006-01-02 15:04:05"))
+
+	// バリデーションエラー
+	if _, err := product.NewProduct("", 3000, 10); err != nil {
+		fmt.Println("Error:", err)
+	}
+	if _, err := product.NewProduct("テスト", 0, 10); err != nil {
+		fmt.Println("Error:", err)
+	}
+}
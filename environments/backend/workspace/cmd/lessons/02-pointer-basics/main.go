@@ -0,0 +1,34 @@
+// Command 02-pointer-basics はlessons/bankのデモ
+package main
+
+import (
+	"fmt"
+
+	"workout/lessons/bank"
+)
+
+func main() {
+	alice := &bank.BankAccount{Owner: "Alice", Balance: 1000}
+	bob := &bank.BankAccount{Owner: "Bob", Balance: 500}
+
+	fmt.Printf("初期: Alice=%d, Bob=%d\n", alice.Balance, bob.Balance)
+
+	// 入金
+	alice.Deposit(500)
+	fmt.Printf("Alice入金後: %d\n", alice.Balance)
+
+	// 出金
+	alice.Withdraw(200)
+	fmt.Printf("Alice出金後: %d\n", alice.Balance)
+
+	// 残高不足
+	if err := alice.Withdraw(10000); err != nil {
+		fmt.Println("Error:", err)
+	}
+
+	// 送金
+	if err := alice.Transfer(bob, 300); err != nil {
+		fmt.Println("Error:", err)
+	}
+	fmt.Printf("送金後: Alice=%d, Bob=%d\n", alice.Balance, bob.Balance)
+}
@@ -0,0 +1,33 @@
+// Command 03-methods-and-receivers はlessons/taskのデモ
+package main
+
+import (
+	"fmt"
+
+	"workout/lessons/task"
+)
+
+func main() {
+	t, err := task.NewTask("Go学習", 3)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println(t)
+	fmt.Println("高優先度:", t.IsHighPriority())
+
+	t.Complete()
+	fmt.Println(t)
+
+	// 高優先度タスク
+	urgent, _ := task.NewTask("本番障害対応", 5)
+	fmt.Println(urgent)
+	fmt.Println("高優先度:", urgent.IsHighPriority())
+
+	// バリデーションエラー
+	_, err = task.NewTask("", 3)
+	fmt.Println("Error:", err)
+
+	_, err = task.NewTask("テスト", 6)
+	fmt.Println("Error:", err)
+}
@@ -0,0 +1,30 @@
+// Command 04-interface-basics はlessons/notifyのデモ
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"workout/lessons/notify"
+)
+
+// failingNotifier は常に失敗するNotifier（SendAllが残りに送り続けることを見せる）
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(message string) error {
+	return errors.New("connection refused")
+}
+
+func main() {
+	notifiers := []notify.Notifier{
+		&notify.EmailNotifier{To: "tanaka@example.com"},
+		failingNotifier{},
+		&notify.SlackNotifier{Channel: "#general"},
+	}
+
+	errs := notify.SendAll(notifiers, "デプロイが完了しました")
+	fmt.Printf("失敗: %d件\n", len(errs))
+	for _, err := range errs {
+		fmt.Println("  -", err)
+	}
+}
@@ -0,0 +1,18 @@
+// Command 05-interface-advanced はlessons/paymentのデモ
+package main
+
+import (
+	"fmt"
+
+	"workout/lessons/payment"
+)
+
+func main() {
+	card := payment.CreditCard{Number: "4111111111111111", Limit: 10000}
+	bank := payment.BankTransfer{AccountNumber: "123-456-789", Balance: 50000}
+
+	fmt.Println(payment.ProcessPayment(card, 5000))  // 成功
+	fmt.Println(payment.ProcessPayment(card, 15000)) // 限度額超過
+	fmt.Println(payment.ProcessPayment(bank, 30000)) // 成功
+	fmt.Println(payment.ProcessPayment(bank, 60000)) // 残高不足
+}
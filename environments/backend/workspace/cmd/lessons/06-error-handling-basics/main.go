@@ -0,0 +1,37 @@
+// Command 06-error-handling-basics はlessons/signupのデモ
+package main
+
+import (
+	"fmt"
+
+	"workout/lessons/signup"
+)
+
+func main() {
+	var registry signup.Registry
+
+	// 正常ケース
+	user, err := registry.RegisterUser(signup.RegisterRequest{
+		Username: "tanaka",
+		Email:    "tanaka@example.com",
+		Password: "password123",
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+	} else {
+		fmt.Printf("登録成功: %+v\n", user)
+	}
+
+	// エラーケース
+	testCases := []signup.RegisterRequest{
+		{Username: "ab", Email: "test@example.com", Password: "password123"},
+		{Username: "tanaka", Email: "invalid", Password: "password123"},
+		{Username: "tanaka", Email: "test@example.com", Password: "short"},
+	}
+
+	for _, tc := range testCases {
+		if _, err := registry.RegisterUser(tc); err != nil {
+			fmt.Println("Error:", err)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+// Command 07-custom-errors はlessons/apperrorのデモ
+package main
+
+import (
+	"os"
+
+	"workout/lessons/apperror"
+)
+
+func main() {
+	products := apperror.Catalog{1: "Go入門書"}
+	products.HandleRequest(os.Stdout, 1)  // 200
+	products.HandleRequest(os.Stdout, 99) // 404
+	products.HandleRequest(os.Stdout, -1) // 400
+}
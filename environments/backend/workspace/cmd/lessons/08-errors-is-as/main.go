@@ -0,0 +1,15 @@
+// Command 08-errors-is-as はlessons/ordersのデモ
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"workout/lessons/orders"
+)
+
+func main() {
+	orders.HandleOrderRequest(os.Stdout, 1)
+	fmt.Println("---")
+	orders.HandleOrderRequest(os.Stdout, 0)
+}
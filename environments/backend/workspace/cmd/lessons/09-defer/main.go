@@ -0,0 +1,31 @@
+// Command 09-defer はlessons/txのデモ
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"workout/lessons/tx"
+)
+
+func main() {
+	// 正常ケース
+	fmt.Println("=== 正常ケース ===")
+	err := tx.ExecuteInTx(os.Stdout, func(t *tx.Transaction) error {
+		fmt.Printf("[%s] INSERT INTO users ...\n", t.ID)
+		return nil
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+	}
+
+	// エラーケース
+	fmt.Println("\n=== エラーケース ===")
+	err = tx.ExecuteInTx(os.Stdout, func(t *tx.Transaction) error {
+		fmt.Printf("[%s] INSERT INTO users ...\n", t.ID)
+		return fmt.Errorf("duplicate key")
+	})
+	if err != nil {
+		fmt.Println("Error:", err)
+	}
+}
@@ -0,0 +1,25 @@
+// Command 10-panic-recover はlessons/batchのデモ
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"workout/lessons/batch"
+)
+
+func main() {
+	jobs := []batch.Job{
+		{Name: "データ取得", Fn: func() error { return nil }},
+		{Name: "データ変換", Fn: func() error { return fmt.Errorf("invalid format") }},
+		{Name: "データ保存", Fn: func() error { panic("nil pointer dereference") }},
+		{Name: "通知送信", Fn: func() error { return nil }},
+	}
+
+	result := batch.RunBatch(os.Stdout, jobs)
+	fmt.Println("\n=== バッチ結果 ===")
+	fmt.Printf("成功: %d, 失敗: %d (うちpanic: %d)\n", result.Success, result.Failed, result.Panics)
+	for _, e := range result.Errors {
+		fmt.Println("  -", e)
+	}
+}
@@ -0,0 +1,50 @@
+// Command 11-encoding-json はlessons/todoのデモ
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"workout/lessons/todo"
+)
+
+func main() {
+	due := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	list := todo.TodoList{
+		Todos: []todo.Todo{
+			{ID: 1, Title: "Go学習", Done: false, DueDate: &due},
+			{ID: 2, Title: "テスト作成", Done: true}, // DueDateなし → 省略
+			{ID: 3, Title: "デプロイ", Done: false}, // DueDateなし → 省略
+		},
+		Count: 3,
+	}
+
+	// 構造体 → JSON
+	jsonStr, err := todo.ToJSON(list)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println("=== TodoList JSON ===")
+	fmt.Println(jsonStr)
+
+	// JSON → 構造体
+	fmt.Println("\n=== パース結果 ===")
+	var parsed todo.TodoList
+	if err := todo.FromJSON(jsonStr, &parsed); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for _, t := range parsed.Todos {
+		status := "未完了"
+		if t.Done {
+			status = "完了"
+		}
+		dueStr := "なし"
+		if t.DueDate != nil {
+			dueStr = t.DueDate.Format("2006-01-02")
+		}
+		fmt.Printf("[%s] %s (期限: %s)\n", status, t.Title, dueStr)
+	}
+}
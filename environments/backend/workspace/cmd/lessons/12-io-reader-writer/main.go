@@ -0,0 +1,35 @@
+// Command 12-io-reader-writer はlessons/loggerのデモ
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"workout/lessons/logger"
+)
+
+func main() {
+	// 標準出力へのログ
+	fmt.Println("=== 標準出力（レベル: INFO） ===")
+	log := logger.NewLogger(os.Stdout, logger.INFO)
+	log.Info("サーバー起動")
+	log.Warn("メモリ使用率が高い")
+	log.Error("DB接続失敗")
+
+	// WARNレベル以上のみ出力
+	fmt.Println("\n=== 標準出力（レベル: WARN） ===")
+	warnLog := logger.NewLogger(os.Stdout, logger.WARN)
+	warnLog.Info("これは出力されない")
+	warnLog.Warn("これは出力される")
+	warnLog.Error("これも出力される")
+
+	// バッファへのログ（テスト用）
+	fmt.Println("\n=== バッファ出力（テスト） ===")
+	var buf bytes.Buffer
+	testLog := logger.NewLogger(&buf, logger.INFO)
+	testLog.Info("テストメッセージ1")
+	testLog.Warn("テストメッセージ2")
+	testLog.Error("テストメッセージ3")
+	fmt.Print("バッファ内容:\n", buf.String())
+}
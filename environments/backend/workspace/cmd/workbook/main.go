@@ -0,0 +1,497 @@
+// Command workbook は問題集の解答例を検証するツール
+//
+//	workbook check [-root dir] [-timeout 30s] [-parallel n] [-v] [theme[/level]...]
+//	workbook golden [-root dir] [-timeout 30s] [-parallel n] [theme[/level]...]
+//	workbook lint [-root dir] [file.md...]
+//	workbook parse file.md
+//	workbook snippets [-root dir] [-v] [file.md...]
+//	workbook start [-root dir] [-level advanced] <theme>
+//	workbook save [-root dir] [-force] [-timeout 1m]
+//	workbook grade [-root dir] [-dir path] [-timeout 1m] [theme]
+//	workbook report [-root dir] [-phase n]
+//	workbook mark [-root dir] [-level advanced] [-reset] <phase>/<theme> [attempted|passing|complete]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"workout/workbook"
+	"workout/workbook/gocheck"
+	"workout/workbook/grader"
+	"workout/workbook/theme"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "check":
+		err = runCheck(args)
+	case "golden":
+		err = runGolden(args)
+	case "lint":
+		err = runLint(args)
+	case "parse":
+		err = runParse(args)
+	case "snippets":
+		err = runSnippets(args)
+	case "start":
+		err = runStart(args)
+	case "save":
+		err = runSave(args)
+	case "grade":
+		err = runGrade(args)
+	case "report":
+		err = runReport(args)
+	case "mark":
+		err = runMark(args)
+	case "help", "-h", "-help", "--help":
+		usage()
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "workbook: unknown command %q\n", cmd)
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "workbook:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, `usage: workbook <command> [flags] [args]
+
+commands:
+  check     全ての解答例をビルド・実行して、期待出力と比べる
+  golden    解答例を実行して、期待出力（expected_output.txt）を作り直す
+  lint      テーマのmdがTEMPLATE.mdの形式に従っているかを確認する
+  parse     テーマのmdを読んで、構造化した内容をJSONで表示する
+  snippets  テーマのmdのGoコードブロックを型チェックする
+  start     テーマの演習問題の雛形を作業場所（workspace/exercise）に書く
+  save      作業場所のコードを採点し、合格したらsolutions/<theme>/<level>/に保存する
+  grade     作業場所のコードを隠しテストで採点し、要件ごとの結果を表示する
+  report    README.mdと同じ表の形で、テーマごとの進捗を表示する
+  mark      テーマの進捗を手で記録する（Go以外のフェーズなど）
+`)
+}
+
+// errFailed は検証に失敗した解答例があったことを示す（詳細はレポートに出力済み）
+type errFailed int
+
+func (e errFailed) Error() string { return fmt.Sprintf("%d solution(s) failed", int(e)) }
+
+// errSnippets は期待どおりでなかったコードブロックがあったことを示す（詳細は出力済み）
+type errSnippets int
+
+func (e errSnippets) Error() string { return fmt.Sprintf("%d code block(s) failed", int(e)) }
+
+// errViolations はテーマファイルに形式の逸脱があったことを示す（詳細は出力済み）
+type errViolations int
+
+func (e errViolations) Error() string { return fmt.Sprintf("%d template violation(s)", int(e)) }
+
+// errGrade は満たせていない要件があったことを示す（詳細は出力済み）
+var errGrade = errors.New("some requirements are not met")
+
+// runFlags はcheckとgoldenで共通のフラグ
+type runFlags struct {
+	root     string
+	timeout  time.Duration
+	parallel int
+}
+
+func (f *runFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.root, "root", "", "問題集のルートディレクトリ（省略時はカレントディレクトリから探す）")
+	fs.DurationVar(&f.timeout, "timeout", 30*time.Second, "ビルド・実行それぞれの期限")
+	fs.IntVar(&f.parallel, "parallel", runtime.NumCPU(), "同時に実行する数")
+}
+
+// load は引数のパターンに一致する解答例と、それを実行するRunnerを返す
+func (f *runFlags) load(patterns []string) (*workbook.Runner, []workbook.Solution, error) {
+	dir, err := rootDir(f.root)
+	if err != nil {
+		return nil, nil, err
+	}
+	solutions, err := workbook.DiscoverSolutions(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	solutions = workbook.FilterSolutions(solutions, patterns)
+	if len(solutions) == 0 {
+		return nil, nil, fmt.Errorf("no solutions matched %v", patterns)
+	}
+	runner := &workbook.Runner{Timeout: f.timeout, Module: filepath.Join(dir, workbook.ModuleDir)}
+	return runner, solutions, nil
+}
+
+func runCheck(args []string) error {
+	var f runFlags
+	fs := flag.NewFlagSet("check", flag.ExitOnError)
+	f.register(fs)
+	verbose := fs.Bool("v", false, "成功した解答例の出力も表示する")
+	fs.Parse(args)
+
+	runner, solutions, err := f.load(fs.Args())
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return report(runner.RunAll(ctx, solutions, f.parallel), *verbose)
+}
+
+func runGolden(args []string) error {
+	var f runFlags
+	fs := flag.NewFlagSet("golden", flag.ExitOnError)
+	f.register(fs)
+	fs.Parse(args)
+
+	runner, solutions, err := f.load(fs.Args())
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	return report(runner.UpdateAll(ctx, solutions, f.parallel), false)
+}
+
+func report(results []workbook.Result, verbose bool) error {
+	if err := workbook.WriteReport(os.Stdout, results, verbose); err != nil {
+		return err
+	}
+	if n := workbook.Summarize(results).Failed(); n > 0 {
+		return errFailed(n)
+	}
+	return nil
+}
+
+func rootDir(root string) (string, error) {
+	if root != "" {
+		return root, nil
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	return workbook.FindRoot(wd)
+}
+
+// themeFiles は引数で指定したファイル、なければ全フェーズのテーマファイルを返す
+func themeFiles(root string, args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+	dir, err := rootDir(root)
+	if err != nil {
+		return nil, err
+	}
+	return workbook.ThemeFiles(dir)
+}
+
+func runLint(args []string) error {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	root := fs.String("root", "", "問題集のルートディレクトリ（省略時はカレントディレクトリから探す）")
+	fs.Parse(args)
+
+	files, err := themeFiles(*root, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	for _, file := range files {
+		t, err := theme.ParseFile(file)
+		if err != nil {
+			return err
+		}
+		for _, v := range t.Validate() {
+			fmt.Println(v)
+			n++
+		}
+	}
+	fmt.Printf("%d file(s), %d violation(s)\n", len(files), n)
+	if n > 0 {
+		return errViolations(n)
+	}
+	return nil
+}
+
+func runParse(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: workbook parse file.md")
+	}
+	t, err := theme.ParseFile(args[0])
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+func runSnippets(args []string) error {
+	fs := flag.NewFlagSet("snippets", flag.ExitOnError)
+	root := fs.String("root", "", "問題集のルートディレクトリ（省略時はカレントディレクトリから探す）")
+	verbose := fs.Bool("v", false, "期待どおりだったコードブロックと、無視したエラーも表示する")
+	fs.Parse(args)
+
+	files, err := themeFiles(*root, fs.Args())
+	if err != nil {
+		return err
+	}
+
+	checker := gocheck.NewChecker()
+	total, failed := 0, 0
+	for _, file := range files {
+		t, err := theme.ParseFile(file)
+		if err != nil {
+			return err
+		}
+		for _, r := range checker.CheckTheme(t) {
+			total++
+			if !r.OK() {
+				failed++
+				fmt.Printf("%s:%d: %s block (%s): %s\n", r.Path, r.Block.Line, r.Block.Kind, r.Spec.Mode, r.Problem)
+			} else if *verbose {
+				fmt.Printf("%s:%d: %s block (%s): ok\n", r.Path, r.Block.Line, r.Block.Kind, r.Spec.Mode)
+			}
+			if !*verbose {
+				continue
+			}
+			for _, d := range r.Diagnostics {
+				mark := ""
+				if d.Ignored {
+					mark = " (ignored)"
+				}
+				fmt.Printf("    line %d: %s%s\n", d.Line, d.Message, mark)
+			}
+		}
+	}
+	fmt.Printf("%d go code block(s), %d failed\n", total, failed)
+	if failed > 0 {
+		return errSnippets(failed)
+	}
+	return nil
+}
+
+func runStart(args []string) error {
+	fs := flag.NewFlagSet("start", flag.ExitOnError)
+	root := fs.String("root", "", "問題集のルートディレクトリ（省略時はカレントディレクトリから探す）")
+	level := fs.String("level", string(workbook.LevelAdvanced), "解答を保存するレベル（basic, applied, advanced）")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("usage: workbook start [-level advanced] <theme number>")
+	}
+	number, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("theme must be a number like 07: %q", fs.Arg(0))
+	}
+	lv, err := workbook.ParseLevel(*level)
+	if err != nil {
+		return err
+	}
+	dir, err := rootDir(*root)
+	if err != nil {
+		return err
+	}
+
+	ws := &workbook.Workspace{Root: dir}
+	backup, err := ws.Start(number, lv)
+	if err != nil {
+		return err
+	}
+	if backup != "" {
+		fmt.Println("前の作業をバックアップしました:", backup)
+	}
+	cur, err := ws.Current()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s（%s）の雛形を %s に書きました\n", cur.Theme, cur.Level, filepath.Join(workbook.ExerciseDir, "main.go"))
+	fmt.Println("実行: cd environments/backend/workspace && go run ./exercise")
+	return nil
+}
+
+func runSave(args []string) error {
+	fs := flag.NewFlagSet("save", flag.ExitOnError)
+	root := fs.String("root", "", "問題集のルートディレクトリ（省略時はカレントディレクトリから探す）")
+	force := fs.Bool("force", false, "既存の解答を上書きする")
+	timeout := fs.Duration("timeout", time.Minute, "テストの実行の期限")
+	fs.Parse(args)
+
+	dir, err := rootDir(*root)
+	if err != nil {
+		return err
+	}
+	ws := &workbook.Workspace{Root: dir}
+	cur, err := ws.Current()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// 隠しテストに合格した解答だけを保存して完了にする
+	var grade workbook.GradeFunc
+	if suite, err := grader.Load(cur.Theme); err == nil {
+		grade = func(name, exercise string) (bool, error) {
+			g, err := (&grader.Grader{Timeout: *timeout, Module: filepath.Join(dir, workbook.ModuleDir)}).Grade(ctx, suite, exercise)
+			if err != nil {
+				return false, err
+			}
+			return g.Passed(), grader.WriteReport(os.Stdout, g, requirementsOf(dir, name))
+		}
+	}
+	dest, err := ws.Save(*force, grade)
+	if err != nil {
+		return err
+	}
+	fmt.Println("保存しました:", dest)
+	if grade == nil {
+		fmt.Printf("%s には採点がないので完了は記録していません（workbook mark で記録できます）\n", cur.Theme)
+	}
+	return nil
+}
+
+func runGrade(args []string) error {
+	fs := flag.NewFlagSet("grade", flag.ExitOnError)
+	root := fs.String("root", "", "問題集のルートディレクトリ（省略時はカレントディレクトリから探す）")
+	dir := fs.String("dir", "", "採点するパッケージのディレクトリ（省略時はworkspace/exercise）")
+	timeout := fs.Duration("timeout", time.Minute, "テストの実行の期限")
+	fs.Parse(args)
+
+	if fs.NArg() > 1 {
+		return fmt.Errorf("usage: workbook grade [-dir path] [theme]")
+	}
+	rootPath, err := rootDir(*root)
+	if err != nil {
+		return err
+	}
+	ws := &workbook.Workspace{Root: rootPath}
+
+	// テーマを省略したらworkbook startしたテーマを採点する
+	name := fs.Arg(0)
+	if name == "" {
+		cur, err := ws.Current()
+		if err != nil {
+			return fmt.Errorf("%w; or specify a theme: workbook grade 12", err)
+		}
+		name = cur.Theme
+	}
+	suite, err := grader.Load(name)
+	if err != nil {
+		return err
+	}
+	// workbook startした問題を作業場所で採点した場合だけ、進捗に記録する
+	record := false
+	if *dir == "" {
+		*dir = filepath.Join(rootPath, workbook.ExerciseDir)
+		cur, err := ws.Current()
+		record = err == nil && cur.Theme == suite.Theme
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	g, err := (&grader.Grader{Timeout: *timeout, Module: filepath.Join(rootPath, workbook.ModuleDir)}).Grade(ctx, suite, *dir)
+	if err != nil {
+		return err
+	}
+	if err := grader.WriteReport(os.Stdout, g, requirementsOf(rootPath, suite.Theme)); err != nil {
+		return err
+	}
+	if record {
+		if err := ws.RecordGrade(g.Passed()); err != nil {
+			return err
+		}
+	}
+	if !g.Passed() {
+		return errGrade
+	}
+	return nil
+}
+
+// requirementsOf はテーマファイルの演習問題の要件を返す（読めなければnil）
+func requirementsOf(root, name string) []string {
+	t, err := theme.ParseFile(filepath.Join(root, workbook.ThemesDir, name+".md"))
+	if err != nil {
+		return nil
+	}
+	return t.Exercise.Requirements
+}
+
+func runReport(args []string) error {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	root := fs.String("root", "", "問題集のルートディレクトリ（省略時はカレントディレクトリから探す）")
+	phase := fs.Int("phase", 0, "表示するフェーズ（0なら全て）")
+	fs.Parse(args)
+
+	dir, err := rootDir(*root)
+	if err != nil {
+		return err
+	}
+	c, err := workbook.LoadCurriculum(dir)
+	if err != nil {
+		return err
+	}
+	p, err := (&workbook.Workspace{Root: dir}).Progress()
+	if err != nil {
+		return err
+	}
+	if err := workbook.WriteProgressReport(os.Stdout, c, p, *phase); err != nil {
+		return err
+	}
+	fmt.Println()
+	return nil
+}
+
+func runMark(args []string) error {
+	fs := flag.NewFlagSet("mark", flag.ExitOnError)
+	root := fs.String("root", "", "問題集のルートディレクトリ（省略時はカレントディレクトリから探す）")
+	level := fs.String("level", string(workbook.LevelAdvanced), "記録するレベル（basic, applied, advanced）")
+	reset := fs.Bool("reset", false, "記録を消す")
+	fs.Parse(args)
+
+	if (*reset && fs.NArg() != 1) || (!*reset && fs.NArg() != 2) {
+		return fmt.Errorf("usage: workbook mark [-level advanced] <phase>/<theme> <attempted|passing|complete>, or mark -reset <phase>/<theme>")
+	}
+	phase, number, err := workbook.ParseThemeRef(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	lv, err := workbook.ParseLevel(*level)
+	if err != nil {
+		return err
+	}
+	dir, err := rootDir(*root)
+	if err != nil {
+		return err
+	}
+	ws := &workbook.Workspace{Root: dir}
+	if *reset {
+		return ws.Unmark(phase, number, lv)
+	}
+	status, err := workbook.ParseProgressStatus(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	return ws.Mark(phase, number, lv, status)
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrNotFound はリソースが存在しない場合のエラー
+// 呼び出し側はerrors.Isで判定する（リポジトリ層のエラーでラップされていても届く）
+var ErrNotFound = errors.New("not found")
+
+// ValidationError は入力値の検証エラー。Fieldsは項目名→理由
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	keys := make([]string, 0, len(e.Fields))
+	for k := range e.Fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s: %s", k, e.Fields[k])
+	}
+	return "validation failed: " + strings.Join(parts, ", ")
+}
+
+// validator は複数項目のエラーをまとめて返すための小さなヘルパー
+// 最初の1件で止めず、全項目の問題を一度に返したほうがクライアントが直しやすい
+type validator struct {
+	fields map[string]string
+}
+
+func (v *validator) check(ok bool, field, reason string) {
+	if ok {
+		return
+	}
+	if v.fields == nil {
+		v.fields = make(map[string]string)
+	}
+	if _, exists := v.fields[field]; !exists {
+		v.fields[field] = reason
+	}
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields}
+}
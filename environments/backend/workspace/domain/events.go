@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// EventType はトレーニング記録から発生するイベントの種類
+type EventType string
+
+const (
+	EventPersonalRecord EventType = "personal_record" // 自己ベスト更新
+	EventStreakExtended EventType = "streak_extended" // 連続日数が伸びた
+	EventStreakBroken   EventType = "streak_broken"   // 連続日数が途切れた
+)
+
+// EventTypes は全てのイベントの種類
+var EventTypes = []EventType{EventPersonalRecord, EventStreakExtended, EventStreakBroken}
+
+// Event はセッションの記録によって発生したドメインイベント
+type Event struct {
+	Type      EventType `json:"type"`
+	UserID    int       `json:"user_id"`
+	SessionID int       `json:"session_id"`
+
+	// 自己ベスト更新の場合のみ
+	ExerciseID int  `json:"exercise_id,omitempty"`
+	Previous   *Set `json:"previous,omitempty"`
+	Record     *Set `json:"record,omitempty"`
+
+	// 連続記録の場合のみ。途切れた場合のStreakは途切れる前の日数
+	Streak int `json:"streak,omitempty"`
+
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// EventPublisher はイベントの通知先
+// セッションの保存と同じリクエストの中で呼ばれるので、送信を待たずに（アウトボックスに積むなどして）すぐ戻る
+type EventPublisher interface {
+	Publish(e Event) error
+}
+
+// Beats はsがotherより良い記録かを返す（重量が重い、同じ重量なら回数が多い）
+func (s Set) Beats(other Set) bool {
+	if s.WeightKg != other.WeightKg {
+		return s.WeightKg > other.WeightKg
+	}
+	return s.Reps > other.Reps
+}
+
+// DetectEvents はhistory（sessionより前の記録を含む、同じユーザーの過去セッション）と
+// 新しく記録したsessionを比べて、自己ベスト更新と連続記録のイベントを返す
+// 連続記録の「日」はlocのタイムゾーンで数える（記録ごとのオフセットが違っても同じ暦で比べる）
+func DetectEvents(history []*Session, session *Session, loc *time.Location) []Event {
+	var events []Event
+	at := now()
+
+	// 種目ごとの、このセッションより前の最高記録
+	best := make(map[int]Set)
+	for _, h := range history {
+		if h.ID == session.ID || !h.PerformedAt.Before(session.PerformedAt) {
+			continue
+		}
+		for _, set := range h.Sets {
+			if b, ok := best[set.ExerciseID]; !ok || set.Beats(b) {
+				best[set.ExerciseID] = set
+			}
+		}
+	}
+
+	// 同じ種目で複数セット更新しても、通知はセッション内の最高記録1件だけ
+	top := make(map[int]Set)
+	var order []int
+	for _, set := range session.Sets {
+		t, ok := top[set.ExerciseID]
+		if !ok {
+			order = append(order, set.ExerciseID)
+		}
+		if !ok || set.Beats(t) {
+			top[set.ExerciseID] = set
+		}
+	}
+	for _, exerciseID := range order {
+		record := top[exerciseID]
+		// 初めての種目は比べる記録がないので自己ベストとは扱わない
+		prev, ok := best[exerciseID]
+		if !ok || !record.Beats(prev) {
+			continue
+		}
+		events = append(events, Event{Type: EventPersonalRecord, UserID: session.UserID, SessionID: session.ID,
+			ExerciseID: exerciseID, Previous: &prev, Record: &record, OccurredAt: at})
+	}
+
+	if e, ok := detectStreak(history, session, at, loc); ok {
+		events = append(events, e)
+	}
+	return events
+}
+
+// detectStreak は日単位の連続記録を判定する
+// 過去の日付に後から記録した場合は、連続記録のイベントを出さない
+func detectStreak(history []*Session, session *Session, at time.Time, loc *time.Location) (Event, bool) {
+	day := dayOf(session.PerformedAt, loc)
+
+	seen := make(map[time.Time]bool)
+	var days []time.Time
+	for _, h := range history {
+		if h.ID == session.ID {
+			continue
+		}
+		d := dayOf(h.PerformedAt, loc)
+		if !d.Before(day) {
+			// 同じ日にもう記録がある、または未来の記録がある
+			return Event{}, false
+		}
+		if !seen[d] {
+			seen[d] = true
+			days = append(days, d)
+		}
+	}
+	if len(days) == 0 {
+		return Event{}, false
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].After(days[j]) })
+
+	// 直前の記録日から遡って、連続している日数を数える
+	prevStreak := 1
+	for i := 1; i < len(days) && days[i].Equal(days[i-1].AddDate(0, 0, -1)); i++ {
+		prevStreak++
+	}
+
+	e := Event{UserID: session.UserID, SessionID: session.ID, OccurredAt: at}
+	switch {
+	case days[0].Equal(day.AddDate(0, 0, -1)):
+		e.Type = EventStreakExtended
+		e.Streak = prevStreak + 1
+	case prevStreak >= 2:
+		e.Type = EventStreakBroken
+		e.Streak = prevStreak
+	default:
+		return Event{}, false
+	}
+	return e, true
+}
+
+// dayOf はtをlocの時刻に直して、その日の0時に切り捨てる
+func dayOf(t time.Time, loc *time.Location) time.Time {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, loc)
+}
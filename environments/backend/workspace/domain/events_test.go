@@ -0,0 +1,76 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"workout/domain"
+)
+
+func TestDetectEventsStreakUsesOneLocation(t *testing.T) {
+	jst := time.FixedZone("JST", 9*60*60)
+	session := func(id int, at time.Time) *domain.Session {
+		return &domain.Session{ID: id, UserID: 1, PerformedAt: at,
+			Sets: []domain.Set{{ExerciseID: 1, Reps: 5, WeightKg: 100}}}
+	}
+
+	tests := []struct {
+		name    string
+		loc     *time.Location
+		history []time.Time
+		at      time.Time
+		want    domain.EventType // 空なら連続記録のイベントなし
+		streak  int
+	}{
+		{
+			// 5/1 23:30 JSTがUTCで保存されていても、JSTでは5/1と5/2の連続
+			name:    "consecutive days in JST with mixed offsets",
+			loc:     jst,
+			history: []time.Time{time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC)},
+			at:      time.Date(2024, 5, 2, 8, 0, 0, 0, jst),
+			want:    domain.EventStreakExtended,
+			streak:  2,
+		},
+		{
+			// 同じ2つの時刻も、UTCで数えると同じ5/1なので連続記録にはならない
+			name:    "same day in UTC",
+			loc:     time.UTC,
+			history: []time.Time{time.Date(2024, 5, 1, 23, 0, 0, 0, jst)},
+			at:      time.Date(2024, 5, 2, 1, 0, 0, 0, jst),
+		},
+		{
+			name: "broken streak across offsets",
+			loc:  jst,
+			history: []time.Time{
+				time.Date(2024, 4, 30, 20, 0, 0, 0, jst),
+				time.Date(2024, 4, 30, 16, 0, 0, 0, time.UTC), // JSTでは5/1 1:00
+			},
+			at:     time.Date(2024, 5, 3, 7, 0, 0, 0, jst),
+			want:   domain.EventStreakBroken,
+			streak: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var history []*domain.Session
+			for i, at := range tt.history {
+				history = append(history, session(i+1, at))
+			}
+			s := session(len(history)+1, tt.at)
+			history = append(history, s)
+
+			var got *domain.Event
+			for _, e := range domain.DetectEvents(history, s, tt.loc) {
+				if e.Type != domain.EventPersonalRecord {
+					got = &e
+				}
+			}
+			switch {
+			case tt.want == "" && got != nil:
+				t.Errorf("got %s (streak %d), want no streak event", got.Type, got.Streak)
+			case tt.want != "" && (got == nil || got.Type != tt.want || got.Streak != tt.streak):
+				t.Errorf("got %+v, want %s with streak %d", got, tt.want, tt.streak)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// SessionRepository はトレーニング記録の永続化を抽象化するインターフェース
+type SessionRepository interface {
+	FindByID(id int) (*Session, error)
+	// ListByUser はユーザーのセッションを実施日時の新しい順に返す
+	ListByUser(userID int) ([]*Session, error)
+	// Save はIDが0なら新規作成してIDを採番し、それ以外は更新する
+	Save(session *Session) error
+	Delete(id int) error
+}
+
+// ExerciseRepository は種目マスタの取得を抽象化するインターフェース
+type ExerciseRepository interface {
+	FindByID(id int) (*Exercise, error)
+	List() ([]*Exercise, error)
+}
+
+// RoutineRepository はルーティンの永続化を抽象化するインターフェース
+type RoutineRepository interface {
+	FindByID(id int) (*Routine, error)
+	ListByUser(userID int) ([]*Routine, error)
+	Save(routine *Routine) error
+	Delete(id int) error
+}
+
+// InMemorySessionRepository はメモリ上で動くSessionRepositoryの実装
+// HTTPハンドラーから並行に呼ばれるのでMutexで守る
+type InMemorySessionRepository struct {
+	mu       sync.RWMutex
+	sessions map[int]*Session
+	nextID   int
+}
+
+func NewInMemorySessionRepository() *InMemorySessionRepository {
+	return &InMemorySessionRepository{sessions: make(map[int]*Session), nextID: 1}
+}
+
+func (r *InMemorySessionRepository) FindByID(id int) (*Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("session id=%d: %w", id, ErrNotFound)
+	}
+	return cloneSession(s), nil
+}
+
+func (r *InMemorySessionRepository) ListByUser(userID int) ([]*Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := []*Session{}
+	for _, s := range r.sessions {
+		if s.UserID == userID {
+			list = append(list, cloneSession(s))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].PerformedAt.Equal(list[j].PerformedAt) {
+			return list[i].ID > list[j].ID
+		}
+		return list[i].PerformedAt.After(list[j].PerformedAt)
+	})
+	return list, nil
+}
+
+func (r *InMemorySessionRepository) Save(session *Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if session.ID == 0 {
+		session.ID = r.nextID
+		r.nextID++
+	} else if _, ok := r.sessions[session.ID]; !ok {
+		return fmt.Errorf("session id=%d: %w", session.ID, ErrNotFound)
+	}
+	// 呼び出し側が後からスライスを書き換えても保存内容が変わらないようにコピーする
+	r.sessions[session.ID] = cloneSession(session)
+	return nil
+}
+
+func (r *InMemorySessionRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sessions[id]; !ok {
+		return fmt.Errorf("session id=%d: %w", id, ErrNotFound)
+	}
+	delete(r.sessions, id)
+	return nil
+}
+
+func cloneSession(s *Session) *Session {
+	c := *s
+	c.Sets = slices.Clone(s.Sets)
+	return &c
+}
+
+// InMemoryExerciseRepository はメモリ上で動くExerciseRepositoryの実装
+type InMemoryExerciseRepository struct {
+	exercises map[int]*Exercise
+}
+
+// NewInMemoryExerciseRepository は渡した種目で初期化する（IDは1から順に振る）
+func NewInMemoryExerciseRepository(exercises ...Exercise) *InMemoryExerciseRepository {
+	r := &InMemoryExerciseRepository{exercises: make(map[int]*Exercise)}
+	for i, e := range exercises {
+		e.ID = i + 1
+		r.exercises[e.ID] = &e
+	}
+	return r
+}
+
+// DefaultExercises は開発用の初期種目
+func DefaultExercises() []Exercise {
+	return []Exercise{
+		{Name: "ベンチプレス", MuscleGroup: "chest"},
+		{Name: "スクワット", MuscleGroup: "legs"},
+		{Name: "デッドリフト", MuscleGroup: "back"},
+		{Name: "オーバーヘッドプレス", MuscleGroup: "shoulders"},
+		{Name: "懸垂", MuscleGroup: "back"},
+	}
+}
+
+func (r *InMemoryExerciseRepository) FindByID(id int) (*Exercise, error) {
+	e, ok := r.exercises[id]
+	if !ok {
+		return nil, fmt.Errorf("exercise id=%d: %w", id, ErrNotFound)
+	}
+	c := *e
+	return &c, nil
+}
+
+func (r *InMemoryExerciseRepository) List() ([]*Exercise, error) {
+	list := make([]*Exercise, 0, len(r.exercises))
+	for _, e := range r.exercises {
+		c := *e
+		list = append(list, &c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+// InMemoryRoutineRepository はメモリ上で動くRoutineRepositoryの実装
+type InMemoryRoutineRepository struct {
+	mu       sync.RWMutex
+	routines map[int]*Routine
+	nextID   int
+}
+
+func NewInMemoryRoutineRepository() *InMemoryRoutineRepository {
+	return &InMemoryRoutineRepository{routines: make(map[int]*Routine), nextID: 1}
+}
+
+func (r *InMemoryRoutineRepository) FindByID(id int) (*Routine, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rt, ok := r.routines[id]
+	if !ok {
+		return nil, fmt.Errorf("routine id=%d: %w", id, ErrNotFound)
+	}
+	return cloneRoutine(rt), nil
+}
+
+func (r *InMemoryRoutineRepository) ListByUser(userID int) ([]*Routine, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	list := []*Routine{}
+	for _, rt := range r.routines {
+		if rt.UserID == userID {
+			list = append(list, cloneRoutine(rt))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (r *InMemoryRoutineRepository) Save(routine *Routine) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if routine.ID == 0 {
+		routine.ID = r.nextID
+		r.nextID++
+	} else if _, ok := r.routines[routine.ID]; !ok {
+		return fmt.Errorf("routine id=%d: %w", routine.ID, ErrNotFound)
+	}
+	r.routines[routine.ID] = cloneRoutine(routine)
+	return nil
+}
+
+func (r *InMemoryRoutineRepository) Delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.routines[id]; !ok {
+		return fmt.Errorf("routine id=%d: %w", id, ErrNotFound)
+	}
+	delete(r.routines, id)
+	return nil
+}
+
+func cloneRoutine(r *Routine) *Routine {
+	c := *r
+	c.ExerciseIDs = slices.Clone(r.ExerciseIDs)
+	return &c
+}
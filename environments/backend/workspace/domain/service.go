@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"workout/lessons/logger"
+)
+
+// now はテストで時刻を固定できるように差し替え可能にしておく
+var now = time.Now
+
+// SessionService はセッションの作成・更新時に検証を行うサービス
+// ハンドラーはリポジトリを直接触らず、このサービスを経由する
+type SessionService struct {
+	sessions  SessionRepository
+	exercises ExerciseRepository
+	events    EventPublisher
+	loc       *time.Location
+}
+
+func NewSessionService(sessions SessionRepository, exercises ExerciseRepository) *SessionService {
+	return &SessionService{sessions: sessions, exercises: exercises, loc: time.UTC}
+}
+
+// SetLocation は連続記録の日付を数えるタイムゾーンを設定する（デフォルトはUTC）
+func (s *SessionService) SetLocation(loc *time.Location) {
+	s.loc = loc
+}
+
+// SetEventPublisher はセッションの作成・更新時に自己ベスト・連続記録のイベントを送る先を設定する
+func (s *SessionService) SetEventPublisher(p EventPublisher) {
+	s.events = p
+}
+
+func (s *SessionService) Get(id int) (*Session, error) {
+	return s.sessions.FindByID(id)
+}
+
+func (s *SessionService) ListByUser(userID int) ([]*Session, error) {
+	return s.sessions.ListByUser(userID)
+}
+
+// Create は検証してから新しいセッションを保存する
+// ctxはイベントの送信に失敗したときのログに使う（logger.FromContextのロガーに書く）
+func (s *SessionService) Create(ctx context.Context, session *Session) error {
+	session.ID = 0
+	if err := s.validate(session); err != nil {
+		return err
+	}
+	t := now()
+	session.CreatedAt = t
+	session.UpdatedAt = t
+	if err := s.sessions.Save(session); err != nil {
+		return err
+	}
+	s.publishEvents(ctx, session)
+	return nil
+}
+
+// publishEvents は保存済みのsessionから発生したイベントをEventPublisherに渡す
+// 通知の失敗で記録の保存を失敗扱いにはしないので、エラーはctxのロガーに出すだけ（リクエストIDが付く）
+func (s *SessionService) publishEvents(ctx context.Context, session *Session) {
+	if s.events == nil {
+		return
+	}
+	log := logger.FromContext(ctx).With(logger.F("user_id", session.UserID), logger.F("session_id", session.ID))
+	history, err := s.sessions.ListByUser(session.UserID)
+	if err != nil {
+		log.Error("list sessions for events failed", logger.Err(err))
+		return
+	}
+	for _, e := range DetectEvents(history, session, s.loc) {
+		if err := s.events.Publish(e); err != nil {
+			log.Error("publish event failed", logger.F("event", string(e.Type)), logger.Err(err))
+		}
+	}
+}
+
+// Update は既存のセッションを丸ごと置き換える（作成日時は引き継ぐ）
+func (s *SessionService) Update(ctx context.Context, session *Session) error {
+	current, err := s.sessions.FindByID(session.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validate(session); err != nil {
+		return err
+	}
+	session.CreatedAt = current.CreatedAt
+	session.UpdatedAt = now()
+	if err := s.sessions.Save(session); err != nil {
+		return err
+	}
+	// 重量を直した結果の自己ベストなども通知する
+	// 作成時と同じイベントがもう一度検出されても、EventPublisherが冪等キーで重複を除く
+	s.publishEvents(ctx, session)
+	return nil
+}
+
+func (s *SessionService) Delete(id int) error {
+	return s.sessions.Delete(id)
+}
+
+// validate は値の検証に加えて、セットの種目が実在するかを確認する
+func (s *SessionService) validate(session *Session) error {
+	var fields map[string]string
+	var verr *ValidationError
+	if err := session.Validate(); err != nil {
+		if !errors.As(err, &verr) {
+			return err
+		}
+		fields = verr.Fields
+	}
+
+	for i, set := range session.Sets {
+		if set.ExerciseID <= 0 {
+			continue
+		}
+		if _, err := s.exercises.FindByID(set.ExerciseID); err != nil {
+			if !errors.Is(err, ErrNotFound) {
+				return err
+			}
+			if fields == nil {
+				fields = make(map[string]string)
+			}
+			fields[fmt.Sprintf("sets[%d].exercise_id", i)] = "unknown exercise"
+		}
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
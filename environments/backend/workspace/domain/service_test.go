@@ -0,0 +1,56 @@
+package domain_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"workout/domain"
+	"workout/lessons/logger"
+)
+
+// publishFunc は関数をEventPublisherとして使う
+type publishFunc func(e domain.Event) error
+
+func (f publishFunc) Publish(e domain.Event) error { return f(e) }
+
+// 通知に失敗しても保存は成功し、失敗はリクエストIDの付いたログに残る
+func TestSessionServicePublishFailureIsLogged(t *testing.T) {
+	errDown := errors.New("outbox unavailable")
+	svc := domain.NewSessionService(domain.NewInMemorySessionRepository(),
+		domain.NewInMemoryExerciseRepository(domain.DefaultExercises()...))
+	svc.SetEventPublisher(publishFunc(func(domain.Event) error { return errDown }))
+
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.Options{Encoder: logger.JSONEncoder{}})
+	ctx := logger.NewContext(logger.WithRequestID(context.Background(), "req-1"), log)
+
+	for day := 1; day <= 2; day++ {
+		s := &domain.Session{
+			UserID:      1,
+			PerformedAt: time.Date(2024, 5, day, 7, 0, 0, 0, time.UTC),
+			Sets:        []domain.Set{{ExerciseID: 1, Reps: 5, WeightKg: float64(100 + day)}},
+		}
+		if err := svc.Create(ctx, s); err != nil {
+			t.Fatalf("Create day %d: %v", day, err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) == 0 || lines[0] == "" {
+		t.Fatal("publish failure was not logged")
+	}
+	for _, line := range lines {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		if m["msg"] != "publish event failed" || m["request_id"] != "req-1" || m["error"] != errDown.Error() || m["user_id"] != float64(1) {
+			t.Errorf("log line = %s", line)
+		}
+	}
+}
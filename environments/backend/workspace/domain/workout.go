@@ -0,0 +1,80 @@
+// Package domain はトレーニング記録のドメインモデルを定義する
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Exercise はベンチプレスなどの種目
+type Exercise struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	MuscleGroup string `json:"muscle_group"`
+}
+
+// Set は1セット分の記録（何kgを何回）
+type Set struct {
+	ExerciseID int     `json:"exercise_id"`
+	Reps       int     `json:"reps"`
+	WeightKg   float64 `json:"weight_kg"`
+}
+
+// Volume はセットの総負荷（重量×回数）
+func (s Set) Volume() float64 {
+	return s.WeightKg * float64(s.Reps)
+}
+
+// Session は1回のトレーニング
+type Session struct {
+	ID          int       `json:"id"`
+	UserID      int       `json:"user_id"`
+	PerformedAt time.Time `json:"performed_at"`
+	Note        string    `json:"note,omitempty"`
+	Sets        []Set     `json:"sets"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// TotalVolume はセッション全体の総負荷
+func (s *Session) TotalVolume() float64 {
+	var total float64
+	for _, set := range s.Sets {
+		total += set.Volume()
+	}
+	return total
+}
+
+// Validate はセッションの値が正しいかを検証する
+func (s *Session) Validate() error {
+	var v validator
+	v.check(s.UserID > 0, "user_id", "must be positive")
+	v.check(!s.PerformedAt.IsZero(), "performed_at", "is required")
+	v.check(len(s.Note) <= 1000, "note", "must be at most 1000 characters")
+	v.check(len(s.Sets) > 0, "sets", "at least one set is required")
+	for i, set := range s.Sets {
+		field := fmt.Sprintf("sets[%d]", i)
+		v.check(set.ExerciseID > 0, field+".exercise_id", "must be positive")
+		v.check(set.Reps > 0, field+".reps", "must be positive")
+		v.check(set.Reps <= 1000, field+".reps", "must be at most 1000")
+		v.check(set.WeightKg >= 0, field+".weight_kg", "must not be negative")
+	}
+	return v.err()
+}
+
+// Routine は「月曜は胸の日」のような、繰り返し行う種目の組み合わせ
+type Routine struct {
+	ID          int    `json:"id"`
+	UserID      int    `json:"user_id"`
+	Name        string `json:"name"`
+	ExerciseIDs []int  `json:"exercise_ids"`
+}
+
+// Validate はルーティンの値が正しいかを検証する
+func (r *Routine) Validate() error {
+	var v validator
+	v.check(r.UserID > 0, "user_id", "must be positive")
+	v.check(r.Name != "", "name", "is required")
+	v.check(len(r.ExerciseIDs) > 0, "exercise_ids", "at least one exercise is required")
+	return v.err()
+}
@@ -0,0 +1,61 @@
+// Package apperror はテーマ07（カスタムエラーの実装）のHTTPステータス付きエラー
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// AppError はHTTPステータスコード付きのエラー
+type AppError struct {
+	Code    int
+	Message string
+	Detail  string
+}
+
+func (e *AppError) Error() string {
+	return fmt.Sprintf("[%d] %s: %s", e.Code, e.Message, e.Detail)
+}
+
+func NewBadRequestError(detail string) *AppError {
+	return &AppError{Code: 400, Message: "Bad Request", Detail: detail}
+}
+
+func NewNotFoundError(detail string) *AppError {
+	return &AppError{Code: 404, Message: "Not Found", Detail: detail}
+}
+
+func NewInternalError(detail string) *AppError {
+	return &AppError{Code: 500, Message: "Internal Server Error", Detail: detail}
+}
+
+// Catalog はIDから商品名を引く
+type Catalog map[int]string
+
+// GetProduct はidの商品名を返す。idが不正なら400、見つからなければ404のAppError
+func (c Catalog) GetProduct(id int) (string, error) {
+	if id <= 0 {
+		return "", NewBadRequestError(fmt.Sprintf("invalid product id: %d", id))
+	}
+	name, ok := c[id]
+	if !ok {
+		return "", NewNotFoundError(fmt.Sprintf("product id=%d", id))
+	}
+	return name, nil
+}
+
+// HandleRequest は商品を取得し、結果をHTTPのレスポンス風にwに書く
+func (c Catalog) HandleRequest(w io.Writer, productID int) {
+	product, err := c.GetProduct(productID)
+	if err != nil {
+		var appErr *AppError
+		if errors.As(err, &appErr) {
+			fmt.Fprintf(w, "HTTP %d: %s (%s)\n", appErr.Code, appErr.Message, appErr.Detail)
+		} else {
+			fmt.Fprintf(w, "HTTP 500: %v\n", err)
+		}
+		return
+	}
+	fmt.Fprintf(w, "HTTP 200: %s\n", product)
+}
@@ -0,0 +1,59 @@
+package apperror_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"workout/lessons/apperror"
+)
+
+func TestConstructors(t *testing.T) {
+	tests := []struct {
+		err  *apperror.AppError
+		code int
+		want string
+	}{
+		{apperror.NewBadRequestError("bad id"), 400, "[400] Bad Request: bad id"},
+		{apperror.NewNotFoundError("user 1"), 404, "[404] Not Found: user 1"},
+		{apperror.NewInternalError("db down"), 500, "[500] Internal Server Error: db down"},
+	}
+	for _, tt := range tests {
+		if tt.err.Code != tt.code || tt.err.Error() != tt.want {
+			t.Errorf("got %d %q, want %d %q", tt.err.Code, tt.err.Error(), tt.code, tt.want)
+		}
+	}
+}
+
+func TestCatalog(t *testing.T) {
+	catalog := apperror.Catalog{1: "ノートPC"}
+	tests := []struct {
+		name     string
+		id       int
+		wantCode int // 0なら成功
+		wantOut  string
+	}{
+		{name: "found", id: 1, wantOut: "HTTP 200: ノートPC\n"},
+		{name: "not found", id: 2, wantCode: 404, wantOut: "HTTP 404: Not Found (product id=2)\n"},
+		{name: "zero id", id: 0, wantCode: 400, wantOut: "HTTP 400: Bad Request (invalid product id: 0)\n"},
+		{name: "negative id", id: -5, wantCode: 400, wantOut: "HTTP 400: Bad Request (invalid product id: -5)\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := catalog.GetProduct(tt.id)
+			var appErr *apperror.AppError
+			switch {
+			case tt.wantCode == 0 && err != nil:
+				t.Fatalf("GetProduct: %v", err)
+			case tt.wantCode != 0 && (!errors.As(err, &appErr) || appErr.Code != tt.wantCode):
+				t.Fatalf("err = %v, want AppError with code %d", err, tt.wantCode)
+			}
+
+			var buf bytes.Buffer
+			catalog.HandleRequest(&buf, tt.id)
+			if buf.String() != tt.wantOut {
+				t.Errorf("HandleRequest wrote %q, want %q", buf.String(), tt.wantOut)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+// Package bank はテーマ02（ポインタの基本）の銀行口座
+package bank
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BankAccount は残高を持つ口座。メソッドはポインタレシーバーで呼び出し元の残高を変える
+type BankAccount struct {
+	Owner   string
+	Balance int
+}
+
+// Deposit はamountを入金する
+func (a *BankAccount) Deposit(amount int) error {
+	if amount <= 0 {
+		return errors.New("deposit amount must be positive")
+	}
+	a.Balance += amount
+	return nil
+}
+
+// Withdraw はamountを出金する。残高不足ならエラーで、残高は変わらない
+func (a *BankAccount) Withdraw(amount int) error {
+	if amount <= 0 {
+		return errors.New("withdrawal amount must be positive")
+	}
+	if amount > a.Balance {
+		return fmt.Errorf("insufficient funds: have %d, want %d", a.Balance, amount)
+	}
+	a.Balance -= amount
+	return nil
+}
+
+// Transfer はtoへamountを送金する。失敗したらどちらの残高も変わらない
+func (a *BankAccount) Transfer(to *BankAccount, amount int) error {
+	if err := a.Withdraw(amount); err != nil {
+		return fmt.Errorf("transfer failed: %w", err)
+	}
+	if err := to.Deposit(amount); err != nil {
+		// 出金を戻す
+		a.Balance += amount
+		return fmt.Errorf("transfer failed: %w", err)
+	}
+	return nil
+}
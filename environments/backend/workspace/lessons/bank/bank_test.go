@@ -0,0 +1,70 @@
+package bank_test
+
+import (
+	"strings"
+	"testing"
+
+	"workout/lessons/bank"
+)
+
+func TestDepositWithdraw(t *testing.T) {
+	tests := []struct {
+		name        string
+		op          func(a *bank.BankAccount) error
+		wantBalance int
+		wantErr     string
+	}{
+		{name: "deposit", op: func(a *bank.BankAccount) error { return a.Deposit(500) }, wantBalance: 1500},
+		{name: "deposit zero", op: func(a *bank.BankAccount) error { return a.Deposit(0) }, wantBalance: 1000, wantErr: "deposit amount must be positive"},
+		{name: "withdraw", op: func(a *bank.BankAccount) error { return a.Withdraw(300) }, wantBalance: 700},
+		{name: "withdraw all", op: func(a *bank.BankAccount) error { return a.Withdraw(1000) }, wantBalance: 0},
+		{name: "withdraw negative", op: func(a *bank.BankAccount) error { return a.Withdraw(-1) }, wantBalance: 1000, wantErr: "withdrawal amount must be positive"},
+		{name: "insufficient funds", op: func(a *bank.BankAccount) error { return a.Withdraw(1001) }, wantBalance: 1000, wantErr: "insufficient funds: have 1000, want 1001"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &bank.BankAccount{Owner: "Alice", Balance: 1000}
+			err := tt.op(a)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+			if a.Balance != tt.wantBalance {
+				t.Errorf("Balance = %d, want %d", a.Balance, tt.wantBalance)
+			}
+		})
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	tests := []struct {
+		name             string
+		amount           int
+		wantFrom, wantTo int
+		wantErr          string
+	}{
+		{name: "success", amount: 400, wantFrom: 600, wantTo: 600},
+		{name: "insufficient funds", amount: 2000, wantFrom: 1000, wantTo: 200, wantErr: "insufficient funds"},
+		{name: "zero amount", amount: 0, wantFrom: 1000, wantTo: 200, wantErr: "must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := &bank.BankAccount{Owner: "Alice", Balance: 1000}
+			to := &bank.BankAccount{Owner: "Bob", Balance: 200}
+			err := from.Transfer(to, tt.amount)
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Transfer: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.HasPrefix(err.Error(), "transfer failed: ") ||
+				!strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("err = %v, want transfer failed: ...%s", err, tt.wantErr)
+			}
+			// 失敗したらどちらの残高も変わらない
+			if from.Balance != tt.wantFrom || to.Balance != tt.wantTo {
+				t.Errorf("balances = %d, %d; want %d, %d", from.Balance, to.Balance, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+// Package batch はテーマ10（panic/recoverの理解）の安全なバッチ処理
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"workout/lessons/logger"
+)
+
+// Job はバッチの1つのジョブ
+// FnContextがあればFnの代わりに呼ぶ。ctxからジョブ名などの付いたLoggerを取り出せる
+type Job struct {
+	Name      string
+	Fn        func() error
+	FnContext func(ctx context.Context) error
+}
+
+// PanicError はジョブのpanicをrecoverしたエラー
+type PanicError struct {
+	Value any // recoverした値
+}
+
+func (e *PanicError) Error() string { return fmt.Sprintf("panic: %v", e.Value) }
+
+// RunJob は1つのジョブを実行する。panicしたらrecoverして*PanicErrorを返す
+func RunJob(job Job) error {
+	return RunJobContext(context.Background(), job)
+}
+
+// RunJobContext はctxを渡してRunJobと同じようにジョブを実行する
+func RunJobContext(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r}
+		}
+	}()
+	if job.FnContext != nil {
+		return job.FnContext(ctx)
+	}
+	return job.Fn()
+}
+
+// BatchResult はRunBatchの結果
+type BatchResult struct {
+	Success int
+	Failed  int // Panicsを含む
+	Panics  int
+	Errors  []string // "ジョブ名: エラー"
+}
+
+// RunBatch はjobsを順に実行し、進み具合をwに書く（nilなら書かない）
+// panicしたジョブも失敗として数え、次のジョブに進む
+func RunBatch(w io.Writer, jobs []Job) BatchResult {
+	if w == nil {
+		w = io.Discard
+	}
+	result := BatchResult{}
+
+	for _, job := range jobs {
+		fmt.Fprintf(w, "実行中: %s ... ", job.Name)
+		err := RunJob(job)
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", job.Name, err))
+			var perr *PanicError
+			if errors.As(err, &perr) {
+				result.Panics++
+			}
+			fmt.Fprintln(w, "失敗")
+		} else {
+			result.Success++
+			fmt.Fprintln(w, "成功")
+		}
+	}
+
+	return result
+}
+
+// RunBatchContext はRunBatchと同じようにjobsを実行し、進み具合をctxのLogger（logger.FromContext）に書く
+// 全てのログにbatch_id、ジョブのログにはjobが付くので、HTTPのリクエストから起動した場合は
+// request_idと合わせて、どのリクエストのどのジョブのログかを追える
+func RunBatchContext(ctx context.Context, jobs []Job) BatchResult {
+	batchLog := logger.FromContext(ctx).With(logger.F("batch_id", logger.NewID()))
+	batchLog.Info("batch started", logger.F("jobs", len(jobs)))
+	result := BatchResult{}
+
+	for _, job := range jobs {
+		jobLog := batchLog.With(logger.F("job", job.Name))
+		start := time.Now()
+		err := RunJobContext(logger.NewContext(ctx, jobLog), job)
+		elapsed := logger.F("duration", time.Since(start))
+		if err != nil {
+			result.Failed++
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", job.Name, err))
+			var perr *PanicError
+			if errors.As(err, &perr) {
+				result.Panics++
+				jobLog.Error("job panicked", logger.F("panic", fmt.Sprint(perr.Value)), elapsed)
+			} else {
+				jobLog.Error("job failed", logger.Err(err), elapsed)
+			}
+		} else {
+			result.Success++
+			jobLog.Info("job succeeded", elapsed)
+		}
+	}
+
+	batchLog.Info("batch finished",
+		logger.F("success", result.Success), logger.F("failed", result.Failed), logger.F("panics", result.Panics))
+	return result
+}
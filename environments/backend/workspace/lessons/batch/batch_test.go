@@ -0,0 +1,188 @@
+package batch_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"workout/lessons/batch"
+	"workout/lessons/logger"
+)
+
+func TestRunJob(t *testing.T) {
+	errFailed := errors.New("failed")
+	tests := []struct {
+		name      string
+		job       batch.Job
+		wantErr   error
+		wantPanic any
+	}{
+		{name: "success", job: batch.Job{Fn: func() error { return nil }}},
+		{name: "error", job: batch.Job{Fn: func() error { return errFailed }}, wantErr: errFailed},
+		{name: "panic with string", job: batch.Job{Fn: func() error { panic("boom") }}, wantPanic: "boom"},
+		{name: "panic with error", job: batch.Job{Fn: func() error { panic(errFailed) }}, wantPanic: errFailed},
+		{
+			name: "nil map write",
+			job: batch.Job{Fn: func() error {
+				var m map[string]int
+				m["x"] = 1
+				return nil
+			}},
+			wantPanic: "assignment to entry in nil map",
+		},
+		{
+			name:    "FnContext is preferred",
+			job:     batch.Job{Fn: func() error { panic("Fn must not run") }, FnContext: func(context.Context) error { return errFailed }},
+			wantErr: errFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := batch.RunJob(tt.job)
+			if tt.wantPanic == nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			var perr *batch.PanicError
+			if !errors.As(err, &perr) {
+				t.Fatalf("err = %v, want *PanicError", err)
+			}
+			if s, ok := tt.wantPanic.(string); ok {
+				if !strings.Contains(perr.Error(), s) {
+					t.Errorf("Error() = %q, want it to contain %q", perr.Error(), s)
+				}
+			} else if perr.Value != tt.wantPanic {
+				t.Errorf("Value = %v, want %v", perr.Value, tt.wantPanic)
+			}
+		})
+	}
+}
+
+func jobs() []batch.Job {
+	return []batch.Job{
+		{Name: "ok", Fn: func() error { return nil }},
+		{Name: "fail", Fn: func() error { return errors.New("disk full") }},
+		{Name: "panic", Fn: func() error { panic("index out of range") }},
+		{Name: "ok2", Fn: func() error { return nil }},
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	tests := []struct {
+		name string
+		jobs []batch.Job
+		want batch.BatchResult
+	}{
+		{name: "no jobs", want: batch.BatchResult{}},
+		{
+			// panicしたジョブも失敗として数え、後のジョブも実行する
+			name: "mixed",
+			jobs: jobs(),
+			want: batch.BatchResult{Success: 2, Failed: 2, Panics: 1,
+				Errors: []string{"fail: disk full", "panic: panic: index out of range"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			got := batch.RunBatch(&buf, tt.jobs)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if lines := strings.Count(buf.String(), "\n"); lines != len(tt.jobs) {
+				t.Errorf("wrote %d progress lines, want %d:\n%s", lines, len(tt.jobs), buf.String())
+			}
+		})
+	}
+
+	t.Run("nil writer", func(t *testing.T) {
+		if got := batch.RunBatch(nil, jobs()); got.Success != 2 {
+			t.Errorf("got %+v", got)
+		}
+	})
+}
+
+func TestRunParallel(t *testing.T) {
+	want := batch.BatchResult{Success: 2, Failed: 2, Panics: 1,
+		Errors: []string{"fail: disk full", "panic: panic: index out of range"}}
+	for _, workers := range []int{0, 1, 3, 10} {
+		t.Run(strconv.Itoa(workers), func(t *testing.T) {
+			var running, maxRunning atomic.Int32
+			js := jobs()
+			for i, job := range js {
+				js[i].Fn = func() error {
+					n := running.Add(1)
+					defer running.Add(-1)
+					for {
+						m := maxRunning.Load()
+						if n <= m || maxRunning.CompareAndSwap(m, n) {
+							break
+						}
+					}
+					time.Sleep(5 * time.Millisecond)
+					return job.Fn()
+				}
+			}
+			// エラーの一覧は終わった順ではなくジョブの順に並ぶ
+			if got := batch.RunParallel(js, workers); !reflect.DeepEqual(got, want) {
+				t.Errorf("got %+v, want %+v", got, want)
+			}
+			if limit := max(workers, 1); maxRunning.Load() > int32(limit) {
+				t.Errorf("%d jobs ran at once, want at most %d", maxRunning.Load(), limit)
+			}
+		})
+	}
+}
+
+func TestRunBatchContextLogs(t *testing.T) {
+	var buf bytes.Buffer
+	log := logger.New(&buf, logger.Options{Encoder: logger.JSONEncoder{}})
+	ctx := logger.NewContext(logger.WithRequestID(context.Background(), "req-1"), log)
+
+	var jobLogged bool
+	all := append(jobs(), batch.Job{Name: "ctx", FnContext: func(ctx context.Context) error {
+		logger.FromContext(ctx).Info("inside")
+		jobLogged = true
+		return nil
+	}})
+	got := batch.RunBatchContext(ctx, all)
+	if got.Success != 3 || got.Failed != 2 || got.Panics != 1 {
+		t.Fatalf("got %+v", got)
+	}
+	if !jobLogged {
+		t.Fatal("FnContext was not called")
+	}
+
+	var batchID string
+	var msgs []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var m map[string]any
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		// 全てのログに同じbatch_idとリクエストIDが付く
+		if batchID == "" {
+			batchID, _ = m["batch_id"].(string)
+		}
+		if m["batch_id"] != batchID || m["request_id"] != "req-1" {
+			t.Errorf("line %s: batch_id/request_id missing", line)
+		}
+		msgs = append(msgs, m["msg"].(string))
+		if m["msg"] == "inside" && m["job"] != "ctx" {
+			t.Errorf("job log without job field: %s", line)
+		}
+	}
+	want := []string{"batch started", "job succeeded", "job failed", "job panicked", "job succeeded", "inside", "job succeeded", "batch finished"}
+	if !reflect.DeepEqual(msgs, want) {
+		t.Errorf("messages = %q, want %q", msgs, want)
+	}
+}
@@ -0,0 +1,58 @@
+package batch
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// RunParallel はテーマ13（goroutineとWaitGroup）のRunBatch。jobsを最大workers個ずつ並行に実行する
+// 結果の集計はMutexで守り、エラーの一覧はジョブの順に並べ直す
+// recoverは同じgoroutineでしか効かないので、RunJobはgoroutineの中で呼ぶ
+func RunParallel(jobs []Job, workers int) BatchResult {
+	if workers <= 0 {
+		workers = 1
+	}
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result BatchResult
+		errs   = make(map[int]string)
+	)
+	sem := make(chan struct{}, workers) // 同時に動くgoroutineの数を制限する
+
+	for i, job := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			err := RunJob(job)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				result.Success++
+				return
+			}
+			result.Failed++
+			if errors.As(err, new(*PanicError)) {
+				result.Panics++
+			}
+			errs[i] = fmt.Sprintf("%s: %v", job.Name, err)
+		}()
+	}
+	wg.Wait()
+
+	keys := make([]int, 0, len(errs))
+	for i := range errs {
+		keys = append(keys, i)
+	}
+	sort.Ints(keys)
+	for _, i := range keys {
+		result.Errors = append(result.Errors, errs[i])
+	}
+	return result
+}
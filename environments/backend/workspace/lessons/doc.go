@@ -0,0 +1,22 @@
+// Package lessons はPhase 3のテーマの解答例を、他のパッケージからimportできるライブラリにしたもの
+//
+// 型・関数はテーマごとのサブパッケージに置き、標準出力に書いていた関数はio.Writerを受け取る。
+// solutions/phase3-backend-basic/<テーマ>/advanced/main.go はそれを使うデモで、
+// workbook check -v 12/advanced のように実行できる。同じデモは cmd/lessons/<テーマ>/ にもあり、
+// go run ./cmd/lessons/12-io-reader-writer のように実行できる
+//
+//	product  01 構造体と埋め込み
+//	bank     02 ポインタの基本
+//	task     03 メソッドとレシーバー
+//	notify   04 Interfaceの基本
+//	payment  05 Interfaceの応用
+//	signup   06 エラーハンドリングの基本
+//	apperror 07 カスタムエラーの実装
+//	orders   08 errors.Is/Asの活用
+//	tx       09 defer文の活用（17 contextと28 database/sqlのExecuteInTxも）
+//	batch    10 panic/recoverの理解（13 goroutineとWaitGroupのRunParallelも）
+//	todo     11 encoding/jsonと構造体タグ
+//	logger   12 io.Reader/io.Writerの理解
+//	stream   12 io.Reader/io.Writerの理解（Stageを組み合わせるストリーム処理）
+//	fakesql  27・28 database/sql（解答例を動かすためのメモリ上のドライバー）
+package lessons
@@ -0,0 +1,141 @@
+// Package fakesql はテーマ27・28（database/sql）の解答例を動かすための最小のdatabase/sqlドライバー
+// 本番ではPostgreSQLのドライバー（pgxなど）を使う。ここではSQLを解釈せず、
+// SQLの文字列ごとに登録したGoの関数を呼んで、メモリ上のデータを読み書きする
+package fakesql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// QueryFunc は1つのSQLの処理。argsはプレースホルダ（?）に渡された値
+type QueryFunc func(args []driver.Value) (*Result, error)
+
+// Result はQueryFuncの結果。Queryなら列と行を、Execなら件数とIDを返す
+type Result struct {
+	Columns  []string
+	Rows     [][]driver.Value
+	Affected int64
+	LastID   int64
+}
+
+func (r *Result) LastInsertId() (int64, error) { return r.LastID, nil }
+func (r *Result) RowsAffected() (int64, error) { return r.Affected, nil }
+
+// DB はdriver.Connector。sql.OpenDB(db)でsql.DBを作る
+// Queriesのキーは空白を1つにそろえたSQL
+type DB struct {
+	Queries  map[string]QueryFunc
+	Snapshot func() (restore func()) // トランザクションの開始時に呼び、ロールバックでrestoreする
+
+	mu       sync.Mutex
+	prepares int
+}
+
+// Prepares はPrepareされた回数を返す
+func (d *DB) Prepares() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.prepares
+}
+
+func (d *DB) Connect(context.Context) (driver.Conn, error) {
+	return &conn{db: d}, nil
+}
+
+func (d *DB) Driver() driver.Driver { return fakeDriver{d} }
+
+type fakeDriver struct{ db *DB }
+
+func (fd fakeDriver) Open(string) (driver.Conn, error) { return fd.db.Connect(context.Background()) }
+
+type conn struct {
+	db      *DB
+	restore func()
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	fn, ok := c.db.Queries[strings.Join(strings.Fields(query), " ")]
+	if !ok {
+		return nil, fmt.Errorf("fakedb: unknown query %q", query)
+	}
+	c.db.prepares++
+	return &stmt{db: c.db, fn: fn}, nil
+}
+
+func (c *conn) Close() error { return nil }
+
+func (c *conn) Begin() (driver.Tx, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	if c.db.Snapshot != nil {
+		c.restore = c.db.Snapshot()
+	}
+	return &tx{c}, nil
+}
+
+type tx struct{ c *conn }
+
+func (t *tx) Commit() error {
+	t.c.restore = nil
+	return nil
+}
+
+func (t *tx) Rollback() error {
+	t.c.db.mu.Lock()
+	defer t.c.db.mu.Unlock()
+	if t.c.restore != nil {
+		t.c.restore()
+		t.c.restore = nil
+	}
+	return nil
+}
+
+type stmt struct {
+	db *DB
+	fn QueryFunc
+}
+
+func (s *stmt) Close() error  { return nil }
+func (s *stmt) NumInput() int { return -1 }
+
+func (s *stmt) run(args []driver.Value) (*Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	return s.fn(args)
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.run(args)
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	r, err := s.run(args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{r: r}, nil
+}
+
+type rows struct {
+	r *Result
+	i int
+}
+
+func (r *rows) Columns() []string { return r.r.Columns }
+func (r *rows) Close() error      { return nil }
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.i >= len(r.r.Rows) {
+		return io.EOF
+	}
+	copy(dest, r.r.Rows[r.i])
+	r.i++
+	return nil
+}
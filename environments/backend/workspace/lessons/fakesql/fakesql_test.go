@@ -0,0 +1,125 @@
+package fakesql_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+
+	"workout/lessons/fakesql"
+)
+
+// newCounterDB はcountersテーブル（name→value）を持つDB。トランザクションはロールバックで元に戻る
+func newCounterDB(counters map[string]int64) *fakesql.DB {
+	return &fakesql.DB{
+		Snapshot: func() func() {
+			saved := maps.Clone(counters)
+			return func() { clear(counters); maps.Copy(counters, saved) }
+		},
+		Queries: map[string]fakesql.QueryFunc{
+			"SELECT value FROM counters WHERE name = ?": func(args []driver.Value) (*fakesql.Result, error) {
+				r := &fakesql.Result{Columns: []string{"value"}}
+				if v, ok := counters[args[0].(string)]; ok {
+					r.Rows = append(r.Rows, []driver.Value{v})
+				}
+				return r, nil
+			},
+			"UPDATE counters SET value = value + 1 WHERE name = ?": func(args []driver.Value) (*fakesql.Result, error) {
+				name := args[0].(string)
+				if _, ok := counters[name]; !ok {
+					return &fakesql.Result{}, nil
+				}
+				counters[name]++
+				return &fakesql.Result{Affected: 1}, nil
+			},
+		},
+	}
+}
+
+func TestQueryAndExec(t *testing.T) {
+	counters := map[string]int64{"visits": 1}
+	fake := newCounterDB(counters)
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	tests := []struct {
+		name         string
+		counter      string
+		wantAffected int64
+		want         int64
+		wantErr      error
+	}{
+		{name: "existing row", counter: "visits", wantAffected: 1, want: 2},
+		{name: "missing row", counter: "likes", wantAffected: 0, wantErr: sql.ErrNoRows},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// SQLの空白の違いは無視する
+			res, err := db.Exec("UPDATE counters\n\tSET value = value + 1\n\tWHERE name = ?", tt.counter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n, _ := res.RowsAffected(); n != tt.wantAffected {
+				t.Errorf("RowsAffected = %d, want %d", n, tt.wantAffected)
+			}
+			var got int64
+			err = db.QueryRow("SELECT value FROM counters WHERE name = ?", tt.counter).Scan(&got)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("got %d, %v; want %d, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+	if fake.Prepares() != 4 {
+		t.Errorf("Prepares = %d, want 4", fake.Prepares())
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	tests := []struct {
+		name   string
+		commit bool
+		want   int64
+	}{
+		{name: "commit keeps changes", commit: true, want: 2},
+		{name: "rollback restores snapshot", commit: false, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counters := map[string]int64{"visits": 0}
+			db := sql.OpenDB(newCounterDB(counters))
+			defer db.Close()
+
+			tx, err := db.Begin()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for range 2 {
+				if _, err := tx.Exec("UPDATE counters SET value = value + 1 WHERE name = ?", "visits"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.commit {
+				err = tx.Commit()
+			} else {
+				err = tx.Rollback()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if counters["visits"] != tt.want {
+				t.Errorf("visits = %d, want %d", counters["visits"], tt.want)
+			}
+		})
+	}
+}
+
+func TestUnknownQuery(t *testing.T) {
+	db := sql.OpenDB(&fakesql.DB{})
+	defer db.Close()
+	_, err := db.Exec("DELETE FROM users")
+	if err == nil || !strings.Contains(err.Error(), `unknown query "DELETE FROM users"`) {
+		t.Errorf("err = %v", err)
+	}
+}
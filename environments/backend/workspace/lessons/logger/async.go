@@ -0,0 +1,204 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// DropPolicy はAsyncWriterのバッファがいっぱいのときの動作
+type DropPolicy int
+
+const (
+	// Block は空きができるまでWriteを待たせる（ログは失わないが、書き込みが遅いと呼び出し元も遅くなる）
+	Block DropPolicy = iota
+	// DropNewest は書こうとしたログを捨てる
+	DropNewest
+	// DropOldest はバッファの一番古いログを捨てて、新しいログを入れる
+	DropOldest
+)
+
+// AsyncOptions はAsyncWriterの動作設定
+type AsyncOptions struct {
+	BufferSize int // バッファに溜めるWriteの回数（0なら1024）
+	Policy     DropPolicy
+}
+
+// AsyncWriter はWriteをリングバッファに溜め、別のgoroutineで出力先に書くio.Writer
+// Loggerは1件のログを1回のWriteで書くので、バッファの1要素が1件のログになる
+//
+//	f, _ := logger.OpenRotatingFile("app.log", logger.RotateOptions{MaxSize: 10 << 20})
+//	w := logger.NewAsyncWriter(f, logger.AsyncOptions{Policy: logger.DropOldest})
+//	defer w.Close()
+//	log := logger.New(w, logger.Options{AddTime: true})
+type AsyncWriter struct {
+	out    io.Writer
+	policy DropPolicy
+
+	mu      sync.Mutex
+	cond    *sync.Cond // バッファ・書き込み中・closedの変化を知らせる
+	buf     [][]byte   // リングバッファ
+	head    int        // 一番古い要素の位置
+	count   int
+	writing bool // バックグラウンドのgoroutineが出力先に書いている途中
+	closed  bool
+	dropped int64 // 捨てたログの数
+	err     error // 出力先への書き込みで最初に起きたエラー（Flush・Closeで返す）
+	done    chan struct{}
+}
+
+// ErrWriterClosed はCloseした後のAsyncWriterにWriteした場合のエラー
+var ErrWriterClosed = errors.New("logger: async writer closed")
+
+// NewAsyncWriter はwに非同期で書くAsyncWriterを作り、書き込み用のgoroutineを起動する
+// 使い終わったら必ずCloseする
+func NewAsyncWriter(w io.Writer, opts AsyncOptions) *AsyncWriter {
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 1024
+	}
+	a := &AsyncWriter{
+		out:    w,
+		policy: opts.Policy,
+		buf:    make([][]byte, opts.BufferSize),
+		done:   make(chan struct{}),
+	}
+	a.cond = sync.NewCond(&a.mu)
+	go a.run()
+	return a
+}
+
+// Write はpのコピーをバッファに入れてすぐに戻る。出力先のエラーはここでは返らない
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return 0, ErrWriterClosed
+	}
+	if a.count == len(a.buf) {
+		switch a.policy {
+		case DropNewest:
+			a.dropped++
+			return len(p), nil
+		case DropOldest:
+			a.buf[a.head] = nil
+			a.head = (a.head + 1) % len(a.buf)
+			a.count--
+			a.dropped++
+		default:
+			for a.count == len(a.buf) && !a.closed {
+				a.cond.Wait()
+			}
+			if a.closed {
+				return 0, ErrWriterClosed
+			}
+		}
+	}
+	// pは呼び出し元が再利用するかもしれないのでコピーする
+	a.buf[(a.head+a.count)%len(a.buf)] = append([]byte(nil), p...)
+	a.count++
+	a.cond.Broadcast()
+	return len(p), nil
+}
+
+// run はバッファに溜まったログをまとめて取り出して出力先に書く
+func (a *AsyncWriter) run() {
+	defer close(a.done)
+	batch := make([][]byte, 0, len(a.buf))
+	a.mu.Lock()
+	for {
+		for a.count == 0 && !a.closed {
+			a.cond.Wait()
+		}
+		if a.count == 0 && a.closed {
+			a.mu.Unlock()
+			return
+		}
+		for a.count > 0 {
+			batch = append(batch, a.buf[a.head])
+			a.buf[a.head] = nil
+			a.head = (a.head + 1) % len(a.buf)
+			a.count--
+		}
+		a.writing = true
+		a.cond.Broadcast() // Blockで待っているWriteを起こす
+		a.mu.Unlock()
+
+		var err error
+		for _, p := range batch {
+			if _, werr := a.out.Write(p); werr != nil && err == nil {
+				err = werr
+			}
+		}
+		clear(batch)
+		batch = batch[:0]
+
+		a.mu.Lock()
+		if err != nil && a.err == nil {
+			a.err = err
+		}
+		a.writing = false
+		a.cond.Broadcast() // Flushを起こす
+	}
+}
+
+// Flush はそれまでにWriteしたログを全て出力先に書き終えるまで待つ
+// 出力先にSyncがあれば（*os.File、RotatingFile）ディスクにも書き出す
+// 前回のFlush以降に起きた書き込みのエラーを返す。Closeした後に呼んでも何もしない
+func (a *AsyncWriter) Flush() error {
+	if a.isStopped() {
+		return nil
+	}
+	return a.flush()
+}
+
+func (a *AsyncWriter) flush() error {
+	a.mu.Lock()
+	for (a.count > 0 || a.writing) && !a.isStopped() {
+		a.cond.Wait()
+	}
+	err := a.err
+	a.err = nil
+	a.mu.Unlock()
+
+	if s, ok := a.out.(interface{ Sync() error }); ok {
+		err = errors.Join(err, s.Sync())
+	}
+	return err
+}
+
+// isStopped はバックグラウンドのgoroutineが終了したかを返す
+func (a *AsyncWriter) isStopped() bool {
+	select {
+	case <-a.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close は残りのログを書き終えてからgoroutineを止め、出力先がio.Closerなら閉じる
+// deferで何度呼んでもよく、2回目以降はnilを返す
+func (a *AsyncWriter) Close() error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return nil
+	}
+	a.closed = true
+	a.cond.Broadcast()
+	a.mu.Unlock()
+
+	<-a.done
+	err := a.flush()
+	if c, ok := a.out.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
+
+// Dropped はバッファがいっぱいで捨てたログの数を返す
+func (a *AsyncWriter) Dropped() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.dropped
+}
@@ -0,0 +1,127 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"workout/lessons/logger"
+)
+
+// gate はreleaseを閉じるまでWriteを止める出力先
+type gate struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+	err     error
+	closed  bool
+}
+
+func newGate() *gate {
+	return &gate{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gate) Write(p []byte) (int, error) {
+	g.once.Do(func() { close(g.started) })
+	<-g.release
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.buf.Write(p)
+	return len(p), g.err
+}
+
+func (g *gate) Close() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.closed = true
+	return nil
+}
+
+func (g *gate) String() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.buf.String()
+}
+
+func TestAsyncWriterFlushAndClose(t *testing.T) {
+	out := newGate()
+	close(out.release)
+	w := logger.NewAsyncWriter(out, logger.AsyncOptions{BufferSize: 4})
+	l := logger.NewLogger(w, logger.INFO)
+	for i := range 10 {
+		l.Info(fmt.Sprint("line ", i))
+	}
+	if err := l.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	// Blockではログを失わず、順番も変わらない
+	var want strings.Builder
+	for i := range 10 {
+		fmt.Fprintf(&want, "[INFO] line %d\n", i)
+	}
+	if out.String() != want.String() {
+		t.Errorf("got\n%s", out.String())
+	}
+
+	if err := w.Close(); err != nil || !out.closed {
+		t.Fatalf("Close: %v, closed %v", err, out.closed)
+	}
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close: %v", err)
+	}
+	if _, err := w.Write([]byte("x")); !errors.Is(err, logger.ErrWriterClosed) {
+		t.Errorf("Write after Close: %v", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Errorf("Flush after Close: %v", err)
+	}
+}
+
+func TestAsyncWriterDropPolicy(t *testing.T) {
+	tests := []struct {
+		policy logger.DropPolicy
+		want   string
+	}{
+		// 1件目は書き込み中、2-3件目がバッファに入り、4-5件目で溢れる
+		{policy: logger.DropNewest, want: "1\n2\n3\n"},
+		{policy: logger.DropOldest, want: "1\n4\n5\n"},
+	}
+	for _, tt := range tests {
+		out := newGate()
+		w := logger.NewAsyncWriter(out, logger.AsyncOptions{BufferSize: 2, Policy: tt.policy})
+		w.Write([]byte("1\n"))
+		<-out.started
+		for _, s := range []string{"2\n", "3\n", "4\n", "5\n"} {
+			w.Write([]byte(s))
+		}
+		close(out.release)
+		w.Close()
+		if out.String() != tt.want || w.Dropped() != 2 {
+			t.Errorf("policy %d: got %q dropped %d, want %q dropped 2", tt.policy, out.String(), w.Dropped(), tt.want)
+		}
+	}
+}
+
+func TestAsyncWriterReportsWriteError(t *testing.T) {
+	out := newGate()
+	out.err = errors.New("disk full")
+	close(out.release)
+	w := logger.NewAsyncWriter(out, logger.AsyncOptions{})
+	defer w.Close()
+
+	// Writeはすぐ戻るので、出力先のエラーはFlushで受け取る
+	if _, err := w.Write([]byte("x\n")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Flush(); err == nil || err.Error() != "disk full" {
+		t.Errorf("Flush = %v, want disk full", err)
+	}
+	if err := w.Flush(); err != nil {
+		t.Errorf("second Flush = %v, want nil", err)
+	}
+}
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"sync/atomic"
+)
+
+type ctxKey int
+
+const (
+	loggerKey ctxKey = iota
+	requestIDKey
+	traceIDKey
+)
+
+var defaultLogger atomic.Pointer[Logger]
+
+func init() { defaultLogger.Store(New(os.Stderr, Options{AddTime: true})) }
+
+// Default はcontextにLoggerがない場合に使うLogger（SetDefaultしなければ標準エラー出力に書く）
+func Default() *Logger { return defaultLogger.Load() }
+
+// SetDefault はDefaultが返すLoggerをlにする（mainで出力先や形式を決めたときに使う）
+func SetDefault(l *Logger) { defaultLogger.Store(l) }
+
+// NewContext はlを持つcontextを返す
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext はctxのLogger（なければDefault）を返す
+// ctxにリクエストID・トレースIDがあれば、まだ付けていない場合に request_id・trace_id として付ける
+func FromContext(ctx context.Context) *Logger {
+	l, ok := ctx.Value(loggerKey).(*Logger)
+	if !ok {
+		l = Default()
+	}
+	return l.withContextIDs(ctx)
+}
+
+// withContextIDs はctxのリクエストID・トレースIDのうち、まだ付けていないものを付けたLoggerを返す
+func (l *Logger) withContextIDs(ctx context.Context) *Logger {
+	var fields []Field
+	if id := RequestID(ctx); id != "" && !l.hasField("request_id") {
+		fields = append(fields, F("request_id", id))
+	}
+	if id := TraceID(ctx); id != "" && !l.hasField("trace_id") {
+		fields = append(fields, F("trace_id", id))
+	}
+	if len(fields) == 0 {
+		return l
+	}
+	return l.With(fields...)
+}
+
+// WithRequestID はリクエストIDを持つcontextを返す
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID はctxのリクエストIDを返す（なければ空）
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithTraceID はトレースID（複数のサービスをまたいで同じ処理を追うID）を持つcontextを返す
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey, id)
+}
+
+// TraceID はctxのトレースIDを返す（なければ空）
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey).(string)
+	return id
+}
+
+// NewID はリクエストIDなどに使うランダムな16桁の16進数を返す
+func NewID() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,36 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"workout/lessons/logger"
+)
+
+func TestContext(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.New(&buf, logger.Options{})
+	ctx := logger.WithTraceID(logger.WithRequestID(context.Background(), "req-1"), "trace-1")
+	ctx = logger.NewContext(ctx, l)
+
+	logger.FromContext(ctx).Info("hello")
+	if want := "[INFO] hello request_id=req-1 trace_id=trace-1\n"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+
+	// 既に付けてあれば重ねて付けない
+	buf.Reset()
+	logger.FromContext(logger.NewContext(ctx, l.With(logger.F("request_id", "req-1")))).Info("again")
+	if strings.Count(buf.String(), "request_id") != 1 {
+		t.Errorf("got %q", buf.String())
+	}
+
+	if logger.FromContext(context.Background()) != logger.Default() {
+		t.Error("FromContext without a Logger should return Default()")
+	}
+	if id := logger.NewID(); len(id) != 16 || id == logger.NewID() {
+		t.Errorf("NewID() = %q", id)
+	}
+}
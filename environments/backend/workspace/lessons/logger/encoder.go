@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TimeFormat は全てのEncoderが使う時刻の形式（ミリ秒まで）
+const TimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// Encoder は1件のログをbufに書く（末尾の改行はLoggerが付ける）
+type Encoder interface {
+	Encode(buf *bytes.Buffer, e Entry)
+}
+
+// TextEncoder は人が読むための形式
+//
+//	2025-01-02T03:04:05.000Z [INFO] main.go:12 サーバー起動 port=8080
+//
+// 時刻・呼び出し元がなければ解答例と同じ "[INFO] サーバー起動" になる
+type TextEncoder struct{}
+
+func (TextEncoder) Encode(buf *bytes.Buffer, e Entry) {
+	if !e.Time.IsZero() {
+		buf.WriteString(e.Time.Format(TimeFormat))
+		buf.WriteByte(' ')
+	}
+	fmt.Fprintf(buf, "[%s] ", e.Level)
+	if e.Caller != "" {
+		buf.WriteString(e.Caller)
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(e.Message)
+	for _, f := range e.Fields {
+		buf.WriteByte(' ')
+		writeKeyValue(buf, f.Key, textValue(f.Value))
+	}
+}
+
+// LogfmtEncoder はkey=valueを並べる形式（grepしやすく、多くのログ基盤が読める）
+// 組み込みのキーと同じ名前のフィールドは "fields." を付けて書く（fieldKey）
+//
+//	time=2025-01-02T03:04:05.000Z level=INFO caller=main.go:12 msg="サーバー 起動" port=8080
+type LogfmtEncoder struct{}
+
+func (LogfmtEncoder) Encode(buf *bytes.Buffer, e Entry) {
+	if !e.Time.IsZero() {
+		writeKeyValue(buf, "time", e.Time.Format(TimeFormat))
+		buf.WriteByte(' ')
+	}
+	writeKeyValue(buf, "level", e.Level.String())
+	if e.Caller != "" {
+		buf.WriteByte(' ')
+		writeKeyValue(buf, "caller", e.Caller)
+	}
+	buf.WriteByte(' ')
+	writeKeyValue(buf, "msg", e.Message)
+	for _, f := range e.Fields {
+		buf.WriteByte(' ')
+		writeKeyValue(buf, fieldKey(f.Key), textValue(f.Value))
+	}
+}
+
+// JSONEncoder は1行に1つのJSONオブジェクトを書く形式（NDJSON）
+// フィールドは渡した順に並ぶ。組み込みのキーと同じ名前のフィールドは "fields." を付けて書く（fieldKey）
+//
+//	{"time":"2025-01-02T03:04:05.000Z","level":"INFO","caller":"main.go:12","msg":"サーバー起動","port":8080}
+type JSONEncoder struct{}
+
+func (JSONEncoder) Encode(buf *bytes.Buffer, e Entry) {
+	buf.WriteByte('{')
+	if !e.Time.IsZero() {
+		writeJSONField(buf, "time", e.Time.Format(TimeFormat))
+		buf.WriteByte(',')
+	}
+	writeJSONField(buf, "level", e.Level.String())
+	if e.Caller != "" {
+		buf.WriteByte(',')
+		writeJSONField(buf, "caller", e.Caller)
+	}
+	buf.WriteByte(',')
+	writeJSONField(buf, "msg", e.Message)
+	for _, f := range e.Fields {
+		buf.WriteByte(',')
+		writeJSONField(buf, fieldKey(f.Key), f.Value)
+	}
+	buf.WriteByte('}')
+}
+
+// reservedKeys はEncoderが時刻・レベルなどに使うキー
+var reservedKeys = []string{"time", "level", "caller", "msg"}
+
+// fieldKey はフィールドのキーを返す。組み込みのキーと同じなら "fields.msg" のようにする
+// （F("msg", ...)でJSONのキーが重複すると、読む側によってどちらの値になるかが変わる）
+func fieldKey(key string) string {
+	if slices.Contains(reservedKeys, key) {
+		return "fields." + key
+	}
+	return key
+}
+
+func writeJSONField(buf *bytes.Buffer, key string, value any) {
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+	buf.Write(jsonValue(value))
+}
+
+// jsonValue は値をJSONにする。errorやStringerは文字列にし、JSONにできない値はfmtの表記の文字列にする
+func jsonValue(v any) []byte {
+	switch x := v.(type) {
+	case error:
+		v = x.Error()
+	case json.Marshaler:
+		// time.Timeなどは自分のMarshalJSONを使う
+	case fmt.Stringer:
+		v = x.String()
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(fmt.Sprint(v))
+	}
+	return data
+}
+
+// textValue はtext/logfmtでの値の表記
+func textValue(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return x
+	case error:
+		return x.Error()
+	case time.Time:
+		return x.Format(TimeFormat)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func writeKeyValue(buf *bytes.Buffer, key, value string) {
+	buf.WriteString(key)
+	buf.WriteByte('=')
+	if needsQuote(value) {
+		buf.WriteString(strconv.Quote(value))
+	} else {
+		buf.WriteString(value)
+	}
+}
+
+// needsQuote は空白・=・"・制御文字を含む値か空文字列かを返す（そのままだとkey=valueの区切りが分からない）
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	return strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '=' || r == '"' || !unicode.IsPrint(r)
+	}) >= 0
+}
@@ -0,0 +1,107 @@
+package logger
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"strings"
+)
+
+// RequestIDHeader はリクエストIDを受け取り、レスポンスで返すヘッダー
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDRe は受け取ったリクエストIDとして使ってよい形式（ログを壊す文字や長すぎる値は使わない）
+var requestIDRe = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Middleware はリクエストごとのLoggerをcontextに入れ、アクセスログを書くミドルウェア（テーマ10のrecover・loggingミドルウェア）
+//
+//   - X-Request-IDヘッダーのIDを使い、なければ作ってレスポンスのヘッダーにも付ける
+//   - W3Cのtraceparentヘッダーがあれば、そのトレースIDも付ける
+//   - ハンドラーはlogger.FromContext(r.Context())で、IDの付いたLoggerを取り出せる
+//   - panicしたらrecoverしてスタックトレースをERRORで書き、500を返す
+//
+// api.Chainにそのまま渡せる
+func Middleware(l *Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := l.opts.Now()
+			id := r.Header.Get(RequestIDHeader)
+			if !requestIDRe.MatchString(id) {
+				id = NewID()
+			}
+			w.Header().Set(RequestIDHeader, id)
+
+			ctx := WithRequestID(r.Context(), id)
+			fields := []Field{F("request_id", id)}
+			if trace, ok := parseTraceparent(r.Header.Get("traceparent")); ok {
+				ctx = WithTraceID(ctx, trace)
+				fields = append(fields, F("trace_id", trace))
+			}
+			reqLog := l.With(fields...)
+			r = r.WithContext(NewContext(ctx, reqLog))
+
+			rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				if v := recover(); v != nil {
+					if v == http.ErrAbortHandler {
+						panic(v)
+					}
+					reqLog.Error("panic", F("panic", fmt.Sprint(v)), F("stack", string(debug.Stack())))
+					if !rec.wroteHeader {
+						http.Error(rec, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					}
+				}
+				fields := []Field{
+					F("method", r.Method),
+					F("path", r.URL.Path),
+					F("status", rec.status),
+					F("bytes", rec.bytes),
+					F("duration", l.opts.Now().Sub(start)),
+				}
+				if rec.status >= 500 {
+					reqLog.Error("request", fields...)
+				} else {
+					reqLog.Info("request", fields...)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+// parseTraceparent は "00-<32桁のトレースID>-<16桁の親ID>-<フラグ>" からトレースIDを取り出す
+func parseTraceparent(h string) (string, bool) {
+	parts := strings.Split(h, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || strings.Trim(parts[1], "0123456789abcdef") != "" || strings.Trim(parts[1], "0") == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// responseRecorder はアクセスログ用にステータスコードと書いたバイト数を記録する
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap はhttp.ResponseControllerが元のResponseWriterの機能（Flushなど）を使えるようにする
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
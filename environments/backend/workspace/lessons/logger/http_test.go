@@ -0,0 +1,111 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"workout/lessons/logger"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		header        http.Header
+		handler       http.HandlerFunc
+		wantStatus    int
+		wantRequestID string // 空なら新しく作ったID
+		wantTraceID   string
+		wantLevel     string
+	}{
+		{
+			name:          "keeps a valid request id",
+			header:        http.Header{"X-Request-Id": {"abc-123"}},
+			handler:       func(w http.ResponseWriter, r *http.Request) { w.Write([]byte("ok")) },
+			wantStatus:    200,
+			wantRequestID: "abc-123",
+			wantLevel:     "INFO",
+		},
+		{
+			name:       "replaces an unsafe request id",
+			header:     http.Header{"X-Request-Id": {"bad id\n"}},
+			handler:    func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) },
+			wantStatus: 204,
+			wantLevel:  "INFO",
+		},
+		{
+			name:        "trace id from traceparent",
+			header:      http.Header{"Traceparent": {"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}},
+			handler:     func(w http.ResponseWriter, r *http.Request) {},
+			wantStatus:  200,
+			wantTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			wantLevel:   "INFO",
+		},
+		{
+			name:       "invalid traceparent is ignored",
+			header:     http.Header{"Traceparent": {"00-00000000000000000000000000000000-00f067aa0ba902b7-01"}},
+			handler:    func(w http.ResponseWriter, r *http.Request) {},
+			wantStatus: 200,
+			wantLevel:  "INFO",
+		},
+		{
+			name:       "panic becomes 500",
+			handler:    func(w http.ResponseWriter, r *http.Request) { panic("boom") },
+			wantStatus: 500,
+			wantLevel:  "ERROR",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := logger.New(&buf, logger.Options{Encoder: logger.JSONEncoder{}})
+			var fromHandler string
+			h := logger.Middleware(l)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fromHandler = logger.RequestID(r.Context())
+				tt.handler(w, r)
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/users", nil)
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			id := rec.Header().Get(logger.RequestIDHeader)
+			if (tt.wantRequestID != "" && id != tt.wantRequestID) || len(id) == 0 || id != fromHandler {
+				t.Errorf("response id %q, handler id %q, want %q", id, fromHandler, tt.wantRequestID)
+			}
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			var access map[string]any
+			if err := json.Unmarshal([]byte(lines[len(lines)-1]), &access); err != nil {
+				t.Fatalf("access log %q: %v", lines[len(lines)-1], err)
+			}
+			if access["msg"] != "request" || access["level"] != tt.wantLevel || access["request_id"] != id ||
+				access["status"] != float64(tt.wantStatus) || access["path"] != "/users" {
+				t.Errorf("access log = %v", access)
+			}
+			if got, _ := access["trace_id"].(string); got != tt.wantTraceID {
+				t.Errorf("trace_id = %q, want %q", got, tt.wantTraceID)
+			}
+		})
+	}
+}
+
+func TestMiddlewareRepanicsAbortHandler(t *testing.T) {
+	h := logger.Middleware(logger.NewLogger(&bytes.Buffer{}, logger.INFO))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if r := recover(); r != http.ErrAbortHandler {
+			t.Errorf("recover() = %v, want ErrAbortHandler", r)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
@@ -0,0 +1,243 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"workout/lessons/logger"
+)
+
+var fixedTime = time.Date(2025, 1, 2, 3, 4, 5, 678_000_000, time.UTC)
+
+func fixedNow() time.Time { return fixedTime }
+
+func TestNewLoggerLevels(t *testing.T) {
+	tests := []struct {
+		level logger.LogLevel
+		want  string
+	}{
+		{logger.DEBUG, "[DEBUG] d\n[INFO] i\n[WARN] w\n[ERROR] e\n"},
+		{logger.INFO, "[INFO] i\n[WARN] w\n[ERROR] e\n"},
+		{logger.WARN, "[WARN] w\n[ERROR] e\n"},
+		{logger.ERROR, "[ERROR] e\n"},
+		{logger.FATAL, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			l := logger.NewLogger(&buf, tt.level)
+			l.Debug("d")
+			l.Info("i")
+			l.Warn("w")
+			l.Error("e")
+			if buf.String() != tt.want {
+				t.Errorf("got %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    logger.LogLevel
+		wantErr bool
+	}{
+		{in: "debug", want: logger.DEBUG},
+		{in: "INFO", want: logger.INFO},
+		{in: "Warn", want: logger.WARN},
+		{in: "error", want: logger.ERROR},
+		{in: "fatal", want: logger.FATAL},
+		{in: "", want: logger.INFO, wantErr: true},
+		{in: "warning", want: logger.INFO, wantErr: true},
+		{in: "UNKNOWN", want: logger.INFO, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := logger.ParseLevel(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// FuzzParseLevel は受け付けた文字列がレベル名と大文字小文字を除いて一致し、String()で元に戻ることを確かめる
+func FuzzParseLevel(f *testing.F) {
+	for _, s := range []string{"debug", "INFO", "wArN", "error", "fatal", "", "unknown", "ınfo"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		l, err := logger.ParseLevel(s)
+		if err != nil {
+			if l != logger.INFO {
+				t.Errorf("ParseLevel(%q) failed but returned %v, want INFO", s, l)
+			}
+			return
+		}
+		if !strings.EqualFold(l.String(), s) {
+			t.Errorf("ParseLevel(%q) = %v", s, l)
+		}
+		if again, err := logger.ParseLevel(l.String()); err != nil || again != l {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", l.String(), again, err, l)
+		}
+	})
+}
+
+func TestEncoders(t *testing.T) {
+	fields := []logger.Field{
+		logger.F("port", 8080),
+		logger.F("path", "/users list"),
+		logger.Err(errors.New("connection refused")),
+		logger.F("empty", ""),
+	}
+	tests := []struct {
+		name    string
+		encoder logger.Encoder
+		want    string
+	}{
+		{
+			name:    "text",
+			encoder: logger.TextEncoder{},
+			want:    `2025-01-02T03:04:05.678Z [WARN] logger_test.go:0 サーバー起動 port=8080 path="/users list" error="connection refused" empty=""`,
+		},
+		{
+			name:    "logfmt",
+			encoder: logger.LogfmtEncoder{},
+			want:    `time=2025-01-02T03:04:05.678Z level=WARN caller=logger_test.go:0 msg=サーバー起動 port=8080 path="/users list" error="connection refused" empty=""`,
+		},
+		{
+			name:    "json",
+			encoder: logger.JSONEncoder{},
+			want:    `{"time":"2025-01-02T03:04:05.678Z","level":"WARN","caller":"logger_test.go:0","msg":"サーバー起動","port":8080,"path":"/users list","error":"connection refused","empty":""}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.encoder.Encode(&buf, logger.Entry{
+				Time: fixedTime, Level: logger.WARN, Caller: "logger_test.go:0", Message: "サーバー起動", Fields: fields,
+			})
+			if buf.String() != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONEncoderValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"nil", nil, `null`},
+		{"duration is a Stringer", 1500 * time.Millisecond, `"1.5s"`},
+		{"time uses MarshalJSON", fixedTime, `"2025-01-02T03:04:05.678Z"`},
+		{"map", map[string]int{"a": 1}, `{"a":1}`},
+		{"unsupported value falls back to fmt", func() {}, `"0x`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger.JSONEncoder{}.Encode(&buf, logger.Entry{Message: "m", Fields: []logger.Field{logger.F("v", tt.value)}})
+			prefix := `{"level":"INFO","msg":"m","v":` + tt.want
+			if !strings.HasPrefix(buf.String(), prefix) {
+				t.Errorf("got %s, want prefix %s", buf.String(), prefix)
+			}
+		})
+	}
+}
+
+// 組み込みのキーと同じ名前のフィールドは、キーを重複させずに "fields." を付ける
+func TestReservedFieldKeys(t *testing.T) {
+	fields := []logger.Field{logger.F("msg", "user"), logger.F("level", 3), logger.F("time", "noon"), logger.F("caller", "x"), logger.F("port", 80)}
+	e := logger.Entry{Time: fixedTime, Level: logger.INFO, Caller: "main.go:1", Message: "m", Fields: fields}
+
+	var buf bytes.Buffer
+	logger.JSONEncoder{}.Encode(&buf, e)
+	var got map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"time": "2025-01-02T03:04:05.678Z", "level": "INFO", "caller": "main.go:1", "msg": "m",
+		"fields.msg": "user", "fields.level": 3.0, "fields.time": "noon", "fields.caller": "x", "port": 80.0,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("json got %v, want %v", got, want)
+	}
+	if n := strings.Count(buf.String(), `"msg":`); n != 1 {
+		t.Errorf("json has %d msg keys: %s", n, buf.String())
+	}
+
+	buf.Reset()
+	logger.LogfmtEncoder{}.Encode(&buf, e)
+	wantLogfmt := `time=2025-01-02T03:04:05.678Z level=INFO caller=main.go:1 msg=m fields.msg=user fields.level=3 fields.time=noon fields.caller=x port=80`
+	if buf.String() != wantLogfmt {
+		t.Errorf("logfmt got\n%s\nwant\n%s", buf.String(), wantLogfmt)
+	}
+}
+
+func TestOptionsAndWith(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.New(&buf, logger.Options{Level: logger.DEBUG, AddTime: true, AddCaller: true, Now: fixedNow, Encoder: logger.LogfmtEncoder{}})
+	reqLog := l.With(logger.F("request_id", "abc"))
+	reqLog.Debug("created", logger.F("user_id", 42))
+	l.Info("plain")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines: %q", len(lines), buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "time=2025-01-02T03:04:05.678Z level=DEBUG caller=logger_test.go:") ||
+		!strings.HasSuffix(lines[0], " msg=created request_id=abc user_id=42") {
+		t.Errorf("line 1 = %s", lines[0])
+	}
+	// Withは親のLoggerにフィールドを付けない
+	if strings.Contains(lines[1], "request_id") {
+		t.Errorf("line 2 = %s", lines[1])
+	}
+	if !l.Enabled(logger.DEBUG) || logger.NewLogger(&buf, logger.WARN).Enabled(logger.INFO) {
+		t.Error("Enabled does not follow Options.Level")
+	}
+}
+
+func TestFatalCallsExit(t *testing.T) {
+	var buf bytes.Buffer
+	code := -1
+	l := logger.New(&buf, logger.Options{Exit: func(c int) { code = c }})
+	l.Fatal("cannot start", logger.F("port", 80))
+	if code != 1 || buf.String() != "[FATAL] cannot start port=80\n" {
+		t.Errorf("exit code %d, output %q", code, buf.String())
+	}
+}
+
+// FuzzLogfmtValue はどんな値でも、logfmtの値を1つのトークンとして読み戻せることを確かめる
+func FuzzLogfmtValue(f *testing.F) {
+	for _, s := range []string{"plain", "", "with space", `quote"d`, "a=b", "改行\nあり", "\x00", " "} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, value string) {
+		var buf bytes.Buffer
+		logger.LogfmtEncoder{}.Encode(&buf, logger.Entry{Message: "m", Fields: []logger.Field{logger.F("k", value)}})
+		encoded, ok := strings.CutPrefix(buf.String(), "level=INFO msg=m k=")
+		if !ok {
+			t.Fatalf("unexpected prefix: %q", buf.String())
+		}
+		if strings.HasPrefix(encoded, `"`) {
+			got, err := strconv.Unquote(encoded)
+			if err != nil || got != value {
+				t.Errorf("Unquote(%s) = %q, %v; want %q", encoded, got, err, value)
+			}
+			return
+		}
+		if encoded != value || strings.ContainsAny(encoded, " =\"\n") {
+			t.Errorf("unquoted value %q for %q", encoded, value)
+		}
+	})
+}
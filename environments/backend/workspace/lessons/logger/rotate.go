@@ -0,0 +1,283 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat はローテートしたファイル名に付ける時刻（名前の順が古い順になる）
+const backupTimeFormat = "20060102T150405.000"
+
+// RotateOptions はRotatingFileの動作設定
+type RotateOptions struct {
+	MaxSize    int64 // このバイト数を超えて書く前にローテートする（0ならサイズではローテートしない）
+	Daily      bool  // 日付が変わったらローテートする
+	MaxBackups int   // 残す古いファイルの数（0なら全て残す）
+	Compress   bool  // ローテートしたファイルをgzipで圧縮する（Writeを止めないよう、別のgoroutineで）
+	// Now は時刻を返す（nilならtime.Now）。日付の変わり目とファイル名に使う
+	Now func() time.Time
+}
+
+// RotatingFile はサイズと日付でローテートするファイルのio.Writer
+// app.logがいっぱいになると app-20250102T030405.000.log（Compressなら.log.gz）に名前を変え、
+// 新しいapp.logに書き続ける。NewLoggerの出力先にそのまま渡せる
+type RotatingFile struct {
+	mu   sync.Mutex
+	path string
+	opts RotateOptions
+	file *os.File
+	size int64
+	day  string // 今のファイルに書き始めた日付
+
+	// 圧縮と古いファイルの削除はmuの外で、1つずつ順に行う
+	bg     sync.WaitGroup
+	bgMu   sync.Mutex
+	bgErrs []error // 裏で失敗した圧縮・削除のエラー（Closeで返す）
+}
+
+// OpenRotatingFile はpathを追記用に開く。既にあれば続きに書く
+func OpenRotatingFile(path string, opts RotateOptions) (*RotatingFile, error) {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	r := &RotatingFile{path: path, opts: opts}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file, r.size = f, info.Size()
+	r.day = r.opts.Now().Format(time.DateOnly)
+	if info.Size() > 0 {
+		// 前回のプロセスが書いたファイルなら、最後に書いた日付から数える
+		r.day = info.ModTime().In(r.opts.Now().Location()).Format(time.DateOnly)
+	}
+	return nil
+}
+
+// Write はpを書く。書く前に必要ならローテートする
+// 1回のWriteは分割しないので、1行ずつ書けば行の途中で別のファイルに分かれない
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+	if r.shouldRotate(len(p)) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *RotatingFile) shouldRotate(n int) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.opts.MaxSize > 0 && r.size+int64(n) > r.opts.MaxSize {
+		return true
+	}
+	return r.opts.Daily && r.opts.Now().Format(time.DateOnly) != r.day
+}
+
+// Rotate は今のファイルをすぐにローテートする（SIGHUPを受けたときなど）
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return os.ErrClosed
+	}
+	return r.rotate()
+}
+
+func (r *RotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return err
+	}
+	r.file = nil
+	backup, err := r.backupName()
+	if err == nil {
+		err = os.Rename(r.path, backup)
+	}
+	if err != nil {
+		// 名前を変えられなければ同じファイルに書き続ける
+		return errors.Join(err, r.open())
+	}
+	if !r.opts.Compress {
+		return errors.Join(r.removeOldBackups(), r.open())
+	}
+	// 圧縮は大きなファイルだと時間がかかるので、ロックを持ったまま待たずに裏で行う
+	// 古いファイルの削除は、圧縮し終えてから数える（圧縮中のファイルを消さない）
+	r.bg.Add(1)
+	go func() {
+		defer r.bg.Done()
+		r.bgMu.Lock()
+		defer r.bgMu.Unlock()
+		// 先に終わった別のローテートが、古いファイルとして圧縮前に消していることがある
+		var err error
+		if exists(backup) {
+			err = compressFile(backup)
+		}
+		// 圧縮や古いファイルの削除に失敗しても、ログは新しいファイルに書き続ける
+		if err := errors.Join(err, r.removeOldBackups()); err != nil {
+			r.bgErrs = append(r.bgErrs, err)
+		}
+	}()
+	return r.open()
+}
+
+// backupName は app.log を app-<時刻>.log にした名前を返す。同じ名前があれば連番を付ける
+func (r *RotatingFile) backupName() (string, error) {
+	base, ext := r.splitPath()
+	stamp := r.opts.Now().Format(backupTimeFormat)
+	for i := 0; i < 1000; i++ {
+		name := fmt.Sprintf("%s-%s%s", base, stamp, ext)
+		if i > 0 {
+			name = fmt.Sprintf("%s-%s.%d%s", base, stamp, i, ext)
+		}
+		if !exists(name) && !exists(name+".gz") {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("rotate %s: too many backups at %s", r.path, stamp)
+}
+
+func (r *RotatingFile) splitPath() (base, ext string) {
+	ext = filepath.Ext(r.path)
+	return strings.TrimSuffix(r.path, ext), ext
+}
+
+// Backups はローテートした古いファイルを古い順に返す
+func (r *RotatingFile) Backups() ([]string, error) {
+	base, ext := r.splitPath()
+	matches, err := filepath.Glob(base + "-*" + ext + "*")
+	if err != nil {
+		return nil, err
+	}
+	var list []string
+	for _, m := range matches {
+		if !strings.HasSuffix(m, ext) && !strings.HasSuffix(m, ext+".gz") {
+			continue
+		}
+		// app-errors.log のような別のログは除く
+		stamp := strings.TrimPrefix(m, base+"-")
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, stamp[:len(backupTimeFormat)]); err == nil {
+			list = append(list, m)
+		}
+	}
+	sort.Strings(list)
+	return list, nil
+}
+
+func (r *RotatingFile) removeOldBackups() error {
+	if r.opts.MaxBackups <= 0 {
+		return nil
+	}
+	list, err := r.Backups()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for len(list) > r.opts.MaxBackups {
+		errs = append(errs, os.Remove(list[0]))
+		list = list[1:]
+	}
+	return errors.Join(errs...)
+}
+
+// Sync はファイルの内容をディスクに書き出す
+func (r *RotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	return r.file.Sync()
+}
+
+// Close はファイルを閉じ、裏で行っている圧縮が終わるのを待つ。2回目以降は何もしない
+// 圧縮や古いファイルの削除に失敗していれば、そのエラーも返す
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	var err error
+	if r.file != nil {
+		err = r.file.Close()
+		r.file = nil
+	}
+	r.mu.Unlock()
+
+	r.bg.Wait()
+	r.bgMu.Lock()
+	defer r.bgMu.Unlock()
+	err = errors.Join(append([]error{err}, r.bgErrs...)...)
+	r.bgErrs = nil
+	return err
+}
+
+// compressFile はnameをname.gzに圧縮して元のファイルを消す
+// 途中で失敗しても元のファイルは残り、書きかけの.gzは残さない
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := name + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(tmp)
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	zw.Name = filepath.Base(name)
+	if _, err := io.Copy(zw, src); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, name+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
+
+func exists(name string) bool {
+	_, err := os.Stat(name)
+	return err == nil
+}
@@ -0,0 +1,188 @@
+package logger_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"workout/lessons/logger"
+)
+
+// clock は呼ぶたびに1ミリ秒進む時計（ローテートしたファイル名が重ならない）
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(time.Millisecond)
+	return c.now
+}
+
+func (c *clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// readLog はファイルの中身を返す。.gzなら展開する
+func readLog(t *testing.T, name string) string {
+	t.Helper()
+	f, err := os.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if strings.HasSuffix(name, ".gz") {
+		zr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return string(data)
+}
+
+// allLogs はローテートした古いファイルから今のファイルまでを順につなげた中身を返す
+func allLogs(t *testing.T, r *logger.RotatingFile, path string) (string, []string) {
+	t.Helper()
+	backups, err := r.Backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	for _, name := range append(backups, path) {
+		b.WriteString(readLog(t, name))
+	}
+	return b.String(), backups
+}
+
+func TestRotatingFileMaxSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	c := &clock{now: fixedTime}
+	r, err := logger.OpenRotatingFile(path, logger.RotateOptions{MaxSize: 20, Now: c.Now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want strings.Builder
+	for i := range 5 {
+		line := fmt.Sprintf("line %04d\n", i) // 10バイトなので2行ずつ
+		want.WriteString(line)
+		if _, err := r.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	got, backups := allLogs(t, r, path)
+	if len(backups) != 2 {
+		t.Errorf("backups = %v, want 2 files", backups)
+	}
+	if got != want.String() {
+		t.Errorf("logs = %q, want %q", got, want.String())
+	}
+	if _, err := r.Write([]byte("x\n")); err == nil {
+		t.Error("Write after Close succeeded")
+	}
+}
+
+func TestRotatingFileDaily(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	c := &clock{now: fixedTime}
+	r, err := logger.OpenRotatingFile(path, logger.RotateOptions{Daily: true, Now: c.Now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	r.Write([]byte("day 1\n"))
+	r.Write([]byte("day 1 again\n"))
+	c.Advance(24 * time.Hour)
+	r.Write([]byte("day 2\n"))
+
+	backups, err := r.Backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 1 || readLog(t, backups[0]) != "day 1\nday 1 again\n" {
+		t.Fatalf("backups = %v", backups)
+	}
+	if got := readLog(t, path); got != "day 2\n" {
+		t.Errorf("current file = %q", got)
+	}
+}
+
+// 圧縮は裏で行われ、Closeがその完了を待つ。並行に書いても行は分かれず、欠けない
+func TestRotatingFileCompress(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxBackups  int
+		wantBackups int // 0なら数えない
+	}{
+		{name: "keep all", maxBackups: 0},
+		{name: "keep 2", maxBackups: 2, wantBackups: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "app.log")
+			c := &clock{now: fixedTime}
+			r, err := logger.OpenRotatingFile(path, logger.RotateOptions{
+				MaxSize: 200, MaxBackups: tt.maxBackups, Compress: true, Now: c.Now,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			const writers, lines = 4, 50
+			var wg sync.WaitGroup
+			for w := range writers {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range lines {
+						r.Write([]byte(fmt.Sprintf("writer %d line %04d\n", w, i)))
+					}
+				}()
+			}
+			wg.Wait()
+			if err := r.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			got, backups := allLogs(t, r, path)
+			for _, b := range backups {
+				if !strings.HasSuffix(b, ".log.gz") {
+					t.Errorf("backup %s is not compressed", b)
+				}
+			}
+			if tt.wantBackups > 0 && len(backups) != tt.wantBackups {
+				t.Errorf("backups = %v, want %d files", backups, tt.wantBackups)
+			}
+			if leftovers, _ := filepath.Glob(filepath.Join(dir, "*.tmp")); len(leftovers) > 0 {
+				t.Errorf("temporary files left: %v", leftovers)
+			}
+			all := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+			for _, line := range all {
+				if !strings.HasPrefix(line, "writer ") || len(line) != len("writer 0 line 0000") {
+					t.Fatalf("broken line %q", line)
+				}
+			}
+			if tt.maxBackups == 0 && len(all) != writers*lines {
+				t.Errorf("got %d lines, want %d", len(all), writers*lines)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"sync"
+	"time"
+)
+
+// Sampling はINFO以下の同じレベル・同じメッセージのログを間引く設定
+// アクセスログのように大量に出るログで、出力先やログ基盤があふれるのを防ぐ。WARN以上は間引かない
+//
+//	Sampling{Tick: time.Second, First: 10, Thereafter: 100}
+//	// 1秒ごとに、同じメッセージは最初の10件を全て出し、その後は100件に1件だけ出す
+type Sampling struct {
+	Tick       time.Duration // 数え直す間隔（0なら1秒）
+	First      int           // 間隔ごとに最初に全て出す件数
+	Thereafter int           // その後はこの件数に1件だけ出す（0なら出さない）
+}
+
+// sampler はSamplingに従って出すかを決める。Withで作った子のLoggerと共有する
+type sampler struct {
+	cfg Sampling
+
+	mu      sync.Mutex
+	start   time.Time      // 今の間隔の始まり
+	counts  map[string]int // レベルとメッセージごとの件数
+	dropped int64
+}
+
+func newSampler(cfg Sampling) *sampler {
+	if cfg.Tick <= 0 {
+		cfg.Tick = time.Second
+	}
+	return &sampler{cfg: cfg, counts: map[string]int{}}
+}
+
+func (s *sampler) allow(level LogLevel, msg string, now time.Time) bool {
+	if level > INFO {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.start) >= s.cfg.Tick {
+		s.start = now
+		clear(s.counts)
+	}
+	key := level.String() + "\x00" + msg
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.cfg.First || (s.cfg.Thereafter > 0 && (n-s.cfg.First)%s.cfg.Thereafter == 0) {
+		return true
+	}
+	s.dropped++
+	return false
+}
+
+// Sampled は間引いて出さなかったログの数を返す（Samplingがなければ0）
+func (l *Logger) Sampled() int64 {
+	if l.sampler == nil {
+		return 0
+	}
+	l.sampler.mu.Lock()
+	defer l.sampler.mu.Unlock()
+	return l.sampler.dropped
+}
@@ -0,0 +1,41 @@
+package logger_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"workout/lessons/logger"
+)
+
+func TestSampling(t *testing.T) {
+	var buf bytes.Buffer
+	now := fixedTime
+	l := logger.New(&buf, logger.Options{
+		Now:      func() time.Time { return now },
+		Sampling: &logger.Sampling{Tick: time.Second, First: 2, Thereafter: 3},
+	})
+	for range 8 {
+		l.Info("request")
+		l.Warn("slow") // WARN以上は間引かない
+	}
+	// 1-2件目と、その後3件ごと（5件目・8件目）
+	if got := strings.Count(buf.String(), "[INFO] request"); got != 4 {
+		t.Errorf("INFO written %d times, want 4", got)
+	}
+	if got := strings.Count(buf.String(), "[WARN] slow"); got != 8 {
+		t.Errorf("WARN written %d times, want 8", got)
+	}
+	if l.Sampled() != 4 || l.With(logger.F("k", 1)).Sampled() != 4 {
+		t.Errorf("Sampled() = %d, want 4 (shared with children)", l.Sampled())
+	}
+
+	// 次の間隔では数え直す
+	buf.Reset()
+	now = now.Add(time.Second)
+	l.Info("request")
+	if buf.Len() == 0 {
+		t.Error("first log in a new tick was dropped")
+	}
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+)
+
+// Handler はLoggerに書くslog.Handler
+// slog.New(logger.NewHandler(l)) で、log/slogを使うコードもLoggerと同じ形式・同じ出力先に書ける
+// slog.SetDefaultすれば、標準のlogパッケージの出力もLoggerに流れる
+type Handler struct {
+	l      *Logger
+	fields []Field // WithAttrsで付けたフィールド
+	prefix string  // WithGroupで付けたグループ名（"request."）
+}
+
+// NewHandler はlに書くHandlerを作る
+func NewHandler(l *Logger) *Handler {
+	return &Handler{l: l}
+}
+
+// Slog はlに書くslog.Loggerを返す
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(NewHandler(l))
+}
+
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.Enabled(fromSlogLevel(level))
+}
+
+// Handle はrをLoggerに書く。slog.InfoContextなどに渡したctxのリクエストID・トレースIDも付ける
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	e := Entry{Level: fromSlogLevel(r.Level), Message: r.Message}
+	if h.l.opts.AddCaller && r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		e.Caller = shortCaller(f.File, f.Line)
+	}
+	e.Fields = make([]Field, 0, len(h.fields)+r.NumAttrs())
+	e.Fields = append(e.Fields, h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		e.Fields = appendAttr(e.Fields, h.prefix, a)
+		return true
+	})
+	h.l.withContextIDs(ctx).write(e)
+	return nil
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.fields = make([]Field, len(h.fields), len(h.fields)+len(attrs))
+	copy(h2.fields, h.fields)
+	for _, a := range attrs {
+		h2.fields = appendAttr(h2.fields, h.prefix, a)
+	}
+	return &h2
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// appendAttr はaをFieldにして足す。グループは"group.key"のように平らにする
+func appendAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendAttr(fields, prefix, ga)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: prefix + a.Key, Value: a.Value.Any()})
+}
+
+// fromSlogLevel はslogのレベルを一番近いLogLevelにする（slog.LevelError以上はERROR）
+func fromSlogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return DEBUG
+	case level < slog.LevelWarn:
+		return INFO
+	case level < slog.LevelError:
+		return WARN
+	default:
+		return ERROR
+	}
+}
@@ -0,0 +1,31 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"workout/lessons/logger"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := logger.New(&buf, logger.Options{Level: logger.DEBUG})
+	s := l.Slog().With("service", "api").WithGroup("req")
+	ctx := logger.WithRequestID(context.Background(), "req-1")
+	s.InfoContext(ctx, "handled", "status", 200, slog.Group("user", "id", 7))
+	s.Log(ctx, slog.LevelDebug-4, "trace level")
+	s.Log(ctx, slog.LevelError+4, "critical")
+
+	// ctxのIDはLoggerのWithと同じ扱いで、先頭に付く
+	want := "[INFO] handled request_id=req-1 service=api req.status=200 req.user.id=7\n" +
+		"[DEBUG] trace level request_id=req-1 service=api\n" +
+		"[ERROR] critical request_id=req-1 service=api\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+	if logger.NewLogger(&buf, logger.WARN).Slog().Enabled(ctx, slog.LevelInfo) {
+		t.Error("Handler.Enabled does not follow the Logger level")
+	}
+}
@@ -0,0 +1,55 @@
+// Package notify はテーマ04（Interfaceの基本）の演習問題の通知
+// 送信はシミュレーションで、Outに書くだけ（nilなら標準出力）
+package notify
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Notifier はメッセージを送る手段
+type Notifier interface {
+	Notify(message string) error
+}
+
+// EmailNotifier はメール送信をシミュレートする
+type EmailNotifier struct {
+	To  string
+	Out io.Writer
+}
+
+func (e *EmailNotifier) Notify(message string) error {
+	_, err := fmt.Fprintf(output(e.Out), "[Email] To: %s, Message: %s\n", e.To, message)
+	return err
+}
+
+// SlackNotifier はSlack送信をシミュレートする
+type SlackNotifier struct {
+	Channel string
+	Out     io.Writer
+}
+
+func (s *SlackNotifier) Notify(message string) error {
+	_, err := fmt.Fprintf(output(s.Out), "[Slack] Channel: %s, Message: %s\n", s.Channel, message)
+	return err
+}
+
+// SendAll は全てのNotifierに送信し、失敗したものだけのエラーを返す
+// 途中で失敗しても残りのNotifierには送る
+func SendAll(notifiers []Notifier, message string) []error {
+	var errs []error
+	for _, n := range notifiers {
+		if err := n.Notify(message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+func output(w io.Writer) io.Writer {
+	if w == nil {
+		return os.Stdout
+	}
+	return w
+}
@@ -0,0 +1,50 @@
+package notify_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"workout/lessons/notify"
+)
+
+// failing は常に失敗するNotifier
+type failing struct{ err error }
+
+func (f failing) Notify(string) error { return f.err }
+
+func TestSendAll(t *testing.T) {
+	errDown := errors.New("smtp down")
+	tests := []struct {
+		name    string
+		failing []notify.Notifier
+		wantErr []error
+	}{
+		{name: "all succeed"},
+		{name: "one fails", failing: []notify.Notifier{failing{errDown}}, wantErr: []error{errDown}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			// 失敗するNotifierを先頭に置いても、後のNotifierには送る
+			notifiers := append(tt.failing,
+				&notify.EmailNotifier{To: "user@example.com", Out: &buf},
+				&notify.SlackNotifier{Channel: "#general", Out: &buf},
+			)
+			errs := notify.SendAll(notifiers, "デプロイ完了")
+			if len(errs) != len(tt.wantErr) {
+				t.Fatalf("errs = %v, want %v", errs, tt.wantErr)
+			}
+			for i := range errs {
+				if !errors.Is(errs[i], tt.wantErr[i]) {
+					t.Errorf("errs[%d] = %v, want %v", i, errs[i], tt.wantErr[i])
+				}
+			}
+			want := "[Email] To: user@example.com, Message: デプロイ完了\n" +
+				"[Slack] Channel: #general, Message: デプロイ完了\n"
+			if buf.String() != want {
+				t.Errorf("got %q, want %q", buf.String(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Package orders はテーマ08（errors.Is/Asの活用）の層をまたぐエラーラッピング
+package orders
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrNotFound は注文が存在しない場合のセンチネルエラー
+var ErrNotFound = errors.New("not found")
+
+// AppError はUnwrapを実装したカスタムエラー
+type AppError struct {
+	StatusCode int
+	Message    string
+	Err        error
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("[%d] %s: %v", e.StatusCode, e.Message, e.Err)
+	}
+	return fmt.Sprintf("[%d] %s", e.StatusCode, e.Message)
+}
+
+func (e *AppError) Unwrap() error { return e.Err }
+
+// FindOrderInDB はDB層。id=0の注文は存在しない
+func FindOrderInDB(id int) (string, error) {
+	if id == 0 {
+		return "", ErrNotFound
+	}
+	return "注文#" + fmt.Sprint(id), nil
+}
+
+// GetOrder はリポジトリ層。DB層のエラーに文脈を付けてラップする
+func GetOrder(id int) (string, error) {
+	order, err := FindOrderInDB(id)
+	if err != nil {
+		return "", fmt.Errorf("order repository: %w", err)
+	}
+	return order, nil
+}
+
+// ProcessOrder はサービス層。エラーをAppErrorに変換し、成功したらwに書く
+func ProcessOrder(w io.Writer, id int) error {
+	order, err := GetOrder(id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return &AppError{StatusCode: 404, Message: "order not found", Err: err}
+		}
+		return &AppError{StatusCode: 500, Message: "internal error", Err: err}
+	}
+	fmt.Fprintln(w, "処理完了:", order)
+	return nil
+}
+
+// HandleOrderRequest はハンドラー層。errors.As/errors.Isでエラーを判定してwに書く
+func HandleOrderRequest(w io.Writer, id int) {
+	err := ProcessOrder(w, id)
+	if err == nil {
+		return
+	}
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		fmt.Fprintf(w, "HTTP %d: %s\n", appErr.StatusCode, appErr.Message)
+	}
+	if errors.Is(err, ErrNotFound) {
+		fmt.Fprintln(w, "→ リソースが見つかりません")
+	}
+}
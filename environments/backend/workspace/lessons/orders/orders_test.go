@@ -0,0 +1,71 @@
+package orders_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"workout/lessons/orders"
+)
+
+func TestProcessOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       int
+		wantCode int // 0なら成功
+		wantOut  string
+	}{
+		{name: "found", id: 42, wantOut: "処理完了: 注文#42\n"},
+		{name: "not found", id: 0, wantCode: 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := orders.ProcessOrder(&buf, tt.id)
+			if tt.wantCode == 0 {
+				if err != nil {
+					t.Fatalf("ProcessOrder: %v", err)
+				}
+				if buf.String() != tt.wantOut {
+					t.Errorf("wrote %q, want %q", buf.String(), tt.wantOut)
+				}
+				return
+			}
+			var appErr *orders.AppError
+			if !errors.As(err, &appErr) || appErr.StatusCode != tt.wantCode {
+				t.Fatalf("err = %v, want AppError %d", err, tt.wantCode)
+			}
+			// AppError → リポジトリ層のラップ → ErrNotFound と辿れる
+			if !errors.Is(err, orders.ErrNotFound) {
+				t.Error("errors.Is(err, ErrNotFound) = false")
+			}
+			if want := "[404] order not found: order repository: not found"; err.Error() != want {
+				t.Errorf("Error() = %q, want %q", err.Error(), want)
+			}
+		})
+	}
+}
+
+func TestAppErrorWithoutCause(t *testing.T) {
+	err := &orders.AppError{StatusCode: 500, Message: "internal error"}
+	if err.Error() != "[500] internal error" || err.Unwrap() != nil {
+		t.Errorf("Error() = %q, Unwrap() = %v", err.Error(), err.Unwrap())
+	}
+}
+
+func TestHandleOrderRequest(t *testing.T) {
+	tests := []struct {
+		id   int
+		want string
+	}{
+		{id: 7, want: "処理完了: 注文#7\n"},
+		{id: 0, want: "HTTP 404: order not found\n→ リソースが見つかりません\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		orders.HandleOrderRequest(&buf, tt.id)
+		if buf.String() != tt.want {
+			t.Errorf("id %d: wrote %q, want %q", tt.id, buf.String(), tt.want)
+		}
+	}
+}
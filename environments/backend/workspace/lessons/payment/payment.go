@@ -0,0 +1,65 @@
+// Package payment はテーマ05（Interfaceの応用）の支払い方法
+package payment
+
+import "fmt"
+
+// PaymentMethod は支払い方法のインターフェース
+type PaymentMethod interface {
+	Pay(amount int) error
+	Name() string
+}
+
+// CreditCard は限度額まで支払えるクレジットカード
+type CreditCard struct {
+	Number string
+	Limit  int
+}
+
+func (c CreditCard) Pay(amount int) error {
+	if amount > c.Limit {
+		return fmt.Errorf("credit limit exceeded: limit=%d, amount=%d", c.Limit, amount)
+	}
+	return nil
+}
+
+func (c CreditCard) Name() string { return "クレジットカード" }
+
+// BankTransfer は残高まで支払える銀行振込
+type BankTransfer struct {
+	AccountNumber string
+	Balance       int
+}
+
+func (b BankTransfer) Pay(amount int) error {
+	if amount > b.Balance {
+		return fmt.Errorf("insufficient balance: balance=%d, amount=%d", b.Balance, amount)
+	}
+	return nil
+}
+
+func (b BankTransfer) Name() string { return "銀行振込" }
+
+// ProcessPayment は型スイッチで支払い方法に応じたメッセージを返す
+func ProcessPayment(method PaymentMethod, amount int) string {
+	err := method.Pay(amount)
+	if err != nil {
+		return fmt.Sprintf("支払い失敗 (%s): %v", method.Name(), err)
+	}
+
+	switch v := method.(type) {
+	case CreditCard:
+		return fmt.Sprintf("カード(%s)で%d円支払い完了", last4(v.Number), amount)
+	case BankTransfer:
+		return fmt.Sprintf("口座(%s)から%d円振込完了", v.AccountNumber, amount)
+	default:
+		return fmt.Sprintf("%sで%d円支払い完了", method.Name(), amount)
+	}
+}
+
+// last4 はカード番号の下4桁を返す（4桁未満ならそのまま）
+func last4(number string) string {
+	if len(number) <= 4 {
+		return number
+	}
+	return number[len(number)-4:]
+}
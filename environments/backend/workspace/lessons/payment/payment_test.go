@@ -0,0 +1,79 @@
+package payment_test
+
+import (
+	"errors"
+	"testing"
+
+	"workout/lessons/payment"
+)
+
+// wallet は型スイッチのどのcaseにも当たらない支払い方法
+type wallet struct{ err error }
+
+func (w wallet) Pay(int) error { return w.err }
+func (w wallet) Name() string  { return "電子マネー" }
+
+func TestProcessPayment(t *testing.T) {
+	tests := []struct {
+		name   string
+		method payment.PaymentMethod
+		amount int
+		want   string
+	}{
+		{
+			name:   "credit card",
+			method: payment.CreditCard{Number: "1234-5678-9012-3456", Limit: 10000},
+			amount: 5000,
+			want:   "カード(3456)で5000円支払い完了",
+		},
+		{
+			name:   "credit card at the limit",
+			method: payment.CreditCard{Number: "1234-5678-9012-3456", Limit: 10000},
+			amount: 10000,
+			want:   "カード(3456)で10000円支払い完了",
+		},
+		{
+			name:   "short card number",
+			method: payment.CreditCard{Number: "123", Limit: 100},
+			amount: 1,
+			want:   "カード(123)で1円支払い完了",
+		},
+		{
+			name:   "credit limit exceeded",
+			method: payment.CreditCard{Number: "1234-5678-9012-3456", Limit: 10000},
+			amount: 10001,
+			want:   "支払い失敗 (クレジットカード): credit limit exceeded: limit=10000, amount=10001",
+		},
+		{
+			name:   "bank transfer",
+			method: payment.BankTransfer{AccountNumber: "001-1234567", Balance: 50000},
+			amount: 30000,
+			want:   "口座(001-1234567)から30000円振込完了",
+		},
+		{
+			name:   "insufficient balance",
+			method: payment.BankTransfer{AccountNumber: "001-1234567", Balance: 100},
+			amount: 200,
+			want:   "支払い失敗 (銀行振込): insufficient balance: balance=100, amount=200",
+		},
+		{
+			name:   "other method",
+			method: wallet{},
+			amount: 300,
+			want:   "電子マネーで300円支払い完了",
+		},
+		{
+			name:   "other method fails",
+			method: wallet{err: errors.New("残高不足")},
+			amount: 300,
+			want:   "支払い失敗 (電子マネー): 残高不足",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := payment.ProcessPayment(tt.method, tt.amount); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package product_test
+
+import (
+	"testing"
+	"time"
+
+	"workout/lessons/product"
+)
+
+func TestNewProduct(t *testing.T) {
+	tests := []struct {
+		name    string
+		pname   string
+		price   int
+		stock   int
+		wantErr string
+	}{
+		{name: "valid", pname: "ノートPC", price: 89800, stock: 3},
+		{name: "zero stock is allowed", pname: "マウス", price: 2980, stock: 0},
+		{name: "empty name", pname: "", price: 100, wantErr: "name is required"},
+		{name: "zero price", pname: "無料", price: 0, wantErr: "price must be positive"},
+		{name: "negative price", pname: "返金", price: -1, wantErr: "price must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := product.NewProduct(tt.pname, tt.price, tt.stock)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				if p != nil {
+					t.Errorf("product = %+v, want nil", p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewProduct: %v", err)
+			}
+			if p.Name != tt.pname || p.Price != tt.price || p.Stock != tt.stock {
+				t.Errorf("got %+v", p)
+			}
+			if p.CreatedAt.IsZero() || !p.CreatedAt.Equal(p.UpdatedAt) {
+				t.Errorf("CreatedAt=%v UpdatedAt=%v, want the same non-zero time", p.CreatedAt, p.UpdatedAt)
+			}
+		})
+	}
+}
+
+func TestTouch(t *testing.T) {
+	p, err := product.NewProduct("キーボード", 5000, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := p.UpdatedAt
+	p.CreatedAt = before.Add(-time.Hour)
+	// 埋め込んだBaseModelのメソッドをProductから呼べる
+	p.Touch()
+	if p.UpdatedAt.Before(before) {
+		t.Errorf("UpdatedAt went back: %v -> %v", before, p.UpdatedAt)
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		stock int
+		want  string
+	}{
+		{stock: 2, want: "マウス - ¥2980 (在庫あり, 残り2個)"},
+		{stock: 0, want: "マウス - ¥2980 (在庫なし, 残り0個)"},
+	}
+	for _, tt := range tests {
+		p := product.Product{Name: "マウス", Price: 2980, Stock: tt.stock}
+		if got := p.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+		if p.IsInStock() != (tt.stock > 0) {
+			t.Errorf("IsInStock() with stock %d = %v", tt.stock, p.IsInStock())
+		}
+	}
+}
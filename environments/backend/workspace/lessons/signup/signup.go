@@ -0,0 +1,57 @@
+// Package signup はテーマ06（エラーハンドリングの基本）のユーザー登録
+package signup
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// RegisterRequest は登録の入力
+type RegisterRequest struct {
+	Username string
+	Email    string
+	Password string
+}
+
+// User は登録したユーザー
+type User struct {
+	ID       int
+	Username string
+	Email    string
+}
+
+// ValidateRequest はreqの最初に見つかった問題をエラーで返す
+func ValidateRequest(req RegisterRequest) error {
+	if req.Username == "" {
+		return fmt.Errorf("username is required")
+	}
+	if len(req.Username) < 3 {
+		return fmt.Errorf("username must be at least 3 characters")
+	}
+	if !strings.Contains(req.Email, "@") {
+		return fmt.Errorf("email must contain @")
+	}
+	if len(req.Password) < 8 {
+		return fmt.Errorf("password must be at least 8 characters")
+	}
+	return nil
+}
+
+// Registry はユーザーにIDを振って登録する（解答例のパッケージ変数nextIDの代わり）
+type Registry struct {
+	mu     sync.Mutex
+	nextID int
+}
+
+// RegisterUser はreqを検証してユーザーを作る。IDは1から順に振る
+func (r *Registry) RegisterUser(req RegisterRequest) (*User, error) {
+	if err := ValidateRequest(req); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	return &User{ID: r.nextID, Username: req.Username, Email: req.Email}, nil
+}
@@ -0,0 +1,105 @@
+package signup_test
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"workout/lessons/signup"
+)
+
+var valid = signup.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: "password123"}
+
+func TestValidateRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(r *signup.RegisterRequest)
+		wantErr string
+	}{
+		{name: "valid", modify: func(*signup.RegisterRequest) {}},
+		{name: "username is exactly 3 characters", modify: func(r *signup.RegisterRequest) { r.Username = "bob" }},
+		{name: "password is exactly 8 characters", modify: func(r *signup.RegisterRequest) { r.Password = "12345678" }},
+		{name: "empty username", modify: func(r *signup.RegisterRequest) { r.Username = "" }, wantErr: "username is required"},
+		{name: "short username", modify: func(r *signup.RegisterRequest) { r.Username = "ab" }, wantErr: "username must be at least 3 characters"},
+		{name: "email without @", modify: func(r *signup.RegisterRequest) { r.Email = "alice.example.com" }, wantErr: "email must contain @"},
+		{name: "short password", modify: func(r *signup.RegisterRequest) { r.Password = "1234567" }, wantErr: "password must be at least 8 characters"},
+		{
+			// 最初に見つかった問題だけを返す
+			name:    "several problems",
+			modify:  func(r *signup.RegisterRequest) { *r = signup.RegisterRequest{} },
+			wantErr: "username is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.modify(&req)
+			err := signup.ValidateRequest(req)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("err = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRegisterUser(t *testing.T) {
+	var r signup.Registry
+	u, err := r.RegisterUser(valid)
+	if err != nil {
+		t.Fatalf("RegisterUser: %v", err)
+	}
+	if *u != (signup.User{ID: 1, Username: "alice", Email: "alice@example.com"}) {
+		t.Errorf("got %+v", *u)
+	}
+
+	bad := valid
+	bad.Email = "invalid"
+	_, err = r.RegisterUser(bad)
+	if err == nil || !strings.HasPrefix(err.Error(), "validation failed: ") {
+		t.Fatalf("err = %v, want validation failed", err)
+	}
+	if errors.Unwrap(err) == nil {
+		t.Error("validation error is not wrapped with %w")
+	}
+
+	// 失敗した登録ではIDを消費しない
+	u, _ = r.RegisterUser(valid)
+	if u.ID != 2 {
+		t.Errorf("ID = %d, want 2", u.ID)
+	}
+}
+
+func TestRegisterUserConcurrentIDsAreUnique(t *testing.T) {
+	var r signup.Registry
+	const n = 50
+	ids := make(chan int, n)
+	var wg sync.WaitGroup
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			u, err := r.RegisterUser(valid)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			ids <- u.ID
+		}()
+	}
+	wg.Wait()
+	close(ids)
+	seen := make(map[int]bool)
+	for id := range ids {
+		if seen[id] || id < 1 || id > n {
+			t.Errorf("unexpected or duplicate ID %d", id)
+		}
+		seen[id] = true
+	}
+}
@@ -0,0 +1,87 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// MaxLineSize は1行の最大のバイト数。これより長い行はbufio.ErrTooLongになる
+const MaxLineSize = 1 << 20
+
+// ScanLines はrを1行ずつ読んでfnを呼ぶ。行末の改行（\n・\r\n）は含まない
+// fnがエラーを返したらそこで止めて、何行目かを付けて返す
+func ScanLines(r io.Reader, fn func(line []byte) error) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), MaxLineSize)
+	for n := 1; sc.Scan(); n++ {
+		if err := fn(sc.Bytes()); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	return sc.Err()
+}
+
+// MapLines は1行ずつfnで変換して書くStage。fnに渡したlineは次の行を読むと上書きされる
+func MapLines(fn func(line []byte) ([]byte, error)) Stage {
+	return Stage{Name: "map lines", Fn: func(ctx context.Context, r io.Reader, w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		err := ScanLines(r, func(line []byte) error {
+			out, err := fn(line)
+			if err != nil {
+				return err
+			}
+			bw.Write(out)
+			return bw.WriteByte('\n')
+		})
+		if err != nil {
+			return err
+		}
+		return bw.Flush()
+	}}
+}
+
+// FilterLines はkeepがtrueを返した行だけを書くStage
+func FilterLines(keep func(line []byte) bool) Stage {
+	return Stage{Name: "filter lines", Fn: func(ctx context.Context, r io.Reader, w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		err := ScanLines(r, func(line []byte) error {
+			if !keep(line) {
+				return nil
+			}
+			bw.Write(line)
+			return bw.WriteByte('\n')
+		})
+		if err != nil {
+			return err
+		}
+		return bw.Flush()
+	}}
+}
+
+// Head は最初のn行だけを書き、残りは読まずに終わるStage
+func Head(n int) Stage {
+	return Stage{Name: "head", Fn: func(ctx context.Context, r io.Reader, w io.Writer) error {
+		if n <= 0 {
+			return nil
+		}
+		bw := bufio.NewWriter(w)
+		count := 0
+		err := ScanLines(r, func(line []byte) error {
+			bw.Write(line)
+			if err := bw.WriteByte('\n'); err != nil {
+				return err
+			}
+			if count++; count == n {
+				return errStopped
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errStopped) {
+			return err
+		}
+		return bw.Flush()
+	}}
+}
@@ -0,0 +1,182 @@
+// Package stream はテーマ12（io.Reader/io.Writerの理解）を発展させた、組み合わせて使うストリーム処理の部品
+//
+// 各部品はio.Readerから読んでio.Writerに書くStageで、Pipelineでつなぐと
+// 間をio.Pipeでつないで並行に動かす。ファイル全体をメモリに読み込まないので、大きなデータも一定のメモリで処理できる
+//
+//	h := sha256.New()
+//	err := stream.NewPipeline(
+//		stream.Gunzip(),
+//		stream.CSVToNDJSON(),
+//		stream.Checksum(h),
+//		stream.Gzip(gzip.DefaultCompression),
+//	).Run(ctx, src, dst)
+package stream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sync"
+)
+
+// Stage はPipelineの1段。Fnはrを最後まで読んでwに書く
+// rが返すエラーやwへの書き込みのエラーはそのまま返せばよい（Pipelineが最初の原因のエラーを返す）
+type Stage struct {
+	Name string // エラーメッセージに使う名前
+	Fn   func(ctx context.Context, r io.Reader, w io.Writer) error
+}
+
+// ReaderStage はrを包むio.Readerを作る関数からStageを作る
+func ReaderStage(name string, wrap func(ctx context.Context, r io.Reader) io.Reader) Stage {
+	return Stage{Name: name, Fn: func(ctx context.Context, r io.Reader, w io.Writer) error {
+		_, err := io.Copy(w, wrap(ctx, r))
+		return err
+	}}
+}
+
+// Pipeline はStageを順につないだもの
+type Pipeline struct {
+	stages []Stage
+}
+
+// NewPipeline はstagesを順につなぐPipelineを作る
+func NewPipeline(stages ...Stage) *Pipeline {
+	return &Pipeline{stages: stages}
+}
+
+// Then は最後にsを足す
+func (p *Pipeline) Then(s Stage) *Pipeline {
+	p.stages = append(p.stages, s)
+	return p
+}
+
+// Run はsrcから読んだデータを全てのStageに通してdstに書く
+//
+// どこかのStageが失敗すると、前後のパイプを同じエラーで閉じて他のStageも止め、
+// 最初に失敗したStageのエラーを "stream: <Stage名>: <エラー>" の形で返す。
+// Stageがpanicした場合も同じように止め、*PanicErrorをラップしたエラーを返す（プロセスは落ちない）。
+// ctxがキャンセルされた場合も全てのStageを止め、ctx.Err()をラップしたエラーを返す
+//
+// ただし、キャンセルで起こせるのはStageの間のパイプで待っているStageだけで、
+// src.Readやdst.Writeの中で止まっているStageは、その呼び出しが戻るまで止まらない（Runもそれまで戻らない）。
+// ネットワーク接続のように止まりうるsrc・dstは、呼び出し側でキャンセル時に閉じるか期限を設定する
+//
+//	stop := context.AfterFunc(ctx, func() { conn.Close() })
+//	defer stop()
+func (p *Pipeline) Run(ctx context.Context, src io.Reader, dst io.Writer) error {
+	if len(p.stages) == 0 {
+		_, err := io.Copy(dst, &ctxReader{ctx: ctx, r: src})
+		return err
+	}
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		readers  []*io.PipeReader
+		writers  []*io.PipeWriter
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	// closeAll は全てのパイプをerrで閉じ、読み書きで待っているStageを起こす
+	closeAll := func(err error) {
+		for _, r := range readers {
+			r.CloseWithError(err)
+		}
+		for _, w := range writers {
+			w.CloseWithError(err)
+		}
+	}
+
+	var in io.Reader = &ctxReader{ctx: ctx, r: src}
+	inputs := make([]io.Reader, len(p.stages))
+	outputs := make([]io.Writer, len(p.stages))
+	for i := range p.stages {
+		inputs[i] = in
+		if i == len(p.stages)-1 {
+			outputs[i] = dst
+			break
+		}
+		pr, pw := io.Pipe()
+		readers, writers = append(readers, pr), append(writers, pw)
+		outputs[i], in = pw, pr
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		fail(fmt.Errorf("stream: %w", ctx.Err()))
+		closeAll(ctx.Err())
+	})
+	defer stop()
+
+	var wg sync.WaitGroup
+	for i, s := range p.stages {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := runStage(ctx, s, inputs[i], outputs[i])
+			if errors.Is(err, errStopped) {
+				err = nil
+			}
+			if err != nil {
+				fail(fmt.Errorf("stream: %s: %w", s.Name, err))
+				closeAll(err)
+				return
+			}
+			// 書き終えたことを次のStageにEOFで知らせる
+			if pw, ok := outputs[i].(*io.PipeWriter); ok {
+				pw.Close()
+			}
+			// 前のStageがまだ書いていても、読まないまま終わったなら書けないようにする
+			if pr, ok := inputs[i].(*io.PipeReader); ok {
+				pr.CloseWithError(errStopped)
+			}
+		}()
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	return firstErr
+}
+
+// PanicError はStageのpanicをrecoverしたエラー
+type PanicError struct {
+	Value any    // recoverした値
+	Stack []byte // panicしたgoroutineのスタックトレース
+}
+
+func (e *PanicError) Error() string { return fmt.Sprintf("panic: %v", e.Value) }
+
+// runStage はs.Fnを呼ぶ。panicしたらrecoverして*PanicErrorを返す
+func runStage(ctx context.Context, s Stage, r io.Reader, w io.Writer) (err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+		}
+	}()
+	return s.Fn(ctx, r, w)
+}
+
+// errStopped は後ろのStageが入力を最後まで読まずに正常に終わったときに、前のStageの書き込みが返すエラー
+// （headのように必要な分だけ読むStage）。前のStageはこのエラーで終わっても失敗とみなさない
+var errStopped = errors.New("stream: downstream stage finished")
+
+// ctxReader はctxがキャンセルされたら読むのをやめるio.Reader
+// ctxを確かめるのはReadを呼ぶ前だけなので、r.Readの中で待っている間のキャンセルでは戻らない
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
@@ -0,0 +1,182 @@
+package stream_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"workout/lessons/stream"
+)
+
+// numbered は "line 0\n" から "line n-1\n" までの入力を返す
+func numbered(n int) string {
+	var b strings.Builder
+	for i := range n {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	return b.String()
+}
+
+func TestPipelineRoundTrip(t *testing.T) {
+	input := numbered(10_000)
+	h := sha256.New()
+	var out bytes.Buffer
+	err := stream.NewPipeline(
+		stream.Gzip(gzip.BestSpeed),
+		stream.Gunzip(),
+		stream.Checksum(h),
+	).Run(context.Background(), strings.NewReader(input), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != input {
+		t.Errorf("output differs from input (%d bytes, want %d)", out.Len(), len(input))
+	}
+	if want := sha256.Sum256([]byte(input)); !bytes.Equal(h.Sum(nil), want[:]) {
+		t.Error("checksum differs")
+	}
+}
+
+func TestPipelineLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages []stream.Stage
+		input  string
+		want   string
+	}{
+		{
+			name:   "no stages copies",
+			stages: nil,
+			input:  "a\nb\n",
+			want:   "a\nb\n",
+		},
+		{
+			name: "filter and map",
+			stages: []stream.Stage{
+				stream.FilterLines(func(line []byte) bool { return !bytes.HasPrefix(line, []byte("#")) }),
+				stream.MapLines(func(line []byte) ([]byte, error) { return bytes.ToUpper(line), nil }),
+			},
+			input: "# comment\na\r\nb\n",
+			want:  "A\nB\n",
+		},
+		{
+			// Headが読むのをやめても、前のStageは書けずに止まったまま待たない
+			name:   "head stops upstream",
+			stages: []stream.Stage{stream.MapLines(func(line []byte) ([]byte, error) { return line, nil }), stream.Head(2)},
+			input:  numbered(100_000),
+			want:   "line 0\nline 1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := stream.NewPipeline(tt.stages...).Run(context.Background(), strings.NewReader(tt.input), &out); err != nil {
+				t.Fatal(err)
+			}
+			if out.String() != tt.want {
+				t.Errorf("got %q, want %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+var errBadLine = errors.New("bad line")
+
+func TestPipelineErrors(t *testing.T) {
+	passThrough := stream.MapLines(func(line []byte) ([]byte, error) { return line, nil })
+	tests := []struct {
+		name    string
+		stages  []stream.Stage
+		input   string
+		wantMsg string
+		check   func(t *testing.T, err error)
+	}{
+		{
+			name: "stage error",
+			stages: []stream.Stage{passThrough, stream.MapLines(func(line []byte) ([]byte, error) {
+				if string(line) == "line 3" {
+					return nil, errBadLine
+				}
+				return line, nil
+			})},
+			input:   numbered(10),
+			wantMsg: "stream: map lines: line 4: bad line",
+			check: func(t *testing.T, err error) {
+				if !errors.Is(err, errBadLine) {
+					t.Errorf("errors.Is(%v, errBadLine) = false", err)
+				}
+			},
+		},
+		{
+			name:    "corrupt gzip",
+			stages:  []stream.Stage{stream.Gunzip(), passThrough},
+			input:   "this is not gzip data",
+			wantMsg: "stream: gunzip: gzip: invalid header",
+		},
+		{
+			// panicはプロセスを落とさず、Pipelineのエラーになる
+			name: "stage panics",
+			stages: []stream.Stage{passThrough, {Name: "boom", Fn: func(ctx context.Context, r io.Reader, w io.Writer) error {
+				panic("boom")
+			}}, passThrough},
+			input:   numbered(100_000),
+			wantMsg: "stream: boom: panic: boom",
+			check: func(t *testing.T, err error) {
+				var pe *stream.PanicError
+				if !errors.As(err, &pe) || pe.Value != "boom" || !bytes.Contains(pe.Stack, []byte("pipeline_test.go")) {
+					t.Errorf("errors.As(%v, *PanicError) = %+v", err, pe)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := stream.NewPipeline(tt.stages...).Run(context.Background(), strings.NewReader(tt.input), io.Discard)
+			if err == nil || err.Error() != tt.wantMsg {
+				t.Fatalf("err = %v, want %q", err, tt.wantMsg)
+			}
+			if tt.check != nil {
+				tt.check(t, err)
+			}
+		})
+	}
+}
+
+// cancelReader は1回読んだらcancelを呼ぶ、終わりのない入力
+type cancelReader struct {
+	cancel context.CancelFunc
+}
+
+func (c *cancelReader) Read(p []byte) (int, error) {
+	c.cancel()
+	for i := range p {
+		p[i] = 'x'
+	}
+	return len(p), nil
+}
+
+func TestPipelineCancel(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages []stream.Stage
+	}{
+		{name: "no stages"},
+		{name: "stages", stages: []stream.Stage{stream.Gzip(gzip.BestSpeed), stream.Gunzip()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			err := stream.NewPipeline(tt.stages...).Run(ctx, &cancelReader{cancel: cancel}, io.Discard)
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("err = %v, want context.Canceled", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Record は1件のレコード。CSVの値は文字列、NDJSONの数値はjson.Numberになる
+type Record map[string]any
+
+// RecordReader はレコードを1件ずつ読む。最後まで読んだらio.EOFを返す
+type RecordReader interface {
+	Read() (Record, error)
+}
+
+// CSVReader は1行目を見出しとしてCSVを読むRecordReader
+type CSVReader struct {
+	r      *csv.Reader
+	header []string
+}
+
+func NewCSVReader(r io.Reader) *CSVReader {
+	cr := csv.NewReader(r)
+	cr.ReuseRecord = true
+	return &CSVReader{r: cr}
+}
+
+func (c *CSVReader) Read() (Record, error) {
+	if c.header == nil {
+		h, err := c.r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("csv header: %w", err)
+		}
+		c.header = append([]string(nil), h...)
+	}
+	// 列の数が見出しと違う行は、行番号を含むcsv.ParseErrorになる
+	row, err := c.r.Read()
+	if err != nil {
+		return nil, err
+	}
+	rec := make(Record, len(row))
+	for i, v := range row {
+		rec[c.header[i]] = v
+	}
+	return rec, nil
+}
+
+// NDJSONReader は1行に1つのJSONオブジェクトを読むRecordReader。空行は読み飛ばす
+// オブジェクトでない行（配列・null など）はエラーにする
+type NDJSONReader struct {
+	sc   *bufio.Scanner
+	line int
+}
+
+func NewNDJSONReader(r io.Reader) *NDJSONReader {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), MaxLineSize)
+	return &NDJSONReader{sc: sc}
+}
+
+func (n *NDJSONReader) Read() (Record, error) {
+	for n.sc.Scan() {
+		n.line++
+		line := bytes.TrimSpace(n.sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(line))
+		dec.UseNumber() // 大きな整数をfloat64にして桁を落とさない
+		var rec Record
+		if err := dec.Decode(&rec); err != nil {
+			return nil, fmt.Errorf("ndjson line %d: %w", n.line, err)
+		}
+		if dec.More() {
+			return nil, fmt.Errorf("ndjson line %d: more than one value", n.line)
+		}
+		if rec == nil {
+			// nullはエラーにならずにnilのmapになる
+			return nil, fmt.Errorf("ndjson line %d: not a JSON object", n.line)
+		}
+		return rec, nil
+	}
+	if err := n.sc.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// EachRecord はrrの全てのレコードにfnを呼ぶ
+func EachRecord(rr RecordReader, fn func(Record) error) error {
+	for {
+		rec, err := rr.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+}
+
+// CSVToNDJSON はCSVを読んで1行1レコードのJSONにして書くStage
+func CSVToNDJSON() Stage {
+	return Stage{Name: "csv to ndjson", Fn: func(ctx context.Context, r io.Reader, w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		enc := json.NewEncoder(bw)
+		if err := EachRecord(NewCSVReader(r), func(rec Record) error { return enc.Encode(rec) }); err != nil {
+			return err
+		}
+		return bw.Flush()
+	}}
+}
+
+// FilterRecords はNDJSONを読んでkeepがtrueを返したレコードだけを書くStage
+func FilterRecords(keep func(Record) bool) Stage {
+	return Stage{Name: "filter records", Fn: func(ctx context.Context, r io.Reader, w io.Writer) error {
+		bw := bufio.NewWriter(w)
+		enc := json.NewEncoder(bw)
+		err := EachRecord(NewNDJSONReader(r), func(rec Record) error {
+			if !keep(rec) {
+				return nil
+			}
+			return enc.Encode(rec)
+		})
+		if err != nil {
+			return err
+		}
+		return bw.Flush()
+	}}
+}
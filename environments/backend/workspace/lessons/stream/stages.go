@@ -0,0 +1,138 @@
+package stream
+
+import (
+	"compress/gzip"
+	"context"
+	"hash"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Gzip はgzipで圧縮するStage（levelはgzip.DefaultCompressionなど）
+func Gzip(level int) Stage {
+	return Stage{Name: "gzip", Fn: func(ctx context.Context, r io.Reader, w io.Writer) error {
+		zw, err := gzip.NewWriterLevel(w, level)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(zw, r); err != nil {
+			return err
+		}
+		// Closeで残りのデータとチェックサムを書く
+		return zw.Close()
+	}}
+}
+
+// Gunzip はgzipを展開するStage。壊れたデータやチェックサムの不一致はエラーになる
+func Gunzip() Stage {
+	return Stage{Name: "gunzip", Fn: func(ctx context.Context, r io.Reader, w io.Writer) error {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		_, err = io.Copy(w, zr)
+		return err
+	}}
+}
+
+// Checksum は通ったデータをそのまま書き、hにも書くStage（io.TeeReader）
+// Pipeline.Runが終わった後に hex.EncodeToString(h.Sum(nil)) で値を取り出す
+func Checksum(h hash.Hash) Stage {
+	return ReaderStage("checksum", func(ctx context.Context, r io.Reader) io.Reader {
+		return io.TeeReader(r, h)
+	})
+}
+
+// ProgressReader は読んだバイト数を数え、every バイトごとと最後にfnを呼ぶio.Reader
+type ProgressReader struct {
+	r     io.Reader
+	every int64
+	fn    func(total int64)
+	total atomic.Int64
+	next  int64 // 次にfnを呼ぶバイト数
+	last  int64 // 最後にfnに渡したバイト数
+}
+
+func NewProgressReader(r io.Reader, every int64, fn func(total int64)) *ProgressReader {
+	return &ProgressReader{r: r, every: every, fn: fn, next: every}
+}
+
+func (p *ProgressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	total := p.total.Add(int64(n))
+	if p.every > 0 && total >= p.next {
+		p.report(total)
+		for p.next <= total {
+			p.next += p.every
+		}
+	}
+	if err == io.EOF && total != p.last {
+		p.report(total)
+	}
+	return n, err
+}
+
+func (p *ProgressReader) report(total int64) {
+	p.last = total
+	p.fn(total)
+}
+
+// Total はそれまでに読んだバイト数を返す（別のgoroutineから呼んでよい）
+func (p *ProgressReader) Total() int64 { return p.total.Load() }
+
+// Progress は通ったバイト数をeveryバイトごとと最後にfnで知らせるStage
+func Progress(every int64, fn func(total int64)) Stage {
+	return ReaderStage("progress", func(ctx context.Context, r io.Reader) io.Reader {
+		return NewProgressReader(r, every, fn)
+	})
+}
+
+// ThrottledReader は1秒あたりbytesPerSecバイトを超えないように読むio.Reader
+// 待っている間にctxがキャンセルされたらctx.Err()を返す
+type ThrottledReader struct {
+	ctx   context.Context
+	r     io.Reader
+	rate  int64 // 1秒あたりのバイト数
+	start time.Time
+	total int64
+}
+
+func NewThrottledReader(ctx context.Context, r io.Reader, bytesPerSec int64) *ThrottledReader {
+	return &ThrottledReader{ctx: ctx, r: r, rate: bytesPerSec}
+}
+
+func (t *ThrottledReader) Read(b []byte) (int, error) {
+	if t.rate <= 0 {
+		return t.r.Read(b)
+	}
+	if t.start.IsZero() {
+		t.start = time.Now()
+	}
+	// 1回に読む量を0.1秒分までにして、待ち時間が長くなりすぎないようにする
+	if limit := max(t.rate/10, 1); int64(len(b)) > limit {
+		b = b[:limit]
+	}
+	n, err := t.r.Read(b)
+	t.total += int64(n)
+	// total バイト読むのにかかるべき時間まで待つ
+	due := t.start.Add(time.Duration(float64(t.total) / float64(t.rate) * float64(time.Second)))
+	if wait := time.Until(due); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-t.ctx.Done():
+			return n, t.ctx.Err()
+		}
+	}
+	return n, err
+}
+
+// Throttle は1秒あたりbytesPerSecバイトまでに流す量を抑えるStage
+func Throttle(bytesPerSec int64) Stage {
+	return ReaderStage("throttle", func(ctx context.Context, r io.Reader) io.Reader {
+		return NewThrottledReader(ctx, r, bytesPerSec)
+	})
+}
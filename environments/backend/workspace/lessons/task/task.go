@@ -0,0 +1,43 @@
+// Package task はテーマ03（メソッドとレシーバー）のタスク
+package task
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Task は優先度（1-5）付きのタスク
+type Task struct {
+	Title    string
+	Done     bool
+	Priority int
+}
+
+// NewTask はTaskのコンストラクタ
+func NewTask(title string, priority int) (*Task, error) {
+	if title == "" {
+		return nil, errors.New("title is required")
+	}
+	if priority < 1 || priority > 5 {
+		return nil, fmt.Errorf("priority must be 1-5, got %d", priority)
+	}
+	return &Task{Title: title, Priority: priority}, nil
+}
+
+// Complete はタスクを完了にする（状態を変えるのでポインタレシーバー）
+func (t *Task) Complete() {
+	t.Done = true
+}
+
+// IsHighPriority は優先度4以上かを返す（読み取りだけなので値レシーバー）
+func (t Task) IsHighPriority() bool {
+	return t.Priority >= 4
+}
+
+func (t Task) String() string {
+	check := " "
+	if t.Done {
+		check = "x"
+	}
+	return fmt.Sprintf("[%s] %s (優先度:%d)", check, t.Title, t.Priority)
+}
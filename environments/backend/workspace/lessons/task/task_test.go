@@ -0,0 +1,69 @@
+package task_test
+
+import (
+	"testing"
+
+	"workout/lessons/task"
+)
+
+func TestNewTask(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		priority int
+		wantErr  string
+	}{
+		{name: "lowest priority", title: "買い物", priority: 1},
+		{name: "highest priority", title: "締め切り", priority: 5},
+		{name: "empty title", title: "", priority: 3, wantErr: "title is required"},
+		{name: "priority too low", title: "x", priority: 0, wantErr: "priority must be 1-5, got 0"},
+		{name: "priority too high", title: "x", priority: 6, wantErr: "priority must be 1-5, got 6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := task.NewTask(tt.title, tt.priority)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewTask: %v", err)
+			}
+			want := task.Task{Title: tt.title, Priority: tt.priority}
+			if *got != want {
+				t.Errorf("got %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
+
+func TestTaskMethods(t *testing.T) {
+	tests := []struct {
+		priority int
+		done     bool
+		wantHigh bool
+		want     string
+	}{
+		{priority: 3, want: "[ ] レポート (優先度:3)"},
+		{priority: 4, wantHigh: true, want: "[ ] レポート (優先度:4)"},
+		{priority: 5, done: true, wantHigh: true, want: "[x] レポート (優先度:5)"},
+	}
+	for _, tt := range tests {
+		tk, err := task.NewTask("レポート", tt.priority)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.done {
+			// ポインタレシーバーなので、呼び出し元のtkが変わる
+			tk.Complete()
+		}
+		if tk.IsHighPriority() != tt.wantHigh {
+			t.Errorf("priority %d: IsHighPriority() = %v", tt.priority, tk.IsHighPriority())
+		}
+		if got := tk.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Package todo はテーマ11（encoding/jsonと構造体タグ）のTODOのJSON表現
+package todo
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Todo は1つのTODO。DueDateがnilならJSONから省略する
+type Todo struct {
+	ID      int        `json:"id"`
+	Title   string     `json:"title"`
+	Done    bool       `json:"done"`
+	DueDate *time.Time `json:"due_date,omitempty"`
+}
+
+// TodoList はAPIレスポンスのTODOの一覧
+type TodoList struct {
+	Todos []Todo `json:"todos"`
+	Count int    `json:"count"`
+}
+
+// ToJSON はvをインデント付きのJSONにする
+func ToJSON(v any) (string, error) {
+	jsonBytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("marshal error: %w", err)
+	}
+	return string(jsonBytes), nil
+}
+
+// FromJSON はjsonStrをvに読み込む
+func FromJSON(jsonStr string, v any) error {
+	if err := json.Unmarshal([]byte(jsonStr), v); err != nil {
+		return fmt.Errorf("unmarshal error: %w", err)
+	}
+	return nil
+}
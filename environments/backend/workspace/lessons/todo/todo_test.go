@@ -0,0 +1,128 @@
+package todo_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"workout/lessons/todo"
+)
+
+func TestToJSON(t *testing.T) {
+	due := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{
+			// DueDateがnilなら省略する
+			name: "without due date",
+			v:    todo.Todo{ID: 1, Title: "牛乳を買う"},
+			want: "{\n  \"id\": 1,\n  \"title\": \"牛乳を買う\",\n  \"done\": false\n}",
+		},
+		{
+			name: "with due date",
+			v:    todo.Todo{ID: 2, Title: "確定申告", Done: true, DueDate: &due},
+			want: "{\n  \"id\": 2,\n  \"title\": \"確定申告\",\n  \"done\": true,\n  \"due_date\": \"2024-12-31T00:00:00Z\"\n}",
+		},
+		{
+			name: "empty list",
+			v:    todo.TodoList{},
+			want: "{\n  \"todos\": null,\n  \"count\": 0\n}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := todo.ToJSON(tt.v)
+			if err != nil {
+				t.Fatalf("ToJSON: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("unsupported value", func(t *testing.T) {
+		_, err := todo.ToJSON(make(chan int))
+		if err == nil || !strings.HasPrefix(err.Error(), "marshal error: ") {
+			t.Errorf("err = %v, want marshal error", err)
+		}
+	})
+}
+
+func TestFromJSON(t *testing.T) {
+	due := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		input   string
+		want    todo.TodoList
+		wantErr bool
+	}{
+		{
+			name:  "list",
+			input: `{"todos":[{"id":1,"title":"a","done":true,"due_date":"2024-12-31T00:00:00Z"},{"id":2,"title":"b"}],"count":2}`,
+			want:  todo.TodoList{Todos: []todo.Todo{{ID: 1, Title: "a", Done: true, DueDate: &due}, {ID: 2, Title: "b"}}, Count: 2},
+		},
+		{
+			// 知らないフィールドは無視する
+			name:  "unknown field",
+			input: `{"todos":[],"count":0,"page":1}`,
+			want:  todo.TodoList{Todos: []todo.Todo{}},
+		},
+		{name: "broken JSON", input: `{"todos":[`, wantErr: true},
+		{name: "wrong type", input: `{"count":"two"}`, wantErr: true},
+		{name: "bad time", input: `{"todos":[{"due_date":"tomorrow"}]}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got todo.TodoList
+			err := todo.FromJSON(tt.input, &got)
+			if tt.wantErr {
+				if err == nil || !strings.HasPrefix(err.Error(), "unmarshal error: ") {
+					t.Fatalf("err = %v, want unmarshal error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FromJSON: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// FuzzTodoRoundTrip はFromJSONで読めたTodoが、ToJSONとFromJSONで同じ値に戻ることを確かめる
+func FuzzTodoRoundTrip(f *testing.F) {
+	f.Add(`{"id":1,"title":"牛乳を買う","done":false}`)
+	f.Add(`{"id":2,"title":"確定申告","done":true,"due_date":"2024-12-31T00:00:00+09:00"}`)
+	f.Add(`{"title":"\u0000\ud800"}`)
+	f.Add(`[]`)
+	f.Fuzz(func(t *testing.T, input string) {
+		var first todo.Todo
+		if err := todo.FromJSON(input, &first); err != nil {
+			return
+		}
+		out, err := todo.ToJSON(first)
+		if err != nil {
+			// time.Timeは年が0-9999の範囲外だとMarshalできない
+			if first.DueDate != nil && (first.DueDate.Year() < 0 || first.DueDate.Year() > 9999) {
+				return
+			}
+			t.Fatalf("ToJSON(%+v): %v", first, err)
+		}
+		var second todo.Todo
+		if err := todo.FromJSON(out, &second); err != nil {
+			t.Fatalf("FromJSON(%s): %v", out, err)
+		}
+		if first.ID != second.ID || first.Title != second.Title || first.Done != second.Done ||
+			(first.DueDate == nil) != (second.DueDate == nil) ||
+			(first.DueDate != nil && !first.DueDate.Equal(*second.DueDate)) {
+			t.Errorf("round trip changed %+v to %+v", first, second)
+		}
+	})
+}
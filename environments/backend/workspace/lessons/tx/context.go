@@ -0,0 +1,42 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// BeginTxContext はテーマ17（context）のBeginTx。ctxが既に終わっていれば開始しない
+// 開始したトランザクションのExecとCommitは、ctxが終わった後はエラーになる
+func BeginTxContext(ctx context.Context, log io.Writer) (*Transaction, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("begin: %w", err)
+	}
+	tx := BeginTx(log)
+	tx.ctx = ctx
+	return tx, nil
+}
+
+// Exec はSQLの実行のシミュレーション。costだけ時間がかかり、その間にctxが終わればエラー
+func (tx *Transaction) Exec(query string, cost time.Duration) error {
+	timer := time.NewTimer(cost)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		fmt.Fprintf(tx.Log, "[%s] %s\n", tx.ID, query)
+		return nil
+	case <-tx.ctx.Done():
+		return fmt.Errorf("exec %q: %w", query, tx.ctx.Err())
+	}
+}
+
+// ExecuteInTxContext はctxの期限内にfnを実行してコミットする
+// fnのエラー、panic、キャンセルのいずれでもdeferでロールバックする
+func ExecuteInTxContext(ctx context.Context, log io.Writer, fn func(tx *Transaction) error) error {
+	tx, err := BeginTxContext(ctx, log)
+	if err != nil {
+		return err
+	}
+	return run(tx, fn)
+}
@@ -0,0 +1,92 @@
+// Package tx はテーマ09（defer文の活用）のトランザクション
+// Transactionは実際のDBを使わず、BEGIN/COMMIT/ROLLBACKをLogに書く
+// テーマ17のcontext版（ExecuteInTxContext）とテーマ28のdatabase/sql版（ExecuteInSQLTx）もここに置く
+package tx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+var lastID atomic.Int64
+
+// Transaction はコミットかロールバックのどちらか一度だけ終わるトランザクション
+type Transaction struct {
+	ID         string
+	Log        io.Writer
+	ctx        context.Context
+	committed  bool
+	rolledback bool
+}
+
+// BeginTx はトランザクションを開始する。logがnilなら何も書かない
+func BeginTx(log io.Writer) *Transaction {
+	if log == nil {
+		log = io.Discard
+	}
+	tx := &Transaction{ID: fmt.Sprintf("tx_%d", lastID.Add(1)), Log: log, ctx: context.Background()}
+	fmt.Fprintf(tx.Log, "[%s] BEGIN\n", tx.ID)
+	return tx
+}
+
+// Commit はコミットする。コミット済み・ロールバック済みならエラー
+// 開始時のctxが終わった後もコミットしない（呼び出し元は既に結果を待っていない）
+func (tx *Transaction) Commit() error {
+	if tx.committed {
+		return errors.New("already committed")
+	}
+	if tx.rolledback {
+		return errors.New("already rolled back")
+	}
+	if err := tx.ctx.Err(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	tx.committed = true
+	fmt.Fprintf(tx.Log, "[%s] COMMIT\n", tx.ID)
+	return nil
+}
+
+// Rollback はロールバックする。コミット済み・ロールバック済みなら何もしない
+// そのため defer tx.Rollback() を常に書いておける
+func (tx *Transaction) Rollback() error {
+	if tx.committed {
+		fmt.Fprintf(tx.Log, "[%s] ROLLBACK skipped (already committed)\n", tx.ID)
+		return nil
+	}
+	if tx.rolledback {
+		return nil
+	}
+	tx.rolledback = true
+	fmt.Fprintf(tx.Log, "[%s] ROLLBACK\n", tx.ID)
+	return nil
+}
+
+// Committed はコミット済みかを返す
+func (tx *Transaction) Committed() bool { return tx.committed }
+
+// RolledBack はロールバック済みかを返す
+func (tx *Transaction) RolledBack() bool { return tx.rolledback }
+
+// ExecuteInTx はトランザクション内でfnを実行する
+// fnが成功したらコミットし、エラーを返すかpanicしたらdeferでロールバックする
+func ExecuteInTx(log io.Writer, fn func(tx *Transaction) error) error {
+	return run(BeginTx(log), fn)
+}
+
+func run(tx *Transaction, fn func(tx *Transaction) error) error {
+	defer func() {
+		if tx.committed {
+			return
+		}
+		tx.Rollback()
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,276 @@
+package tx_test
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"maps"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"workout/lessons/fakesql"
+	"workout/lessons/tx"
+)
+
+// steps はログから "[tx_1] " を除いた操作の一覧を返す
+func steps(log string) []string {
+	re := regexp.MustCompile(`^\[tx_\d+\] `)
+	var out []string
+	for _, line := range strings.Split(strings.TrimSpace(log), "\n") {
+		out = append(out, re.ReplaceAllString(line, ""))
+	}
+	return out
+}
+
+func TestExecuteInTx(t *testing.T) {
+	errFailed := errors.New("insert failed")
+	tests := []struct {
+		name      string
+		fn        func(*tx.Transaction) error
+		wantErr   error
+		wantPanic bool
+		wantSteps []string
+	}{
+		{
+			name:      "commit",
+			fn:        func(*tx.Transaction) error { return nil },
+			wantSteps: []string{"BEGIN", "COMMIT"},
+		},
+		{
+			name:      "rollback on error",
+			fn:        func(*tx.Transaction) error { return errFailed },
+			wantErr:   errFailed,
+			wantSteps: []string{"BEGIN", "ROLLBACK"},
+		},
+		{
+			// panicしてもdeferでロールバックしてから、panicは呼び出し元に伝わる
+			name:      "rollback on panic",
+			fn:        func(*tx.Transaction) error { panic("boom") },
+			wantPanic: true,
+			wantSteps: []string{"BEGIN", "ROLLBACK"},
+		},
+		{
+			name: "fn commits itself",
+			fn: func(t *tx.Transaction) error {
+				return t.Commit()
+			},
+			wantErr:   errors.New("already committed"),
+			wantSteps: []string{"BEGIN", "COMMIT"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var log bytes.Buffer
+			var err error
+			func() {
+				defer func() {
+					if r := recover(); (r != nil) != tt.wantPanic {
+						t.Errorf("recover() = %v, want panic %v", r, tt.wantPanic)
+					}
+				}()
+				err = tx.ExecuteInTx(&log, tt.fn)
+			}()
+			if tt.wantErr != nil && (err == nil || err.Error() != tt.wantErr.Error()) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if got := steps(log.String()); strings.Join(got, ",") != strings.Join(tt.wantSteps, ",") {
+				t.Errorf("steps = %q, want %q", got, tt.wantSteps)
+			}
+		})
+	}
+}
+
+func TestCommitRollbackStates(t *testing.T) {
+	var log bytes.Buffer
+	committed := tx.BeginTx(&log)
+	if err := committed.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if err := committed.Commit(); err == nil || err.Error() != "already committed" {
+		t.Errorf("second Commit: %v", err)
+	}
+	// コミット後のRollbackはスキップされる（defer tx.Rollback()を書いておける）
+	if err := committed.Rollback(); err != nil || committed.RolledBack() {
+		t.Errorf("Rollback after commit: err=%v rolledBack=%v", err, committed.RolledBack())
+	}
+
+	rolled := tx.BeginTx(nil)
+	rolled.Rollback()
+	rolled.Rollback()
+	if err := rolled.Commit(); err == nil || err.Error() != "already rolled back" {
+		t.Errorf("Commit after rollback: %v", err)
+	}
+	if !rolled.RolledBack() || rolled.Committed() {
+		t.Errorf("state = committed %v, rolled back %v", rolled.Committed(), rolled.RolledBack())
+	}
+
+	want := []string{"BEGIN", "COMMIT", "ROLLBACK skipped (already committed)"}
+	if got := steps(log.String()); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("steps = %q, want %q", got, want)
+	}
+	if committed.ID == rolled.ID {
+		t.Errorf("IDs are not unique: %s", committed.ID)
+	}
+}
+
+func TestExecuteInTxContext(t *testing.T) {
+	insert := func(cost time.Duration) func(*tx.Transaction) error {
+		return func(t *tx.Transaction) error {
+			if err := t.Exec("INSERT INTO users ...", time.Millisecond); err != nil {
+				return err
+			}
+			return t.Exec("UPDATE stats ...", cost)
+		}
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name      string
+		ctx       context.Context
+		timeout   time.Duration
+		fn        func(*tx.Transaction) error
+		wantErr   error
+		wantSteps []string
+	}{
+		{
+			name:      "within deadline",
+			ctx:       context.Background(),
+			timeout:   time.Second,
+			fn:        insert(time.Millisecond),
+			wantSteps: []string{"BEGIN", "INSERT INTO users ...", "UPDATE stats ...", "COMMIT"},
+		},
+		{
+			// 途中で期限が切れたらExecがエラーを返し、ロールバックする
+			name:      "deadline exceeded during exec",
+			ctx:       context.Background(),
+			timeout:   20 * time.Millisecond,
+			fn:        insert(time.Second),
+			wantErr:   context.DeadlineExceeded,
+			wantSteps: []string{"BEGIN", "INSERT INTO users ...", "ROLLBACK"},
+		},
+		{
+			// 期限が切れた後はfnが成功してもコミットしない
+			name:    "deadline exceeded before commit",
+			ctx:     context.Background(),
+			timeout: 10 * time.Millisecond,
+			fn: func(*tx.Transaction) error {
+				time.Sleep(30 * time.Millisecond)
+				return nil
+			},
+			wantErr:   context.DeadlineExceeded,
+			wantSteps: []string{"BEGIN", "ROLLBACK"},
+		},
+		{
+			name:      "already canceled",
+			ctx:       canceled,
+			timeout:   time.Second,
+			fn:        insert(time.Millisecond),
+			wantErr:   context.Canceled,
+			wantSteps: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(tt.ctx, tt.timeout)
+			defer cancel()
+			var log bytes.Buffer
+			err := tx.ExecuteInTxContext(ctx, &log, tt.fn)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			var got []string
+			if log.Len() > 0 {
+				got = steps(log.String())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantSteps, ",") {
+				t.Errorf("steps = %q, want %q", got, tt.wantSteps)
+			}
+		})
+	}
+}
+
+// newAccountDB はaccountsテーブル（id→残高）を持つDB。ロールバックで開始時の残高に戻す
+func newAccountDB(balances map[int64]int64) *fakesql.DB {
+	return &fakesql.DB{
+		Snapshot: func() func() {
+			saved := maps.Clone(balances)
+			return func() { clear(balances); maps.Copy(balances, saved) }
+		},
+		Queries: map[string]fakesql.QueryFunc{
+			"UPDATE accounts SET balance = balance + ? WHERE id = ?": func(args []driver.Value) (*fakesql.Result, error) {
+				balances[args[1].(int64)] += args[0].(int64)
+				return &fakesql.Result{Affected: 1}, nil
+			},
+		},
+	}
+}
+
+func TestExecuteInSQLTx(t *testing.T) {
+	errFailed := errors.New("insufficient funds")
+	deposit := func(ctx context.Context, t *sql.Tx) error {
+		_, err := t.ExecContext(ctx, "UPDATE accounts SET balance = balance + ? WHERE id = ?", int64(100), int64(1))
+		return err
+	}
+	tests := []struct {
+		name      string
+		fn        func(ctx context.Context, t *sql.Tx) error
+		wantErr   error
+		wantPanic bool
+		want      int64
+	}{
+		{name: "commit", fn: deposit, want: 100},
+		{
+			name: "rollback on error",
+			fn: func(ctx context.Context, t *sql.Tx) error {
+				if err := deposit(ctx, t); err != nil {
+					return err
+				}
+				return errFailed
+			},
+			wantErr: errFailed,
+			want:    0,
+		},
+		{
+			// panicしてもロールバックしてから、panicは呼び出し元に伝わる
+			name: "rollback on panic",
+			fn: func(ctx context.Context, t *sql.Tx) error {
+				deposit(ctx, t)
+				panic("boom")
+			},
+			wantPanic: true,
+			want:      0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			balances := map[int64]int64{1: 0}
+			db := sql.OpenDB(newAccountDB(balances))
+			defer db.Close()
+			ctx := context.Background()
+
+			var err error
+			func() {
+				defer func() {
+					if r := recover(); (r != nil) != tt.wantPanic {
+						t.Errorf("recover() = %v, want panic %v", r, tt.wantPanic)
+					}
+				}()
+				err = tx.ExecuteInSQLTx(ctx, db, func(sqlTx *sql.Tx) error { return tt.fn(ctx, sqlTx) })
+			}()
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if balances[1] != tt.want {
+				t.Errorf("balance = %d, want %d", balances[1], tt.want)
+			}
+		})
+	}
+}
@@ -1,37 +1,215 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"workout/alerts"
+	"workout/api"
+	"workout/domain"
+	"workout/lessons/logger"
+	"workout/migrations"
+	"workout/notifier"
+	"workout/postgres"
 )
 
-type Notifier interface {
-	Notify(message string) error
+func main() {
+	log, err := newLogger()
+	if err != nil {
+		logger.Default().Fatal("configure logger", logger.Err(err))
+	}
+	// api以外のパッケージがlogger.Default()で書くログも同じ形式にする
+	logger.SetDefault(log)
+	if err := run(log); err != nil {
+		log.Fatal("server failed", logger.Err(err))
+	}
 }
 
-type EmailNotifier struct {
-	To string
+// newLogger はLOG_LEVEL（debug, info, warn, error）とLOG_FORMAT（text, json, logfmt）に従うLoggerを作る
+func newLogger() (*logger.Logger, error) {
+	level, err := logger.ParseLevel(getenv("LOG_LEVEL", "info"))
+	if err != nil {
+		return nil, err
+	}
+	var enc logger.Encoder
+	switch format := getenv("LOG_FORMAT", "text"); format {
+	case "text":
+		enc = logger.TextEncoder{}
+	case "json":
+		enc = logger.JSONEncoder{}
+	case "logfmt":
+		enc = logger.LogfmtEncoder{}
+	default:
+		return nil, fmt.Errorf("unknown LOG_FORMAT %q (want text, json or logfmt)", format)
+	}
+	return logger.New(os.Stderr, logger.Options{Level: level, Encoder: enc, AddTime: true}), nil
 }
 
-type SlackNotifier struct {
-	Channel string
-}
+func run(log *logger.Logger) error {
+	addr := ":" + getenv("PORT", "8080")
+
+	// SIGTERM（docker stop）とSIGINT（Ctrl+C）で終了処理を始める
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	repos, closeRepos, err := openRepositories(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer closeRepos()
+
+	// 通知はアウトボックスに書くだけにして、送信はDispatcherがバックグラウンドで行う
+	outbox, err := notifier.OpenFileOutbox(getenv("OUTBOX_PATH", "tmp/outbox.json"))
+	if err != nil {
+		return err
+	}
+	// 再試行を諦めた通知はデッドレターに移す。REPLAY_DEAD_LETTERS=true で起動すると送り直す
+	dispatcher := notifier.NewDispatcher(outbox, map[string]notifier.Notifier{
+		alerts.ChannelEmail: emailNotifier(),
+		alerts.ChannelSlack: slackNotifier(),
+	}, notifier.DispatcherOptions{
+		Timeout: 30 * time.Second,
+		// 送信済みのエントリは1週間で消す（その間は同じイベントの通知を重複として捨てる）
+		Retention:   7 * 24 * time.Hour,
+		DeadLetters: notifier.NewFileDeadLetterStore(getenv("DEAD_LETTER_PATH", "tmp/deadletters.json")),
+		OnError: func(e notifier.OutboxEntry, err error) {
+			log.Error("outbox update failed", logger.F("entry", e.ID), logger.F("channel", e.Channel), logger.Err(err))
+		},
+	})
+	if os.Getenv("REPLAY_DEAD_LETTERS") == "true" {
+		result, err := dispatcher.ReplayDeadLetters(ctx)
+		if err != nil {
+			return fmt.Errorf("replay dead letters: %w", err)
+		}
+		log.Info("dead letters replayed",
+			logger.F("replayed", result.Replayed), logger.F("failed", result.Failed), logger.F("skipped", result.Skipped))
+	}
+	dispatchCtx, stopDispatch := context.WithCancel(ctx)
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		dispatcher.Run(dispatchCtx)
+	}()
+	// サーバーを止めた後に、送信中の通知を戻してから終了する
+	defer func() {
+		stopDispatch()
+		<-dispatcherDone
+	}()
+
+	router, err := alerts.NewRouter(repos.preferences,
+		dispatcher.Notifier(alerts.ChannelEmail), dispatcher.Notifier(alerts.ChannelSlack))
+	if err != nil {
+		return err
+	}
+	sessions := domain.NewSessionService(repos.sessions, repos.exercises)
+	// 連続記録の日付はサーバーのタイムゾーン（TZ環境変数）で数える
+	sessions.SetLocation(time.Local)
+	sessions.SetEventPublisher(alerts.NewPublisher(router, repos.exercises))
+
+	srv := &http.Server{
+		Addr: addr,
+		Handler: api.NewServer(api.Services{
+			Sessions:    sessions,
+			Exercises:   repos.exercises,
+			Preferences: repos.preferences,
+			Logger:      log,
+		}),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("listening", logger.F("addr", addr), logger.F("go_env", getenv("GO_ENV", "development")))
+		errCh <- srv.ListenAndServe()
+	}()
 
-func (e *EmailNotifier) Notify(message string) error {
-	fmt.Printf("Sending email to %s: %s\n", e.To, message)
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	// 処理中のリクエストが終わるのを最大10秒待ってから止める
+	log.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	log.Info("server stopped")
 	return nil
 }
 
-func (s *SlackNotifier) Notify(message string) error {
-	fmt.Printf("Sending Slack message to channel %s: %s\n", s.Channel, message)
-	return nil
+type repositories struct {
+	sessions    domain.SessionRepository
+	exercises   domain.ExerciseRepository
+	preferences api.PreferenceStore
+}
+
+// openRepositories はDATABASE_URLがあればPostgreSQL、なければメモリ上のリポジトリを使う
+// docker compose --profile db で起動したときだけDBに接続される
+func openRepositories(ctx context.Context, log *logger.Logger) (repositories, func(), error) {
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		log.Info("DATABASE_URL is not set; using in-memory repositories")
+		return repositories{
+			sessions:    domain.NewInMemorySessionRepository(),
+			exercises:   domain.NewInMemoryExerciseRepository(domain.DefaultExercises()...),
+			preferences: notifier.NewMemoryPreferenceStore(),
+		}, func() {}, nil
+	}
+
+	db, err := postgres.Open(ctx, databaseURL)
+	if err != nil {
+		return repositories{}, nil, err
+	}
+	if err := postgres.Migrate(ctx, db, migrations.FS); err != nil {
+		db.Close()
+		return repositories{}, nil, fmt.Errorf("migrate: %w", err)
+	}
+	return repositories{
+		sessions:    postgres.NewSessionRepository(db),
+		exercises:   postgres.NewExerciseRepository(db),
+		preferences: postgres.NewPreferenceStore(db),
+	}, func() { db.Close() }, nil
 }
 
-func SendAll(notifiers []Notifier, message string) []error {
-	var errors []error
-	for _, notifier := range notifiers {
-		if err := notifier.Notify(message); err != nil {
-			errors = append(errors, err)
+// emailNotifier はSMTP_ADDRがあればSMTPで送り、なければ標準出力に表示するEmailNotifierを返す
+func emailNotifier() *notifier.EmailNotifier {
+	e := &notifier.EmailNotifier{
+		From:            getenv("MAIL_FROM", "workout@localhost"),
+		SubjectTemplate: "workout: トレーニングのお知らせ",
+	}
+	if addr := os.Getenv("SMTP_ADDR"); addr != "" {
+		e.SMTP = &notifier.SMTPConfig{
+			Addr:     addr,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			StartTLS: os.Getenv("SMTP_STARTTLS") != "false",
+			Timeout:  10 * time.Second,
 		}
 	}
-	return errors
-}	
\ No newline at end of file
+	return e
+}
+
+// slackNotifier はSLACK_WEBHOOK_URLがなければ標準出力に表示するSlackNotifierを返す
+// 429の再試行はDispatcherに任せる（MaxRetries: -1）
+func slackNotifier() *notifier.SlackNotifier {
+	return &notifier.SlackNotifier{WebhookURL: os.Getenv("SLACK_WEBHOOK_URL"), Username: "workout", MaxRetries: -1}
+}
+
+func getenv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
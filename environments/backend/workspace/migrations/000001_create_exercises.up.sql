@@ -0,0 +1,13 @@
+CREATE TABLE exercises (
+    id           SERIAL PRIMARY KEY,
+    name         TEXT NOT NULL UNIQUE,
+    muscle_group TEXT NOT NULL
+);
+
+-- domain.DefaultExercises と同じ初期データ
+INSERT INTO exercises (name, muscle_group) VALUES
+    ('ベンチプレス', 'chest'),
+    ('スクワット', 'legs'),
+    ('デッドリフト', 'back'),
+    ('オーバーヘッドプレス', 'shoulders'),
+    ('懸垂', 'back');
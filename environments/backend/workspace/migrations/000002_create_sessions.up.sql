@@ -0,0 +1,20 @@
+CREATE TABLE sessions (
+    id           SERIAL PRIMARY KEY,
+    user_id      INTEGER NOT NULL,
+    performed_at TIMESTAMPTZ NOT NULL,
+    note         TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL,
+    updated_at   TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX sessions_user_id_performed_at_idx ON sessions (user_id, performed_at DESC);
+
+-- セットの順番を保つためにpositionを持たせる
+CREATE TABLE session_sets (
+    session_id  INTEGER NOT NULL REFERENCES sessions (id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL,
+    exercise_id INTEGER NOT NULL REFERENCES exercises (id),
+    reps        INTEGER NOT NULL CHECK (reps > 0),
+    weight_kg   NUMERIC(6, 2) NOT NULL CHECK (weight_kg >= 0),
+    PRIMARY KEY (session_id, position)
+);
@@ -0,0 +1,14 @@
+CREATE TABLE routines (
+    id      SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    name    TEXT NOT NULL
+);
+
+CREATE INDEX routines_user_id_idx ON routines (user_id);
+
+CREATE TABLE routine_exercises (
+    routine_id  INTEGER NOT NULL REFERENCES routines (id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL,
+    exercise_id INTEGER NOT NULL REFERENCES exercises (id),
+    PRIMARY KEY (routine_id, position)
+);
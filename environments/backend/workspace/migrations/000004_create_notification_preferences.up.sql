@@ -0,0 +1,6 @@
+CREATE TABLE notification_preferences (
+    user_id       INTEGER PRIMARY KEY,
+    email         TEXT NOT NULL DEFAULT '',
+    slack_channel TEXT NOT NULL DEFAULT '',
+    opt_out       TEXT[] NOT NULL DEFAULT '{}'
+);
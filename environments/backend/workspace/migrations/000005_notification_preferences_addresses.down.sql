@@ -0,0 +1,15 @@
+ALTER TABLE notification_preferences
+    ADD COLUMN email         TEXT NOT NULL DEFAULT '',
+    ADD COLUMN slack_channel TEXT NOT NULL DEFAULT '';
+
+UPDATE notification_preferences SET
+    email = COALESCE(addresses->>'email', ''),
+    slack_channel = COALESCE(addresses->>'slack', ''),
+    opt_out = ARRAY(
+        SELECT substr(t, length('workout.') + 1) FROM unnest(muted_topics) AS t
+        WHERE t LIKE 'workout.%');
+
+ALTER TABLE notification_preferences
+    DROP COLUMN addresses,
+    DROP COLUMN muted_topics,
+    DROP COLUMN min_severity;
@@ -0,0 +1,18 @@
+-- 通知設定をnotifier.Preferencesに合わせる
+-- 宛先はチャネル名→宛先のaddressesに、拒否したイベントはmuted_topicsのトピック（workout.<type>）に移す
+-- opt_outは受け取りを拒否したチャネル名になる
+ALTER TABLE notification_preferences
+    ADD COLUMN addresses    JSONB   NOT NULL DEFAULT '{}',
+    ADD COLUMN muted_topics TEXT[]  NOT NULL DEFAULT '{}',
+    ADD COLUMN min_severity INTEGER NOT NULL DEFAULT 0;
+
+UPDATE notification_preferences SET
+    addresses = jsonb_strip_nulls(jsonb_build_object(
+        'email', NULLIF(email, ''),
+        'slack', NULLIF(slack_channel, ''))),
+    muted_topics = ARRAY(SELECT 'workout.' || t FROM unnest(opt_out) AS t),
+    opt_out = '{}';
+
+ALTER TABLE notification_preferences
+    DROP COLUMN email,
+    DROP COLUMN slack_channel;
@@ -0,0 +1,15 @@
+// Package migrations はgolang-migrate形式のSQLマイグレーションを埋め込む
+//
+// CLIで適用する場合（コンテナ内）:
+//
+//	migrate -path migrations -database "$DATABASE_URL" up
+//
+// アプリ起動時にはpostgres.Migrateが同じファイルを適用する（戻すときはpostgres.MigrateDown）
+package migrations
+
+import "embed"
+
+// FS は{version}_{title}.{up|down}.sql のファイル群
+//
+//go:embed *.sql
+var FS embed.FS
@@ -0,0 +1,250 @@
+package notifier
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrDeadLetterNotFound は指定IDのデッドレターが存在しない場合のエラー
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter は再試行を諦めたメッセージ1件分の記録
+// 再送で同じ宛先・同じ冪等キーになるように、送信時のctxのRecipientとIdempotencyKeyも残す
+type DeadLetter struct {
+	ID             string    `json:"id"`
+	Notifier       string    `json:"notifier"`
+	Recipient      string    `json:"recipient,omitempty"`
+	IdempotencyKey string    `json:"idempotency_key,omitempty"`
+	Message        string    `json:"message"`
+	Error          string    `json:"error"`
+	Attempts       int       `json:"attempts"`
+	FailedAt       time.Time `json:"failed_at"`
+}
+
+// DeadLetterStore はデッドレターの保存先
+type DeadLetterStore interface {
+	Put(dl DeadLetter) error
+	List() ([]DeadLetter, error)
+	Delete(id string) error
+}
+
+// MemoryDeadLetterStore はメモリ上のDeadLetterStore（テスト・開発用）
+type MemoryDeadLetterStore struct {
+	mu      sync.Mutex
+	letters map[string]DeadLetter
+}
+
+func NewMemoryDeadLetterStore() *MemoryDeadLetterStore {
+	return &MemoryDeadLetterStore{letters: make(map[string]DeadLetter)}
+}
+
+func (s *MemoryDeadLetterStore) Put(dl DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters[dl.ID] = dl
+	return nil
+}
+
+func (s *MemoryDeadLetterStore) List() ([]DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]DeadLetter, 0, len(s.letters))
+	for _, dl := range s.letters {
+		list = append(list, dl)
+	}
+	sortDeadLetters(list)
+	return list, nil
+}
+
+func (s *MemoryDeadLetterStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.letters[id]; !ok {
+		return fmt.Errorf("%w: id=%s", ErrDeadLetterNotFound, id)
+	}
+	delete(s.letters, id)
+	return nil
+}
+
+// FileDeadLetterStore はJSONファイルに保存するDeadLetterStore
+// プロセスを再起動しても残るので、後から中身を確認して再送できる
+type FileDeadLetterStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileDeadLetterStore(path string) *FileDeadLetterStore {
+	return &FileDeadLetterStore{path: path}
+}
+
+func (s *FileDeadLetterStore) Put(dl DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	letters, err := s.load()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range letters {
+		if letters[i].ID == dl.ID {
+			letters[i] = dl
+			replaced = true
+		}
+	}
+	if !replaced {
+		letters = append(letters, dl)
+	}
+	return s.save(letters)
+}
+
+func (s *FileDeadLetterStore) List() ([]DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	letters, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	sortDeadLetters(letters)
+	return letters, nil
+}
+
+func (s *FileDeadLetterStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	letters, err := s.load()
+	if err != nil {
+		return err
+	}
+	for i, dl := range letters {
+		if dl.ID == id {
+			return s.save(append(letters[:i], letters[i+1:]...))
+		}
+	}
+	return fmt.Errorf("%w: id=%s", ErrDeadLetterNotFound, id)
+}
+
+func (s *FileDeadLetterStore) load() ([]DeadLetter, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read dead letters: %w", err)
+	}
+	var letters []DeadLetter
+	if err := json.Unmarshal(data, &letters); err != nil {
+		return nil, fmt.Errorf("decode dead letters %s: %w", s.path, err)
+	}
+	return letters, nil
+}
+
+// save は一時ファイルに書いてからrenameする
+// 書き込み途中でプロセスが落ちても、元のファイルが壊れないようにするため
+func (s *FileDeadLetterStore) save(letters []DeadLetter) error {
+	data, err := json.MarshalIndent(letters, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("write dead letters: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".deadletters-*")
+	if err != nil {
+		return fmt.Errorf("write dead letters: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write dead letters: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write dead letters: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write dead letters: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("write dead letters: %w", err)
+	}
+	return nil
+}
+
+// ReplayResult はReplayの結果
+type ReplayResult struct {
+	Replayed int
+	Failed   int
+	Skipped  int // resolveが送信先を見つけられなかった件数
+}
+
+// Replay はデッドレターを再送し、成功したものをストアから削除する
+// resolveはDeadLetter.Notifierの名前から送信先のNotifierを返す
+// 記録したRecipientとIdempotencyKeyはWithRecipient・WithIdempotencyKeyでctxに戻して送る
+// WithRetryでデッドレターを設定したNotifierを返すと二重に記録されるので、素のNotifierを返すこと
+func Replay(ctx context.Context, store DeadLetterStore, resolve func(name string) (Notifier, bool)) (ReplayResult, error) {
+	var result ReplayResult
+	letters, err := store.List()
+	if err != nil {
+		return result, err
+	}
+
+	var errs []error
+	for _, dl := range letters {
+		if err := ctx.Err(); err != nil {
+			return result, errors.Join(append(errs, err)...)
+		}
+		n, ok := resolve(dl.Notifier)
+		if !ok {
+			result.Skipped++
+			continue
+		}
+		if err := notifyOne(dl.context(ctx), n, dl.Message, 0); err != nil {
+			result.Failed++
+			dl.Attempts++
+			dl.Error = err.Error()
+			dl.FailedAt = time.Now()
+			if putErr := store.Put(dl); putErr != nil {
+				errs = append(errs, putErr)
+			}
+			continue
+		}
+		if err := store.Delete(dl.ID); err != nil {
+			errs = append(errs, err)
+		}
+		result.Replayed++
+	}
+	return result, errors.Join(errs...)
+}
+
+// context はctxに送信時の宛先と冪等キーを載せて返す
+func (dl DeadLetter) context(ctx context.Context) context.Context {
+	if dl.IdempotencyKey != "" {
+		ctx = WithIdempotencyKey(ctx, dl.IdempotencyKey)
+	}
+	if dl.Recipient != "" {
+		ctx = WithRecipient(ctx, dl.Recipient)
+	}
+	return ctx
+}
+
+func sortDeadLetters(letters []DeadLetter) {
+	sort.Slice(letters, func(i, j int) bool {
+		return letters[i].FailedAt.Before(letters[j].FailedAt)
+	})
+}
+
+// newID はランダムな16進数のIDを返す
+func newID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
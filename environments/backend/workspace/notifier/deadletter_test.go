@@ -0,0 +1,190 @@
+package notifier_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"workout/notifier"
+)
+
+var failedAt = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func letter(id string, minutes int) notifier.DeadLetter {
+	return notifier.DeadLetter{
+		ID:             id,
+		Notifier:       "email",
+		Recipient:      id + "@example.com",
+		IdempotencyKey: "evt-" + id,
+		Message:        "hello " + id,
+		Error:          "connection refused",
+		Attempts:       3,
+		FailedAt:       failedAt.Add(time.Duration(minutes) * time.Minute),
+	}
+}
+
+// ids はIDを名前順に返す（Replayで失敗した時刻が同じになっても順序に左右されないように）
+func ids(letters []notifier.DeadLetter) []string {
+	var out []string
+	for _, dl := range letters {
+		out = append(out, dl.ID)
+	}
+	slices.Sort(out)
+	return out
+}
+
+func TestFileDeadLetterStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead", "letters.json")
+	s := notifier.NewFileDeadLetterStore(path)
+
+	// ファイルがなければ空
+	if letters, err := s.List(); err != nil || len(letters) != 0 {
+		t.Fatalf("List on missing file = %v, %v", letters, err)
+	}
+
+	for _, dl := range []notifier.DeadLetter{letter("b", 2), letter("a", 1), letter("c", 3)} {
+		if err := s.Put(dl); err != nil {
+			t.Fatalf("Put(%s): %v", dl.ID, err)
+		}
+	}
+	// 同じIDのPutは上書き
+	updated := letter("b", 2)
+	updated.Attempts = 4
+	if err := s.Put(updated); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("c"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Delete("c"); !errors.Is(err, notifier.ErrDeadLetterNotFound) {
+		t.Errorf("Delete of missing id = %v, want ErrDeadLetterNotFound", err)
+	}
+
+	// 開き直しても同じ内容が失敗した順に読める
+	letters, err := notifier.NewFileDeadLetterStore(path).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []notifier.DeadLetter{letter("a", 1), updated}
+	if !reflect.DeepEqual(letters, want) {
+		t.Errorf("List = %+v, want %+v", letters, want)
+	}
+
+	// 一時ファイルを残さない
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("dir has %d files, want only letters.json", len(entries))
+	}
+
+	if err := os.WriteFile(path, []byte("{broken"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.List(); err == nil {
+		t.Error("List of broken file succeeded")
+	}
+}
+
+// sent はReplayで送られた内容
+type sent struct {
+	Message, Recipient, Key string
+}
+
+func TestReplay(t *testing.T) {
+	errDown := errors.New("still down")
+	tests := []struct {
+		name        string
+		fail        bool
+		channel     string // Notifierを解決できるチャネル
+		wantResult  notifier.ReplayResult
+		wantSent    []sent
+		wantLeft    []string
+		wantAttempt int // 残ったデッドレターのAttempts
+	}{
+		{
+			name:       "replayed",
+			channel:    "email",
+			wantResult: notifier.ReplayResult{Replayed: 2},
+			wantSent: []sent{
+				{"hello a", "a@example.com", "evt-a"},
+				{"hello b", "b@example.com", "evt-b"},
+			},
+		},
+		{
+			name:        "failed again",
+			fail:        true,
+			channel:     "email",
+			wantResult:  notifier.ReplayResult{Failed: 2},
+			wantLeft:    []string{"a", "b"},
+			wantAttempt: 4,
+		},
+		{
+			name:        "unknown notifier",
+			channel:     "slack",
+			wantResult:  notifier.ReplayResult{Skipped: 2},
+			wantLeft:    []string{"a", "b"},
+			wantAttempt: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := notifier.NewMemoryDeadLetterStore()
+			store.Put(letter("a", 1))
+			store.Put(letter("b", 2))
+
+			var got []sent
+			n := notifyFunc(func(ctx context.Context, message string) error {
+				if tt.fail {
+					return errDown
+				}
+				// 記録した宛先と冪等キーで送り直す
+				recipient, _ := notifier.RecipientFromContext(ctx)
+				key, _ := notifier.IdempotencyKeyFromContext(ctx)
+				got = append(got, sent{message, recipient, key})
+				return nil
+			})
+			result, err := notifier.Replay(context.Background(), store, func(name string) (notifier.Notifier, bool) {
+				return n, name == tt.channel
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.wantResult {
+				t.Errorf("result = %+v, want %+v", result, tt.wantResult)
+			}
+			if !reflect.DeepEqual(got, tt.wantSent) {
+				t.Errorf("sent = %+v, want %+v", got, tt.wantSent)
+			}
+			left, _ := store.List()
+			if !reflect.DeepEqual(ids(left), tt.wantLeft) {
+				t.Errorf("left = %v, want %v", ids(left), tt.wantLeft)
+			}
+			for _, dl := range left {
+				if dl.Attempts != tt.wantAttempt {
+					t.Errorf("%s: Attempts = %d, want %d", dl.ID, dl.Attempts, tt.wantAttempt)
+				}
+				if tt.fail && dl.Error != errDown.Error() {
+					t.Errorf("%s: Error = %q, want %q", dl.ID, dl.Error, errDown)
+				}
+			}
+		})
+	}
+
+	t.Run("canceled", func(t *testing.T) {
+		store := notifier.NewMemoryDeadLetterStore()
+		store.Put(letter("a", 1))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := notifier.Replay(ctx, store, func(string) (notifier.Notifier, bool) {
+			t.Error("resolve called after cancel")
+			return nil, false
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("err = %v, want context.Canceled", err)
+		}
+	})
+}
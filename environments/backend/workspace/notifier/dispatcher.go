@@ -0,0 +1,283 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// DispatcherOptions はDispatcherの動作設定
+type DispatcherOptions struct {
+	Workers      int           // 同時に送信するワーカー数（0なら4）
+	PollInterval time.Duration // アウトボックスを確認する間隔（0なら1秒）
+	BatchSize    int           // 1回に取得する件数（0ならWorkersの2倍）
+	// Lease はワーカーがエントリを占有する時間（0なら1分）
+	// この間に完了しなければ、別のワーカー・別のプロセスが再取得する
+	Lease time.Duration
+	// Timeout は1回の送信の期限（0なら期限なし）
+	Timeout     time.Duration
+	MaxAttempts int // 最初の1回を含む試行回数（0なら5回）
+	Backoff     Backoff
+	// Retryable がfalseを返したエラーは即座に諦める（nilならDefaultRetryable）
+	Retryable func(error) bool
+	// DeadLetters は再試行を諦めた通知の保存先（nilならメモリ上に保存し、再起動で消える）
+	// 諦めたエントリはアウトボックスから削除し、ReplayDeadLettersで送り直せる
+	DeadLetters DeadLetterStore
+	// Retention は送信済みのエントリを残しておく期間（0なら削除しない）
+	// 残している間は同じ冪等キーの通知を重複として捨てるので、再送が起こりうる期間より長くする
+	Retention time.Duration
+	// PurgeInterval は送信済みのエントリを削除する間隔（0なら1時間）
+	PurgeInterval time.Duration
+	// OnError はアウトボックスの更新に失敗した場合に呼ばれる（nilならlogに出力）
+	OnError func(e OutboxEntry, err error)
+}
+
+// Dispatcher はアウトボックスのエントリをワーカープールで各Notifierへ配送する
+// 送信後に状態を記録する前にプロセスが落ちた場合は、再起動後にもう一度送る（at-least-once）
+// 送り直しを1通にまとめられるのは、冪等キーを見る受信側（WithIdempotencyKeyを参照）だけ
+// 再試行はアウトボックスに次の送信時刻を記録して行うので、WithRetryで包む必要はない
+type Dispatcher struct {
+	outbox   Outbox
+	channels map[string]Notifier
+	opts     DispatcherOptions
+	wake     chan struct{}
+}
+
+func NewDispatcher(outbox Outbox, channels map[string]Notifier, opts DispatcherOptions) *Dispatcher {
+	if opts.Workers <= 0 {
+		opts.Workers = 4
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = opts.Workers * 2
+	}
+	if opts.PurgeInterval <= 0 {
+		opts.PurgeInterval = time.Hour
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = time.Minute
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 5
+	}
+	if opts.Retryable == nil {
+		opts.Retryable = DefaultRetryable
+	}
+	if opts.DeadLetters == nil {
+		opts.DeadLetters = NewMemoryDeadLetterStore()
+	}
+	if opts.OnError == nil {
+		opts.OnError = func(e OutboxEntry, err error) {
+			log.Printf("outbox: entry %s (%s): %v", e.ID, e.Channel, err)
+		}
+	}
+	return &Dispatcher{outbox: outbox, channels: channels, opts: opts, wake: make(chan struct{}, 1)}
+}
+
+// Notifier はchannel宛ての通知をアウトボックスに書き込むNotifierを返す
+// Notifyが成功した時点で永続化済みなので、直後にプロセスが落ちても通知は失われない
+func (d *Dispatcher) Notifier(channel string) *OutboxNotifier {
+	return &OutboxNotifier{d: d, channel: channel}
+}
+
+// OutboxNotifier はアウトボックスへ書き込むだけのNotifier
+type OutboxNotifier struct {
+	d       *Dispatcher
+	channel string
+}
+
+func (n *OutboxNotifier) Notify(message string) error {
+	return n.NotifyWithKey("", message)
+}
+
+// NotifyContext はctxの冪等キー（WithIdempotencyKey）と宛先（WithRecipient）も一緒に書き込む
+// Routerのチャネルにすると、Dispatchはアウトボックスに書くだけで戻り、送信はDispatcherが行う
+func (n *OutboxNotifier) NotifyContext(ctx context.Context, message string) error {
+	key, _ := IdempotencyKeyFromContext(ctx)
+	recipient, _ := RecipientFromContext(ctx)
+	return n.enqueue(OutboxEntry{IdempotencyKey: key, Recipient: recipient, Message: message})
+}
+
+// NotifyWithKey は冪等キー付きで書き込む。同じキーで2回呼んでもアウトボックスには1件だけ入る
+// 送信自体はat-least-onceなので、送り直しが起きても受信側でキーから重複と分かる
+func (n *OutboxNotifier) NotifyWithKey(key, message string) error {
+	return n.enqueue(OutboxEntry{IdempotencyKey: key, Message: message})
+}
+
+func (n *OutboxNotifier) enqueue(e OutboxEntry) error {
+	if _, ok := n.d.channels[n.channel]; !ok {
+		return fmt.Errorf("unknown channel %q", n.channel)
+	}
+	e.Channel = n.channel
+	_, dup, err := n.d.outbox.Enqueue(e)
+	if err != nil {
+		return err
+	}
+	if !dup {
+		n.d.signal()
+	}
+	return nil
+}
+
+func (d *Dispatcher) signal() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run はctxがキャンセルされるまでアウトボックスを監視して配送し続ける
+// Retentionを設定していれば、PurgeIntervalごとに古い送信済みのエントリも削除する
+func (d *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(d.opts.PollInterval)
+	defer ticker.Stop()
+	purge := time.NewTicker(d.opts.PurgeInterval)
+	defer purge.Stop()
+
+	for {
+		if _, err := d.ProcessReady(ctx); err != nil && ctx.Err() == nil {
+			d.opts.OnError(OutboxEntry{}, err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		case <-d.wake:
+		case <-purge.C:
+			if _, err := d.PurgeDelivered(time.Now()); err != nil {
+				d.opts.OnError(OutboxEntry{}, err)
+			}
+		}
+	}
+}
+
+// PurgeDelivered はnowからRetentionより前に送信済みになったエントリを削除する（Retentionが0なら何もしない）
+func (d *Dispatcher) PurgeDelivered(now time.Time) (int, error) {
+	if d.opts.Retention <= 0 {
+		return 0, nil
+	}
+	n, err := d.outbox.PurgeDelivered(now.Add(-d.opts.Retention))
+	if err != nil {
+		return n, fmt.Errorf("purge delivered entries: %w", err)
+	}
+	return n, nil
+}
+
+// ProcessReady は今送信できるエントリを1バッチ分取得して配送し、処理した件数を返す
+func (d *Dispatcher) ProcessReady(ctx context.Context) (int, error) {
+	entries, err := d.outbox.Claim(time.Now(), d.opts.BatchSize, d.opts.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("claim outbox entries: %w", err)
+	}
+	if len(entries) == 0 {
+		return 0, nil
+	}
+
+	jobs := make(chan OutboxEntry)
+	var wg sync.WaitGroup
+	for range min(d.opts.Workers, len(entries)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for e := range jobs {
+				d.deliver(ctx, e)
+			}
+		}()
+	}
+
+	processed := 0
+	for _, e := range entries {
+		if ctx.Err() != nil {
+			// 停止中に渡せなかったエントリは、リース切れを待たずにすぐ再取得できるよう戻しておく
+			d.release(e)
+			continue
+		}
+		jobs <- e
+		processed++
+	}
+	close(jobs)
+	wg.Wait()
+	return processed, nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, e OutboxEntry) {
+	n, ok := d.channels[e.Channel]
+	if !ok {
+		d.deadLetter(e, fmt.Sprintf("unknown channel %q", e.Channel))
+		return
+	}
+
+	ctx = WithIdempotencyKey(ctx, e.IdempotencyKey)
+	if e.Recipient != "" {
+		ctx = WithRecipient(ctx, e.Recipient)
+	}
+	err := notifyOne(ctx, n, e.Message, d.opts.Timeout)
+	switch {
+	case err == nil:
+		if markErr := d.outbox.MarkDelivered(e.ID, time.Now()); markErr != nil {
+			d.opts.OnError(e, markErr)
+		}
+	case ctx.Err() != nil:
+		// 停止によるキャンセルは失敗として数えない
+		d.release(e)
+	case d.opts.Retryable(err) && e.Attempts < d.opts.MaxAttempts:
+		d.markRetry(e, err.Error(), time.Now().Add(retryDelay(d.opts.Backoff, e.Attempts, err)))
+	default:
+		d.deadLetter(e, err.Error())
+	}
+}
+
+func (d *Dispatcher) markRetry(e OutboxEntry, msg string, next time.Time) {
+	if err := d.outbox.MarkRetry(e.ID, msg, next); err != nil {
+		d.opts.OnError(e, err)
+	}
+}
+
+func (d *Dispatcher) release(e OutboxEntry) {
+	if err := d.outbox.Release(e.ID); err != nil {
+		d.opts.OnError(e, err)
+	}
+}
+
+// deadLetter はエントリをDeadLetterStoreに移してアウトボックスから削除する
+// 保存に失敗したら通知が消えないよう、アウトボックスに残して後で送り直す
+func (d *Dispatcher) deadLetter(e OutboxEntry, msg string) {
+	dl := DeadLetter{
+		ID:             e.ID,
+		Notifier:       e.Channel,
+		Recipient:      e.Recipient,
+		IdempotencyKey: e.IdempotencyKey,
+		Message:        e.Message,
+		Error:          msg,
+		Attempts:       e.Attempts,
+		FailedAt:       time.Now(),
+	}
+	if err := d.opts.DeadLetters.Put(dl); err != nil {
+		d.opts.OnError(e, fmt.Errorf("dead letter: %w", err))
+		d.markRetry(e, msg, time.Now().Add(d.opts.Backoff.Delay(e.Attempts)))
+		return
+	}
+	if err := d.outbox.Delete(e.ID); err != nil {
+		d.opts.OnError(e, err)
+	}
+}
+
+// DeadLetters は再試行を諦めた通知の保存先を返す
+func (d *Dispatcher) DeadLetters() DeadLetterStore {
+	return d.opts.DeadLetters
+}
+
+// ReplayDeadLetters はデッドレターを元のチャネルのアウトボックスに書き戻し、Dispatcherに送り直させる
+// 宛先と冪等キーは記録したものを使う。チャネルがなくなったデッドレターはそのまま残す
+func (d *Dispatcher) ReplayDeadLetters(ctx context.Context) (ReplayResult, error) {
+	return Replay(ctx, d.opts.DeadLetters, func(name string) (Notifier, bool) {
+		if _, ok := d.channels[name]; !ok {
+			return nil, false
+		}
+		return d.Notifier(name), true
+	})
+}
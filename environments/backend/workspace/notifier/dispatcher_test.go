@@ -0,0 +1,359 @@
+package notifier_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"workout/notifier"
+	"workout/notifier/slacktest"
+	"workout/notifier/smtptest"
+)
+
+type notifyFunc func(ctx context.Context, message string) error
+
+func (f notifyFunc) Notify(message string) error { return f(context.Background(), message) }
+func (f notifyFunc) NotifyContext(ctx context.Context, message string) error {
+	return f(ctx, message)
+}
+
+func openOutbox(t *testing.T) (*notifier.FileOutbox, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	o, err := notifier.OpenFileOutbox(path)
+	if err != nil {
+		t.Fatalf("OpenFileOutbox: %v", err)
+	}
+	return o, path
+}
+
+func onlyEntry(t *testing.T, o notifier.Outbox) notifier.OutboxEntry {
+	t.Helper()
+	entries, err := o.List("")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("outbox has %d entries, want 1", len(entries))
+	}
+	return entries[0]
+}
+
+func TestDispatcherRetriesThenDeadLetters(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantAttempts int
+	}{
+		{name: "transient error", err: errors.New("connection reset"), wantAttempts: 3},
+		{name: "permanent error", err: notifier.Permanent(errors.New("invalid address")), wantAttempts: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, _ := openOutbox(t)
+			fail := notifyFunc(func(context.Context, string) error { return tt.err })
+			dls := notifier.NewMemoryDeadLetterStore()
+			d := notifier.NewDispatcher(o, map[string]notifier.Notifier{"ops": fail}, notifier.DispatcherOptions{
+				MaxAttempts: 3,
+				Backoff:     notifier.Backoff{Initial: time.Nanosecond, Max: time.Nanosecond},
+				DeadLetters: dls,
+			})
+			ctx := notifier.WithRecipient(notifier.WithIdempotencyKey(context.Background(), "disk:1"), "oncall@example.com")
+			if err := d.Notifier("ops").NotifyContext(ctx, "disk full"); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+
+			for range 5 {
+				if _, err := d.ProcessReady(context.Background()); err != nil {
+					t.Fatalf("ProcessReady: %v", err)
+				}
+			}
+			// 諦めたエントリはアウトボックスからデッドレターに移る
+			if entries, _ := o.List(""); len(entries) != 0 {
+				t.Errorf("outbox still has %+v", entries)
+			}
+			letters, _ := dls.List()
+			if len(letters) != 1 {
+				t.Fatalf("dead letters = %+v, want 1", letters)
+			}
+			dl := letters[0]
+			if dl.Notifier != "ops" || dl.Message != "disk full" || dl.Recipient != "oncall@example.com" ||
+				dl.IdempotencyKey != "disk:1" || dl.Attempts != tt.wantAttempts || dl.Error != tt.err.Error() {
+				t.Errorf("dead letter = %+v, want %d attempts", dl, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+// failingStore はPutが常に失敗するDeadLetterStore
+type failingStore struct{ notifier.DeadLetterStore }
+
+func (failingStore) Put(notifier.DeadLetter) error { return errors.New("disk full") }
+
+// デッドレターに保存できなければ、通知を消さずにアウトボックスに残す
+func TestDispatcherKeepsEntryWhenDeadLetterFails(t *testing.T) {
+	o, _ := openOutbox(t)
+	var reported []error
+	d := notifier.NewDispatcher(o, map[string]notifier.Notifier{
+		"ops": notifyFunc(func(context.Context, string) error { return notifier.Permanent(errors.New("invalid address")) }),
+	}, notifier.DispatcherOptions{
+		DeadLetters: failingStore{},
+		OnError:     func(_ notifier.OutboxEntry, err error) { reported = append(reported, err) },
+	})
+	if err := d.Notifier("ops").Notify("disk full"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.ProcessReady(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	e := onlyEntry(t, o)
+	if e.Status != notifier.OutboxPending || e.LastError != "invalid address" || len(reported) != 1 {
+		t.Errorf("entry = %+v, reported = %v", e, reported)
+	}
+}
+
+func TestDispatcherReplayDeadLetters(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Config{})
+	defer srv.Close()
+	o, _ := openOutbox(t)
+	dls := notifier.NewMemoryDeadLetterStore()
+	dls.Put(notifier.DeadLetter{ID: "1", Notifier: "email", Recipient: "tanaka@example.com", IdempotencyKey: "pr:42", Message: "新記録"})
+	dls.Put(notifier.DeadLetter{ID: "2", Notifier: "fax", Message: "届かない"})
+	d := notifier.NewDispatcher(o, map[string]notifier.Notifier{"email": newEmail(srv)}, notifier.DispatcherOptions{DeadLetters: dls})
+
+	result, err := d.ReplayDeadLetters(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != (notifier.ReplayResult{Replayed: 1, Skipped: 1}) {
+		t.Errorf("result = %+v", result)
+	}
+	// 元の宛先と冪等キーでアウトボックスに戻り、Dispatcherが送る
+	e := onlyEntry(t, o)
+	if e.Channel != "email" || e.Recipient != "tanaka@example.com" || e.IdempotencyKey != "pr:42" {
+		t.Errorf("entry = %+v", e)
+	}
+	if _, err := d.ProcessReady(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if m := onlyMessage(t, srv); !reflect.DeepEqual(m.To, []string{"tanaka@example.com"}) {
+		t.Errorf("sent to %v", m.To)
+	}
+	if letters, _ := dls.List(); len(letters) != 1 || letters[0].ID != "2" {
+		t.Errorf("dead letters left = %+v, want only the fax one", letters)
+	}
+}
+
+// 停止によるキャンセルは試行回数に数えないので、何度止めてもデッドレターにならない
+func TestDispatcherCancelDoesNotCountAttempt(t *testing.T) {
+	o, _ := openOutbox(t)
+	started := make(chan struct{}, 1)
+	block := notifyFunc(func(ctx context.Context, _ string) error {
+		started <- struct{}{}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	d := notifier.NewDispatcher(o, map[string]notifier.Notifier{"slow": block}, notifier.DispatcherOptions{MaxAttempts: 1})
+	if err := d.Notifier("slow").Notify("hello"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	for range 3 {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			d.ProcessReady(ctx)
+		}()
+		<-started
+		cancel()
+		<-done
+
+		e := onlyEntry(t, o)
+		if e.Status != notifier.OutboxPending || e.Attempts != 0 || !e.LeaseUntil.IsZero() {
+			t.Fatalf("after cancel: status=%s attempts=%d lease=%v, want pending/0/zero", e.Status, e.Attempts, e.LeaseUntil)
+		}
+	}
+}
+
+// 配送済みの記録前に落ちて送り直しても、同じ冪等キーなのでSlackには1回だけ投稿される
+func TestDispatcherPassesIdempotencyKeyToSlack(t *testing.T) {
+	srv := slacktest.NewServer()
+	defer srv.Close()
+	o, path := openOutbox(t)
+	d := notifier.NewDispatcher(o, map[string]notifier.Notifier{
+		"slack": &notifier.SlackNotifier{WebhookURL: srv.URL, MaxRetries: -1},
+	}, notifier.DispatcherOptions{})
+
+	n := d.Notifier("slack")
+	for range 2 {
+		if err := n.NotifyWithKey("pr:42", "新記録"); err != nil {
+			t.Fatalf("NotifyWithKey: %v", err)
+		}
+	}
+	if _, err := d.ProcessReady(context.Background()); err != nil {
+		t.Fatalf("ProcessReady: %v", err)
+	}
+	e := onlyEntry(t, o)
+	if e.Status != notifier.OutboxDelivered {
+		t.Fatalf("status = %s, want delivered", e.Status)
+	}
+
+	// MarkDeliveredの前にプロセスが落ちた状態を作り、再起動後のアウトボックスから送り直す
+	if err := o.MarkRetry(e.ID, "crashed", time.Now()); err != nil {
+		t.Fatalf("MarkRetry: %v", err)
+	}
+	reopened, err := notifier.OpenFileOutbox(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	d = notifier.NewDispatcher(reopened, map[string]notifier.Notifier{
+		"slack": &notifier.SlackNotifier{WebhookURL: srv.URL, MaxRetries: -1},
+	}, notifier.DispatcherOptions{})
+	if _, err := d.ProcessReady(context.Background()); err != nil {
+		t.Fatalf("ProcessReady: %v", err)
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) != 2 {
+		t.Fatalf("requests = %d, want 2", len(reqs))
+	}
+	for i, r := range reqs {
+		if got := r.Header.Get(notifier.IdempotencyKeyHeader); got != "pr:42" {
+			t.Errorf("request %d %s = %q", i, notifier.IdempotencyKeyHeader, got)
+		}
+	}
+	if got := len(srv.Delivered()); got != 1 {
+		t.Errorf("posted %d times, want 1", got)
+	}
+}
+
+func TestEmailMessageIDFromIdempotencyKey(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Config{})
+	defer srv.Close()
+	e := newEmail(srv)
+
+	ctx := notifier.WithIdempotencyKey(context.Background(), "pr:42")
+	for range 2 {
+		if err := e.NotifyContext(ctx, "新記録"); err != nil {
+			t.Fatalf("NotifyContext: %v", err)
+		}
+	}
+	if err := e.Notify("キーなし"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	var ids []string
+	for _, m := range srv.Messages() {
+		parsed, err := m.Parse()
+		if err != nil {
+			t.Fatalf("parse: %v", err)
+		}
+		ids = append(ids, parsed.Header.Get("Message-ID"))
+	}
+	if ids[0] != ids[1] {
+		t.Errorf("same key produced different Message-IDs: %s, %s", ids[0], ids[1])
+	}
+	if ids[2] == ids[0] {
+		t.Errorf("message without a key reused Message-ID %s", ids[2])
+	}
+}
+
+func TestFileOutboxPurgeDelivered(t *testing.T) {
+	o, path := openOutbox(t)
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	enqueue := func(key string) notifier.OutboxEntry {
+		t.Helper()
+		e, _, err := o.Enqueue(notifier.OutboxEntry{Channel: "email", IdempotencyKey: key, Message: key})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	old, recent, pending := enqueue("old"), enqueue("recent"), enqueue("pending")
+	o.MarkDelivered(old.ID, base)
+	o.MarkDelivered(recent.ID, base.Add(2*time.Hour))
+
+	n, err := o.PurgeDelivered(base.Add(time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("PurgeDelivered = %d, %v; want 1", n, err)
+	}
+	// 開き直しても、削除したエントリは戻らない
+	reopened, err := notifier.OpenFileOutbox(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries, _ := reopened.List("")
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.ID)
+	}
+	if want := []string{recent.ID, pending.ID}; !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+
+	// 削除したエントリの冪等キーは、もう重複として扱わない
+	if _, dup, _ := o.Enqueue(notifier.OutboxEntry{Channel: "email", IdempotencyKey: "old"}); dup {
+		t.Error("purged key is still treated as a duplicate")
+	}
+	if _, dup, _ := o.Enqueue(notifier.OutboxEntry{Channel: "email", IdempotencyKey: "recent"}); !dup {
+		t.Error("kept key is not treated as a duplicate")
+	}
+	if n, err := o.PurgeDelivered(base); err != nil || n != 0 {
+		t.Errorf("second PurgeDelivered = %d, %v; want 0", n, err)
+	}
+}
+
+func TestDispatcherRunPurgesDelivered(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention time.Duration
+		wantLeft  int
+	}{
+		{name: "purges after retention", retention: time.Nanosecond, wantLeft: 0},
+		{name: "zero retention keeps entries", retention: 0, wantLeft: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, _ := openOutbox(t)
+			ok := notifyFunc(func(context.Context, string) error { return nil })
+			d := notifier.NewDispatcher(o, map[string]notifier.Notifier{"ops": ok}, notifier.DispatcherOptions{
+				PollInterval:  time.Millisecond,
+				PurgeInterval: 5 * time.Millisecond,
+				Retention:     tt.retention,
+			})
+			if err := d.Notifier("ops").Notify("hello"); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				d.Run(ctx)
+			}()
+			// 送信されてから、何回か削除の間隔が過ぎるのを待つ
+			deadline := time.Now().Add(5 * time.Second)
+			for {
+				entries, _ := o.List("")
+				delivered, _ := o.List(notifier.OutboxDelivered)
+				if len(entries) == tt.wantLeft && len(delivered) == tt.wantLeft {
+					time.Sleep(20 * time.Millisecond)
+					break
+				}
+				if time.Now().After(deadline) {
+					t.Fatalf("outbox = %+v, want %d delivered entries left", entries, tt.wantLeft)
+				}
+				time.Sleep(time.Millisecond)
+			}
+			cancel()
+			<-done
+			if entries, _ := o.List(""); len(entries) != tt.wantLeft {
+				t.Errorf("outbox = %+v, want %d entries", entries, tt.wantLeft)
+			}
+		})
+	}
+}
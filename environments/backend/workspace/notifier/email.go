@@ -0,0 +1,370 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ErrStartTLSUnsupported はSTARTTLS必須なのにサーバーが対応していない場合のエラー
+var ErrStartTLSUnsupported = errors.New("smtp server does not support STARTTLS")
+
+// SMTPConfig はSMTPサーバーへの接続設定
+type SMTPConfig struct {
+	Addr     string // "host:port"
+	Username string // 空ならAUTHしない
+	Password string
+	// StartTLS がtrueならSTARTTLSを必須にする（平文のままAUTHさせないため）
+	StartTLS  bool
+	TLSConfig *tls.Config
+	// LocalName はEHLOで名乗るホスト名（空なら"localhost"）
+	LocalName string
+	Timeout   time.Duration
+}
+
+// EmailNotifier はメールで通知するNotifier
+// SMTPがnilの場合は送信せず標準出力に表示するだけ（ローカル開発用）
+type EmailNotifier struct {
+	To   string
+	Cc   []string
+	Bcc  []string
+	From string
+
+	// SubjectTemplate は件名のtext/template（空なら"Notification"）
+	SubjectTemplate string
+	// TextTemplate はテキスト本文のtext/template（空ならメッセージそのまま）
+	TextTemplate string
+	// HTMLTemplate を設定するとtext/htmlを含むmultipart/alternativeで送る
+	HTMLTemplate string
+
+	SMTP *SMTPConfig
+}
+
+// EmailData はテンプレートに渡すデータ
+type EmailData struct {
+	To      string
+	Message string
+}
+
+func (e *EmailNotifier) Notify(message string) error {
+	return e.NotifyContext(context.Background(), message)
+}
+
+// NotifyContext はMIMEメッセージを組み立ててSMTPで送信する
+// ctxに冪等キー（WithIdempotencyKey）があれば、キーから決まるMessage-IDを付ける
+// ctxに宛先（WithRecipient）があれば、To・Cc・Bccの代わりにその宛先だけに送る
+func (e *EmailNotifier) NotifyContext(ctx context.Context, message string) error {
+	if to, ok := RecipientFromContext(ctx); ok {
+		c := *e
+		c.To, c.Cc, c.Bcc = to, nil, nil
+		e = &c
+	}
+	if e.SMTP == nil {
+		fmt.Printf("Sending email to %s: %s\n", e.To, message)
+		return nil
+	}
+
+	key, _ := IdempotencyKeyFromContext(ctx)
+	msg, err := e.buildMessage(message, key)
+	if err != nil {
+		// 宛先やテンプレートの誤りは何度送り直しても直らない
+		return Permanent(err)
+	}
+	return e.send(ctx, msg)
+}
+
+// Recipients はエンベロープの宛先（To, Cc, Bcc）を返す
+func (e *EmailNotifier) Recipients() []string {
+	var rcpts []string
+	for _, list := range [][]string{{e.To}, e.Cc, e.Bcc} {
+		for _, addr := range list {
+			if addr != "" {
+				rcpts = append(rcpts, addr)
+			}
+		}
+	}
+	return rcpts
+}
+
+// BuildMessage は送信するRFC 5322形式のメッセージを組み立てる
+// Bccはヘッダーに含めず、エンベロープにだけ載せる
+func (e *EmailNotifier) BuildMessage(message string) ([]byte, error) {
+	return e.buildMessage(message, "")
+}
+
+// buildMessage はkeyが空でなければ、キーから決まるMessage-IDを付ける
+// 同じ通知を送り直しても同じMessage-IDになり、受信側で重複と分かる
+func (e *EmailNotifier) buildMessage(message, key string) ([]byte, error) {
+	from, err := mail.ParseAddress(e.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from address %q: %w", e.From, err)
+	}
+	to, err := parseAddressList(nonEmpty(e.To))
+	if err != nil {
+		return nil, err
+	}
+	if len(to) == 0 {
+		return nil, errors.New("email recipient is required")
+	}
+	cc, err := parseAddressList(e.Cc)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := parseAddressList(e.Bcc); err != nil {
+		return nil, err
+	}
+
+	data := EmailData{To: e.To, Message: message}
+	subject, err := renderText("subject", e.SubjectTemplate, "Notification", data)
+	if err != nil {
+		return nil, err
+	}
+	text, err := renderText("text", e.TextTemplate, message, data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeHeader(&buf, "From", from.String())
+	writeHeader(&buf, "To", joinAddresses(to))
+	if len(cc) > 0 {
+		writeHeader(&buf, "Cc", joinAddresses(cc))
+	}
+	writeHeader(&buf, "Subject", mime.QEncoding.Encode("UTF-8", subject))
+	writeHeader(&buf, "Date", time.Now().Format(time.RFC1123Z))
+	writeHeader(&buf, "Message-ID", newMessageID(from.Address, key))
+	writeHeader(&buf, "MIME-Version", "1.0")
+
+	if e.HTMLTemplate == "" {
+		writeHeader(&buf, "Content-Type", `text/plain; charset="UTF-8"`)
+		writeHeader(&buf, "Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		if err := writeQuotedPrintable(&buf, text); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	html, err := renderHTML(e.HTMLTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// テキスト版を先に置く: 受信側は後ろのパートほど優先して表示する
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{`text/plain; charset="UTF-8"`, text},
+		{`text/html; charset="UTF-8"`, html},
+	} {
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeQuotedPrintable(w, part.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	writeHeader(&buf, "Content-Type", fmt.Sprintf(`multipart/alternative; boundary="%s"`, mw.Boundary()))
+	buf.WriteString("\r\n")
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+func (e *EmailNotifier) send(ctx context.Context, msg []byte) error {
+	cfg := e.SMTP
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("invalid smtp addr %q: %w", cfg.Addr, err)
+	}
+
+	dialer := net.Dialer{Timeout: cfg.Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("smtp dial: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else if cfg.Timeout > 0 {
+		conn.SetDeadline(time.Now().Add(cfg.Timeout))
+	}
+	// 送信途中でキャンセルされたら接続を切って、ブロック中の読み書きを抜けさせる
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return ctxErr(ctx, fmt.Errorf("smtp greeting: %w", err))
+	}
+	defer c.Close()
+
+	localName := cfg.LocalName
+	if localName == "" {
+		localName = "localhost"
+	}
+	if err := c.Hello(localName); err != nil {
+		return ctxErr(ctx, fmt.Errorf("smtp hello: %w", err))
+	}
+
+	if cfg.StartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return Permanent(ErrStartTLSUnsupported)
+		}
+		tlsConfig := &tls.Config{}
+		if cfg.TLSConfig != nil {
+			tlsConfig = cfg.TLSConfig.Clone()
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = host
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return ctxErr(ctx, fmt.Errorf("smtp starttls: %w", err))
+		}
+	}
+
+	if cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, host)); err != nil {
+			return ctxErr(ctx, fmt.Errorf("smtp auth: %w", err))
+		}
+	}
+
+	from, _ := mail.ParseAddress(e.From)
+	if err := c.Mail(from.Address); err != nil {
+		return ctxErr(ctx, fmt.Errorf("smtp mail from: %w", err))
+	}
+	for _, rcpt := range e.Recipients() {
+		addr, _ := mail.ParseAddress(rcpt)
+		if err := c.Rcpt(addr.Address); err != nil {
+			return ctxErr(ctx, fmt.Errorf("smtp rcpt to %s: %w", addr.Address, err))
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return ctxErr(ctx, fmt.Errorf("smtp data: %w", err))
+	}
+	if _, err := w.Write(msg); err != nil {
+		return ctxErr(ctx, fmt.Errorf("smtp data: %w", err))
+	}
+	if err := w.Close(); err != nil {
+		return ctxErr(ctx, fmt.Errorf("smtp data: %w", err))
+	}
+	return ctxErr(ctx, c.Quit())
+}
+
+// ctxErr はcontextが原因で接続が切れた場合に、その理由をエラーに含める
+func ctxErr(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("%w (%w)", ctx.Err(), err)
+	}
+	return err
+}
+
+func renderText(name, tmpl, fallback string, data EmailData) (string, error) {
+	if tmpl == "" {
+		return fallback, nil
+	}
+	t, err := template.New(name).Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parse %s template: %w", name, err)
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("render %s template: %w", name, err)
+	}
+	return sb.String(), nil
+}
+
+// renderHTML はhtml/templateで描画する（メッセージ中のタグはエスケープされる）
+func renderHTML(tmpl string, data EmailData) (string, error) {
+	t, err := htmltemplate.New("html").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parse html template: %w", err)
+	}
+	var sb strings.Builder
+	if err := t.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("render html template: %w", err)
+	}
+	return sb.String(), nil
+}
+
+func parseAddressList(list []string) ([]*mail.Address, error) {
+	addrs := make([]*mail.Address, 0, len(list))
+	for _, s := range list {
+		if s == "" {
+			continue
+		}
+		addr, err := mail.ParseAddress(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %q: %w", s, err)
+		}
+		addrs = append(addrs, addr)
+	}
+	return addrs, nil
+}
+
+func joinAddresses(addrs []*mail.Address) string {
+	s := make([]string, len(addrs))
+	for i, a := range addrs {
+		s[i] = a.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+func nonEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+func writeHeader(buf *bytes.Buffer, key, value string) {
+	fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+}
+
+func writeQuotedPrintable(w io.Writer, s string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(s)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// newMessageID はkeyが空ならランダムな、空でなければkeyのハッシュから作ったMessage-IDを返す
+// （キーにはMessage-IDに使えない文字が入りうるのでハッシュにする）
+func newMessageID(from, key string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	id := newID()
+	if key != "" {
+		sum := sha256.Sum256([]byte(key))
+		id = hex.EncodeToString(sum[:16])
+	}
+	return fmt.Sprintf("<%s@%s>", id, domain)
+}
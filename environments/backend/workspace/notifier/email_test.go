@@ -0,0 +1,231 @@
+package notifier_test
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"slices"
+	"strings"
+	"testing"
+
+	"workout/notifier"
+	"workout/notifier/smtptest"
+)
+
+func newEmail(srv *smtptest.Server) *notifier.EmailNotifier {
+	return &notifier.EmailNotifier{
+		From: "Workout <noreply@example.com>",
+		To:   "alice@example.com",
+		SMTP: &notifier.SMTPConfig{Addr: srv.Addr},
+	}
+}
+
+func onlyMessage(t *testing.T, srv *smtptest.Server) smtptest.Message {
+	t.Helper()
+	msgs := srv.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("delivered %d messages, want 1", len(msgs))
+	}
+	return msgs[0]
+}
+
+func TestEmailNotifierEnvelope(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Config{})
+	defer srv.Close()
+
+	e := newEmail(srv)
+	e.Cc = []string{"Bob <bob@example.com>"}
+	e.Bcc = []string{"audit@example.com"}
+	e.SubjectTemplate = "[workout] {{.To}}"
+	if err := e.Notify("新記録です"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	m := onlyMessage(t, srv)
+	if m.From != "noreply@example.com" {
+		t.Errorf("MAIL FROM = %q, want noreply@example.com", m.From)
+	}
+	wantTo := []string{"alice@example.com", "bob@example.com", "audit@example.com"}
+	if !slices.Equal(m.To, wantTo) {
+		t.Errorf("RCPT TO = %v, want %v", m.To, wantTo)
+	}
+
+	parsed, err := m.Parse()
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	h := parsed.Header
+	if got := h.Get("To"); got != "<alice@example.com>" {
+		t.Errorf("To header = %q", got)
+	}
+	if got := h.Get("Cc"); got != `"Bob" <bob@example.com>` {
+		t.Errorf("Cc header = %q", got)
+	}
+	// Bccはエンベロープにだけ載せ、ヘッダーに出してはいけない
+	if got := h.Get("Bcc"); got != "" {
+		t.Errorf("Bcc header = %q, want none", got)
+	}
+	if strings.Contains(string(m.Data), "audit@example.com") {
+		t.Error("message data leaks the Bcc address")
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(h.Get("Subject"))
+	if err != nil || subject != "[workout] alice@example.com" {
+		t.Errorf("Subject = %q (%v)", subject, err)
+	}
+	body, _ := io.ReadAll(parsed.Body)
+	if !strings.Contains(string(body), "=E6=96=B0=E8=A8=98=E9=8C=B2") {
+		t.Errorf("body is not quoted-printable UTF-8: %q", body)
+	}
+}
+
+func TestEmailNotifierMultipartAlternative(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Config{})
+	defer srv.Close()
+
+	e := newEmail(srv)
+	e.TextTemplate = "通知: {{.Message}}"
+	e.HTMLTemplate = "<p>{{.Message}}</p>"
+	if err := e.Notify("<b>100kg</b>"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	parsed, err := onlyMessage(t, srv).Parse()
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(parsed.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v)", parsed.Header.Get("Content-Type"), err)
+	}
+
+	// 受信側は後ろのパートを優先するので、textが先・htmlが後
+	want := []struct{ contentType, body string }{
+		{"text/plain", "通知: <b>100kg</b>"},
+		{"text/html", "<p>&lt;b&gt;100kg&lt;/b&gt;</p>"},
+	}
+	mr := multipart.NewReader(parsed.Body, params["boundary"])
+	for i, w := range want {
+		part, err := mr.NextPart()
+		if err != nil {
+			t.Fatalf("part %d: %v", i, err)
+		}
+		ct, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if ct != w.contentType {
+			t.Errorf("part %d Content-Type = %q, want %q", i, ct, w.contentType)
+		}
+		// multipart.Readerはquoted-printableを自動で復号する
+		body, _ := io.ReadAll(part)
+		if string(body) != w.body {
+			t.Errorf("part %d body = %q, want %q", i, body, w.body)
+		}
+	}
+	if _, err := mr.NextPart(); err != io.EOF {
+		t.Errorf("extra part after html: %v", err)
+	}
+}
+
+func TestEmailNotifierStartTLSAndAuth(t *testing.T) {
+	srv := smtptest.NewServer(smtptest.Config{TLS: true, Username: "app", Password: "secret"})
+	defer srv.Close()
+
+	e := newEmail(srv)
+	e.SMTP.StartTLS = true
+	e.SMTP.TLSConfig = srv.ClientTLSConfig()
+	e.SMTP.Username = "app"
+	e.SMTP.Password = "secret"
+	if err := e.Notify("hello"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	m := onlyMessage(t, srv)
+	if !m.TLS {
+		t.Error("message was not sent over STARTTLS")
+	}
+	if m.Username != "app" {
+		t.Errorf("authenticated as %q, want app", m.Username)
+	}
+}
+
+func TestEmailNotifierErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		server    smtptest.Config
+		setup     func(e *notifier.EmailNotifier, srv *smtptest.Server)
+		wantCode  int   // SMTPの応答コード（0なら確かめない）
+		wantErr   error // errors.Isで確かめるエラー
+		permanent bool  // DefaultRetryableがfalseになるべきか
+	}{
+		{
+			name:      "wrong password",
+			server:    smtptest.Config{Username: "app", Password: "secret"},
+			setup:     func(e *notifier.EmailNotifier, _ *smtptest.Server) { e.SMTP.Username, e.SMTP.Password = "app", "wrong" },
+			wantCode:  535,
+			permanent: true,
+		},
+		{
+			name:      "auth required",
+			server:    smtptest.Config{Username: "app", Password: "secret"},
+			setup:     func(*notifier.EmailNotifier, *smtptest.Server) {},
+			wantCode:  530,
+			permanent: true,
+		},
+		{
+			name:      "rejected recipient",
+			server:    smtptest.Config{RejectRecipients: []string{"gone@example.com"}},
+			setup:     func(e *notifier.EmailNotifier, _ *smtptest.Server) { e.Bcc = []string{"gone@example.com"} },
+			wantCode:  550,
+			permanent: true,
+		},
+		{
+			name:      "starttls unsupported",
+			server:    smtptest.Config{},
+			setup:     func(e *notifier.EmailNotifier, _ *smtptest.Server) { e.SMTP.StartTLS = true },
+			wantErr:   notifier.ErrStartTLSUnsupported,
+			permanent: true,
+		},
+		{
+			name:      "invalid from",
+			server:    smtptest.Config{},
+			setup:     func(e *notifier.EmailNotifier, _ *smtptest.Server) { e.From = "not an address" },
+			permanent: true,
+		},
+		{
+			name:   "server down",
+			server: smtptest.Config{},
+			setup: func(e *notifier.EmailNotifier, srv *smtptest.Server) {
+				srv.Close()
+			},
+			permanent: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := smtptest.NewServer(tt.server)
+			defer srv.Close()
+			e := newEmail(srv)
+			tt.setup(e, srv)
+
+			err := e.Notify("hello")
+			if err == nil {
+				t.Fatal("Notify succeeded, want error")
+			}
+			if tt.wantCode != 0 {
+				var smtpErr *textproto.Error
+				if !errors.As(err, &smtpErr) || smtpErr.Code != tt.wantCode {
+					t.Errorf("err = %v, want SMTP %d", err, tt.wantCode)
+				}
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := !notifier.DefaultRetryable(err); got != tt.permanent {
+				t.Errorf("permanent = %v, want %v (err: %v)", got, tt.permanent, err)
+			}
+			if len(srv.Messages()) != 0 {
+				t.Error("message was delivered despite the error")
+			}
+		})
+	}
+}
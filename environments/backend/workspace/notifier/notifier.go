@@ -0,0 +1,16 @@
+// Package notifier はメール・Slackなど複数チャネルへの通知を扱う
+package notifier
+
+type Notifier interface {
+	Notify(message string) error
+}
+
+func SendAll(notifiers []Notifier, message string) []error {
+	var errors []error
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(message); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	return errors
+}
@@ -0,0 +1,325 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrOutboxEntryNotFound は指定IDのエントリが存在しない場合のエラー
+var ErrOutboxEntryNotFound = errors.New("outbox entry not found")
+
+// OutboxStatus はアウトボックスのエントリの状態
+type OutboxStatus string
+
+const (
+	OutboxPending   OutboxStatus = "pending"   // 送信待ち
+	OutboxSending   OutboxStatus = "sending"   // ワーカーが取得済み（リース期限まで）
+	OutboxDelivered OutboxStatus = "delivered" // 送信完了
+)
+
+// OutboxEntry はアウトボックスに保存された1件の通知
+type OutboxEntry struct {
+	ID             string `json:"id"`
+	IdempotencyKey string `json:"idempotency_key"`
+	Channel        string `json:"channel"`
+	// Recipient はチャネル内の宛先（メールアドレスやSlackのチャンネル）。空ならNotifierの設定どおりに送る
+	Recipient     string       `json:"recipient,omitempty"`
+	Message       string       `json:"message"`
+	Status        OutboxStatus `json:"status"`
+	Attempts      int          `json:"attempts"`
+	LastError     string       `json:"last_error,omitempty"`
+	CreatedAt     time.Time    `json:"created_at"`
+	NextAttemptAt time.Time    `json:"next_attempt_at"`
+	// LeaseUntil を過ぎてもsendingのままのエントリは、ワーカーが落ちたとみなして再取得する
+	LeaseUntil  time.Time `json:"lease_until,omitempty"`
+	DeliveredAt time.Time `json:"delivered_at,omitempty"`
+}
+
+// Outbox は送信前の通知を永続化する保存先
+type Outbox interface {
+	// Enqueue はエントリを保存する。同じチャネルに同じIdempotencyKeyが既にあれば保存せず、既存のエントリとtrueを返す
+	// （同じイベントをメールとSlackに送る場合など、チャネルが違えば同じキーでも別の通知）
+	Enqueue(e OutboxEntry) (OutboxEntry, bool, error)
+	// Claim は送信可能なエントリを最大limit件取得し、leaseの間sendingにする（Attemptsを1増やす）
+	Claim(now time.Time, limit int, lease time.Duration) ([]OutboxEntry, error)
+	// Release はClaimしたが送信を試みなかったエントリをpendingに戻し、Claimで増やしたAttemptsも戻す
+	Release(id string) error
+	MarkDelivered(id string, at time.Time) error
+	MarkRetry(id string, errMsg string, next time.Time) error
+	// Delete はエントリを削除する。再試行を諦めたエントリは、DeadLetterStoreに移してから削除する
+	Delete(id string) error
+	// PurgeDelivered はbeforeより前に送信済みになったエントリを削除し、削除した件数を返す
+	PurgeDelivered(before time.Time) (int, error)
+	List(status OutboxStatus) ([]OutboxEntry, error)
+}
+
+// FileOutbox はJSONファイルに保存するOutbox
+// 状態が変わるたびにファイル全体を書き直すので、プロセスが落ちても直前の状態から再開できる
+type FileOutbox struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]*OutboxEntry
+	byKey   map[string]string // dedupKey(Channel, IdempotencyKey) → ID
+}
+
+// OpenFileOutbox はpathのアウトボックスを開く（存在しなければ空で作る）
+func OpenFileOutbox(path string) (*FileOutbox, error) {
+	o := &FileOutbox{path: path, entries: make(map[string]*OutboxEntry), byKey: make(map[string]string)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return o, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read outbox: %w", err)
+	}
+	var entries []OutboxEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("decode outbox %s: %w", path, err)
+	}
+	for i := range entries {
+		e := entries[i]
+		o.entries[e.ID] = &e
+		o.byKey[dedupKey(e)] = e.ID
+	}
+	return o, nil
+}
+
+// dedupKey は重複を判定するキー（チャネルごとにIdempotencyKeyを区別する）
+func dedupKey(e OutboxEntry) string {
+	return e.Channel + "\x00" + e.IdempotencyKey
+}
+
+func (o *FileOutbox) Enqueue(e OutboxEntry) (OutboxEntry, bool, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if e.IdempotencyKey == "" {
+		e.IdempotencyKey = newID()
+	}
+	if id, ok := o.byKey[dedupKey(e)]; ok {
+		return *o.entries[id], true, nil
+	}
+	if e.ID == "" {
+		e.ID = newID()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	if e.NextAttemptAt.IsZero() {
+		e.NextAttemptAt = e.CreatedAt
+	}
+	e.Status = OutboxPending
+
+	o.entries[e.ID] = &e
+	o.byKey[dedupKey(e)] = e.ID
+	if err := o.save(); err != nil {
+		delete(o.entries, e.ID)
+		delete(o.byKey, dedupKey(e))
+		return OutboxEntry{}, false, err
+	}
+	return e, false, nil
+}
+
+func (o *FileOutbox) Claim(now time.Time, limit int, lease time.Duration) ([]OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var ready []*OutboxEntry
+	for _, e := range o.entries {
+		switch {
+		case e.Status == OutboxPending && !e.NextAttemptAt.After(now):
+			ready = append(ready, e)
+		case e.Status == OutboxSending && e.LeaseUntil.Before(now):
+			ready = append(ready, e)
+		}
+	}
+	// 古いものから送る
+	sort.Slice(ready, func(i, j int) bool { return ready[i].CreatedAt.Before(ready[j].CreatedAt) })
+	if limit > 0 && len(ready) > limit {
+		ready = ready[:limit]
+	}
+	if len(ready) == 0 {
+		return nil, nil
+	}
+
+	claimed := make([]OutboxEntry, len(ready))
+	for i, e := range ready {
+		e.Status = OutboxSending
+		e.LeaseUntil = now.Add(lease)
+		e.Attempts++
+		claimed[i] = *e
+	}
+	if err := o.save(); err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+func (o *FileOutbox) MarkDelivered(id string, at time.Time) error {
+	return o.update(id, func(e *OutboxEntry) {
+		e.Status = OutboxDelivered
+		e.DeliveredAt = at
+		e.LastError = ""
+	})
+}
+
+func (o *FileOutbox) MarkRetry(id string, errMsg string, next time.Time) error {
+	return o.update(id, func(e *OutboxEntry) {
+		e.Status = OutboxPending
+		e.LastError = errMsg
+		e.NextAttemptAt = next
+	})
+}
+
+func (o *FileOutbox) Release(id string) error {
+	return o.update(id, func(e *OutboxEntry) {
+		if e.Status != OutboxSending {
+			return
+		}
+		e.Status = OutboxPending
+		e.LeaseUntil = time.Time{}
+		e.Attempts = max(e.Attempts-1, 0)
+	})
+}
+
+func (o *FileOutbox) Delete(id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	e, ok := o.entries[id]
+	if !ok {
+		return fmt.Errorf("%w: id=%s", ErrOutboxEntryNotFound, id)
+	}
+	delete(o.entries, id)
+	delete(o.byKey, dedupKey(*e))
+	if err := o.save(); err != nil {
+		o.entries[id] = e
+		o.byKey[dedupKey(*e)] = id
+		return err
+	}
+	return nil
+}
+
+func (o *FileOutbox) List(status OutboxStatus) ([]OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var list []OutboxEntry
+	for _, e := range o.entries {
+		if status == "" || e.Status == status {
+			list = append(list, *e)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list, nil
+}
+
+// PurgeDelivered はbeforeより前に送信済みになったエントリを削除する
+// 削除したエントリのIdempotencyKeyは重複チェックの対象から外れる
+func (o *FileOutbox) PurgeDelivered(before time.Time) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := 0
+	for id, e := range o.entries {
+		if e.Status == OutboxDelivered && e.DeliveredAt.Before(before) {
+			delete(o.entries, id)
+			delete(o.byKey, dedupKey(*e))
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, nil
+	}
+	return n, o.save()
+}
+
+func (o *FileOutbox) update(id string, fn func(e *OutboxEntry)) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	e, ok := o.entries[id]
+	if !ok {
+		return fmt.Errorf("%w: id=%s", ErrOutboxEntryNotFound, id)
+	}
+	prev := *e
+	fn(e)
+	if err := o.save(); err != nil {
+		*e = prev
+		return err
+	}
+	return nil
+}
+
+// save は一時ファイルに書いてからrenameする（FileDeadLetterStore.saveと同じ理由）
+func (o *FileOutbox) save() error {
+	list := make([]OutboxEntry, 0, len(o.entries))
+	for _, e := range o.entries {
+		list = append(list, *e)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(o.path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("write outbox: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".outbox-*")
+	if err != nil {
+		return fmt.Errorf("write outbox: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write outbox: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write outbox: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write outbox: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), o.path); err != nil {
+		return fmt.Errorf("write outbox: %w", err)
+	}
+	return nil
+}
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey はctxに冪等キーを載せる
+// Dispatcherは送信時にエントリのキーを載せるので、送り直しても受信側で重複と分かる
+// 送信側は送ったキーを記録しないので、重複を捨てるかどうかは受信側次第
+//   - EmailNotifierはキーからMessage-IDを作る（メールクライアントは同じMessage-IDのメールを1通にまとめる）
+//   - SlackNotifierはIdempotency-Keyヘッダーに載せる（Webhookの手前のプロキシやslacktestが重複を捨てる）
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFromContext はctxに載っている冪等キーを返す
+func IdempotencyKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	return key, ok
+}
+
+type recipientKey struct{}
+
+// WithRecipient はctxにチャネル内の宛先を載せる
+// EmailNotifierは宛先（To）に、SlackNotifierはチャンネルに使い、設定されている値より優先する
+// Routerは受信者ごとの宛先（Preferences.Addresses）をここに載せて送る
+func WithRecipient(ctx context.Context, recipient string) context.Context {
+	return context.WithValue(ctx, recipientKey{}, recipient)
+}
+
+// RecipientFromContext はctxに載っている宛先を返す
+func RecipientFromContext(ctx context.Context) (string, bool) {
+	r, ok := ctx.Value(recipientKey{}).(string)
+	return r, ok && r != ""
+}
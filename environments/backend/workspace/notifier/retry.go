@@ -0,0 +1,194 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/textproto"
+	"time"
+)
+
+// Backoff は指数バックオフの設定
+type Backoff struct {
+	Initial    time.Duration // 1回目の再試行までの待ち時間（0なら100ms）
+	Max        time.Duration // 待ち時間の上限（0なら30秒）
+	Multiplier float64       // 1回ごとの倍率（0なら2）
+	// Jitter は待ち時間を±Jitterの割合でばらつかせる（0〜1）
+	// 同時に失敗した送信が一斉に再試行してサーバーを叩くのを防ぐ
+	Jitter float64
+}
+
+// Delay はattempt回目（1始まり）の失敗後に待つ時間を返す
+func (b Backoff) Delay(attempt int) time.Duration {
+	initial, max, mult := b.Initial, b.Max, b.Multiplier
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	if max <= 0 {
+		max = 30 * time.Second
+	}
+	if mult <= 0 {
+		mult = 2
+	}
+
+	d := float64(initial)
+	for i := 1; i < attempt && d < float64(max); i++ {
+		d *= mult
+	}
+	if d > float64(max) {
+		d = float64(max)
+	}
+	if b.Jitter > 0 {
+		d += d * b.Jitter * (rand.Float64()*2 - 1)
+	}
+	return time.Duration(d)
+}
+
+// retryDelay はattempt回目の失敗後に待つ時間を返す
+// Slackの429のようにサーバーが待ち時間を指定していれば、それより短くしない
+func retryDelay(b Backoff, attempt int, err error) time.Duration {
+	d := b.Delay(attempt)
+	var slackErr *SlackError
+	if errors.As(err, &slackErr) && slackErr.RetryAfter > d {
+		return slackErr.RetryAfter
+	}
+	return d
+}
+
+// permanentError は再試行しても成功しないエラーの目印
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent はerrを「再試行しても無駄なエラー」としてマークする
+// 宛先不正や認証エラーなど、何度送っても結果が変わらない場合に使う
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent はerrがPermanentでマークされているかを返す
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// DefaultRetryable は一時的なエラーかどうかを判定する
+// Permanent・キャンセル・SMTPの5xx・Slackの4xx（429を除く）は再試行しない
+func DefaultRetryable(err error) bool {
+	if IsPermanent(err) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		return smtpErr.Code < 500
+	}
+	var slackErr *SlackError
+	if errors.As(err, &slackErr) {
+		code := slackErr.StatusCode
+		return code == http.StatusTooManyRequests || code >= 500
+	}
+	return true
+}
+
+// RetryOptions はWithRetryの設定
+type RetryOptions struct {
+	// Name はデッドレターに記録する送信先の名前（再送時にNotifierを特定するのに使う）
+	Name        string
+	MaxAttempts int // 最初の1回を含む試行回数（0なら3回）
+	Backoff     Backoff
+	// Retryable がfalseを返したエラーは即座に諦める（nilならDefaultRetryable）
+	Retryable func(error) bool
+	// DeadLetters が設定されていれば、諦めたメッセージをここに保存する
+	DeadLetters DeadLetterStore
+}
+
+// RetryError は再試行を諦めたときのエラー
+type RetryError struct {
+	Attempts     int
+	DeadLetterID string // デッドレターに保存した場合のID
+	Err          error
+}
+
+func (e *RetryError) Error() string {
+	if e.DeadLetterID != "" {
+		return fmt.Sprintf("notify failed after %d attempt(s), dead-lettered as %s: %v", e.Attempts, e.DeadLetterID, e.Err)
+	}
+	return fmt.Sprintf("notify failed after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error { return e.Err }
+
+// WithRetry はnを再試行付きのNotifierで包む
+// SlackNotifierを包む場合はMaxRetriesを-1にする（429の再送が内側と外側で掛け算にならないように）
+func WithRetry(n Notifier, opts RetryOptions) ContextNotifier {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	if opts.Retryable == nil {
+		opts.Retryable = DefaultRetryable
+	}
+	return &retryNotifier{next: n, opts: opts}
+}
+
+type retryNotifier struct {
+	next Notifier
+	opts RetryOptions
+}
+
+func (r *retryNotifier) Notify(message string) error {
+	return r.NotifyContext(context.Background(), message)
+}
+
+func (r *retryNotifier) NotifyContext(ctx context.Context, message string) error {
+	var err error
+	attempt := 0
+	for attempt < r.opts.MaxAttempts {
+		attempt++
+		err = notifyOne(ctx, r.next, message, 0)
+		if err == nil {
+			return nil
+		}
+		if !r.opts.Retryable(err) || attempt == r.opts.MaxAttempts {
+			break
+		}
+
+		timer := time.NewTimer(retryDelay(r.opts.Backoff, attempt, err))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			err = errors.Join(err, ctx.Err())
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	retryErr := &RetryError{Attempts: attempt, Err: err}
+	if r.opts.DeadLetters != nil {
+		dl := DeadLetter{
+			ID:       newID(),
+			Notifier: r.opts.Name,
+			Message:  message,
+			Error:    err.Error(),
+			Attempts: attempt,
+			FailedAt: time.Now(),
+		}
+		dl.Recipient, _ = RecipientFromContext(ctx)
+		dl.IdempotencyKey, _ = IdempotencyKeyFromContext(ctx)
+		// デッドレターへの保存にも失敗したらメッセージが消えるので、両方のエラーを返す
+		if putErr := r.opts.DeadLetters.Put(dl); putErr != nil {
+			return errors.Join(retryErr, fmt.Errorf("dead letter: %w", putErr))
+		}
+		retryErr.DeadLetterID = dl.ID
+	}
+	return retryErr
+}
@@ -0,0 +1,164 @@
+package notifier_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"workout/notifier"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff notifier.Backoff
+		want    []time.Duration // attempt 1, 2, 3, ...
+	}{
+		{
+			name:    "defaults",
+			backoff: notifier.Backoff{},
+			want:    []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond},
+		},
+		{
+			name:    "multiplier and max",
+			backoff: notifier.Backoff{Initial: time.Second, Max: 10 * time.Second, Multiplier: 3},
+			want:    []time.Duration{time.Second, 3 * time.Second, 9 * time.Second, 10 * time.Second, 10 * time.Second},
+		},
+		{
+			name:    "initial above max",
+			backoff: notifier.Backoff{Initial: time.Minute, Max: time.Second},
+			want:    []time.Duration{time.Second, time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				if got := tt.backoff.Delay(i + 1); got != want {
+					t.Errorf("Delay(%d) = %v, want %v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBackoffJitter(t *testing.T) {
+	for _, jitter := range []float64{0.1, 0.5, 1} {
+		t.Run(strconv.FormatFloat(jitter, 'f', -1, 64), func(t *testing.T) {
+			b := notifier.Backoff{Initial: time.Second, Max: time.Minute, Jitter: jitter}
+			base := 4 * time.Second // 3回目
+			lo := time.Duration(float64(base) * (1 - jitter))
+			hi := time.Duration(float64(base) * (1 + jitter))
+			seen := make(map[time.Duration]bool)
+			for range 1000 {
+				d := b.Delay(3)
+				if d < lo || d > hi {
+					t.Fatalf("Delay(3) = %v, want within [%v, %v]", d, lo, hi)
+				}
+				seen[d] = true
+			}
+			// 毎回同じ値なら、同時に失敗した送信が一斉に再試行してしまう
+			if len(seen) < 10 {
+				t.Errorf("only %d distinct delays in 1000 samples", len(seen))
+			}
+		})
+	}
+}
+
+// failTimes は最初のn回だけerrを返し、その後は成功するNotifier。呼ばれた回数を数える
+type failTimes struct {
+	n     int
+	err   error
+	calls int
+}
+
+func (f *failTimes) Notify(message string) error {
+	f.calls++
+	if f.calls <= f.n {
+		return f.err
+	}
+	return nil
+}
+
+func TestWithRetry(t *testing.T) {
+	errTransient := errors.New("connection reset")
+	errBadAddress := errors.New("invalid address")
+	tests := []struct {
+		name         string
+		fails        int
+		err          error
+		wantCalls    int
+		wantErr      error
+		wantAttempts int
+	}{
+		{name: "first try", fails: 0, err: errTransient, wantCalls: 1},
+		{name: "succeeds after retries", fails: 2, err: errTransient, wantCalls: 3},
+		{name: "gives up after MaxAttempts", fails: 10, err: errTransient, wantCalls: 3, wantErr: errTransient, wantAttempts: 3},
+		{
+			// Permanentでマークしたエラーは再試行しない
+			name: "permanent error", fails: 10, err: notifier.Permanent(errBadAddress),
+			wantCalls: 1, wantErr: errBadAddress, wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := &failTimes{n: tt.fails, err: tt.err}
+			dls := notifier.NewMemoryDeadLetterStore()
+			n := notifier.WithRetry(next, notifier.RetryOptions{
+				Name:        "email",
+				MaxAttempts: 3,
+				Backoff:     notifier.Backoff{Initial: time.Nanosecond, Max: time.Nanosecond},
+				DeadLetters: dls,
+			})
+			ctx := notifier.WithRecipient(notifier.WithIdempotencyKey(context.Background(), "evt-1"), "tanaka@example.com")
+			err := n.NotifyContext(ctx, "hello")
+
+			if next.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", next.calls, tt.wantCalls)
+			}
+			letters, _ := dls.List()
+			if tt.wantErr == nil {
+				if err != nil || len(letters) != 0 {
+					t.Fatalf("err = %v, dead letters = %v", err, letters)
+				}
+				return
+			}
+
+			var retryErr *notifier.RetryError
+			if !errors.As(err, &retryErr) || !errors.Is(err, tt.wantErr) || retryErr.Attempts != tt.wantAttempts {
+				t.Fatalf("err = %v, want *RetryError wrapping %v after %d attempts", err, tt.wantErr, tt.wantAttempts)
+			}
+			if len(letters) != 1 {
+				t.Fatalf("dead letters = %v, want 1", letters)
+			}
+			dl := letters[0]
+			if dl.ID != retryErr.DeadLetterID || dl.Notifier != "email" || dl.Message != "hello" || dl.Attempts != tt.wantAttempts ||
+				dl.Recipient != "tanaka@example.com" || dl.IdempotencyKey != "evt-1" {
+				t.Errorf("dead letter = %+v", dl)
+			}
+		})
+	}
+}
+
+func TestWithRetryCancelDuringBackoff(t *testing.T) {
+	errTransient := errors.New("connection reset")
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	fail := notifyFunc(func(context.Context, string) error {
+		calls++
+		time.AfterFunc(10*time.Millisecond, cancel)
+		return errTransient
+	})
+	n := notifier.WithRetry(fail, notifier.RetryOptions{MaxAttempts: 5, Backoff: notifier.Backoff{Initial: time.Hour}})
+
+	start := time.Now()
+	err := n.NotifyContext(ctx, "hello")
+	// 1時間の待ちをキャンセルで打ち切り、それ以上送らない
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("NotifyContext took %v after cancel", elapsed)
+	}
+	if calls != 1 || !errors.Is(err, context.Canceled) || !errors.Is(err, errTransient) {
+		t.Errorf("calls = %d, err = %v; want 1 call and both the last error and context.Canceled", calls, err)
+	}
+}
@@ -0,0 +1,309 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// ErrNoRoute はどのチャネルにも送る先がなかった場合のエラー
+var ErrNoRoute = errors.New("no channel matched the message")
+
+// Severity はメッセージの重要度
+type Severity int
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+	SeverityCritical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "INFO"
+	case SeverityWarning:
+		return "WARNING"
+	case SeverityError:
+		return "ERROR"
+	case SeverityCritical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MarshalText はJSONなどで"INFO"のような名前で表す
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Severity) UnmarshalText(text []byte) error {
+	sev, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = sev
+	return nil
+}
+
+// ParseSeverity は"info"や"ERROR"などの文字列をSeverityに変換する
+func ParseSeverity(s string) (Severity, error) {
+	for sev := SeverityInfo; sev <= SeverityCritical; sev++ {
+		if strings.EqualFold(s, sev.String()) {
+			return sev, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown severity: %q", s)
+}
+
+// Message はルーティングとテンプレート描画に使う構造化されたメッセージ
+type Message struct {
+	Severity  Severity
+	Topic     string // "digest" や "workout.record" など
+	Recipient string // 受信者の設定を引くためのID
+	Title     string
+	Body      string
+	Data      map[string]any
+}
+
+// Rule は「どのメッセージをどのチャネルへ送るか」の条件
+type Rule struct {
+	// MinSeverity 以上のメッセージだけが対象
+	MinSeverity Severity
+	// Topics はpath.Matchのパターン（"workout.*"など）。空なら全トピックが対象
+	Topics   []string
+	Channels []string
+}
+
+func (r Rule) matches(msg Message) bool {
+	if msg.Severity < r.MinSeverity {
+		return false
+	}
+	if len(r.Topics) == 0 {
+		return true
+	}
+	return matchTopic(r.Topics, msg.Topic)
+}
+
+// Preferences は受信者ごとの通知設定
+type Preferences struct {
+	// Addresses はチャネル名→その受信者の宛先（"email"→メールアドレス、"slack"→チャンネル）
+	// AddRecipientChannelで登録したチャネルは、宛先がなければ送らない
+	Addresses map[string]string `json:"addresses,omitempty"`
+	// OptOut は受け取りを拒否したチャネル名
+	OptOut []string `json:"opt_out,omitempty"`
+	// MutedTopics はpath.Matchのパターン。一致したトピックは受け取らない
+	MutedTopics []string `json:"muted_topics,omitempty"`
+	// MinSeverity 未満のメッセージは受け取らない
+	MinSeverity Severity `json:"min_severity"`
+}
+
+// Validate は設定の値を検証し、問題のある項目名→理由を返す（問題がなければnil）
+func (p Preferences) Validate() map[string]string {
+	fields := make(map[string]string)
+	for name, addr := range p.Addresses {
+		if addr == "" {
+			fields["addresses."+name] = "must not be empty"
+		}
+	}
+	for i, pattern := range p.MutedTopics {
+		if _, err := path.Match(pattern, ""); err != nil {
+			fields[fmt.Sprintf("muted_topics[%d]", i)] = "invalid pattern"
+		}
+	}
+	if p.MinSeverity < SeverityInfo || p.MinSeverity > SeverityCritical {
+		fields["min_severity"] = "unknown severity"
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// allows はこの設定の受信者がchannelでmsgを受け取るかを返す
+func (p Preferences) allows(channel string, msg Message) bool {
+	if msg.Severity < p.MinSeverity {
+		return false
+	}
+	if slices.Contains(p.OptOut, channel) {
+		return false
+	}
+	return !matchTopic(p.MutedTopics, msg.Topic)
+}
+
+// PreferenceStore は受信者の通知設定を取得する
+type PreferenceStore interface {
+	Preferences(recipient string) (Preferences, error)
+}
+
+// MemoryPreferenceStore はメモリ上のPreferenceStore
+// 設定が登録されていない受信者はゼロ値（全て受け取る）として扱う
+type MemoryPreferenceStore struct {
+	mu    sync.RWMutex
+	prefs map[string]Preferences
+}
+
+func NewMemoryPreferenceStore() *MemoryPreferenceStore {
+	return &MemoryPreferenceStore{prefs: make(map[string]Preferences)}
+}
+
+func (s *MemoryPreferenceStore) SavePreferences(recipient string, p Preferences) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prefs[recipient] = p
+	return nil
+}
+
+func (s *MemoryPreferenceStore) Preferences(recipient string) (Preferences, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.prefs[recipient], nil
+}
+
+// DefaultTemplate はチャネルにテンプレートを指定しなかった場合に使う
+const DefaultTemplate = "[{{.Severity}}] {{.Title}}{{if .Body}}\n{{.Body}}{{end}}"
+
+type channel struct {
+	notifier Notifier
+	tmpl     *template.Template
+	// perRecipient は受信者ごとの宛先（Preferences.Addresses）に送るチャネルか
+	perRecipient bool
+}
+
+// Router はメッセージの重要度・トピック・受信者の設定から送信先チャネルを選ぶ
+// チャネルごとにテンプレートを持ち、同じMessageでもSlack向け・メール向けに描き分けられる
+type Router struct {
+	channels map[string]channel
+	rules    []Rule
+	prefs    PreferenceStore
+}
+
+// NewRouter はRouterを作る。prefsがnilなら受信者の設定は見ない
+func NewRouter(prefs PreferenceStore) *Router {
+	return &Router{channels: make(map[string]channel), prefs: prefs}
+}
+
+// AddChannel は名前付きの送信先を登録する。tmplはtext/templateで、Messageを受け取る
+func (r *Router) AddChannel(name string, n Notifier, tmpl string) error {
+	return r.addChannel(name, n, tmpl, false)
+}
+
+// AddRecipientChannel は受信者ごとの宛先に送るチャネルを登録する
+// 送るときはPreferences.Addresses[name]をWithRecipientでctxに載せる。宛先のない受信者には送らない
+func (r *Router) AddRecipientChannel(name string, n Notifier, tmpl string) error {
+	return r.addChannel(name, n, tmpl, true)
+}
+
+func (r *Router) addChannel(name string, n Notifier, tmpl string, perRecipient bool) error {
+	if tmpl == "" {
+		tmpl = DefaultTemplate
+	}
+	t, err := template.New(name).Option("missingkey=zero").Parse(tmpl)
+	if err != nil {
+		return fmt.Errorf("parse template for channel %q: %w", name, err)
+	}
+	r.channels[name] = channel{notifier: n, tmpl: t, perRecipient: perRecipient}
+	return nil
+}
+
+// AddRule はルールを追加する。存在しないチャネルを指定した場合はエラー
+func (r *Router) AddRule(rule Rule) error {
+	for _, name := range rule.Channels {
+		if _, ok := r.channels[name]; !ok {
+			return fmt.Errorf("rule refers to unknown channel %q", name)
+		}
+	}
+	r.rules = append(r.rules, rule)
+	return nil
+}
+
+// Route はmsgを送るチャネル名を返す（ルール順・重複なし）
+func (r *Router) Route(msg Message) ([]string, error) {
+	names, _, err := r.route(msg)
+	return names, err
+}
+
+// route はチャネル名と、受信者の設定を返す
+func (r *Router) route(msg Message) ([]string, Preferences, error) {
+	prefs := Preferences{}
+	if r.prefs != nil && msg.Recipient != "" {
+		p, err := r.prefs.Preferences(msg.Recipient)
+		if err != nil {
+			return nil, Preferences{}, fmt.Errorf("load preferences for %q: %w", msg.Recipient, err)
+		}
+		prefs = p
+	}
+
+	var names []string
+	for _, rule := range r.rules {
+		if !rule.matches(msg) {
+			continue
+		}
+		for _, name := range rule.Channels {
+			if slices.Contains(names, name) || !prefs.allows(name, msg) {
+				continue
+			}
+			if r.channels[name].perRecipient && prefs.Addresses[name] == "" {
+				continue
+			}
+			names = append(names, name)
+		}
+	}
+	return names, prefs, nil
+}
+
+// Render はチャネルnameのテンプレートでmsgを文字列にする
+func (r *Router) Render(name string, msg Message) (string, error) {
+	ch, ok := r.channels[name]
+	if !ok {
+		return "", fmt.Errorf("unknown channel %q", name)
+	}
+	var sb strings.Builder
+	if err := ch.tmpl.Execute(&sb, msg); err != nil {
+		return "", fmt.Errorf("render template for channel %q: %w", name, err)
+	}
+	return sb.String(), nil
+}
+
+// Dispatch はmsgをルーティングし、各チャネルのテンプレートで描画して並行に送信する
+// ctxの冪等キー（WithIdempotencyKey）は各チャネルのNotifierにそのまま渡る
+// 送り先がなければErrNoRouteを返す
+func (r *Router) Dispatch(ctx context.Context, msg Message, opts SendOptions) (Results, error) {
+	names, prefs, err := r.route(msg)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, ErrNoRoute
+	}
+
+	deliveries := make([]delivery, 0, len(names))
+	for _, name := range names {
+		text, err := r.Render(name, msg)
+		if err != nil {
+			return nil, err
+		}
+		d := delivery{name: name, notifier: r.channels[name].notifier, message: text}
+		if r.channels[name].perRecipient {
+			d.recipient = prefs.Addresses[name]
+		}
+		deliveries = append(deliveries, d)
+	}
+	return sendEach(ctx, deliveries, opts), nil
+}
+
+func matchTopic(patterns []string, topic string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, topic); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,247 @@
+package notifier_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"workout/notifier"
+)
+
+// prefsFunc は関数をPreferenceStoreとして使う
+type prefsFunc func(recipient string) (notifier.Preferences, error)
+
+func (f prefsFunc) Preferences(recipient string) (notifier.Preferences, error) { return f(recipient) }
+
+// newRouter はemail・slack・pagerの3チャネルと、よく使う形のルールを持つRouterを作る
+//   - workout.* のトピックはslackへ
+//   - ERROR以上はemailとpagerへ
+//   - digestはemailへ
+func newRouter(t *testing.T, prefs notifier.PreferenceStore) *notifier.Router {
+	t.Helper()
+	r := notifier.NewRouter(prefs)
+	nop := notifyFunc(func(context.Context, string) error { return nil })
+	for _, name := range []string{"email", "slack", "pager"} {
+		if err := r.AddChannel(name, nop, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, rule := range []notifier.Rule{
+		{Topics: []string{"workout.*"}, Channels: []string{"slack"}},
+		{MinSeverity: notifier.SeverityError, Channels: []string{"email", "pager"}},
+		{Topics: []string{"digest"}, Channels: []string{"email"}},
+	} {
+		if err := r.AddRule(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r
+}
+
+func TestRouterRoute(t *testing.T) {
+	tests := []struct {
+		name  string
+		msg   notifier.Message
+		prefs notifier.Preferences
+		want  []string
+	}{
+		{
+			name: "topic wildcard",
+			msg:  notifier.Message{Topic: "workout.record"},
+			want: []string{"slack"},
+		},
+		{
+			// path.Matchの*は.をまたぐが/はまたがない
+			name: "wildcard does not cross slash",
+			msg:  notifier.Message{Topic: "workout.record/2025"},
+		},
+		{
+			name: "severity rule",
+			msg:  notifier.Message{Severity: notifier.SeverityCritical, Topic: "db"},
+			want: []string{"email", "pager"},
+		},
+		{
+			name: "below rule severity",
+			msg:  notifier.Message{Severity: notifier.SeverityWarning, Topic: "db"},
+		},
+		{
+			// 複数のルールに一致しても同じチャネルは1回だけ、ルール順に並ぶ
+			name: "no duplicates",
+			msg:  notifier.Message{Severity: notifier.SeverityError, Topic: "digest"},
+			want: []string{"email", "pager"},
+		},
+		{
+			name:  "opt out",
+			msg:   notifier.Message{Severity: notifier.SeverityError, Topic: "workout.record", Recipient: "1"},
+			prefs: notifier.Preferences{OptOut: []string{"pager"}},
+			want:  []string{"slack", "email"},
+		},
+		{
+			name:  "muted topic",
+			msg:   notifier.Message{Severity: notifier.SeverityError, Topic: "workout.record", Recipient: "1"},
+			prefs: notifier.Preferences{MutedTopics: []string{"workout.*"}},
+		},
+		{
+			name:  "recipient min severity",
+			msg:   notifier.Message{Severity: notifier.SeverityError, Topic: "workout.record", Recipient: "1"},
+			prefs: notifier.Preferences{MinSeverity: notifier.SeverityCritical},
+		},
+		{
+			// 受信者のないメッセージには設定を使わない
+			name:  "no recipient",
+			msg:   notifier.Message{Topic: "workout.record"},
+			prefs: notifier.Preferences{OptOut: []string{"slack"}},
+			want:  []string{"slack"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := notifier.NewMemoryPreferenceStore()
+			store.SavePreferences("1", tt.prefs)
+			got, err := newRouter(t, store).Route(tt.msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Route = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("preferences error", func(t *testing.T) {
+		errDB := errors.New("db down")
+		r := newRouter(t, prefsFunc(func(string) (notifier.Preferences, error) {
+			return notifier.Preferences{}, errDB
+		}))
+		if _, err := r.Route(notifier.Message{Topic: "digest", Recipient: "1"}); !errors.Is(err, errDB) {
+			t.Errorf("err = %v, want %v", err, errDB)
+		}
+	})
+}
+
+func TestRouterRender(t *testing.T) {
+	nop := notifyFunc(func(context.Context, string) error { return nil })
+	msg := notifier.Message{
+		Severity: notifier.SeverityWarning,
+		Title:    "ベンチプレス 80kg",
+		Body:     "自己ベスト更新",
+		Data:     map[string]any{"user": "tanaka"},
+	}
+	tests := []struct {
+		name    string
+		tmpl    string
+		msg     notifier.Message
+		want    string
+		wantErr string
+	}{
+		{name: "default template", msg: msg, want: "[WARNING] ベンチプレス 80kg\n自己ベスト更新"},
+		{name: "default template without body", msg: notifier.Message{Title: "done"}, want: "[INFO] done"},
+		{name: "custom template", tmpl: "{{.Data.user}}: {{.Title}}", msg: msg, want: "tanaka: ベンチプレス 80kg"},
+		{name: "execute error", tmpl: "{{.Missing}}", msg: msg, wantErr: `render template for channel "email"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := notifier.NewRouter(nil)
+			if err := r.AddChannel("email", nop, tt.tmpl); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.Render("email", tt.msg)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("err = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Render = %q, %v; want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	t.Run("parse error", func(t *testing.T) {
+		err := notifier.NewRouter(nil).AddChannel("email", nop, "{{.Title")
+		if err == nil || !strings.Contains(err.Error(), `parse template for channel "email"`) {
+			t.Errorf("AddChannel = %v, want parse error", err)
+		}
+	})
+	t.Run("unknown channel", func(t *testing.T) {
+		if _, err := notifier.NewRouter(nil).Render("sms", msg); err == nil {
+			t.Error("Render of unknown channel succeeded")
+		}
+	})
+	t.Run("rule with unknown channel", func(t *testing.T) {
+		if err := notifier.NewRouter(nil).AddRule(notifier.Rule{Channels: []string{"sms"}}); err == nil {
+			t.Error("AddRule with unknown channel succeeded")
+		}
+	})
+}
+
+func TestRouterDispatch(t *testing.T) {
+	var mu sync.Mutex
+	got := make(map[string]sent)
+	record := func(name string) notifier.Notifier {
+		return notifyFunc(func(ctx context.Context, message string) error {
+			recipient, _ := notifier.RecipientFromContext(ctx)
+			key, _ := notifier.IdempotencyKeyFromContext(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			got[name] = sent{message, recipient, key}
+			return nil
+		})
+	}
+	store := notifier.NewMemoryPreferenceStore()
+	store.SavePreferences("1", notifier.Preferences{Addresses: map[string]string{"email": "tanaka@example.com"}})
+
+	r := notifier.NewRouter(store)
+	if err := r.AddRecipientChannel("email", record("email"), "{{.Title}}"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddChannel("slack", record("slack"), "*{{.Title}}*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddRule(notifier.Rule{Topics: []string{"workout.*"}, Channels: []string{"email", "slack"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := notifier.WithIdempotencyKey(context.Background(), "evt-1")
+	rs, err := r.Dispatch(ctx, notifier.Message{Topic: "workout.record", Recipient: "1", Title: "PR"}, notifier.SendOptions{})
+	if err != nil || !rs.OK() {
+		t.Fatalf("Dispatch = %v, %v", rs.Errors(), err)
+	}
+	// 受信者ごとのチャネルには登録された宛先を、どのチャネルにも冪等キーを渡す
+	want := map[string]sent{
+		"email": {"PR", "tanaka@example.com", "evt-1"},
+		"slack": {"*PR*", "", "evt-1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sent = %+v, want %+v", got, want)
+	}
+
+	// 宛先のない受信者にはemailを送らない
+	clear(got)
+	if _, err := r.Dispatch(ctx, notifier.Message{Topic: "workout.record", Recipient: "2", Title: "PR"}, notifier.SendOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["email"]; ok || len(got) != 1 {
+		t.Errorf("sent = %+v, want slack only", got)
+	}
+
+	if _, err := r.Dispatch(ctx, notifier.Message{Topic: "digest"}, notifier.SendOptions{}); !errors.Is(err, notifier.ErrNoRoute) {
+		t.Errorf("err = %v, want ErrNoRoute", err)
+	}
+
+	// 描画に失敗したら何も送らない
+	if err := r.AddChannel("broken", record("broken"), "{{.Missing}}"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.AddRule(notifier.Rule{Topics: []string{"digest"}, Channels: []string{"slack", "broken"}}); err != nil {
+		t.Fatal(err)
+	}
+	clear(got)
+	if _, err := r.Dispatch(ctx, notifier.Message{Topic: "digest"}, notifier.SendOptions{}); err == nil || len(got) != 0 {
+		t.Errorf("err = %v, sent = %+v; want render error and nothing sent", err, got)
+	}
+}
@@ -0,0 +1,211 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ContextNotifier はキャンセル・期限に対応したNotifier
+// 実装していないNotifierは別goroutineで実行し、期限切れの時点で待つのをやめる
+type ContextNotifier interface {
+	Notifier
+	NotifyContext(ctx context.Context, message string) error
+}
+
+// Status は1つのNotifierの送信結果の種類
+type Status int
+
+const (
+	StatusSucceeded Status = iota
+	StatusFailed
+	StatusTimedOut
+	StatusCanceled
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusSucceeded:
+		return "succeeded"
+	case StatusFailed:
+		return "failed"
+	case StatusTimedOut:
+		return "timed out"
+	case StatusCanceled:
+		return "canceled"
+	default:
+		return "unknown"
+	}
+}
+
+// Result はどのNotifierがどういう結果になったかを保持する
+type Result struct {
+	Index    int
+	Name     string // Routerが送った場合のチャネル名
+	Notifier Notifier
+	Status   Status
+	Err      error
+	Duration time.Duration
+}
+
+// Results はSendAllContextの結果。順序は渡したnotifiersと同じ
+type Results []Result
+
+// Errors は失敗した結果のエラーだけを、どのNotifierかわかる形で返す
+func (rs Results) Errors() []error {
+	var errs []error
+	for _, r := range rs {
+		if r.Err == nil {
+			continue
+		}
+		if r.Name != "" {
+			errs = append(errs, fmt.Errorf("channel %q %s: %w", r.Name, r.Status, r.Err))
+		} else {
+			errs = append(errs, fmt.Errorf("notifier[%d] %T %s: %w", r.Index, r.Notifier, r.Status, r.Err))
+		}
+	}
+	return errs
+}
+
+// OK は全てのNotifierが成功したかを返す
+func (rs Results) OK() bool {
+	for _, r := range rs {
+		if r.Status != StatusSucceeded {
+			return false
+		}
+	}
+	return true
+}
+
+// SendOptions はSendAllContextの動作設定
+type SendOptions struct {
+	// Timeout は各Notifierごとの送信期限（0なら親contextの期限のみ）
+	Timeout time.Duration
+	// MaxConcurrency は同時に送信するNotifierの上限（0以下なら無制限）
+	MaxConcurrency int
+}
+
+// SendAllContext は全てのNotifierへgoroutineで並行に送信する
+// 遅いNotifierがいても他の送信は待たされず、ctxのキャンセルで打ち切れる
+func SendAllContext(ctx context.Context, notifiers []Notifier, message string, opts SendOptions) Results {
+	deliveries := make([]delivery, len(notifiers))
+	for i, n := range notifiers {
+		deliveries[i] = delivery{notifier: n, message: message}
+	}
+	return sendEach(ctx, deliveries, opts)
+}
+
+// delivery は1つのNotifierに送る内容
+// Routerのようにチャネルごとに本文が違う場合もSendAllContextと同じ並行送信を使えるようにする
+type delivery struct {
+	name      string
+	notifier  Notifier
+	message   string
+	recipient string // 空でなければWithRecipientでctxに載せる
+}
+
+func sendEach(ctx context.Context, deliveries []delivery, opts SendOptions) Results {
+	results := make(Results, len(deliveries))
+
+	var sem chan struct{}
+	if opts.MaxConcurrency > 0 {
+		sem = make(chan struct{}, opts.MaxConcurrency)
+	}
+
+	var wg sync.WaitGroup
+	for i, d := range deliveries {
+		results[i] = Result{Index: i, Name: d.name, Notifier: d.notifier}
+
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				// 枠が空く前にキャンセルされたNotifierは送信しない
+				results[i].Status = StatusCanceled
+				results[i].Err = ctx.Err()
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func(r *Result, d delivery) {
+			defer wg.Done()
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+			nctx := ctx
+			if d.recipient != "" {
+				nctx = WithRecipient(ctx, d.recipient)
+			}
+			start := time.Now()
+			r.Err = notifyOne(nctx, r.Notifier, d.message, opts.Timeout)
+			r.Duration = time.Since(start)
+			r.Status = classify(ctx, r.Err)
+		}(&results[i], d)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// WithTimeout はnに個別の送信期限を付けたNotifierを返す
+// SendOptions.Timeoutより短い期限を特定のNotifierだけに設定したい場合に使う
+func WithTimeout(n Notifier, d time.Duration) ContextNotifier {
+	return &timeoutNotifier{next: n, timeout: d}
+}
+
+type timeoutNotifier struct {
+	next    Notifier
+	timeout time.Duration
+}
+
+func (t *timeoutNotifier) Notify(message string) error {
+	return t.NotifyContext(context.Background(), message)
+}
+
+func (t *timeoutNotifier) NotifyContext(ctx context.Context, message string) error {
+	return notifyOne(ctx, t.next, message, t.timeout)
+}
+
+func notifyOne(ctx context.Context, n Notifier, message string, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if cn, ok := n.(ContextNotifier); ok {
+		return cn.NotifyContext(ctx, message)
+	}
+
+	// contextに対応していないNotifierは止められないので、結果を待つのをやめるだけ
+	// バッファ付きにしておくことで、後から終わったgoroutineがリークしない
+	done := make(chan error, 1)
+	go func() { done <- n.Notify(message) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// classify はerrから結果の種類を決める。Notifier自身のエラーは、親がキャンセルされた後でも失敗として残す
+// 親のキャンセルを見るのはerrがcontextのエラーのときだけで、個別の期限切れと親のキャンセルを区別するため
+func classify(parent context.Context, err error) Status {
+	switch {
+	case err == nil:
+		return StatusSucceeded
+	case !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, context.Canceled):
+		return StatusFailed
+	case errors.Is(parent.Err(), context.Canceled), errors.Is(err, context.Canceled):
+		return StatusCanceled
+	default:
+		return StatusTimedOut
+	}
+}
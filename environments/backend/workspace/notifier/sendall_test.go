@@ -0,0 +1,171 @@
+package notifier_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"workout/notifier"
+)
+
+// blockingNotify はctxが終わるまで返らない、contextに対応したNotifier
+func blockingNotify(ctx context.Context, _ string) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// legacyNotifier はcontextに対応していないNotifier
+type legacyNotifier func(message string) error
+
+func (f legacyNotifier) Notify(message string) error { return f(message) }
+
+func statuses(rs notifier.Results) []notifier.Status {
+	var out []notifier.Status
+	for _, r := range rs {
+		out = append(out, r.Status)
+	}
+	return out
+}
+
+func TestSendAllContextStatuses(t *testing.T) {
+	errDown := errors.New("smtp: connection refused")
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+
+	tests := []struct {
+		name      string
+		notifiers []notifier.Notifier
+		timeout   time.Duration
+		cancel    bool // 送信を始めてすぐに親のctxをキャンセルする
+		want      []notifier.Status
+	}{
+		{
+			name: "success and failure",
+			notifiers: []notifier.Notifier{
+				notifyFunc(func(context.Context, string) error { return nil }),
+				notifyFunc(func(context.Context, string) error { return errDown }),
+			},
+			want: []notifier.Status{notifier.StatusSucceeded, notifier.StatusFailed},
+		},
+		{
+			// 遅いNotifierだけが期限切れになり、他は待たされない
+			name: "per-notifier timeout",
+			notifiers: []notifier.Notifier{
+				notifyFunc(blockingNotify),
+				legacyNotifier(func(string) error { <-release; return nil }),
+				notifyFunc(func(context.Context, string) error { return nil }),
+			},
+			timeout: 10 * time.Millisecond,
+			want:    []notifier.Status{notifier.StatusTimedOut, notifier.StatusTimedOut, notifier.StatusSucceeded},
+		},
+		{
+			// 個別の期限があっても、親のキャンセルで止まったものはキャンセルとして数える
+			name: "parent cancel",
+			notifiers: []notifier.Notifier{
+				notifyFunc(blockingNotify),
+				legacyNotifier(func(string) error { <-release; return nil }),
+			},
+			timeout: time.Minute,
+			cancel:  true,
+			want:    []notifier.Status{notifier.StatusCanceled, notifier.StatusCanceled},
+		},
+		{
+			// 親がキャンセルされた後に返ってきても、Notifier自身のエラーは失敗のまま
+			name: "failure after parent cancel is not hidden",
+			notifiers: []notifier.Notifier{
+				notifyFunc(func(ctx context.Context, _ string) error {
+					<-ctx.Done()
+					return errDown
+				}),
+			},
+			cancel: true,
+			want:   []notifier.Status{notifier.StatusFailed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				time.AfterFunc(10*time.Millisecond, cancel)
+			}
+			rs := notifier.SendAllContext(ctx, tt.notifiers, "deploy finished", notifier.SendOptions{Timeout: tt.timeout})
+			got := statuses(rs)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("result[%d] = %v (err %v), want %v", i, got[i], rs[i].Err, tt.want[i])
+				}
+			}
+			if rs.OK() != (len(rs.Errors()) == 0) {
+				t.Errorf("OK() = %v but Errors() = %v", rs.OK(), rs.Errors())
+			}
+		})
+	}
+
+	t.Run("failure keeps the notifier error", func(t *testing.T) {
+		rs := notifier.SendAllContext(context.Background(), []notifier.Notifier{
+			notifyFunc(func(context.Context, string) error { return errDown }),
+		}, "m", notifier.SendOptions{})
+		if errs := rs.Errors(); len(errs) != 1 || !errors.Is(errs[0], errDown) {
+			t.Errorf("Errors() = %v, want one wrapping %v", errs, errDown)
+		}
+	})
+}
+
+func TestSendAllContextMaxConcurrency(t *testing.T) {
+	tests := []struct {
+		maxConcurrency int
+		wantMax        int32
+	}{
+		{maxConcurrency: 1, wantMax: 1},
+		{maxConcurrency: 3, wantMax: 3},
+		{maxConcurrency: 0, wantMax: 8}, // 無制限
+	}
+	for _, tt := range tests {
+		var running, maxRunning atomic.Int32
+		n := notifyFunc(func(context.Context, string) error {
+			cur := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if cur <= m || maxRunning.CompareAndSwap(m, cur) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		})
+		notifiers := make([]notifier.Notifier, 8)
+		for i := range notifiers {
+			notifiers[i] = n
+		}
+
+		rs := notifier.SendAllContext(context.Background(), notifiers, "m", notifier.SendOptions{MaxConcurrency: tt.maxConcurrency})
+		if !rs.OK() {
+			t.Errorf("MaxConcurrency %d: %v", tt.maxConcurrency, rs.Errors())
+		}
+		if got := maxRunning.Load(); got > tt.wantMax || (tt.maxConcurrency > 0 && got != tt.wantMax) {
+			t.Errorf("MaxConcurrency %d: %d notifiers ran at once, want %d", tt.maxConcurrency, got, tt.wantMax)
+		}
+	}
+
+	// 枠が空くのを待っている間にキャンセルされたNotifierは送信しない
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	first := notifyFunc(func(context.Context, string) error {
+		calls.Add(1)
+		cancel()
+		time.Sleep(50 * time.Millisecond) // キャンセルの後もしばらく枠を使い続ける
+		return nil
+	})
+	second := notifyFunc(func(context.Context, string) error { calls.Add(1); return nil })
+	rs := notifier.SendAllContext(ctx, []notifier.Notifier{first, second}, "m", notifier.SendOptions{MaxConcurrency: 1})
+	if calls.Load() != 1 || rs[1].Status != notifier.StatusCanceled || !errors.Is(rs[1].Err, context.Canceled) {
+		t.Errorf("calls = %d, second = %v %v; want 1 call and second canceled", calls.Load(), rs[1].Status, rs[1].Err)
+	}
+}
@@ -0,0 +1,206 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// SlackNotifier はSlackのIncoming Webhookへ通知するNotifier
+// WebhookURLが空の場合は送信せず標準出力に表示するだけ（ローカル開発用）
+type SlackNotifier struct {
+	Channel    string
+	WebhookURL string
+	// ThreadTS を設定すると、そのメッセージのスレッドに返信する
+	ThreadTS  string
+	Username  string
+	IconEmoji string
+
+	// MaxRetries は429が返ったときにこのNotifierの中で再送する回数（0なら3回、負なら再送しない）
+	// WithRetryやDispatcherで包む場合は-1にして、再試行をそちらに任せる（Retry-Afterはそちらでも守る）
+	MaxRetries int
+	// MaxRetryAfter より長いRetry-Afterが返ったら待たずに諦める（0なら30秒）
+	MaxRetryAfter time.Duration
+
+	HTTPClient *http.Client
+}
+
+// SlackMessage はWebhookに送るBlock Kit形式のペイロード
+type SlackMessage struct {
+	Channel   string       `json:"channel,omitempty"`
+	Text      string       `json:"text"`
+	Blocks    []SlackBlock `json:"blocks,omitempty"`
+	ThreadTS  string       `json:"thread_ts,omitempty"`
+	Username  string       `json:"username,omitempty"`
+	IconEmoji string       `json:"icon_emoji,omitempty"`
+}
+
+// SlackBlock はBlock Kitのブロック（section / context など）
+type SlackBlock struct {
+	Type     string      `json:"type"`
+	Text     *SlackText  `json:"text,omitempty"`
+	Elements []SlackText `json:"elements,omitempty"`
+}
+
+// SlackText はBlock Kitのテキストオブジェクト
+type SlackText struct {
+	Type string `json:"type"` // "mrkdwn" または "plain_text"
+	Text string `json:"text"`
+}
+
+// SlackError はWebhookが2xx以外を返した場合のエラー
+type SlackError struct {
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // 429の場合のみ
+}
+
+func (e *SlackError) Error() string {
+	if e.StatusCode == http.StatusTooManyRequests {
+		return fmt.Sprintf("slack webhook rate limited (retry after %s)", e.RetryAfter)
+	}
+	return fmt.Sprintf("slack webhook returned %d: %s", e.StatusCode, e.Body)
+}
+
+// InThread はthreadTSのスレッドに返信するSlackNotifierのコピーを返す
+func (s *SlackNotifier) InThread(threadTS string) *SlackNotifier {
+	c := *s
+	c.ThreadTS = threadTS
+	return &c
+}
+
+// InChannel はchannelに送るSlackNotifierのコピーを返す
+func (s *SlackNotifier) InChannel(channel string) *SlackNotifier {
+	c := *s
+	c.Channel = channel
+	return &c
+}
+
+func (s *SlackNotifier) Notify(message string) error {
+	return s.NotifyContext(context.Background(), message)
+}
+
+// NotifyContext はBlock KitのJSONをWebhookへPOSTする
+// 429が返った場合はRetry-Afterの秒数だけ待ってから再送する
+// ctxに冪等キー（WithIdempotencyKey）があれば、Idempotency-Keyヘッダーに載せる
+// ctxに宛先（WithRecipient）があれば、Channelの代わりにそのチャンネルに送る
+func (s *SlackNotifier) NotifyContext(ctx context.Context, message string) error {
+	if channel, ok := RecipientFromContext(ctx); ok {
+		s = s.InChannel(channel)
+	}
+	if s.WebhookURL == "" {
+		fmt.Printf("Sending Slack message to channel %s: %s\n", s.Channel, message)
+		return nil
+	}
+
+	body, err := json.Marshal(s.BuildMessage(message))
+	if err != nil {
+		return err
+	}
+
+	maxRetries := s.MaxRetries
+	switch {
+	case maxRetries == 0:
+		maxRetries = 3
+	case maxRetries < 0:
+		maxRetries = 0
+	}
+	maxWait := s.MaxRetryAfter
+	if maxWait == 0 {
+		maxWait = 30 * time.Second
+	}
+
+	key, _ := IdempotencyKeyFromContext(ctx)
+	for attempt := 0; ; attempt++ {
+		err := s.post(ctx, body, key)
+		var slackErr *SlackError
+		if !errors.As(err, &slackErr) || slackErr.StatusCode != http.StatusTooManyRequests {
+			return err
+		}
+		if attempt >= maxRetries || slackErr.RetryAfter > maxWait {
+			return err
+		}
+
+		timer := time.NewTimer(slackErr.RetryAfter)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// BuildMessage はメッセージ本文からWebhookのペイロードを組み立てる
+// textは通知やスクリーンリーダー用のフォールバックとしてblocksと同じ内容を入れる
+func (s *SlackNotifier) BuildMessage(message string) SlackMessage {
+	return SlackMessage{
+		Channel: s.Channel,
+		Text:    message,
+		Blocks: []SlackBlock{
+			{Type: "section", Text: &SlackText{Type: "mrkdwn", Text: message}},
+		},
+		ThreadTS:  s.ThreadTS,
+		Username:  s.Username,
+		IconEmoji: s.IconEmoji,
+	}
+}
+
+// IdempotencyKeyHeader は冪等キーを載せるHTTPヘッダー
+// Slack自体は見ないが、Webhookの手前のプロキシやslacktestは同じキーの2回目を送信済みとして扱う
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+func (s *SlackNotifier) post(ctx context.Context, body []byte, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set(IdempotencyKeyHeader, key)
+	}
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("slack webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 大きなエラーページを丸ごと読まないように上限を付ける
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	return &SlackError{
+		StatusCode: resp.StatusCode,
+		Body:       string(respBody),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+// parseRetryAfter はRetry-Afterヘッダー（秒数またはHTTP日付）を解釈する
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return time.Second
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+		return 0
+	}
+	return time.Second
+}
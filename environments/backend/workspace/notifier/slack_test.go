@@ -0,0 +1,178 @@
+package notifier_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"workout/notifier"
+	"workout/notifier/slacktest"
+)
+
+func TestSlackNotifierPayload(t *testing.T) {
+	srv := slacktest.NewServer()
+	defer srv.Close()
+
+	s := &notifier.SlackNotifier{
+		Channel:    "#training",
+		WebhookURL: srv.URL,
+		Username:   "workout",
+		IconEmoji:  ":muscle:",
+	}
+	if err := s.Notify("*ベンチプレス* 100kg"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	reqs := srv.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("got %d requests, want 1", len(reqs))
+	}
+	if ct := reqs[0].Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	// 構造体を経由せず、生のJSONのキーがSlackの仕様どおりかを確かめる
+	var raw map[string]any
+	if err := json.Unmarshal(reqs[0].Body, &raw); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	want := map[string]any{
+		"channel":    "#training",
+		"text":       "*ベンチプレス* 100kg",
+		"username":   "workout",
+		"icon_emoji": ":muscle:",
+	}
+	for k, v := range want {
+		if raw[k] != v {
+			t.Errorf("%s = %v, want %v", k, raw[k], v)
+		}
+	}
+	if _, ok := raw["thread_ts"]; ok {
+		t.Error("thread_ts is sent without a thread")
+	}
+	blocks, _ := raw["blocks"].([]any)
+	if len(blocks) != 1 {
+		t.Fatalf("blocks = %v, want 1 section", raw["blocks"])
+	}
+	section := blocks[0].(map[string]any)
+	text := section["text"].(map[string]any)
+	if section["type"] != "section" || text["type"] != "mrkdwn" || text["text"] != "*ベンチプレス* 100kg" {
+		t.Errorf("block = %v", section)
+	}
+}
+
+func TestSlackNotifierThread(t *testing.T) {
+	srv := slacktest.NewServer()
+	defer srv.Close()
+
+	parent := &notifier.SlackNotifier{WebhookURL: srv.URL}
+	reply := parent.InThread("1700000000.000100")
+	if err := reply.Notify("返信"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if err := parent.Notify("新しい投稿"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	got := srv.Delivered()
+	if len(got) != 2 {
+		t.Fatalf("delivered %d, want 2", len(got))
+	}
+	if got[0].ThreadTS != "1700000000.000100" {
+		t.Errorf("thread_ts = %q", got[0].ThreadTS)
+	}
+	// InThreadはコピーを返すので、元のNotifierはスレッドに入らない
+	if got[1].ThreadTS != "" {
+		t.Errorf("parent thread_ts = %q, want empty", got[1].ThreadTS)
+	}
+}
+
+func TestSlackNotifierRateLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		limited       int // 429を返す回数
+		retryAfter    int
+		maxRetries    int
+		maxRetryAfter time.Duration
+		wantRequests  int
+		wantErr       bool
+	}{
+		{name: "retry after 429", limited: 2, maxRetries: 0, wantRequests: 3},
+		{name: "give up after max retries", limited: 5, maxRetries: 1, wantRequests: 2, wantErr: true},
+		{name: "retries disabled", limited: 1, maxRetries: -1, wantRequests: 1, wantErr: true},
+		{name: "retry-after too long", limited: 1, retryAfter: 60, maxRetryAfter: time.Second, wantRequests: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := slacktest.NewServer()
+			defer srv.Close()
+			srv.RateLimit(tt.limited, tt.retryAfter)
+
+			s := &notifier.SlackNotifier{WebhookURL: srv.URL, MaxRetries: tt.maxRetries, MaxRetryAfter: tt.maxRetryAfter}
+			err := s.Notify("hello")
+			if got := len(srv.Requests()); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Notify: %v", err)
+				}
+				return
+			}
+			var slackErr *notifier.SlackError
+			if !errors.As(err, &slackErr) || slackErr.StatusCode != http.StatusTooManyRequests {
+				t.Fatalf("err = %v, want 429 SlackError", err)
+			}
+			if slackErr.RetryAfter != time.Duration(tt.retryAfter)*time.Second {
+				t.Errorf("RetryAfter = %s, want %ds", slackErr.RetryAfter, tt.retryAfter)
+			}
+			if !notifier.DefaultRetryable(err) {
+				t.Error("429 should be retryable")
+			}
+		})
+	}
+}
+
+func TestSlackNotifierCancelWhileWaiting(t *testing.T) {
+	srv := slacktest.NewServer()
+	defer srv.Close()
+	srv.RateLimit(1, 30)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	s := &notifier.SlackNotifier{WebhookURL: srv.URL}
+	start := time.Now()
+	err := s.NotifyContext(ctx, "hello")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("waited %s for Retry-After despite cancellation", elapsed)
+	}
+}
+
+// WithRetryで包んだ場合、内側の再送を止めても外側がRetry-Afterを守って送り直す
+func TestSlackNotifierWithRetryHonorsRetryAfter(t *testing.T) {
+	srv := slacktest.NewServer()
+	defer srv.Close()
+	srv.RateLimit(1, 1)
+
+	s := &notifier.SlackNotifier{WebhookURL: srv.URL, MaxRetries: -1}
+	n := notifier.WithRetry(s, notifier.RetryOptions{
+		MaxAttempts: 2,
+		Backoff:     notifier.Backoff{Initial: time.Millisecond},
+	})
+	start := time.Now()
+	if err := n.Notify("hello"); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got := len(srv.Requests()); got != 2 {
+		t.Errorf("requests = %d, want 2 (no multiplied retries)", got)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, before Retry-After: 1", elapsed)
+	}
+}
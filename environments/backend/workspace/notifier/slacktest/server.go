@@ -0,0 +1,118 @@
+// Package slacktest はSlackのIncoming Webhookを模したテスト用サーバーを提供する
+// 受け取ったペイロードの検証と、429（レート制限）・Idempotency-Keyによる重複の再現ができる
+package slacktest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+
+	"workout/notifier"
+)
+
+// Request はサーバーが受け取った1回分のリクエスト
+type Request struct {
+	Header  http.Header
+	Body    []byte
+	Payload notifier.SlackMessage
+	// RateLimited はこのリクエストに429を返したかどうか
+	RateLimited bool
+	// Duplicate は受理済みのIdempotency-Keyだったので、投稿せずに200を返したかどうか
+	Duplicate bool
+}
+
+// Server はhttptest.Serverの上に作ったSlack Webhookのスタンドイン
+// WebhookURLにはServer.URLをそのまま渡せばよい
+type Server struct {
+	*httptest.Server
+
+	mu            sync.Mutex
+	requests      []Request
+	keys          map[string]bool // 受理したIdempotency-Key
+	limitLeft     int
+	retryAfterSec int
+}
+
+// NewServer はサーバーを起動する
+func NewServer() *Server {
+	s := &Server{keys: make(map[string]bool)}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// RateLimit は次のn回のリクエストに、Retry-After: retryAfterSec 付きの429を返すようにする
+func (s *Server) RateLimit(n, retryAfterSec int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limitLeft = n
+	s.retryAfterSec = retryAfterSec
+}
+
+// Requests はこれまでに受け取ったリクエスト（429を返したものも含む）を返す
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Delivered は実際に投稿された（429でも重複でもない）ペイロードだけを返す
+func (s *Server) Delivered() []notifier.SlackMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var msgs []notifier.SlackMessage
+	for _, r := range s.requests {
+		if !r.RateLimited && !r.Duplicate {
+			msgs = append(msgs, r.Payload)
+		}
+	}
+	return msgs
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "invalid_method", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+		return
+	}
+	// 本物のSlackと同じく、壊れたJSONやtext/blocksのないペイロードは400
+	var payload notifier.SlackMessage
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid_payload", http.StatusBadRequest)
+		return
+	}
+	if payload.Text == "" && len(payload.Blocks) == 0 {
+		http.Error(w, "no_text", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	req := Request{Header: r.Header.Clone(), Body: body, Payload: payload}
+	limited := s.limitLeft > 0
+	if limited {
+		s.limitLeft--
+		req.RateLimited = true
+	}
+	key := r.Header.Get(notifier.IdempotencyKeyHeader)
+	if !limited && key != "" {
+		req.Duplicate = s.keys[key]
+		s.keys[key] = true
+	}
+	retryAfter := s.retryAfterSec
+	s.requests = append(s.requests, req)
+	s.mu.Unlock()
+
+	if limited {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		http.Error(w, "rate_limited", http.StatusTooManyRequests)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	io.WriteString(w, "ok")
+}
@@ -0,0 +1,348 @@
+// Package smtptest はテスト用のインプロセスSMTPサーバーを提供する
+// httptestと同じ感覚で、ネットワークの外に出さずに送信内容を検証できる
+package smtptest
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config はサーバーの動作設定
+type Config struct {
+	// TLS がtrueならSTARTTLSを提供する（自己署名証明書を使う）
+	TLS bool
+	// Username が空でなければ、MAILの前にAUTH PLAINを要求する
+	Username string
+	Password string
+	// RejectRecipients のアドレスへのRCPTには550を返す（存在しない宛先の再現）
+	RejectRecipients []string
+}
+
+// Message はサーバーが受け取った1通分のエンベロープと本文
+type Message struct {
+	From     string
+	To       []string
+	Data     []byte
+	Username string // 認証したユーザー（未認証なら空）
+	TLS      bool   // STARTTLS済みの接続で受け取ったか
+}
+
+// Parse は本文をヘッダーとボディに分解する
+func (m Message) Parse() (*mail.Message, error) {
+	return mail.ReadMessage(bytes.NewReader(m.Data))
+}
+
+// Server はテスト用のSMTPサーバー
+type Server struct {
+	Addr string // "127.0.0.1:port"
+
+	cfg       Config
+	listener  net.Listener
+	tlsConfig *tls.Config
+	certPool  *x509.CertPool
+
+	mu       sync.Mutex
+	messages []Message
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+// NewServer はサーバーを起動する。起動に失敗した場合はpanicする（httptest.NewServerと同じ）
+func NewServer(cfg Config) *Server {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(fmt.Sprintf("smtptest: failed to listen: %v", err))
+	}
+	s := &Server{Addr: l.Addr().String(), cfg: cfg, listener: l, conns: make(map[net.Conn]struct{})}
+	if cfg.TLS {
+		cert, pool, err := selfSignedCert()
+		if err != nil {
+			l.Close()
+			panic(fmt.Sprintf("smtptest: failed to create certificate: %v", err))
+		}
+		s.tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		s.certPool = pool
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+	return s
+}
+
+// ClientTLSConfig はサーバーの自己署名証明書を信頼するクライアント用の設定を返す
+func (s *Server) ClientTLSConfig() *tls.Config {
+	return &tls.Config{RootCAs: s.certPool}
+}
+
+// Messages はこれまでに受け取ったメッセージを返す
+func (s *Server) Messages() []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Message(nil), s.messages...)
+}
+
+// Close はサーバーを停止し、残っている接続も切断する
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	for c := range s.conns {
+		c.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer func() {
+				s.mu.Lock()
+				delete(s.conns, conn)
+				s.mu.Unlock()
+				conn.Close()
+			}()
+			conn.SetDeadline(time.Now().Add(30 * time.Second))
+			s.handle(conn)
+		}()
+	}
+}
+
+// session は1接続分の状態
+type session struct {
+	text     *textproto.Conn
+	tls      bool
+	username string
+	from     string
+	to       []string
+	inMail   bool
+}
+
+func (s *Server) handle(conn net.Conn) {
+	sess := &session{text: textproto.NewConn(conn)}
+	sess.reply(220, "smtptest ESMTP ready")
+
+	for {
+		line, err := sess.text.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(verb) {
+		case "HELO":
+			sess.reset()
+			sess.reply(250, "smtptest")
+		case "EHLO":
+			sess.reset()
+			ext := []string{"smtptest", "8BITMIME"}
+			if s.tlsConfig != nil && !sess.tls {
+				ext = append(ext, "STARTTLS")
+			}
+			if s.cfg.Username != "" {
+				ext = append(ext, "AUTH PLAIN")
+			}
+			sess.replyMulti(250, ext)
+		case "STARTTLS":
+			if s.tlsConfig == nil || sess.tls {
+				sess.reply(502, "5.5.1 STARTTLS not available")
+				continue
+			}
+			sess.reply(220, "2.0.0 Ready to start TLS")
+			tlsConn := tls.Server(conn, s.tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			// TLS確立後はEHLOからやり直すのがSMTPの決まり
+			sess = &session{text: textproto.NewConn(tlsConn), tls: true}
+		case "AUTH":
+			s.auth(sess, arg)
+		case "MAIL":
+			if s.cfg.Username != "" && sess.username == "" {
+				sess.reply(530, "5.7.0 Authentication required")
+				continue
+			}
+			addr, ok := parsePath(arg, "FROM:")
+			if !ok {
+				sess.reply(501, "5.5.4 Syntax: MAIL FROM:<address>")
+				continue
+			}
+			sess.reset()
+			sess.from = addr
+			sess.inMail = true
+			sess.reply(250, "2.1.0 OK")
+		case "RCPT":
+			if !sess.inMail {
+				sess.reply(503, "5.5.1 Need MAIL before RCPT")
+				continue
+			}
+			addr, ok := parsePath(arg, "TO:")
+			if !ok {
+				sess.reply(501, "5.5.4 Syntax: RCPT TO:<address>")
+				continue
+			}
+			if slices.Contains(s.cfg.RejectRecipients, addr) {
+				sess.reply(550, "5.1.1 Mailbox unavailable")
+				continue
+			}
+			sess.to = append(sess.to, addr)
+			sess.reply(250, "2.1.5 OK")
+		case "DATA":
+			if len(sess.to) == 0 {
+				sess.reply(503, "5.5.1 Need RCPT before DATA")
+				continue
+			}
+			sess.reply(354, "End data with <CR><LF>.<CR><LF>")
+			data, err := sess.text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, Message{
+				From:     sess.from,
+				To:       sess.to,
+				Data:     data,
+				Username: sess.username,
+				TLS:      sess.tls,
+			})
+			s.mu.Unlock()
+			sess.reset()
+			sess.reply(250, "2.0.0 OK: queued")
+		case "RSET":
+			sess.reset()
+			sess.reply(250, "2.0.0 OK")
+		case "NOOP":
+			sess.reply(250, "2.0.0 OK")
+		case "QUIT":
+			sess.reply(221, "2.0.0 Bye")
+			return
+		default:
+			sess.reply(502, "5.5.2 Command not recognized")
+		}
+	}
+}
+
+func (s *Server) auth(sess *session, arg string) {
+	if s.cfg.Username == "" {
+		sess.reply(502, "5.5.1 AUTH not available")
+		return
+	}
+	mech, resp, _ := strings.Cut(arg, " ")
+	if !strings.EqualFold(mech, "PLAIN") {
+		sess.reply(504, "5.5.4 Unrecognized authentication type")
+		return
+	}
+	if resp == "" {
+		sess.reply(334, "")
+		line, err := sess.text.ReadLine()
+		if err != nil {
+			return
+		}
+		resp = line
+	}
+	decoded, err := base64.StdEncoding.DecodeString(resp)
+	if err != nil {
+		sess.reply(501, "5.5.2 Cannot decode response")
+		return
+	}
+	// PLAIN: authzid \0 authcid \0 passwd
+	parts := strings.Split(string(decoded), "\x00")
+	if len(parts) != 3 || parts[1] != s.cfg.Username || parts[2] != s.cfg.Password {
+		sess.reply(535, "5.7.8 Authentication credentials invalid")
+		return
+	}
+	sess.username = parts[1]
+	sess.reply(235, "2.7.0 Authentication successful")
+}
+
+func (sess *session) reset() {
+	sess.from = ""
+	sess.to = nil
+	sess.inMail = false
+}
+
+func (sess *session) reply(code int, msg string) {
+	sess.text.PrintfLine("%d %s", code, msg)
+}
+
+func (sess *session) replyMulti(code int, lines []string) {
+	for i, l := range lines {
+		sep := "-"
+		if i == len(lines)-1 {
+			sep = " "
+		}
+		sess.text.PrintfLine("%d%s%s", code, sep, l)
+	}
+}
+
+// parsePath は "FROM:<a@example.com> SIZE=100" のような引数からアドレスを取り出す
+func parsePath(arg, prefix string) (string, bool) {
+	if len(arg) < len(prefix) || !strings.EqualFold(arg[:len(prefix)], prefix) {
+		return "", false
+	}
+	rest := strings.TrimSpace(arg[len(prefix):])
+	if !strings.HasPrefix(rest, "<") {
+		return "", false
+	}
+	end := strings.Index(rest, ">")
+	if end < 0 {
+		return "", false
+	}
+	return rest[1:end], true
+}
+
+func selfSignedCert() (tls.Certificate, *x509.CertPool, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"smtptest"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		DNSNames:              []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool, nil
+}
@@ -0,0 +1,33 @@
+// Package postgres はdomainのリポジトリをdatabase/sqlとPostgreSQLで実装する
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	// database/sqlに"pgx"ドライバーを登録する
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// Open はDATABASE_URL形式の接続文字列でDBを開き、疎通を確認する
+func Open(ctx context.Context, databaseURL string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+
+	// 接続プール: APIサーバー1台で使う前提の控えめな値
+	db.SetMaxOpenConns(10)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxLifetime(30 * time.Minute)
+
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ping database: %w", err)
+	}
+	return db, nil
+}
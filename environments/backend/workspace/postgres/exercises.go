@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"workout/domain"
+)
+
+// ExerciseRepository はdomain.ExerciseRepositoryのPostgreSQL実装
+type ExerciseRepository struct {
+	db *sql.DB
+}
+
+func NewExerciseRepository(db *sql.DB) *ExerciseRepository {
+	return &ExerciseRepository{db: db}
+}
+
+func (r *ExerciseRepository) FindByID(id int) (*domain.Exercise, error) {
+	var e domain.Exercise
+	err := r.db.QueryRow(`SELECT id, name, muscle_group FROM exercises WHERE id = $1`, id).
+		Scan(&e.ID, &e.Name, &e.MuscleGroup)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("exercise id=%d: %w", id, domain.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find exercise id=%d: %w", id, err)
+	}
+	return &e, nil
+}
+
+func (r *ExerciseRepository) List() ([]*domain.Exercise, error) {
+	rows, err := r.db.Query(`SELECT id, name, muscle_group FROM exercises ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list exercises: %w", err)
+	}
+	defer rows.Close()
+
+	list := []*domain.Exercise{}
+	for rows.Next() {
+		var e domain.Exercise
+		if err := rows.Scan(&e.ID, &e.Name, &e.MuscleGroup); err != nil {
+			return nil, fmt.Errorf("list exercises: %w", err)
+		}
+		list = append(list, &e)
+	}
+	return list, rows.Err()
+}
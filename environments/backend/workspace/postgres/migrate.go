@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// migrateLockID はpg_advisory_lockのキー。複数のプロセスが同時にマイグレーションしないようにする
+const migrateLockID = 0x776f726b6f7574 // "workout"
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(.+)\.up\.sql$`)
+
+type migration struct {
+	version uint64
+	name    string
+	up      string // *.up.sqlのファイル名
+	down    string // *.down.sqlのファイル名（なければ空）
+}
+
+// Migrate はfsysの*.up.sqlのうち未適用のものを番号順に適用する
+// 適用状況はgolang-migrateと同じschema_migrationsテーブルに記録するので、
+// migrate CLIとどちらを使っても整合性が保たれる
+func Migrate(ctx context.Context, db *sql.DB, fsys fs.FS) error {
+	return withMigrationLock(ctx, db, fsys, func(conn *sql.Conn, migrations []migration, current uint64) error {
+		for _, m := range migrations {
+			if m.version <= current {
+				continue
+			}
+			if err := applyMigration(ctx, conn, fsys, m.up, m.version); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MigrateDown はversionより新しいマイグレーションを新しい順に*.down.sqlで戻す（migrate down相当）
+// versionが0なら全て戻す
+func MigrateDown(ctx context.Context, db *sql.DB, fsys fs.FS, version uint64) error {
+	return withMigrationLock(ctx, db, fsys, func(conn *sql.Conn, migrations []migration, current uint64) error {
+		for i := len(migrations) - 1; i >= 0; i-- {
+			m := migrations[i]
+			if m.version > current || m.version <= version {
+				continue
+			}
+			if m.down == "" {
+				return fmt.Errorf("migration %d_%s has no down file", m.version, m.name)
+			}
+			// 戻した後のバージョンは1つ前のマイグレーション（なければ0）
+			var prev uint64
+			if i > 0 {
+				prev = migrations[i-1].version
+			}
+			if err := applyMigration(ctx, conn, fsys, m.down, prev); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// withMigrationLock はロックを取り、現在のバージョンを読んでからfnを呼ぶ
+func withMigrationLock(ctx context.Context, db *sql.DB, fsys fs.FS, fn func(conn *sql.Conn, migrations []migration, current uint64) error) error {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrateLockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrateLockID)
+
+	if _, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`); err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+
+	var current uint64
+	var dirty bool
+	err = conn.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&current, &dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if dirty {
+		// 途中で失敗した状態。自動で直すと壊れる可能性があるので人に任せる
+		return fmt.Errorf("database is dirty at version %d; fix it manually and run `migrate force`", current)
+	}
+	return fn(conn, migrations, current)
+}
+
+// applyMigration はSQLの実行とバージョンの記録を1つのトランザクションで行う
+// PostgreSQLはDDLもロールバックできるので、失敗しても中途半端な状態にならない
+// versionが0なら（全て戻した場合）schema_migrationsを空にする（golang-migrateと同じ）
+func applyMigration(ctx context.Context, conn *sql.Conn, fsys fs.FS, file string, version uint64) error {
+	body, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, string(body)); err != nil {
+		return fmt.Errorf("migration %s: %w", file, err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return err
+	}
+	if version > 0 {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`, version); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("read migrations: %w", err)
+	}
+	var migrations []migration
+	for _, e := range entries {
+		match := migrationFile.FindStringSubmatch(e.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version %q: %w", e.Name(), err)
+		}
+		m := migration{version: version, name: match[2], up: e.Name()}
+		down := strings.TrimSuffix(e.Name(), ".up.sql") + ".down.sql"
+		if _, err := fs.Stat(fsys, down); err == nil {
+			m.down = down
+		}
+		migrations = append(migrations, m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
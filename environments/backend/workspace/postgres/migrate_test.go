@@ -0,0 +1,196 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var testMigrations = fstest.MapFS{
+	"000001_create_a.up.sql":   {Data: []byte("CREATE TABLE a (id int)")},
+	"000001_create_a.down.sql": {Data: []byte("DROP TABLE a")},
+	"000002_create_b.up.sql":   {Data: []byte("CREATE TABLE b (id int)")},
+	"000002_create_b.down.sql": {Data: []byte("DROP TABLE b")},
+	"README.md":                {Data: []byte("not a migration")},
+}
+
+// expectLocked はロックの取得とschema_migrationsの読み込みを期待する（currentが0なら行なし）
+func expectLocked(mock sqlmock.Sqlmock, current uint64, dirty bool) {
+	mock.ExpectExec(`SELECT pg_advisory_lock`).WithArgs(migrateLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"version", "dirty"})
+	if current > 0 {
+		rows.AddRow(current, dirty)
+	}
+	mock.ExpectQuery(`SELECT version, dirty FROM schema_migrations`).WillReturnRows(rows)
+}
+
+// expectApplied はSQLの実行とバージョンの記録が1つのトランザクションで行われることを期待する
+func expectApplied(mock sqlmock.Sqlmock, sql string, version uint64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(sql).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 1))
+	if version > 0 {
+		mock.ExpectExec(`INSERT INTO schema_migrations`).WithArgs(version).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+}
+
+func expectUnlocked(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).WithArgs(migrateLockID).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"000010_c.up.sql":   {},
+		"000002_b.up.sql":   {},
+		"000002_b.down.sql": {},
+		"000001_a.down.sql": {}, // upのないdownは無視する
+	}
+	got, err := loadMigrations(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []migration{
+		{version: 2, name: "b", up: "000002_b.up.sql", down: "000002_b.down.sql"},
+		{version: 10, name: "c", up: "000010_c.up.sql"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("migration %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name    string
+		current uint64
+		expect  func(mock sqlmock.Sqlmock)
+	}{
+		{
+			name:    "fresh database",
+			current: 0,
+			expect: func(mock sqlmock.Sqlmock) {
+				expectApplied(mock, `CREATE TABLE a`, 1)
+				expectApplied(mock, `CREATE TABLE b`, 2)
+			},
+		},
+		{
+			name:    "only pending",
+			current: 1,
+			expect: func(mock sqlmock.Sqlmock) {
+				expectApplied(mock, `CREATE TABLE b`, 2)
+			},
+		},
+		{
+			name:    "up to date",
+			current: 2,
+			expect:  func(sqlmock.Sqlmock) {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMock(t)
+			expectLocked(mock, tt.current, false)
+			tt.expect(mock)
+			expectUnlocked(mock)
+
+			if err := Migrate(context.Background(), db, testMigrations); err != nil {
+				t.Fatalf("Migrate: %v", err)
+			}
+		})
+	}
+}
+
+func TestMigrateDown(t *testing.T) {
+	tests := []struct {
+		name    string
+		current uint64
+		target  uint64
+		expect  func(mock sqlmock.Sqlmock)
+	}{
+		{
+			name:    "all the way down",
+			current: 2,
+			target:  0,
+			expect: func(mock sqlmock.Sqlmock) {
+				expectApplied(mock, `DROP TABLE b`, 1)
+				// 全て戻したらschema_migrationsは空になる
+				expectApplied(mock, `DROP TABLE a`, 0)
+			},
+		},
+		{
+			name:    "one step",
+			current: 2,
+			target:  1,
+			expect: func(mock sqlmock.Sqlmock) {
+				expectApplied(mock, `DROP TABLE b`, 1)
+			},
+		},
+		{
+			name:    "already below target",
+			current: 1,
+			target:  1,
+			expect:  func(sqlmock.Sqlmock) {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMock(t)
+			expectLocked(mock, tt.current, false)
+			tt.expect(mock)
+			expectUnlocked(mock)
+
+			if err := MigrateDown(context.Background(), db, testMigrations, tt.target); err != nil {
+				t.Fatalf("MigrateDown: %v", err)
+			}
+		})
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	t.Run("dirty database", func(t *testing.T) {
+		db, mock := newMock(t)
+		expectLocked(mock, 1, true)
+		expectUnlocked(mock)
+
+		err := Migrate(context.Background(), db, testMigrations)
+		if err == nil || !strings.Contains(err.Error(), "dirty at version 1") {
+			t.Fatalf("err = %v, want dirty error", err)
+		}
+	})
+
+	t.Run("failed migration rolls back", func(t *testing.T) {
+		db, mock := newMock(t)
+		expectLocked(mock, 1, false)
+		mock.ExpectBegin()
+		mock.ExpectExec(`CREATE TABLE b`).WillReturnError(errors.New("syntax error"))
+		mock.ExpectRollback()
+		expectUnlocked(mock)
+
+		err := Migrate(context.Background(), db, testMigrations)
+		if err == nil || !strings.Contains(err.Error(), "000002_create_b.up.sql") {
+			t.Fatalf("err = %v, want error naming the file", err)
+		}
+	})
+
+	t.Run("missing down file", func(t *testing.T) {
+		fsys := fstest.MapFS{"000001_a.up.sql": {Data: []byte("CREATE TABLE a (id int)")}}
+		db, mock := newMock(t)
+		expectLocked(mock, 1, false)
+		expectUnlocked(mock)
+
+		err := MigrateDown(context.Background(), db, fsys, 0)
+		if err == nil || !strings.Contains(err.Error(), "no down file") {
+			t.Fatalf("err = %v, want missing down file", err)
+		}
+	})
+}
@@ -0,0 +1,299 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"workout/domain"
+	"workout/migrations"
+	"workout/notifier"
+)
+
+// newMock はインプロセスのSQLスタンドイン（sqlmock）を返す
+// 期待したSQLが順番どおりに全て実行されたかは、テストの終わりに確かめる
+func newMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(pgxArgs{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+		db.Close()
+	})
+	return db, mock
+}
+
+// pgxArgs はpgxのドライバーと同じく、[]stringなどdriver.Valueでない引数もそのまま渡す
+type pgxArgs struct{}
+
+func (pgxArgs) ConvertValue(v any) (driver.Value, error) {
+	if driver.IsValue(v) {
+		return driver.DefaultParameterConverter.ConvertValue(v)
+	}
+	if _, ok := v.(driver.Valuer); ok {
+		return driver.DefaultParameterConverter.ConvertValue(v)
+	}
+	return v, nil
+}
+
+// openTestDatabase はDATABASE_URLのPostgreSQLに、このテスト専用のスキーマで接続する
+// DATABASE_URLがなければスキップする（その場合はsqlmockのテストだけが動く）
+//
+//	docker compose --profile db up -d
+//	DATABASE_URL=postgres://... go test ./postgres
+func openTestDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+	databaseURL := os.Getenv("DATABASE_URL")
+	if databaseURL == "" {
+		t.Skip("DATABASE_URL is not set; run with the compose db profile for integration tests")
+	}
+	ctx := context.Background()
+
+	admin, err := Open(ctx, databaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer admin.Close()
+	// 開発用のデータを壊さないよう、ランダムな名前のスキーマを作ってsearch_pathで使う
+	b := make([]byte, 4)
+	rand.Read(b)
+	schema := "test_" + hex.EncodeToString(b)
+	if _, err := admin.ExecContext(ctx, `CREATE SCHEMA `+schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		admin, err := Open(context.Background(), databaseURL)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer admin.Close()
+		if _, err := admin.ExecContext(context.Background(), `DROP SCHEMA `+schema+` CASCADE`); err != nil {
+			t.Error(err)
+		}
+	})
+
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+	db, err := Open(ctx, u.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func schemaVersion(t *testing.T, db *sql.DB) uint64 {
+	t.Helper()
+	var v uint64
+	err := db.QueryRow(`SELECT version FROM schema_migrations`).Scan(&v)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	t.Helper()
+	var exists bool
+	if err := db.QueryRow(`SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists); err != nil {
+		t.Fatal(err)
+	}
+	return exists
+}
+
+func TestIntegrationMigrateUpDown(t *testing.T) {
+	db := openTestDatabase(t)
+	ctx := context.Background()
+
+	if err := Migrate(ctx, db, migrations.FS); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	if v := schemaVersion(t, db); v != 5 {
+		t.Errorf("version = %d, want 5", v)
+	}
+	// 2回目は何もしない
+	if err := Migrate(ctx, db, migrations.FS); err != nil {
+		t.Fatalf("Migrate again: %v", err)
+	}
+
+	if err := MigrateDown(ctx, db, migrations.FS, 2); err != nil {
+		t.Fatalf("MigrateDown 2: %v", err)
+	}
+	if v := schemaVersion(t, db); v != 2 {
+		t.Errorf("version = %d, want 2", v)
+	}
+	if tableExists(t, db, "routines") || !tableExists(t, db, "sessions") {
+		t.Error("down to 2 should drop routines and keep sessions")
+	}
+
+	if err := MigrateDown(ctx, db, migrations.FS, 0); err != nil {
+		t.Fatalf("MigrateDown 0: %v", err)
+	}
+	if v := schemaVersion(t, db); v != 0 {
+		t.Errorf("version = %d, want 0", v)
+	}
+	for _, table := range []string{"exercises", "sessions", "session_sets", "routines", "notification_preferences"} {
+		if tableExists(t, db, table) {
+			t.Errorf("table %s still exists", table)
+		}
+	}
+
+	if err := Migrate(ctx, db, migrations.FS); err != nil {
+		t.Fatalf("Migrate after down: %v", err)
+	}
+}
+
+func migratedDatabase(t *testing.T) *sql.DB {
+	t.Helper()
+	db := openTestDatabase(t)
+	if err := Migrate(context.Background(), db, migrations.FS); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestIntegrationSessionRepository(t *testing.T) {
+	repo := NewSessionRepository(migratedDatabase(t))
+	// TIMESTAMPTZはマイクロ秒までなので、比較できるよう秒で揃える
+	at := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+
+	first := &domain.Session{UserID: 1, PerformedAt: at, Note: "胸", CreatedAt: at, UpdatedAt: at,
+		Sets: []domain.Set{{ExerciseID: 1, Reps: 5, WeightKg: 100}, {ExerciseID: 1, Reps: 5, WeightKg: 102.5}}}
+	second := &domain.Session{UserID: 1, PerformedAt: at.Add(48 * time.Hour), CreatedAt: at, UpdatedAt: at,
+		Sets: []domain.Set{{ExerciseID: 2, Reps: 8, WeightKg: 80}}}
+	other := &domain.Session{UserID: 2, PerformedAt: at, CreatedAt: at, UpdatedAt: at,
+		Sets: []domain.Set{{ExerciseID: 3, Reps: 3, WeightKg: 140}}}
+	for _, s := range []*domain.Session{first, second, other} {
+		if err := repo.Save(s); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	got, err := repo.FindByID(first.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	assertSameSession(t, got, first)
+
+	list, err := repo.ListByUser(1)
+	if err != nil {
+		t.Fatalf("ListByUser: %v", err)
+	}
+	if len(list) != 2 || list[0].ID != second.ID || list[1].ID != first.ID {
+		t.Fatalf("ListByUser = %+v, want [second, first]", list)
+	}
+
+	first.Note = "胸（更新）"
+	first.Sets = []domain.Set{{ExerciseID: 4, Reps: 10, WeightKg: 40}}
+	if err := repo.Save(first); err != nil {
+		t.Fatalf("Save update: %v", err)
+	}
+	got, _ = repo.FindByID(first.ID)
+	assertSameSession(t, got, first)
+
+	// 存在しない種目は外部キー制約で失敗し、採番したIDは戻る
+	bad := &domain.Session{UserID: 1, PerformedAt: at, CreatedAt: at, UpdatedAt: at,
+		Sets: []domain.Set{{ExerciseID: 999, Reps: 1, WeightKg: 1}}}
+	if err := repo.Save(bad); err == nil || bad.ID != 0 {
+		t.Errorf("Save with unknown exercise: err=%v id=%d, want error and id 0", err, bad.ID)
+	}
+
+	if err := repo.Delete(first.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.FindByID(first.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("FindByID after delete: %v, want ErrNotFound", err)
+	}
+	if err := repo.Delete(first.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Delete twice: %v, want ErrNotFound", err)
+	}
+	if err := repo.Save(first); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Save deleted session: %v, want ErrNotFound", err)
+	}
+}
+
+func assertSameSession(t *testing.T, got, want *domain.Session) {
+	t.Helper()
+	if got.ID != want.ID || got.UserID != want.UserID || got.Note != want.Note ||
+		!got.PerformedAt.Equal(want.PerformedAt) || !reflect.DeepEqual(got.Sets, want.Sets) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestIntegrationRoutineRepository(t *testing.T) {
+	repo := NewRoutineRepository(migratedDatabase(t))
+
+	r := &domain.Routine{UserID: 1, Name: "胸の日", ExerciseIDs: []int{1, 4}}
+	if err := repo.Save(r); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	r.ExerciseIDs = []int{4, 1, 5}
+	if err := repo.Save(r); err != nil {
+		t.Fatalf("Save update: %v", err)
+	}
+	got, err := repo.FindByID(r.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if !reflect.DeepEqual(got, r) {
+		t.Errorf("got %+v, want %+v", got, r)
+	}
+
+	list, err := repo.ListByUser(1)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("ListByUser = %+v, %v", list, err)
+	}
+	if err := repo.Delete(r.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := repo.FindByID(r.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("FindByID after delete: %v, want ErrNotFound", err)
+	}
+}
+
+func TestIntegrationPreferenceStore(t *testing.T) {
+	store := NewPreferenceStore(migratedDatabase(t))
+
+	got, err := store.Preferences("1")
+	if err != nil || !reflect.DeepEqual(got, notifier.Preferences{}) {
+		t.Fatalf("Preferences before save = %+v, %v; want zero", got, err)
+	}
+	want := notifier.Preferences{
+		Addresses:   map[string]string{"email": "a@example.com", "slack": "#alice"},
+		OptOut:      []string{"slack"},
+		MutedTopics: []string{"workout.streak_*"},
+		MinSeverity: notifier.SeverityWarning,
+	}
+	for range 2 {
+		if err := store.SavePreferences("1", want); err != nil {
+			t.Fatalf("SavePreferences: %v", err)
+		}
+	}
+	got, err = store.Preferences("1")
+	if err != nil {
+		t.Fatalf("Preferences: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"workout/notifier"
+)
+
+// PreferenceStore はnotifier.PreferenceStoreのPostgreSQL実装
+// 受信者はユーザーIDの10進表記（"1"）で、notification_preferences.user_idに対応する
+type PreferenceStore struct {
+	db *sql.DB
+	// types はTEXT[]をdatabase/sql経由で[]stringに読むために使う
+	types *pgtype.Map
+}
+
+func NewPreferenceStore(db *sql.DB) *PreferenceStore {
+	return &PreferenceStore{db: db, types: pgtype.NewMap()}
+}
+
+// Preferences はrecipientの設定を返す。保存されていなければゼロ値（全て受け取る、宛先なし）
+func (s *PreferenceStore) Preferences(recipient string) (notifier.Preferences, error) {
+	userID, err := parseRecipient(recipient)
+	if err != nil {
+		return notifier.Preferences{}, err
+	}
+	var (
+		p         notifier.Preferences
+		addresses []byte
+		severity  int
+	)
+	err = s.db.QueryRow(`
+SELECT addresses, opt_out, muted_topics, min_severity
+FROM notification_preferences WHERE user_id = $1`, userID).
+		Scan(&addresses, s.types.SQLScanner(&p.OptOut), s.types.SQLScanner(&p.MutedTopics), &severity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notifier.Preferences{}, nil
+	}
+	if err != nil {
+		return notifier.Preferences{}, fmt.Errorf("find notification preferences user_id=%d: %w", userID, err)
+	}
+	if err := json.Unmarshal(addresses, &p.Addresses); err != nil {
+		return notifier.Preferences{}, fmt.Errorf("decode addresses user_id=%d: %w", userID, err)
+	}
+	p.MinSeverity = notifier.Severity(severity)
+	return p, nil
+}
+
+func (s *PreferenceStore) SavePreferences(recipient string, p notifier.Preferences) error {
+	userID, err := parseRecipient(recipient)
+	if err != nil {
+		return err
+	}
+	addresses, err := json.Marshal(p.Addresses)
+	if err != nil {
+		return err
+	}
+	if p.Addresses == nil {
+		addresses = []byte("{}")
+	}
+	optOut, muted := p.OptOut, p.MutedTopics
+	if optOut == nil {
+		optOut = []string{}
+	}
+	if muted == nil {
+		muted = []string{}
+	}
+	_, err = s.db.Exec(`
+INSERT INTO notification_preferences (user_id, addresses, opt_out, muted_topics, min_severity)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id) DO UPDATE
+SET addresses = EXCLUDED.addresses, opt_out = EXCLUDED.opt_out,
+    muted_topics = EXCLUDED.muted_topics, min_severity = EXCLUDED.min_severity`,
+		userID, addresses, optOut, muted, int(p.MinSeverity))
+	if err != nil {
+		return fmt.Errorf("save notification preferences user_id=%d: %w", userID, err)
+	}
+	return nil
+}
+
+func parseRecipient(recipient string) (int, error) {
+	userID, err := strconv.Atoi(recipient)
+	if err != nil {
+		return 0, fmt.Errorf("recipient %q is not a user id: %w", recipient, err)
+	}
+	return userID, nil
+}
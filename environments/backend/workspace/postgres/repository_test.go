@@ -0,0 +1,264 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+
+	"workout/domain"
+	"workout/notifier"
+)
+
+var sessionColumns = []string{"id", "user_id", "performed_at", "note", "created_at", "updated_at", "exercise_id", "reps", "weight_kg"}
+
+func TestSessionRepositorySave(t *testing.T) {
+	at := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+	newSession := func(id int) *domain.Session {
+		return &domain.Session{
+			ID: id, UserID: 1, PerformedAt: at, CreatedAt: at, UpdatedAt: at,
+			Sets: []domain.Set{{ExerciseID: 1, Reps: 5, WeightKg: 100}, {ExerciseID: 2, Reps: 8, WeightKg: 80}},
+		}
+	}
+
+	t.Run("insert", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO sessions`).WithArgs(1, at, "", at, at).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectExec(`INSERT INTO session_sets`).WithArgs(7, 0, 1, 5, 100.0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO session_sets`).WithArgs(7, 1, 2, 8, 80.0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		s := newSession(0)
+		if err := NewSessionRepository(db).Save(s); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+		if s.ID != 7 {
+			t.Errorf("ID = %d, want 7", s.ID)
+		}
+	})
+
+	t.Run("insert rolled back resets ID", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO sessions`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectExec(`INSERT INTO session_sets`).WillReturnError(errors.New("violates foreign key constraint"))
+		mock.ExpectRollback()
+
+		s := newSession(0)
+		if err := NewSessionRepository(db).Save(s); err == nil {
+			t.Fatal("Save succeeded, want error")
+		}
+		if s.ID != 0 {
+			t.Errorf("ID = %d after rollback, want 0", s.ID)
+		}
+	})
+
+	t.Run("update replaces sets", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE sessions SET`).WithArgs(7, 1, at, "", at).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM session_sets WHERE session_id`).WithArgs(7).WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(`INSERT INTO session_sets`).WithArgs(7, 0, 1, 5, 100.0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO session_sets`).WithArgs(7, 1, 2, 8, 80.0).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		if err := NewSessionRepository(db).Save(newSession(7)); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	})
+
+	t.Run("update missing session", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE sessions SET`).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := NewSessionRepository(db).Save(newSession(99))
+		if !errors.Is(err, domain.ErrNotFound) {
+			t.Fatalf("err = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestSessionRepositoryFind(t *testing.T) {
+	at := time.Date(2024, 5, 1, 7, 0, 0, 0, time.UTC)
+	later := at.Add(24 * time.Hour)
+
+	t.Run("find groups joined rows", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectQuery(`FROM sessions s\s+LEFT JOIN session_sets ss .* WHERE s.id = \$1`).WithArgs(7).
+			WillReturnRows(sqlmock.NewRows(sessionColumns).
+				AddRow(7, 1, at, "脚の日", at, at, 2, 5, 120.5).
+				AddRow(7, 1, at, "脚の日", at, at, 2, 5, 110.0))
+
+		got, err := NewSessionRepository(db).FindByID(7)
+		if err != nil {
+			t.Fatalf("FindByID: %v", err)
+		}
+		want := &domain.Session{
+			ID: 7, UserID: 1, PerformedAt: at, Note: "脚の日", CreatedAt: at, UpdatedAt: at,
+			Sets: []domain.Set{{ExerciseID: 2, Reps: 5, WeightKg: 120.5}, {ExerciseID: 2, Reps: 5, WeightKg: 110}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("find missing", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectQuery(`WHERE s.id = \$1`).WithArgs(99).WillReturnRows(sqlmock.NewRows(sessionColumns))
+
+		_, err := NewSessionRepository(db).FindByID(99)
+		if !errors.Is(err, domain.ErrNotFound) {
+			t.Fatalf("err = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("list keeps sessions without sets", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectQuery(`WHERE s.user_id = \$1 ORDER BY s.performed_at DESC`).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(sessionColumns).
+				AddRow(8, 1, later, "", later, later, nil, nil, nil).
+				AddRow(7, 1, at, "", at, at, 1, 5, 100.0))
+
+		got, err := NewSessionRepository(db).ListByUser(1)
+		if err != nil {
+			t.Fatalf("ListByUser: %v", err)
+		}
+		if len(got) != 2 || got[0].ID != 8 || got[1].ID != 7 {
+			t.Fatalf("got %+v, want sessions 8 and 7", got)
+		}
+		if got[0].Sets == nil || len(got[0].Sets) != 0 {
+			t.Errorf("session without sets = %#v, want empty non-nil slice", got[0].Sets)
+		}
+		if len(got[1].Sets) != 1 {
+			t.Errorf("session 7 sets = %+v", got[1].Sets)
+		}
+	})
+
+	t.Run("list empty", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectQuery(`WHERE s.user_id = \$1`).WithArgs(2).WillReturnRows(sqlmock.NewRows(sessionColumns))
+
+		got, err := NewSessionRepository(db).ListByUser(2)
+		// JSONで[]になるよう、nilではなく空のスライスを返す
+		if err != nil || got == nil || len(got) != 0 {
+			t.Fatalf("got %#v, %v; want empty slice", got, err)
+		}
+	})
+}
+
+func TestRepositoryDelete(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		delete   func(db *sql.DB, id int) error
+		affected int64
+		wantErr  error
+	}{
+		{"session", `DELETE FROM sessions WHERE id`, func(db *sql.DB, id int) error { return NewSessionRepository(db).Delete(id) }, 1, nil},
+		{"missing session", `DELETE FROM sessions WHERE id`, func(db *sql.DB, id int) error { return NewSessionRepository(db).Delete(id) }, 0, domain.ErrNotFound},
+		{"routine", `DELETE FROM routines WHERE id`, func(db *sql.DB, id int) error { return NewRoutineRepository(db).Delete(id) }, 1, nil},
+		{"missing routine", `DELETE FROM routines WHERE id`, func(db *sql.DB, id int) error { return NewRoutineRepository(db).Delete(id) }, 0, domain.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMock(t)
+			mock.ExpectExec(tt.query).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err := tt.delete(db, 3)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRoutineRepositorySaveAndFind(t *testing.T) {
+	db, mock := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO routines`).WithArgs(1, "胸の日").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+	mock.ExpectExec(`INSERT INTO routine_exercises`).WithArgs(4, 0, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO routine_exercises`).WithArgs(4, 1, 4).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`WHERE r.id = \$1 ORDER BY re.position`).WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "exercise_id"}).
+			AddRow(4, 1, "胸の日", 1).
+			AddRow(4, 1, "胸の日", 4))
+
+	repo := NewRoutineRepository(db)
+	r := &domain.Routine{UserID: 1, Name: "胸の日", ExerciseIDs: []int{1, 4}}
+	if err := repo.Save(r); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := repo.FindByID(r.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if !reflect.DeepEqual(got, r) {
+		t.Errorf("got %+v, want %+v", got, r)
+	}
+}
+
+func TestPreferenceStore(t *testing.T) {
+	columns := []string{"addresses", "opt_out", "muted_topics", "min_severity"}
+
+	t.Run("find", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectQuery(`FROM notification_preferences WHERE user_id = \$1`).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(columns).
+				AddRow(`{"email":"a@example.com"}`, "{slack}", `{workout.streak_broken}`, 1))
+
+		got, err := NewPreferenceStore(db).Preferences("1")
+		if err != nil {
+			t.Fatalf("Preferences: %v", err)
+		}
+		want := notifier.Preferences{
+			Addresses:   map[string]string{"email": "a@example.com"},
+			OptOut:      []string{"slack"},
+			MutedTopics: []string{"workout.streak_broken"},
+			MinSeverity: notifier.SeverityWarning,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("missing is zero value", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectQuery(`FROM notification_preferences`).WithArgs(2).WillReturnRows(sqlmock.NewRows(columns))
+
+		got, err := NewPreferenceStore(db).Preferences("2")
+		if err != nil || !reflect.DeepEqual(got, notifier.Preferences{}) {
+			t.Fatalf("got %+v, %v; want zero preferences", got, err)
+		}
+	})
+
+	t.Run("save upserts", func(t *testing.T) {
+		db, mock := newMock(t)
+		mock.ExpectExec(`INSERT INTO notification_preferences .* ON CONFLICT \(user_id\) DO UPDATE`).
+			WithArgs(1, []byte(`{"slack":"#alice"}`), []string{}, []string{"workout.*"}, 0).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := NewPreferenceStore(db).SavePreferences("1", notifier.Preferences{
+			Addresses:   map[string]string{"slack": "#alice"},
+			MutedTopics: []string{"workout.*"},
+		})
+		if err != nil {
+			t.Fatalf("SavePreferences: %v", err)
+		}
+	})
+
+	t.Run("recipient must be a user id", func(t *testing.T) {
+		db, _ := newMock(t)
+		if _, err := NewPreferenceStore(db).Preferences("alice"); err == nil {
+			t.Error("Preferences(alice) succeeded")
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"workout/domain"
+)
+
+// RoutineRepository はdomain.RoutineRepositoryのPostgreSQL実装
+type RoutineRepository struct {
+	db *sql.DB
+}
+
+func NewRoutineRepository(db *sql.DB) *RoutineRepository {
+	return &RoutineRepository{db: db}
+}
+
+const selectRoutines = `
+SELECT r.id, r.user_id, r.name, re.exercise_id
+FROM routines r
+LEFT JOIN routine_exercises re ON re.routine_id = r.id
+`
+
+func (r *RoutineRepository) FindByID(id int) (*domain.Routine, error) {
+	rows, err := r.db.Query(selectRoutines+`WHERE r.id = $1 ORDER BY re.position`, id)
+	if err != nil {
+		return nil, fmt.Errorf("find routine id=%d: %w", id, err)
+	}
+	routines, err := scanRoutines(rows)
+	if err != nil {
+		return nil, fmt.Errorf("find routine id=%d: %w", id, err)
+	}
+	if len(routines) == 0 {
+		return nil, fmt.Errorf("routine id=%d: %w", id, domain.ErrNotFound)
+	}
+	return routines[0], nil
+}
+
+func (r *RoutineRepository) ListByUser(userID int) ([]*domain.Routine, error) {
+	rows, err := r.db.Query(selectRoutines+`WHERE r.user_id = $1 ORDER BY r.id, re.position`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list routines user_id=%d: %w", userID, err)
+	}
+	routines, err := scanRoutines(rows)
+	if err != nil {
+		return nil, fmt.Errorf("list routines user_id=%d: %w", userID, err)
+	}
+	return routines, nil
+}
+
+// Save はSessionRepository.Saveと同じく、種目を全て入れ直す
+func (r *RoutineRepository) Save(routine *domain.Routine) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("save routine: %w", err)
+	}
+	defer tx.Rollback()
+
+	if routine.ID == 0 {
+		err = tx.QueryRow(`INSERT INTO routines (user_id, name) VALUES ($1, $2) RETURNING id`,
+			routine.UserID, routine.Name).Scan(&routine.ID)
+		if err != nil {
+			return fmt.Errorf("insert routine: %w", err)
+		}
+		defer func() {
+			if err != nil {
+				routine.ID = 0
+			}
+		}()
+	} else {
+		res, err := tx.Exec(`UPDATE routines SET user_id = $2, name = $3 WHERE id = $1`,
+			routine.ID, routine.UserID, routine.Name)
+		if err != nil {
+			return fmt.Errorf("update routine id=%d: %w", routine.ID, err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("update routine id=%d: %w", routine.ID, err)
+		} else if n == 0 {
+			return fmt.Errorf("routine id=%d: %w", routine.ID, domain.ErrNotFound)
+		}
+		if _, err := tx.Exec(`DELETE FROM routine_exercises WHERE routine_id = $1`, routine.ID); err != nil {
+			return fmt.Errorf("replace exercises of routine id=%d: %w", routine.ID, err)
+		}
+	}
+
+	for i, exerciseID := range routine.ExerciseIDs {
+		_, err = tx.Exec(`INSERT INTO routine_exercises (routine_id, position, exercise_id) VALUES ($1, $2, $3)`,
+			routine.ID, i, exerciseID)
+		if err != nil {
+			return fmt.Errorf("insert exercise %d of routine id=%d: %w", i, routine.ID, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("save routine: %w", err)
+	}
+	return nil
+}
+
+func (r *RoutineRepository) Delete(id int) error {
+	res, err := r.db.Exec(`DELETE FROM routines WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete routine id=%d: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete routine id=%d: %w", id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("routine id=%d: %w", id, domain.ErrNotFound)
+	}
+	return nil
+}
+
+func scanRoutines(rows *sql.Rows) ([]*domain.Routine, error) {
+	defer rows.Close()
+
+	routines := []*domain.Routine{}
+	var current *domain.Routine
+	for rows.Next() {
+		var rt domain.Routine
+		var exerciseID sql.NullInt64
+		if err := rows.Scan(&rt.ID, &rt.UserID, &rt.Name, &exerciseID); err != nil {
+			return nil, err
+		}
+		if current == nil || current.ID != rt.ID {
+			rt.ExerciseIDs = []int{}
+			current = &rt
+			routines = append(routines, current)
+		}
+		if exerciseID.Valid {
+			current.ExerciseIDs = append(current.ExerciseIDs, int(exerciseID.Int64))
+		}
+	}
+	return routines, rows.Err()
+}
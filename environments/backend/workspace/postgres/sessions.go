@@ -0,0 +1,160 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	"workout/domain"
+)
+
+// SessionRepository はdomain.SessionRepositoryのPostgreSQL実装
+// セッション本体はsessions、セットはsession_setsに分けて保存する
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// selectSessions はセッションとセットをLEFT JOINで1回のクエリで取る（N+1を避ける）
+const selectSessions = `
+SELECT s.id, s.user_id, s.performed_at, s.note, s.created_at, s.updated_at,
+       ss.exercise_id, ss.reps, ss.weight_kg
+FROM sessions s
+LEFT JOIN session_sets ss ON ss.session_id = s.id
+`
+
+func (r *SessionRepository) FindByID(id int) (*domain.Session, error) {
+	rows, err := r.db.Query(selectSessions+`WHERE s.id = $1 ORDER BY ss.position`, id)
+	if err != nil {
+		return nil, fmt.Errorf("find session id=%d: %w", id, err)
+	}
+	sessions, err := scanSessions(rows)
+	if err != nil {
+		return nil, fmt.Errorf("find session id=%d: %w", id, err)
+	}
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("session id=%d: %w", id, domain.ErrNotFound)
+	}
+	return sessions[0], nil
+}
+
+func (r *SessionRepository) ListByUser(userID int) ([]*domain.Session, error) {
+	rows, err := r.db.Query(selectSessions+`WHERE s.user_id = $1 ORDER BY s.performed_at DESC, s.id DESC, ss.position`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions user_id=%d: %w", userID, err)
+	}
+	sessions, err := scanSessions(rows)
+	if err != nil {
+		return nil, fmt.Errorf("list sessions user_id=%d: %w", userID, err)
+	}
+	return sessions, nil
+}
+
+// Save はセッションとセットを1つのトランザクションで保存する
+// 更新時はセットを全て消してから入れ直す（差分更新より単純で、順番もそのまま保てる）
+func (r *SessionRepository) Save(session *domain.Session) (err error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("save session: %w", err)
+	}
+	defer tx.Rollback()
+
+	isNew := session.ID == 0
+	if isNew {
+		err = tx.QueryRow(
+			`INSERT INTO sessions (user_id, performed_at, note, created_at, updated_at)
+			 VALUES ($1, $2, $3, $4, $5) RETURNING id`,
+			session.UserID, session.PerformedAt, session.Note, session.CreatedAt, session.UpdatedAt,
+		).Scan(&session.ID)
+		if err != nil {
+			return fmt.Errorf("insert session: %w", err)
+		}
+		// ロールバックされたら採番したIDは無効なので戻す
+		defer func() {
+			if err != nil {
+				session.ID = 0
+			}
+		}()
+	} else {
+		res, err := tx.Exec(
+			`UPDATE sessions SET user_id = $2, performed_at = $3, note = $4, updated_at = $5 WHERE id = $1`,
+			session.ID, session.UserID, session.PerformedAt, session.Note, session.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("update session id=%d: %w", session.ID, err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return fmt.Errorf("update session id=%d: %w", session.ID, err)
+		} else if n == 0 {
+			return fmt.Errorf("session id=%d: %w", session.ID, domain.ErrNotFound)
+		}
+		if _, err := tx.Exec(`DELETE FROM session_sets WHERE session_id = $1`, session.ID); err != nil {
+			return fmt.Errorf("replace sets of session id=%d: %w", session.ID, err)
+		}
+	}
+
+	for i, set := range session.Sets {
+		_, err = tx.Exec(
+			`INSERT INTO session_sets (session_id, position, exercise_id, reps, weight_kg) VALUES ($1, $2, $3, $4, $5)`,
+			session.ID, i, set.ExerciseID, set.Reps, set.WeightKg,
+		)
+		if err != nil {
+			return fmt.Errorf("insert set %d of session id=%d: %w", i, session.ID, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("save session: %w", err)
+	}
+	return nil
+}
+
+func (r *SessionRepository) Delete(id int) error {
+	// session_setsはON DELETE CASCADEで一緒に消える
+	res, err := r.db.Exec(`DELETE FROM sessions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete session id=%d: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete session id=%d: %w", id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("session id=%d: %w", id, domain.ErrNotFound)
+	}
+	return nil
+}
+
+// scanSessions はJOINの結果（セット1件ごとに1行）をセッション単位にまとめる
+// 行はセッションごとに連続している前提（ORDER BYで保証する）
+func scanSessions(rows *sql.Rows) ([]*domain.Session, error) {
+	defer rows.Close()
+
+	sessions := []*domain.Session{}
+	var current *domain.Session
+	for rows.Next() {
+		var s domain.Session
+		var exerciseID, reps sql.NullInt64
+		var weight sql.NullFloat64
+		if err := rows.Scan(&s.ID, &s.UserID, &s.PerformedAt, &s.Note, &s.CreatedAt, &s.UpdatedAt,
+			&exerciseID, &reps, &weight); err != nil {
+			return nil, err
+		}
+		if current == nil || current.ID != s.ID {
+			s.Sets = []domain.Set{}
+			current = &s
+			sessions = append(sessions, current)
+		}
+		// セットが1件もないセッションはLEFT JOINでNULLになる
+		if exerciseID.Valid {
+			current.Sets = append(current.Sets, domain.Set{
+				ExerciseID: int(exerciseID.Int64),
+				Reps:       int(reps.Int64),
+				WeightKg:   weight.Float64,
+			})
+		}
+	}
+	return sessions, rows.Err()
+}
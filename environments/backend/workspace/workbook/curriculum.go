@@ -0,0 +1,175 @@
+package workbook
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Curriculum はREADME.mdに載っているフェーズ・カテゴリ・テーマの一覧
+type Curriculum struct {
+	Phases []*Phase
+}
+
+// Phase は「## Phase 3: バック基礎 (Go)」の1つのフェーズ
+type Phase struct {
+	Number     int
+	Title      string // "バック基礎 (Go)"
+	Categories []*Category
+}
+
+// Category は「### カテゴリA: Goらしさの基礎」の1つのカテゴリ
+type Category struct {
+	Title  string // "カテゴリA: Goらしさの基礎"
+	Themes []CurriculumTheme
+}
+
+// CurriculumTheme はカテゴリの表の1行
+type CurriculumTheme struct {
+	Number  int
+	Title   string // テーマ
+	Summary string // 習得内容
+}
+
+// Themes はフェーズの全てのテーマの数を返す
+func (p *Phase) Themes() int {
+	n := 0
+	for _, c := range p.Categories {
+		n += len(c.Themes)
+	}
+	return n
+}
+
+var phaseHeadingRe = regexp.MustCompile(`^## Phase (\d+): (.+)$`)
+
+// LoadCurriculum はrootのREADME.mdを読む
+func LoadCurriculum(root string) (*Curriculum, error) {
+	f, err := os.Open(filepath.Join(root, "README.md"))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseCurriculum(f)
+}
+
+// ParseCurriculum はREADME.mdの「## Phase」「### カテゴリ」の見出しと、その下の表を読む
+func ParseCurriculum(r io.Reader) (*Curriculum, error) {
+	c := &Curriculum{}
+	var phase *Phase
+	var category *Category
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case strings.HasPrefix(line, "## "):
+			phase, category = nil, nil
+			if m := phaseHeadingRe.FindStringSubmatch(line); m != nil {
+				n, _ := strconv.Atoi(m[1])
+				phase = &Phase{Number: n, Title: m[2]}
+				c.Phases = append(c.Phases, phase)
+			}
+		case strings.HasPrefix(line, "### ") && phase != nil:
+			category = &Category{Title: strings.TrimPrefix(line, "### ")}
+			phase.Categories = append(phase.Categories, category)
+		case strings.HasPrefix(line, "|") && category != nil:
+			cells := tableCells(line)
+			// 見出し行と区切り行は番号にならないので読み飛ばす
+			n, err := strconv.Atoi(cells[0])
+			if err != nil || len(cells) < 2 {
+				continue
+			}
+			t := CurriculumTheme{Number: n, Title: cells[1]}
+			if len(cells) > 2 {
+				t.Summary = cells[2]
+			}
+			category.Themes = append(category.Themes, t)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(c.Phases) == 0 {
+		return nil, fmt.Errorf("no \"## Phase N: ...\" headings found")
+	}
+	return c, nil
+}
+
+func tableCells(line string) []string {
+	cells := strings.Split(strings.Trim(line, "|"), "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+// statusIcons, statusNames は進捗表に表示する状態
+var (
+	statusIcons = map[ProgressStatus]string{ProgressAttempted: "🔄", ProgressPassing: "🟢", ProgressComplete: "✅"}
+	statusNames = map[ProgressStatus]string{ProgressAttempted: "挑戦中", ProgressPassing: "合格", ProgressComplete: "完了"}
+)
+
+// WriteProgressReport はREADME.mdと同じフェーズ・カテゴリの表の形で進捗を書く
+// phaseが0なら全てのフェーズ、それ以外はそのフェーズだけ
+func WriteProgressReport(w io.Writer, c *Curriculum, p *Progress, phase int) error {
+	var b strings.Builder
+	for _, ph := range c.Phases {
+		if phase != 0 && ph.Number != phase {
+			continue
+		}
+		counts := make(map[ProgressStatus]int)
+		for _, cat := range ph.Categories {
+			for _, t := range cat.Themes {
+				if tp := p.Theme(ph.Number, t.Number); tp != nil {
+					counts[tp.Status()]++
+				}
+			}
+		}
+		fmt.Fprintf(&b, "## Phase %d: %s\n\n", ph.Number, ph.Title)
+		fmt.Fprintf(&b, "完了 %d / 合格 %d / 挑戦中 %d / 全%dテーマ\n\n",
+			counts[ProgressComplete], counts[ProgressPassing], counts[ProgressAttempted], ph.Themes())
+
+		for _, cat := range ph.Categories {
+			fmt.Fprintf(&b, "### %s\n\n", cat.Title)
+			b.WriteString("| No | テーマ | 状態 | レベル | 採点回数 | 最終更新 |\n")
+			b.WriteString("|---|---|---|---|---|---|\n")
+			for _, t := range cat.Themes {
+				status, levels, attempts, updated := "-", "-", "-", "-"
+				if tp := p.Theme(ph.Number, t.Number); tp != nil {
+					if st := tp.Status(); st != "" {
+						status = statusIcons[st] + " " + statusNames[st]
+					}
+					levels = levelSummary(tp)
+					attempts = strconv.Itoa(tp.Attempts())
+					updated = tp.UpdatedAt().Local().Format(time.DateOnly)
+				}
+				fmt.Fprintf(&b, "| %02d | %s | %s | %s | %s | %s |\n", t.Number, t.Title, status, levels, attempts, updated)
+			}
+			b.WriteString("\n")
+		}
+	}
+	if b.Len() == 0 {
+		return fmt.Errorf("phase %d is not in README.md", phase)
+	}
+	_, err := io.WriteString(w, strings.TrimSuffix(b.String(), "\n"))
+	return err
+}
+
+// levelSummary は記録のあるレベルを"basic ✅, advanced 🔄"のように並べる
+func levelSummary(t *ThemeProgress) string {
+	var parts []string
+	for _, lv := range Levels {
+		if l, ok := t.Levels[lv]; ok && l.Status != "" {
+			parts = append(parts, string(lv)+" "+statusIcons[l.Status])
+		}
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,253 @@
+package gocheck
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+	"sync"
+
+	"workout/workbook/theme"
+)
+
+// Mode はコードブロックをどう確認するか
+type Mode string
+
+const (
+	// ModeStrict はそのままコンパイルできることを求める（コード例はコピーして動くはずなので）
+	ModeStrict Mode = "ok"
+	// ModeLenient は断片として扱い、前後の文脈がないことによるエラー（未定義の名前・未使用の変数など）を無視する
+	ModeLenient Mode = "lenient"
+	// ModeFail はExpectを含むエラーで失敗することを求める（「これはコンパイルできない」と説明している悪い例）
+	// 前後の文脈がないことによるエラーはModeLenientと同じく無視し、それ以外のエラーがあれば説明と違うとみなす
+	ModeFail Mode = "fail"
+	// ModeCompiles は断片としてコンパイルできることを求め、問題が実行時や設計にあることをReasonに書く
+	// （「値がコピーされて変わらない」「panicする」など、型チェックでは確かめられない悪い例）
+	ModeCompiles Mode = "compiles"
+	// ModeSkip は確認しない（擬似コードなど）
+	ModeSkip Mode = "skip"
+)
+
+// Spec はコードブロックに対する期待
+type Spec struct {
+	Mode   Mode
+	Expect string // ModeFailの場合に、エラーメッセージに含まれるべき文字列
+	Reason string // ModeSkip・ModeCompilesの理由
+}
+
+// AnnotationPrefix はmdのコードブロック直前に書く注記の接頭辞
+//
+//	<!-- check: fail "declared and not used" -->
+//	<!-- check: compiles 実行するとpanicする -->
+//	<!-- check: skip 擬似コード -->
+//	<!-- check: ok -->
+//	<!-- check: lenient -->
+const AnnotationPrefix = "check:"
+
+// SpecFor はコードブロックの注記から期待を決める。注記がなければ種類ごとの既定値を使う
+// 基本・実践のコード例は「そのままコピーして動く」（TEMPLATE.md）ので厳密に、それ以外は断片として確認する
+// 悪い例は本文の説明どおりに失敗するかを確かめたいので、注記を必須にする
+func SpecFor(c theme.CodeBlock) (Spec, error) {
+	if rest, ok := strings.CutPrefix(c.Annotation, AnnotationPrefix); ok {
+		return ParseAnnotation(strings.TrimSpace(rest))
+	}
+	switch c.Kind {
+	case theme.KindBadExample:
+		return Spec{}, fmt.Errorf(`bad example needs a %s annotation: fail "<error>" if it does not compile, compiles <reason> otherwise`, AnnotationPrefix)
+	case theme.KindBasic, theme.KindApplied:
+		return Spec{Mode: ModeStrict}, nil
+	default:
+		return Spec{Mode: ModeLenient}, nil
+	}
+}
+
+// ParseAnnotation は"check:"より後ろの部分を読む
+func ParseAnnotation(s string) (Spec, error) {
+	mode, rest, _ := strings.Cut(s, " ")
+	rest = strings.TrimSpace(rest)
+	switch Mode(mode) {
+	case ModeStrict, ModeLenient:
+		return Spec{Mode: Mode(mode)}, nil
+	case ModeSkip:
+		return Spec{Mode: ModeSkip, Reason: rest}, nil
+	case ModeCompiles:
+		if rest == "" {
+			return Spec{}, fmt.Errorf("%s compiles needs the reason the code is bad: compiles 実行するとpanicする", AnnotationPrefix)
+		}
+		return Spec{Mode: ModeCompiles, Reason: rest}, nil
+	case ModeFail:
+		expect, err := strconv.Unquote(rest)
+		if err != nil || expect == "" {
+			return Spec{}, fmt.Errorf(`%s fail needs a quoted error message: fail "undefined: x"`, AnnotationPrefix)
+		}
+		return Spec{Mode: ModeFail, Expect: expect}, nil
+	default:
+		return Spec{}, fmt.Errorf("unknown %s mode %q (want ok, lenient, fail, compiles or skip)", AnnotationPrefix, mode)
+	}
+}
+
+// Diagnostic は型チェックのエラー1件
+type Diagnostic struct {
+	Line    int // mdファイルでの行番号（追加した行のエラーなら0）
+	Message string
+	Ignored bool // 断片の前後の文脈がないために起きたとして無視したエラー
+}
+
+// Result は1つのコードブロックを確認した結果
+type Result struct {
+	Path        string
+	Block       theme.CodeBlock
+	Spec        Spec
+	Diagnostics []Diagnostic
+	// Problem が空でなければ期待どおりではなかった
+	Problem string
+}
+
+// OK は期待どおりだったかを返す
+func (r Result) OK() bool { return r.Problem == "" }
+
+// lenientIgnores は断片で前後の文脈がないために起きる、無視してよいエラー
+var lenientIgnores = []string{
+	"undefined: ",
+	"declared and not used",
+	"imported and not used",
+	"missing return",
+	"redeclared in this block",
+	"other declaration of",
+	"is not used",
+	"too many return values",
+	"not enough return values",
+	"cannot use _ as value",
+}
+
+// Checker はコードブロックを型チェックする
+// 標準パッケージの読み込みは重いので、1つのCheckerを使い回す
+type Checker struct {
+	mu       sync.Mutex
+	importer types.Importer
+}
+
+func NewChecker() *Checker {
+	return &Checker{importer: importer.Default()}
+}
+
+// CheckTheme はテーマの全てのgoコードブロックを確認する
+func (c *Checker) CheckTheme(t *theme.Theme) []Result {
+	var results []Result
+	for _, block := range t.Code {
+		if block.Lang != "go" {
+			continue
+		}
+		r := c.Check(block)
+		r.Path = t.Path
+		results = append(results, r)
+	}
+	return results
+}
+
+// Check は1つのコードブロックを注記・種類に応じた期待で確認する
+func (c *Checker) Check(block theme.CodeBlock) Result {
+	res := Result{Block: block}
+	spec, err := SpecFor(block)
+	if err != nil {
+		res.Problem = err.Error()
+		return res
+	}
+	res.Spec = spec
+	if spec.Mode == ModeSkip {
+		return res
+	}
+
+	code := block.Code
+	fragment := spec.Mode != ModeStrict
+	if fragment {
+		code = stripPlaceholders(code)
+	}
+	unit := Wrap(code)
+	res.Diagnostics = c.typeCheck(unit, block.Line)
+
+	var errs, expected []Diagnostic
+	for i, d := range res.Diagnostics {
+		switch {
+		case spec.Mode == ModeFail && strings.Contains(d.Message, spec.Expect):
+			expected = append(expected, d)
+		case fragment && ignorable(d.Message):
+			res.Diagnostics[i].Ignored = true
+		default:
+			errs = append(errs, d)
+		}
+	}
+
+	switch spec.Mode {
+	case ModeStrict, ModeLenient, ModeCompiles:
+		if len(errs) > 0 {
+			res.Problem = fmt.Sprintf("does not compile: %s", errs[0].Message)
+		}
+	case ModeFail:
+		switch {
+		case len(expected) == 0 && len(errs) == 0:
+			res.Problem = fmt.Sprintf("expected to fail with %q, but it compiles", spec.Expect)
+		case len(expected) == 0:
+			res.Problem = fmt.Sprintf("expected to fail with %q, but got: %s", spec.Expect, errs[0].Message)
+		case len(errs) > 0:
+			// 説明していない理由でも失敗している（コード例の書き間違いなど）
+			res.Problem = fmt.Sprintf("fails with %q as expected, but also with: %s", spec.Expect, errs[0].Message)
+		}
+	}
+	return res
+}
+
+func ignorable(msg string) bool {
+	for _, s := range lenientIgnores {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// typeCheck はunitを構文解析・型チェックしてエラーを返す
+// fenceLineはmdでのコードブロックの開始フェンスの行番号（エラーの行をmdの行に直すのに使う）
+func (c *Checker) typeCheck(unit *Unit, fenceLine int) []Diagnostic {
+	fset := token.NewFileSet()
+	var diags []Diagnostic
+	report := func(pos token.Position, msg string) {
+		line := 0
+		if orig := unit.OriginalLine(pos.Line); orig > 0 {
+			line = fenceLine + orig
+		}
+		diags = append(diags, Diagnostic{Line: line, Message: msg})
+	}
+
+	f, err := parser.ParseFile(fset, "snippet.go", unit.Source, parser.AllErrors)
+	if err != nil {
+		var list scanner.ErrorList
+		if errors.As(err, &list) {
+			for _, e := range list {
+				report(e.Pos, e.Msg)
+			}
+		} else {
+			report(token.Position{}, err.Error())
+		}
+		return diags
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	conf := types.Config{
+		Importer: c.importer,
+		Error: func(err error) {
+			if te, ok := err.(types.Error); ok {
+				report(fset.Position(te.Pos), te.Msg)
+			}
+		},
+	}
+	conf.Check(f.Name.Name, fset, []*ast.File{f}, nil)
+	return diags
+}
@@ -0,0 +1,273 @@
+// Package gocheck はテーマのmdに載っているGoのコードブロックを型チェックする
+package gocheck
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Unit はコードブロックを型チェックできる1ファイルに包んだもの
+type Unit struct {
+	Source string
+	// lines は包んだ後の各行（0始まり）が、元のコードブロックの何行目（1始まり）だったか（追加した行は0）
+	lines []int
+}
+
+// OriginalLine は包んだ後の行番号（1始まり）を、元のコードブロックの行番号に戻す（追加した行なら0）
+func (u *Unit) OriginalLine(line int) int {
+	if line < 1 || line > len(u.lines) {
+		return 0
+	}
+	return u.lines[line-1]
+}
+
+type unitBuilder struct {
+	src   []string
+	lines []int
+}
+
+func (b *unitBuilder) add(text string, orig int) {
+	b.src = append(b.src, text)
+	b.lines = append(b.lines, orig)
+}
+
+func (b *unitBuilder) unit() *Unit {
+	return &Unit{Source: strings.Join(b.src, "\n") + "\n", lines: b.lines}
+}
+
+// Wrap はコードブロックを型チェックできる形に包む
+//
+// package句があればそのまま使う。断片（宣言と文が混ざったもの）の場合は、
+// トップレベルのfunc/type/var/const/importを宣言として、それ以外の文を
+// func _() error { ... } の中に入れる（断片の中のreturn errに合わせてerrorを返す関数にしている）
+// どちらの場合も、使われているのにimportされていない標準パッケージはimportを補う
+func Wrap(code string) *Unit {
+	lines := strings.Split(strings.TrimRight(code, "\n"), "\n")
+
+	var b unitBuilder
+	if isFile(lines) {
+		for i, l := range lines {
+			b.add(l, i+1)
+		}
+		return addImports(b.unit())
+	}
+
+	b.add("package snippet", 0)
+	var decls, stmts []int // 元の行番号（0始まり）
+	inDecl := false
+	for i, depth := range lineDepths(code, len(lines)) {
+		if depth == 0 {
+			trimmed := strings.TrimSpace(lines[i])
+			switch {
+			case trimmed == "" || strings.HasPrefix(trimmed, "//"):
+				// 空行・コメントは直前と同じ側に入れる
+			case startsDecl(lines[i]):
+				inDecl = true
+			default:
+				inDecl = false
+			}
+		}
+		if inDecl {
+			decls = append(decls, i)
+		} else {
+			stmts = append(stmts, i)
+		}
+	}
+	for _, i := range decls {
+		b.add(lines[i], i+1)
+	}
+	if hasCode(lines, stmts) {
+		b.add("func _() error {", 0)
+		for _, i := range stmts {
+			b.add(lines[i], i+1)
+		}
+		b.add("return nil", 0)
+		b.add("}", 0)
+	}
+	return addImports(b.unit())
+}
+
+func isFile(lines []string) bool {
+	for _, l := range lines {
+		t := strings.TrimSpace(l)
+		if t == "" || strings.HasPrefix(t, "//") {
+			continue
+		}
+		return strings.HasPrefix(t, "package ")
+	}
+	return false
+}
+
+func startsDecl(line string) bool {
+	for _, kw := range []string{"func ", "type ", "var ", "const ", "import "} {
+		if strings.HasPrefix(line, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasCode(lines []string, idx []int) bool {
+	for _, i := range idx {
+		t := strings.TrimSpace(lines[i])
+		if t != "" && !strings.HasPrefix(t, "//") {
+			return true
+		}
+	}
+	return false
+}
+
+// lineDepths は各行の先頭での括弧（{ ( [）の深さを返す
+// 文字列やコメントの中の括弧を数えないよう、go/scannerで字句解析する
+func lineDepths(code string, n int) []int {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", -1, len(code))
+	var s scanner.Scanner
+	s.Init(file, []byte(code), nil, scanner.ScanComments)
+
+	depths := make([]int, n)
+	depth, line := 0, 1
+	for {
+		pos, tok, _ := s.Scan()
+		if tok == token.EOF {
+			break
+		}
+		l := fset.Position(pos).Line
+		for ; line < l && line < n; line++ {
+			depths[line] = depth
+		}
+		switch tok {
+		case token.LBRACE, token.LPAREN, token.LBRACK:
+			depth++
+		case token.RBRACE, token.RPAREN, token.RBRACK:
+			depth = max(depth-1, 0)
+		}
+	}
+	for ; line < n; line++ {
+		depths[line] = depth
+	}
+	return depths
+}
+
+// stdPackages はimportを補う標準パッケージ（パッケージ名 → パス）
+var stdPackages = map[string]string{
+	"bufio": "bufio", "bytes": "bytes", "context": "context", "errors": "errors",
+	"fmt": "fmt", "io": "io", "json": "encoding/json", "log": "log", "http": "net/http",
+	"os": "os", "sort": "sort", "strconv": "strconv", "strings": "strings",
+	"sync": "sync", "time": "time", "httptest": "net/http/httptest", "slog": "log/slog",
+	"reflect": "reflect", "testing": "testing",
+}
+
+// AddImports はsrcで使われているのにimportされていない標準パッケージのimportを足す
+// 雛形の生成など、コードブロックからファイルを作るときに使う
+func AddImports(src string) string {
+	n := strings.Count(strings.TrimRight(src, "\n"), "\n") + 1
+	return addImports(&Unit{Source: src, lines: make([]int, n)}).Source
+}
+
+// addImports はuで使われているのにimportされていない標準パッケージのimportを足す
+func addImports(u *Unit) *Unit {
+	fset := token.NewFileSet()
+	// 構文エラーがあっても読めた部分から判定する
+	f, _ := parser.ParseFile(fset, "", u.Source, parser.SkipObjectResolution)
+	if f == nil || f.Name == nil {
+		return u
+	}
+
+	imported := make(map[string]bool)
+	for _, imp := range f.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		name := path[strings.LastIndex(path, "/")+1:]
+		if imp.Name != nil {
+			name = imp.Name.Name
+		}
+		imported[name] = true
+	}
+	declared := declaredNames(f)
+
+	var missing []string
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if id, ok := sel.X.(*ast.Ident); ok {
+			if path, ok := stdPackages[id.Name]; ok && !imported[id.Name] && !declared[id.Name] && !slices.Contains(missing, path) {
+				missing = append(missing, path)
+			}
+		}
+		return true
+	})
+	if len(missing) == 0 {
+		return u
+	}
+	sort.Strings(missing)
+
+	// package句の直後に1行で足す（行番号の対応がずれないよう、追加した行は0にする）
+	pkgLine := fset.Position(f.Name.Pos()).Line
+	var b unitBuilder
+	src := strings.Split(strings.TrimRight(u.Source, "\n"), "\n")
+	for i, l := range src {
+		b.add(l, u.lines[i])
+		if i+1 == pkgLine {
+			quoted := make([]string, len(missing))
+			for j, p := range missing {
+				quoted[j] = strconv.Quote(p)
+			}
+			b.add("import ("+strings.Join(quoted, "; ")+")", 0)
+		}
+	}
+	return b.unit()
+}
+
+// declaredNames はローカル変数を含めて、ファイル中で宣言されている名前を返す
+// （errやjsonという名前の変数がパッケージ名と衝突する場合にimportを足さないため）
+func declaredNames(f *ast.File) map[string]bool {
+	names := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.DEFINE {
+				for _, l := range n.Lhs {
+					if id, ok := l.(*ast.Ident); ok {
+						names[id.Name] = true
+					}
+				}
+			}
+		case *ast.ValueSpec:
+			for _, id := range n.Names {
+				names[id.Name] = true
+			}
+		case *ast.Field:
+			for _, id := range n.Names {
+				names[id.Name] = true
+			}
+		}
+		return true
+	})
+	return names
+}
+
+// placeholderRe は説明のために省略した「{ ... }」「, ...)」
+var placeholderRe = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`\{\s*\.\.\.\s*\}`), "{}"},
+	{regexp.MustCompile(`,\s*\.\.\.\s*\)`), ")"},
+}
+
+// stripPlaceholders は省略記号をGoとして読める形にする（行数は変えない）
+func stripPlaceholders(code string) string {
+	for _, p := range placeholderRe {
+		code = p.re.ReplaceAllString(code, p.repl)
+	}
+	return code
+}
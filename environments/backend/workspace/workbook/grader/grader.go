@@ -0,0 +1,161 @@
+// Package grader はテーマごとの隠しテストで、演習問題の要件を1つずつ採点する
+//
+// テストは suites/<テーマ>.go.tmpl に置き、採点するときだけ学習者のパッケージに
+// grader_test.go としてコピーする。package句は学習者のコードに合わせて書き換えるので、
+// package mainのままでも、ライブラリのパッケージにしていても採点できる
+package grader
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//go:embed suites/*.go.tmpl
+var suites embed.FS
+
+// helpers は全てのテーマで使える宣言（標準出力を取り込むヘルパーなど）
+//
+//go:embed helpers.go.tmpl
+var helpers []byte
+
+// Case は1つの隠しテスト
+type Case struct {
+	Name        string // テスト関数名
+	Requirement int    // 対応する要件の番号（1始まり）
+	Hint        string // 失敗したときに表示するヒント
+	source      string
+}
+
+// Suite は1つのテーマの隠しテスト
+type Suite struct {
+	Theme string // "12-io-reader-writer"
+	Cases []Case
+	// helpers はテスト以外の宣言（テスト用の偽物の型など）
+	// 学習者のコードを参照すると、それが未実装のとき全てのテストがコンパイルできなくなる
+	helpers []string
+}
+
+var (
+	requirementRe = regexp.MustCompile(`^要件\s*(\d+)`)
+	hintRe        = regexp.MustCompile(`^ヒント[:：]\s*(.+)$`)
+)
+
+// Themes は隠しテストがあるテーマの一覧を返す
+func Themes() []string {
+	files, _ := fs.Glob(suites, "suites/*.go.tmpl")
+	themes := make([]string, len(files))
+	for i, f := range files {
+		themes[i] = strings.TrimSuffix(path.Base(f), ".go.tmpl")
+	}
+	return themes
+}
+
+// Load はテーマの隠しテストを読む。themeは"12-io-reader-writer"か"12"
+func Load(theme string) (*Suite, error) {
+	for _, t := range Themes() {
+		if t == theme || strings.HasPrefix(t, theme+"-") {
+			data, err := suites.ReadFile("suites/" + t + ".go.tmpl")
+			if err != nil {
+				return nil, err
+			}
+			s, err := Parse(t, data)
+			if err != nil {
+				return nil, err
+			}
+			common, err := parse("helpers", helpers)
+			if err != nil {
+				return nil, err
+			}
+			s.helpers = append(common.helpers, s.helpers...)
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("no grader suite for theme %q", theme)
+}
+
+// Parse はテストのソースを読む
+// テスト関数のdocコメントに「要件N: ...」と「ヒント: ...」を書く
+func Parse(theme string, src []byte) (*Suite, error) {
+	s, err := parse(theme, src)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Cases) == 0 {
+		return nil, errors.New(theme + ": no test functions")
+	}
+	return s, nil
+}
+
+func parse(theme string, src []byte) (*Suite, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, theme+".go.tmpl", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	s := &Suite{Theme: theme}
+	text := func(n ast.Node) string {
+		start, end := n.Pos(), n.End()
+		if fn, ok := n.(*ast.FuncDecl); ok && fn.Doc != nil {
+			start = fn.Doc.Pos()
+		}
+		if gd, ok := n.(*ast.GenDecl); ok && gd.Doc != nil {
+			start = gd.Doc.Pos()
+		}
+		return string(src[fset.Position(start).Offset:fset.Position(end).Offset])
+	}
+
+	for _, d := range f.Decls {
+		if gd, ok := d.(*ast.GenDecl); ok && gd.Tok == token.IMPORT {
+			continue
+		}
+		fn, ok := d.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || !strings.HasPrefix(fn.Name.Name, "Test") {
+			s.helpers = append(s.helpers, text(d))
+			continue
+		}
+		c := Case{Name: fn.Name.Name, source: text(fn)}
+		if fn.Doc != nil {
+			for _, line := range strings.Split(fn.Doc.Text(), "\n") {
+				if m := requirementRe.FindStringSubmatch(line); m != nil {
+					c.Requirement, _ = strconv.Atoi(m[1])
+				}
+				if m := hintRe.FindStringSubmatch(line); m != nil {
+					c.Hint = m[1]
+				}
+			}
+		}
+		if c.Requirement == 0 {
+			return nil, fmt.Errorf("%s: %s has no 要件N comment", theme, c.Name)
+		}
+		s.Cases = append(s.Cases, c)
+	}
+	return s, nil
+}
+
+// Source はpkgパッケージのテストファイルとしてのソースを返す（casesが空なら全てのテスト）
+// importはテストが使っている標準パッケージだけを並べる
+func (s *Suite) Source(pkg string, cases ...Case) string {
+	if len(cases) == 0 {
+		cases = s.Cases
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by workbook grade. DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	for _, h := range s.helpers {
+		b.WriteString(h)
+		b.WriteString("\n\n")
+	}
+	for _, c := range cases {
+		b.WriteString(c.source)
+		b.WriteString("\n\n")
+	}
+	return b.String()
+}
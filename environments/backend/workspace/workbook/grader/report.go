@@ -0,0 +1,81 @@
+package grader
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteReport はgを要件ごとに書き出す
+// requirementsはテーマのmdの要件（1番目が要件1）。足りなければテスト名で表示する
+func WriteReport(w io.Writer, g *Grade, requirements []string) error {
+	if g.BuildError != "" {
+		_, err := fmt.Fprintf(w, "%s: ビルドできませんでした\n%s\n", g.Suite.Theme, indent(g.BuildError, "    "))
+		return err
+	}
+
+	numbers := requirementNumbers(g, requirements)
+	graded, passed := 0, 0
+	var b strings.Builder
+	for _, n := range numbers {
+		outcomes := g.Requirement(n)
+		ok := true
+		for _, o := range outcomes {
+			ok = ok && o.Passed
+		}
+		if len(outcomes) > 0 {
+			graded++
+			if ok {
+				passed++
+			}
+		}
+
+		mark, text := "✓", fmt.Sprintf("要件%d", n)
+		if !ok {
+			mark = "✗"
+		}
+		if n <= len(requirements) {
+			text = fmt.Sprintf("%d. %s", n, requirements[n-1])
+		}
+		if len(outcomes) == 0 {
+			mark = "-"
+			text += "（自動採点なし）"
+		}
+		fmt.Fprintf(&b, "  %s %s\n", mark, text)
+
+		for _, o := range outcomes {
+			if o.Passed {
+				continue
+			}
+			for _, m := range o.Messages {
+				b.WriteString(indent(m, "      "))
+				b.WriteByte('\n')
+			}
+			if o.Case.Hint != "" {
+				fmt.Fprintf(&b, "      ヒント: %s\n", o.Case.Hint)
+			}
+		}
+	}
+	if _, err := fmt.Fprintf(w, "%s: %d/%d 要件を満たしています\n", g.Suite.Theme, passed, graded); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// requirementNumbers は1からmdの要件とテストの要件の大きい方までの番号を返す
+func requirementNumbers(g *Grade, requirements []string) []int {
+	last := len(requirements)
+	for _, c := range g.Suite.Cases {
+		last = max(last, c.Requirement)
+	}
+	numbers := make([]int, last)
+	for i := range numbers {
+		numbers[i] = i + 1
+	}
+	return numbers
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
@@ -0,0 +1,328 @@
+package grader
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"workout/workbook"
+	"workout/workbook/gocheck"
+)
+
+// TestFile は採点のときに学習者のパッケージに置くテストファイルの名前
+const TestFile = "grader_test.go"
+
+// Outcome は1つの隠しテストの結果
+type Outcome struct {
+	Case     Case
+	Passed   bool
+	Messages []string // t.Errorfなどのメッセージ、またはコンパイルエラー
+}
+
+// Grade は1つのテーマの採点結果
+type Grade struct {
+	Suite    *Suite
+	Outcomes []Outcome
+	// BuildError はテストと関係なくパッケージ自体がビルドできなかった場合のコンパイラの出力
+	BuildError string
+}
+
+// Passed は全ての隠しテストが通ったかを返す
+func (g *Grade) Passed() bool {
+	if g.BuildError != "" {
+		return false
+	}
+	for _, o := range g.Outcomes {
+		if !o.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Requirement は要件nに対応する結果を返す
+func (g *Grade) Requirement(n int) []Outcome {
+	var list []Outcome
+	for _, o := range g.Outcomes {
+		if o.Case.Requirement == n {
+			list = append(list, o)
+		}
+	}
+	return list
+}
+
+// Grader は学習者のコードを一時ディレクトリにコピーして隠しテストを実行する
+// 学習者のディレクトリには何も書き込まない
+type Grader struct {
+	GoBin   string        // goコマンドのパス（空なら"go"）
+	Timeout time.Duration // テストの実行の期限（0なら1分）。ビルドにはさらに30秒まで待つ
+	// Module はworkoutモジュールのディレクトリ
+	// 学習者のコードがworkbook.ModulePathのパッケージをimportしていれば、一時的なモジュールからここを参照する
+	Module string
+}
+
+// Grade はdirのパッケージをsuiteで採点する
+func (g *Grader) Grade(ctx context.Context, suite *Suite, dir string) (*Grade, error) {
+	pkg, err := packageName(dir)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp("", "workbook-grade-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := workbook.CopyGoFiles(dir, tmp); err != nil {
+		return nil, err
+	}
+	if err := workbook.WriteGoMod(tmp, "exercise", g.Module); err != nil {
+		return nil, err
+	}
+
+	// テストの前にパッケージ自体をビルドして、学習者のコードの誤りとテストの失敗を分ける
+	out, ok, err := g.goCmd(ctx, tmp, "build", "-o", os.DevNull, ".")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return &Grade{Suite: suite, BuildError: cleanBuildOutput(out)}, nil
+	}
+
+	outcomes, compiled, err := g.test(ctx, tmp, pkg, suite, suite.Cases)
+	if err != nil {
+		return nil, err
+	}
+	if !compiled {
+		// まだ書いていない関数があるとテストファイル全体がコンパイルできないので、
+		// テストを1つずつコンパイルして、どの要件が満たせていないかを分ける
+		outcomes = outcomes[:0]
+		for _, c := range suite.Cases {
+			res, _, err := g.test(ctx, tmp, pkg, suite, []Case{c})
+			if err != nil {
+				return nil, err
+			}
+			outcomes = append(outcomes, res...)
+		}
+	}
+	return &Grade{Suite: suite, Outcomes: outcomes}, nil
+}
+
+// test はcasesだけを含むテストファイルを書いて実行する
+// コンパイルできなければ、全てのcaseをコンパイルエラーで失敗にしてcompiled=falseを返す
+func (g *Grader) test(ctx context.Context, dir, pkg string, suite *Suite, cases []Case) (outcomes []Outcome, compiled bool, err error) {
+	src := gocheck.AddImports(suite.Source(pkg, cases...))
+	if err := os.WriteFile(filepath.Join(dir, TestFile), []byte(src), 0o644); err != nil {
+		return nil, false, err
+	}
+	events, buildOutput, err := g.goTest(ctx, dir, "")
+	if err != nil {
+		return nil, false, err
+	}
+	if len(events) == 0 {
+		// コンパイルできないとテストのイベントは出力されない
+		var msgs []string
+		for _, m := range strings.Split(cleanBuildOutput(buildOutput), "\n") {
+			if !slices.Contains(msgs, m) {
+				msgs = append(msgs, m)
+			}
+		}
+		for _, c := range cases {
+			outcomes = append(outcomes, Outcome{Case: c, Messages: msgs})
+		}
+		return outcomes, false, nil
+	}
+	// panicしたテストがあるとテストのバイナリごと終了し、後のテストは実行されないので、
+	// 結果のないテストを1つずつ実行し直す（ビルドはキャッシュされる）
+	for _, c := range cases {
+		if _, ok := events[c.Name]; ok || len(cases) == 1 {
+			continue
+		}
+		rerun, _, err := g.goTest(ctx, dir, "^"+c.Name+"$")
+		if err != nil {
+			return nil, false, err
+		}
+		if r, ok := rerun[c.Name]; ok {
+			events[c.Name] = r
+		}
+	}
+	for _, c := range cases {
+		ev, ok := events[c.Name]
+		o := Outcome{Case: c, Passed: ok && ev.passed}
+		if ok {
+			o.Messages = ev.messages
+		} else {
+			// テストのバイナリごと落ちた（os.Exitなど）
+			o.Messages = []string{"テストが完了しませんでした（無限ループやos.Exitでテスト全体が止まった可能性があります）"}
+		}
+		outcomes = append(outcomes, o)
+	}
+	return outcomes, true, nil
+}
+
+// goTest はdirのテストを実行して、テストごとの結果とコンパイラの出力を返す。runが空なら全てのテスト
+func (g *Grader) goTest(ctx context.Context, dir, run string) (map[string]*testResult, string, error) {
+	// 無限ループしたテストはgo test自身に止めさせて、それまでの結果を残す
+	args := []string{"test", "-json", "-vet=off", "-count=1", "-timeout=" + g.timeout().String()}
+	if run != "" {
+		args = append(args, "-run="+run)
+	}
+	out, _, err := g.goCmd(ctx, dir, append(args, ".")...)
+	if err != nil {
+		return nil, "", err
+	}
+	results, buildOutput := parseEvents(out)
+	return results, buildOutput, nil
+}
+
+// goCmd はdirでgoコマンドを実行する。コマンドが失敗した場合はok=false、
+// 期限切れやキャンセルなど結果を使えない場合はerrを返す
+func (g *Grader) goCmd(ctx context.Context, dir string, args ...string) (out string, ok bool, err error) {
+	timeout := g.timeout() + 30*time.Second
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	bin := g.GoBin
+	if bin == "" {
+		bin = "go"
+	}
+	var buf bytes.Buffer
+	c := exec.CommandContext(ctx, bin, args...)
+	c.Dir = dir
+	// 依存パッケージを取りに行かない（標準ライブラリとworkoutモジュールだけで動くはず）
+	c.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	c.Stdout, c.Stderr = &buf, &buf
+	// 子プロセスが出力を握ったまま残っても待ち続けない
+	c.WaitDelay = time.Second
+	runErr := c.Run()
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return buf.String(), false, fmt.Errorf("go %s: timed out after %s", args[0], timeout)
+	case ctx.Err() != nil:
+		return buf.String(), false, ctx.Err()
+	case errors.As(runErr, new(*exec.ExitError)):
+		return buf.String(), false, nil
+	case runErr != nil:
+		return buf.String(), false, runErr
+	}
+	return buf.String(), true, nil
+}
+
+func (g *Grader) timeout() time.Duration {
+	if g.Timeout <= 0 {
+		return time.Minute
+	}
+	return g.Timeout
+}
+
+// packageName はdirの.goファイルのpackage名を返す
+func packageName(dir string) (string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", err
+	}
+	for _, f := range files {
+		if strings.HasSuffix(f, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), f, nil, parser.PackageClauseOnly)
+		if err != nil {
+			return "", err
+		}
+		return file.Name.Name, nil
+	}
+	return "", fmt.Errorf("no go files in %s", dir)
+}
+
+// testEvent はgo test -jsonの出力の1行
+type testEvent struct {
+	Action string
+	Test   string
+	Output string
+}
+
+type testResult struct {
+	passed   bool
+	messages []string
+}
+
+// messageRe はt.Errorfなどの出力（"    grader_test.go:12: メッセージ"）
+var messageRe = regexp.MustCompile(`^\s+\S+_test\.go:\d+: (.*)$`)
+
+// panicSuffixRe はtestingパッケージがpanicのメッセージに付ける注記
+var panicSuffixRe = regexp.MustCompile(` \[recovered(, repanicked)?\]$`)
+
+// parseEvents はgo test -jsonの出力をテストごとの結果にまとめ、コンパイラの出力と分ける
+// 学習者のコードがfmt.Printlnした出力は捨て、テストのメッセージだけを残す
+func parseEvents(out string) (results map[string]*testResult, buildOutput string) {
+	results = make(map[string]*testResult)
+	var build strings.Builder
+	sc := bufio.NewScanner(strings.NewReader(out))
+	sc.Buffer(nil, 1<<20)
+	for sc.Scan() {
+		var ev testEvent
+		if json.Unmarshal(sc.Bytes(), &ev) != nil {
+			continue
+		}
+		if ev.Action == "build-output" {
+			build.WriteString(ev.Output)
+			continue
+		}
+		if ev.Test == "" {
+			continue
+		}
+		// サブテストは親のテストの結果に含める
+		name, _, _ := strings.Cut(ev.Test, "/")
+		r, ok := results[name]
+		if !ok {
+			r = &testResult{}
+			results[name] = r
+		}
+		switch ev.Action {
+		case "pass":
+			if ev.Test == name {
+				r.passed = true
+			}
+		case "output":
+			line := strings.TrimRight(ev.Output, "\n")
+			msg := ""
+			if m := messageRe.FindStringSubmatch(line); m != nil {
+				msg = m[1]
+			} else if strings.HasPrefix(line, "panic: ") {
+				msg = panicSuffixRe.ReplaceAllString(line, "")
+			}
+			if msg != "" && !slices.Contains(r.messages, msg) {
+				r.messages = append(r.messages, msg)
+			}
+		}
+	}
+	return results, build.String()
+}
+
+// testFileRe は隠しテストの位置（学習者には見えないので表示しない）
+var testFileRe = regexp.MustCompile(`^(\./)?` + regexp.QuoteMeta(TestFile) + `:\d+:\d+: `)
+
+// cleanBuildOutput はコンパイラの出力から、隠しテストの位置やgo testの定型行を取り除く
+func cleanBuildOutput(out string) string {
+	var lines []string
+	for _, l := range strings.Split(strings.TrimSpace(out), "\n") {
+		if strings.HasPrefix(l, "#") || strings.HasPrefix(l, "FAIL") {
+			continue
+		}
+		l = testFileRe.ReplaceAllString(l, "")
+		lines = append(lines, strings.TrimPrefix(l, "./"))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,92 @@
+package workbook
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MaskFile は期待出力と比べる前に置き換える値のルールを書くファイル名（解答例のディレクトリに置く）
+//
+// 1行に1ルールで「名前 正規表現」の形式で書く。#で始まる行は説明
+//
+//	# 作成日時はtime.Now()なので実行ごとに変わる
+//	TIME \d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}
+//	# 名前が#で終わると、値ごとに<TXID#1>, <TXID#2>と番号を振る（同じ値が同じ番号になることも確かめられる）
+//	TXID# tx_\d+
+const MaskFile = "expected_output.mask"
+
+// MaskRule は1つの置き換えルール
+type MaskRule struct {
+	Name     string
+	Pattern  *regexp.Regexp
+	Numbered bool
+}
+
+// Mask は出力中の実行ごとに変わる値を置き換えるルールの集まり
+type Mask []MaskRule
+
+// LoadMask はdirのMaskFileを読む。ファイルがなければ空のMaskを返す
+func LoadMask(dir string) (Mask, error) {
+	data, err := os.ReadFile(filepath.Join(dir, MaskFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ParseMask(data)
+}
+
+// ParseMask はMaskFileの形式のルールを読む
+func ParseMask(data []byte) (Mask, error) {
+	var mask Mask
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, pattern, ok := strings.Cut(text, " ")
+		pattern = strings.TrimSpace(pattern)
+		if !ok || pattern == "" {
+			return nil, fmt.Errorf("%s:%d: want \"NAME REGEXP\"", MaskFile, line)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", MaskFile, line, err)
+		}
+		rule := MaskRule{Name: name, Pattern: re}
+		if n, found := strings.CutSuffix(name, "#"); found {
+			rule.Name, rule.Numbered = n, true
+		}
+		mask = append(mask, rule)
+	}
+	return mask, sc.Err()
+}
+
+// Apply はsの中のルールに一致した部分を<名前>に置き換える
+func (m Mask) Apply(s string) string {
+	for _, rule := range m {
+		if !rule.Numbered {
+			s = rule.Pattern.ReplaceAllLiteralString(s, "<"+rule.Name+">")
+			continue
+		}
+		seen := make(map[string]int)
+		s = rule.Pattern.ReplaceAllStringFunc(s, func(v string) string {
+			n, ok := seen[v]
+			if !ok {
+				n = len(seen) + 1
+				seen[v] = n
+			}
+			return "<" + rule.Name + "#" + strconv.Itoa(n) + ">"
+		})
+	}
+	return s
+}
@@ -0,0 +1,335 @@
+package workbook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"workout/workbook/theme"
+)
+
+// ProgressFile は学習の進捗を記録するファイル（StateDirに置く）
+const ProgressFile = "progress.json"
+
+// ProgressStatus は1つのテーマ・レベルの進み具合
+type ProgressStatus string
+
+const (
+	ProgressAttempted ProgressStatus = "attempted" // 取り組み中（雛形を作った、または採点で要件を満たしていない）
+	ProgressPassing   ProgressStatus = "passing"   // 最後の採点で全ての要件を満たした
+	ProgressComplete  ProgressStatus = "complete"  // 解答を保存した
+)
+
+// ProgressStatuses は進み具合の一覧（進んでいる順）
+var ProgressStatuses = []ProgressStatus{ProgressAttempted, ProgressPassing, ProgressComplete}
+
+// ParseProgressStatus は文字列をProgressStatusにする
+func ParseProgressStatus(s string) (ProgressStatus, error) {
+	for _, st := range ProgressStatuses {
+		if string(st) == s {
+			return st, nil
+		}
+	}
+	return "", fmt.Errorf("unknown status %q (want attempted, passing or complete)", s)
+}
+
+func (s ProgressStatus) rank() int {
+	for i, st := range ProgressStatuses {
+		if st == s {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// LevelProgress は1つのテーマの1つのレベルの記録
+type LevelProgress struct {
+	Status      ProgressStatus `json:"status"`
+	Attempts    int            `json:"attempts"` // 採点した回数
+	StartedAt   time.Time      `json:"started_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	PassedAt    time.Time      `json:"passed_at,omitzero"`
+	CompletedAt time.Time      `json:"completed_at,omitzero"`
+}
+
+// Start は取り組み始めたことを記録する（既に合格・完了していれば状態は戻さない）
+func (l *LevelProgress) Start(now time.Time) {
+	if l.StartedAt.IsZero() {
+		l.StartedAt = now
+	}
+	if l.Status == "" {
+		l.Status = ProgressAttempted
+	}
+	l.UpdatedAt = now
+}
+
+// Graded は採点の結果を記録する。完了したものは不合格でも完了のまま
+func (l *LevelProgress) Graded(passed bool, now time.Time) {
+	l.Start(now)
+	l.Attempts++
+	switch {
+	case l.Status == ProgressComplete:
+	case passed:
+		l.Status = ProgressPassing
+	default:
+		l.Status = ProgressAttempted
+	}
+	if passed {
+		l.PassedAt = now
+	}
+}
+
+// Complete は解答を保存したことを記録する
+func (l *LevelProgress) Complete(now time.Time) {
+	l.Start(now)
+	l.Status = ProgressComplete
+	l.CompletedAt = now
+}
+
+// ThemeProgress は1つのテーマの記録
+type ThemeProgress struct {
+	Phase  int                      `json:"phase"`
+	Number int                      `json:"number"`
+	Name   string                   `json:"name,omitempty"` // "07-custom-errors"（テーマファイルがある場合）
+	Levels map[Level]*LevelProgress `json:"levels"`
+}
+
+// Status はレベルの中で最も進んでいる状態を返す
+func (t *ThemeProgress) Status() ProgressStatus {
+	var best ProgressStatus
+	for _, l := range t.Levels {
+		if l.Status.rank() > best.rank() {
+			best = l.Status
+		}
+	}
+	return best
+}
+
+// Attempts は全てのレベルの採点回数の合計を返す
+func (t *ThemeProgress) Attempts() int {
+	n := 0
+	for _, l := range t.Levels {
+		n += l.Attempts
+	}
+	return n
+}
+
+// UpdatedAt は最後に記録した日時を返す
+func (t *ThemeProgress) UpdatedAt() time.Time {
+	var last time.Time
+	for _, l := range t.Levels {
+		if l.UpdatedAt.After(last) {
+			last = l.UpdatedAt
+		}
+	}
+	return last
+}
+
+// Progress は学習者の進捗の記録
+type Progress struct {
+	Themes []*ThemeProgress `json:"themes"`
+}
+
+// Theme はフェーズphaseのテーマnumberの記録を返す（なければnil）
+func (p *Progress) Theme(phase, number int) *ThemeProgress {
+	for _, t := range p.Themes {
+		if t.Phase == phase && t.Number == number {
+			return t
+		}
+	}
+	return nil
+}
+
+// Level はテーマのレベルの記録を返す。なければ作る
+func (p *Progress) Level(phase, number int, name string, level Level) *LevelProgress {
+	t := p.Theme(phase, number)
+	if t == nil {
+		t = &ThemeProgress{Phase: phase, Number: number, Levels: make(map[Level]*LevelProgress)}
+		p.Themes = append(p.Themes, t)
+		sort.Slice(p.Themes, func(i, j int) bool {
+			a, b := p.Themes[i], p.Themes[j]
+			return a.Phase < b.Phase || (a.Phase == b.Phase && a.Number < b.Number)
+		})
+	}
+	if name != "" {
+		t.Name = name
+	}
+	l, ok := t.Levels[level]
+	if !ok {
+		l = &LevelProgress{}
+		t.Levels[level] = l
+	}
+	return l
+}
+
+// Reset はテーマのレベルの記録を消す
+func (p *Progress) Reset(phase, number int, level Level) {
+	t := p.Theme(phase, number)
+	if t == nil {
+		return
+	}
+	delete(t.Levels, level)
+	if len(t.Levels) == 0 {
+		p.Themes = slices.DeleteFunc(p.Themes, func(x *ThemeProgress) bool { return x == t })
+	}
+}
+
+// LoadProgress はpathの記録を読む（存在しなければ空の記録を返す）
+func LoadProgress(path string) (*Progress, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Progress{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var p Progress
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return &p, nil
+}
+
+// Save はpathに記録を書く。一時ファイルに書いてからrenameするので、途中で落ちても前の記録が残る
+func (p *Progress) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".progress-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+var (
+	phaseDirRe  = regexp.MustCompile(`^phase(\d+)-`)
+	themeNameRe = regexp.MustCompile(`^(\d+)-`)
+)
+
+// PhaseNumber は"phase3-backend-basic"のようなディレクトリ名からフェーズの番号を返す
+func PhaseNumber(dir string) (int, bool) {
+	m := phaseDirRe.FindStringSubmatch(filepath.Base(dir))
+	if m == nil {
+		return 0, false
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n, true
+}
+
+// ThemeNumber は"07-custom-errors"のようなテーマ名から番号を返す
+func ThemeNumber(name string) (int, bool) {
+	m := themeNameRe.FindStringSubmatch(name)
+	if m == nil {
+		return 0, false
+	}
+	n, _ := strconv.Atoi(m[1])
+	return n, true
+}
+
+// ParseThemeRef は"3/07"のような「フェーズ/テーマ」の指定を番号にする
+func ParseThemeRef(s string) (phase, number int, err error) {
+	p, n, ok := strings.Cut(s, "/")
+	if ok {
+		phase, err = strconv.Atoi(p)
+		if err == nil {
+			number, err = strconv.Atoi(n)
+		}
+	}
+	if !ok || err != nil || phase <= 0 || number <= 0 {
+		return 0, 0, fmt.Errorf("theme must be <phase>/<number> like 3/07: %q", s)
+	}
+	return phase, number, nil
+}
+
+func (w *Workspace) progressFile() string { return filepath.Join(w.Root, StateDir, ProgressFile) }
+
+// Progress は進捗の記録を読む
+func (w *Workspace) Progress() (*Progress, error) {
+	return LoadProgress(w.progressFile())
+}
+
+// RecordGrade は今取り組んでいる問題の採点結果を記録する
+func (w *Workspace) RecordGrade(passed bool) error {
+	cur, err := w.Current()
+	if err != nil {
+		return err
+	}
+	return w.record(cur.Theme, cur.Level, func(l *LevelProgress, now time.Time) { l.Graded(passed, now) })
+}
+
+// Mark は進捗を手で記録する（Goの演習以外のフェーズや、workbookを使わずに解いた問題のため）
+func (w *Workspace) Mark(phase, number int, level Level, status ProgressStatus) error {
+	p, err := w.Progress()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	l := p.Level(phase, number, w.themeName(phase, number), level)
+	l.Start(now)
+	l.Status = status
+	switch status {
+	case ProgressPassing:
+		l.PassedAt = now
+	case ProgressComplete:
+		l.CompletedAt = now
+	}
+	return p.Save(w.progressFile())
+}
+
+// Unmark はテーマのレベルの記録を消す
+func (w *Workspace) Unmark(phase, number int, level Level) error {
+	p, err := w.Progress()
+	if err != nil {
+		return err
+	}
+	p.Reset(phase, number, level)
+	return p.Save(w.progressFile())
+}
+
+// record はGoのテーマname（"07-custom-errors"）のlevelの記録をfnで更新して保存する
+func (w *Workspace) record(name string, level Level, fn func(l *LevelProgress, now time.Time)) error {
+	phase, _ := PhaseNumber(ThemesDir)
+	number, ok := ThemeNumber(name)
+	if !ok {
+		return fmt.Errorf("theme name must start with a number: %q", name)
+	}
+	p, err := w.Progress()
+	if err != nil {
+		return err
+	}
+	fn(p.Level(phase, number, name, level), time.Now())
+	return p.Save(w.progressFile())
+}
+
+// themeName はフェーズphaseのテーマnumberのファイル名（拡張子なし）を返す。テーマファイルがなければ""
+func (w *Workspace) themeName(phase, number int) string {
+	dirs, _ := filepath.Glob(filepath.Join(w.Root, fmt.Sprintf("phase%d-*", phase)))
+	for _, dir := range dirs {
+		if path, err := theme.Find(dir, number); err == nil {
+			return strings.TrimSuffix(filepath.Base(path), ".md")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,123 @@
+package workbook
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Diff はwantとgotを行ごとに比べ、違いがあれば"-期待 / +実際"の形式で返す（同じなら空文字）
+// 解答例の出力は数十行程度なので、行番号を揃えて比べるだけの単純な差分にしている
+func Diff(want, got string) string {
+	if want == got {
+		return ""
+	}
+	wl := splitLines(want)
+	gl := splitLines(got)
+	var sb strings.Builder
+	for i := range max(len(wl), len(gl)) {
+		var w, g string
+		var hasW, hasG bool
+		if i < len(wl) {
+			w, hasW = wl[i], true
+		}
+		if i < len(gl) {
+			g, hasG = gl[i], true
+		}
+		if hasW && hasG && w == g {
+			continue
+		}
+		if hasW {
+			fmt.Fprintf(&sb, "%4d - %s\n", i+1, w)
+		}
+		if hasG {
+			fmt.Fprintf(&sb, "%4d + %s\n", i+1, g)
+		}
+	}
+	if sb.Len() == 0 {
+		// 行の内容は同じで末尾の改行だけが違う
+		return "     (trailing newline differs)\n"
+	}
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// Summary は結果の件数をStatusごとに数える
+type Summary struct {
+	Total  int
+	Counts map[Status]int
+}
+
+// Failed は失敗扱いの件数を返す
+func (s Summary) Failed() int {
+	n := 0
+	for st, c := range s.Counts {
+		if !st.OK() {
+			n += c
+		}
+	}
+	return n
+}
+
+func Summarize(results []Result) Summary {
+	s := Summary{Total: len(results), Counts: make(map[Status]int)}
+	for _, r := range results {
+		s.Counts[r.Status]++
+	}
+	return s
+}
+
+// WriteReport は結果の一覧表と、失敗したものの詳細をwに書く
+// verboseなら成功したものの出力も書く
+func WriteReport(w io.Writer, results []Result, verbose bool) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SOLUTION\tSTATUS\tTIME")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Solution.Name(), r.Status, r.Duration.Round(time.Millisecond))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		if r.Status.OK() && !verbose {
+			continue
+		}
+		fmt.Fprintf(w, "\n--- %s: %s\n", r.Status, r.Solution.Name())
+		switch {
+		case r.Diff != "":
+			fmt.Fprint(w, r.Diff)
+		case r.Status.OK():
+			fmt.Fprint(w, indent(r.Stdout))
+		}
+		if r.Stderr != "" && !r.Status.OK() {
+			fmt.Fprint(w, indent(r.Stderr))
+		}
+	}
+
+	s := Summarize(results)
+	var parts []string
+	for _, st := range []Status{StatusPass, StatusFail, StatusBuildError, StatusRunError, StatusTimeout, StatusNoGolden, StatusUpdated} {
+		if c := s.Counts[st]; c > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", c, strings.ToLower(string(st))))
+		}
+	}
+	_, err := fmt.Fprintf(w, "\n%d solutions: %s\n", s.Total, strings.Join(parts, ", "))
+	return err
+}
+
+func indent(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "    " + strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\n", "\n    ") + "\n"
+}
@@ -0,0 +1,315 @@
+package workbook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GoldenFile は解答例の期待出力を保存するファイル名（解答例のディレクトリに置く）
+const GoldenFile = "expected_output.txt"
+
+// Status は1つの解答例のチェック結果
+type Status string
+
+const (
+	StatusPass       Status = "PASS"
+	StatusFail       Status = "FAIL"        // 出力が期待と違う
+	StatusBuildError Status = "BUILD ERROR" // コンパイルできない
+	StatusRunError   Status = "RUN ERROR"   // 終了コードが0以外
+	StatusTimeout    Status = "TIMEOUT"
+	StatusNoGolden   Status = "NO GOLDEN" // 実行はできたが比べる期待出力がない
+	StatusUpdated    Status = "UPDATED"   // 期待出力を書き直した
+)
+
+// OK は失敗扱いにしない結果かを返す
+func (s Status) OK() bool {
+	return s == StatusPass || s == StatusNoGolden || s == StatusUpdated
+}
+
+// Result は1つの解答例をビルド・実行した結果
+type Result struct {
+	Solution Solution
+	Status   Status
+	Stdout   string // MaskFileのルールで置き換えた後の標準出力
+	Stderr   string // ビルドエラーの場合はコンパイラの出力
+	Diff     string // FAILの場合の差分
+	Duration time.Duration
+}
+
+// Runner は解答例を一時ディレクトリにコピーしてビルド・実行する
+// 解答例のディレクトリには何も書き込まないので、実行中のファイル操作がリポジトリを汚さない
+type Runner struct {
+	GoBin   string        // goコマンドのパス（空なら"go"）
+	Timeout time.Duration // ビルドと実行それぞれの期限（0なら30秒）
+	// Module はworkoutモジュールのディレクトリ
+	// ModulePathのパッケージをimportする解答例は、一時的なモジュールからここを参照してビルドする
+	Module string
+}
+
+// Run はsolをビルド・実行し、期待出力と比べる
+func (r *Runner) Run(ctx context.Context, sol Solution) Result {
+	start := time.Now()
+	res := r.run(ctx, sol)
+	res.Solution = sol
+	res.Duration = time.Since(start)
+	return res
+}
+
+func (r *Runner) run(ctx context.Context, sol Solution) Result {
+	mask, err := LoadMask(sol.Dir)
+	if err != nil {
+		return Result{Status: StatusFail, Diff: err.Error()}
+	}
+	stdout, stderr, status, err := r.Execute(ctx, sol)
+	stdout = mask.Apply(stdout)
+	if err != nil {
+		return Result{Status: status, Stdout: stdout, Stderr: stderr}
+	}
+	res := Result{Status: StatusPass, Stdout: stdout, Stderr: stderr}
+
+	want, err := os.ReadFile(filepath.Join(sol.Dir, GoldenFile))
+	if errors.Is(err, os.ErrNotExist) {
+		res.Status = StatusNoGolden
+		return res
+	}
+	if err != nil {
+		res.Status = StatusFail
+		res.Diff = err.Error()
+		return res
+	}
+	if diff := Diff(string(want), stdout); diff != "" {
+		res.Status = StatusFail
+		res.Diff = diff
+	}
+	return res
+}
+
+// UpdateGolden はsolを実行し、置き換え後の出力をGoldenFileに書く
+// ビルド・実行に失敗した場合は書かずにその結果を返す
+func (r *Runner) UpdateGolden(ctx context.Context, sol Solution) Result {
+	start := time.Now()
+	res := r.updateGolden(ctx, sol)
+	res.Solution = sol
+	res.Duration = time.Since(start)
+	return res
+}
+
+func (r *Runner) updateGolden(ctx context.Context, sol Solution) Result {
+	res := Result{Status: StatusUpdated}
+	mask, err := LoadMask(sol.Dir)
+	if err != nil {
+		res.Status, res.Diff = StatusFail, err.Error()
+		return res
+	}
+	stdout, stderr, status, err := r.Execute(ctx, sol)
+	res.Stdout, res.Stderr = mask.Apply(stdout), stderr
+	if err != nil {
+		res.Status = status
+		return res
+	}
+	if err := os.WriteFile(filepath.Join(sol.Dir, GoldenFile), []byte(res.Stdout), 0o644); err != nil {
+		res.Status, res.Diff = StatusFail, err.Error()
+	}
+	return res
+}
+
+// Execute はsolをビルド・実行して標準出力と標準エラーを返す
+// sol.Testならテストのバイナリをビルドして、go test -vと同じ出力で実行する
+// 失敗した場合はどの段階で失敗したかをStatusで返す
+func (r *Runner) Execute(ctx context.Context, sol Solution) (stdout, stderr string, status Status, err error) {
+	tmp, err := os.MkdirTemp("", "workbook-*")
+	if err != nil {
+		return "", "", StatusBuildError, err
+	}
+	defer os.RemoveAll(tmp)
+
+	copyFn := CopyGoFiles
+	if sol.Test {
+		copyFn = copyPackageFiles
+	}
+	if err := copyFn(sol.Dir, tmp); err != nil {
+		return "", err.Error(), StatusBuildError, err
+	}
+	// 解答例はgo.modを持たない単体のプログラムなので、一時的なモジュールとしてビルドする
+	if err := WriteGoMod(tmp, "solution", r.Module); err != nil {
+		return "", err.Error(), StatusBuildError, err
+	}
+
+	bin := filepath.Join(tmp, "solution")
+	build := []string{"build", "-o", bin, "."}
+	if sol.Test {
+		build = []string{"test", "-c", "-o", bin, "."}
+	}
+	// 依存パッケージを取りに行かない（標準ライブラリとworkoutモジュールだけで動くはず）
+	buildEnv := append(os.Environ(), "GOFLAGS=-mod=mod", "GOPROXY=off", "GOWORK=off")
+	if out, status, err := r.exec(ctx, tmp, buildEnv, StatusBuildError, r.goBin(), build...); err != nil {
+		return "", out.stderr + out.stdout, status, err
+	}
+
+	work := filepath.Join(tmp, "work")
+	if err := os.Mkdir(work, 0o755); err != nil {
+		return "", err.Error(), StatusRunError, err
+	}
+	// 実行環境の違いで出力が変わらないよう、最小限の環境変数だけ渡す
+	runEnv := []string{"HOME=" + work, "TMPDIR=" + work, "TZ=UTC", "LANG=C.UTF-8", "PATH=" + os.Getenv("PATH")}
+	if !sol.Test {
+		out, status, err := r.exec(ctx, work, runEnv, StatusRunError, bin)
+		return out.stdout, out.stderr, status, err
+	}
+	// go testと同じく、パッケージのディレクトリ（コピー先）で実行する。テストが失敗すると終了コードが1になる
+	out, status, err := r.exec(ctx, tmp, runEnv, StatusRunError, bin, "-test.v")
+	return elapsedRe.ReplaceAllString(out.stdout, ""), out.stderr, status, err
+}
+
+// elapsedRe はgo test -vの「--- PASS: TestXxx (0.00s)」の実行時間に一致する（実行ごとに変わるので消す）
+var elapsedRe = regexp.MustCompile(`(?m) \(\d+\.\d+s\)$`)
+
+// WriteGoMod はdirに一時的なモジュールnameのgo.modを書く
+// dirのファイルがModulePathのパッケージをimportしていれば、module（workoutモジュールのディレクトリ）への
+// replaceを加え、go.sumもコピーする
+func WriteGoMod(dir, name, module string) error {
+	gomod := "module " + name + "\n\ngo 1.24\n"
+	uses, err := importsModule(dir, ModulePath)
+	if err != nil {
+		return err
+	}
+	if uses {
+		if module == "" {
+			return fmt.Errorf("%s imports %s but the module directory is not set", name, ModulePath)
+		}
+		module, err := filepath.Abs(module)
+		if err != nil {
+			return err
+		}
+		gomod += fmt.Sprintf("\nrequire %s v0.0.0\n\nreplace %s => %s\n", ModulePath, ModulePath, module)
+		sum, err := os.ReadFile(filepath.Join(module, "go.sum"))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, "go.sum"), sum, 0o644); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0o644)
+}
+
+// importsModule はdirの.goファイルがmodule（またはその下のパッケージ）をimportしているかを返す
+func importsModule(dir, module string) (bool, error) {
+	files, err := packageFiles(dir)
+	if err != nil {
+		return false, err
+	}
+	fset := token.NewFileSet()
+	for _, name := range files {
+		f, err := parser.ParseFile(fset, name, nil, parser.ImportsOnly)
+		if err != nil {
+			// 構文エラーはビルドで報告する
+			continue
+		}
+		for _, imp := range f.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			if path == module || strings.HasPrefix(path, module+"/") {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+type output struct {
+	stdout, stderr string
+}
+
+// exec はdirでコマンドを期限付きで実行する。失敗したら期限切れならStatusTimeout、それ以外はfailedを返す
+func (r *Runner) exec(ctx context.Context, dir string, env []string, failed Status, name string, args ...string) (output, Status, error) {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, name, args...)
+	c.Dir, c.Env = dir, env
+	c.Stdout, c.Stderr = &stdout, &stderr
+	// 子プロセスが出力を握ったまま残っても待ち続けない
+	c.WaitDelay = time.Second
+
+	err := c.Run()
+	out := output{stdout: stdout.String(), stderr: stderr.String()}
+	if err == nil {
+		return out, "", nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return out, StatusTimeout, fmt.Errorf("timed out after %s", timeout)
+	}
+	return out, failed, err
+}
+
+func (r *Runner) goBin() string {
+	if r.GoBin != "" {
+		return r.GoBin
+	}
+	return "go"
+}
+
+// CopyGoFiles はsrcの.goファイル（_test.goを除く）をdstにコピーする
+func CopyGoFiles(src, dst string) error {
+	files, err := goFiles(src)
+	if err != nil {
+		return err
+	}
+	return copyFiles(files, dst)
+}
+
+// copyPackageFiles はsrcの.goファイルを_test.goも含めてdstにコピーする
+func copyPackageFiles(src, dst string) error {
+	files, err := packageFiles(src)
+	if err != nil {
+		return err
+	}
+	return copyFiles(files, dst)
+}
+
+// RunAll はsolutionsを最大parallel個ずつ並行にRunし、渡した順に結果を返す
+func (r *Runner) RunAll(ctx context.Context, solutions []Solution, parallel int) []Result {
+	return r.each(ctx, solutions, parallel, r.Run)
+}
+
+// UpdateAll はsolutionsを最大parallel個ずつ並行にUpdateGoldenする
+func (r *Runner) UpdateAll(ctx context.Context, solutions []Solution, parallel int) []Result {
+	return r.each(ctx, solutions, parallel, r.UpdateGolden)
+}
+
+func (r *Runner) each(ctx context.Context, solutions []Solution, parallel int, fn func(context.Context, Solution) Result) []Result {
+	if parallel <= 0 {
+		parallel = 1
+	}
+	results := make([]Result, len(solutions))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, sol := range solutions {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = fn(ctx, sol)
+		}()
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,312 @@
+package workbook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"workout/workbook/gocheck"
+	"workout/workbook/theme"
+)
+
+// リポジトリのルートから見た作業場所
+const (
+	// ThemesDir はGoのテーマファイルの置き場所
+	ThemesDir = "phase3-backend-basic"
+	// ExerciseDir は演習問題を解くディレクトリ（workoutモジュールの中なので go run ./exercise で動かせる）
+	ExerciseDir = "environments/backend/workspace/exercise"
+	// StateDir は今取り組んでいる問題とバックアップの置き場所（.で始まるのでgoのビルド対象にならない）
+	StateDir = "environments/backend/workspace/.workbook"
+)
+
+// ErrNoCurrent はworkbook startをまだ実行していない場合のエラー
+var ErrNoCurrent = errors.New("no exercise in progress (run: workbook start <theme>)")
+
+// Current は今取り組んでいる問題
+type Current struct {
+	Theme     string    `json:"theme"` // "07-custom-errors"
+	Level     Level     `json:"level"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+// Workspace は演習問題の作業場所を管理する
+type Workspace struct {
+	Root string // 問題集のルート
+}
+
+func (w *Workspace) exerciseDir() string { return filepath.Join(w.Root, ExerciseDir) }
+func (w *Workspace) stateFile() string   { return filepath.Join(w.Root, StateDir, "current.json") }
+
+// Start はテーマnumberの演習問題の雛形を作業場所に書く
+// 作業場所に前の問題のファイルが残っていれば、バックアップに移してからにする（バックアップ先を返す）
+// levelは進捗の記録とSaveの保存先に使う
+func (w *Workspace) Start(number int, level Level) (backup string, err error) {
+	path, err := theme.Find(filepath.Join(w.Root, ThemesDir), number)
+	if err != nil {
+		return "", err
+	}
+	t, err := theme.ParseFile(path)
+	if err != nil {
+		return "", err
+	}
+	src, err := Skeleton(t)
+	if err != nil {
+		return "", err
+	}
+
+	if backup, err = w.backup(); err != nil {
+		return "", err
+	}
+	dir := w.exerciseDir()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return backup, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), src, 0o644); err != nil {
+		return backup, err
+	}
+
+	cur := Current{Theme: strings.TrimSuffix(filepath.Base(path), ".md"), Level: level, StartedAt: time.Now()}
+	data, err := json.MarshalIndent(cur, "", "  ")
+	if err != nil {
+		return backup, err
+	}
+	if err := os.MkdirAll(filepath.Dir(w.stateFile()), 0o755); err != nil {
+		return backup, err
+	}
+	if err := os.WriteFile(w.stateFile(), append(data, '\n'), 0o644); err != nil {
+		return backup, err
+	}
+	return backup, w.record(cur.Theme, level, (*LevelProgress).Start)
+}
+
+// Current は今取り組んでいる問題を返す
+func (w *Workspace) Current() (*Current, error) {
+	data, err := os.ReadFile(w.stateFile())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoCurrent
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cur Current
+	if err := json.Unmarshal(data, &cur); err != nil {
+		return nil, fmt.Errorf("read %s: %w", w.stateFile(), err)
+	}
+	return &cur, nil
+}
+
+// ErrNotPassed は採点で満たせていない要件があるので解答を保存しない場合のエラー
+var ErrNotPassed = errors.New("the exercise does not pass the grader yet (see the report above, or run: workbook grade)")
+
+// GradeFunc はテーマtheme（"07-custom-errors"）の演習問題をdirのコードで採点し、全ての要件を満たしたかを返す
+type GradeFunc func(theme, dir string) (passed bool, err error)
+
+// Save は作業場所のGoファイル（_test.goを含む）をgradeで採点し、合格したらsolutions/<theme>/<level>/にコピーしてコピー先を返す
+// 採点の結果は進捗に記録し、完了にするのは合格した場合だけ。既に解答があれば、forceでなければ上書きしない
+// gradeがnil（採点のないテーマ）ならコピーだけして、完了は記録しない
+func (w *Workspace) Save(force bool, grade GradeFunc) (string, error) {
+	cur, err := w.Current()
+	if err != nil {
+		return "", err
+	}
+	files, err := packageFiles(w.exerciseDir())
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no .go files in %s", w.exerciseDir())
+	}
+
+	dest := filepath.Join(w.Root, SolutionsDir, cur.Theme, string(cur.Level))
+	if (isFile(filepath.Join(dest, "main.go")) || hasTestFiles(dest)) && !force {
+		return "", fmt.Errorf("%s already exists (use -force to overwrite)", dest)
+	}
+	complete := false
+	if grade != nil {
+		passed, err := grade(cur.Theme, w.exerciseDir())
+		if err != nil {
+			return "", err
+		}
+		if err := w.RecordGrade(passed); err != nil {
+			return "", err
+		}
+		if !passed {
+			return "", ErrNotPassed
+		}
+		complete = true
+	}
+
+	if err := os.MkdirAll(dest, 0o755); err != nil {
+		return "", err
+	}
+	if err := copyFiles(files, dest); err != nil {
+		return "", err
+	}
+	if !complete {
+		return dest, nil
+	}
+	return dest, w.record(cur.Theme, cur.Level, (*LevelProgress).Complete)
+}
+
+// backup は作業場所にファイルがあれば.workbook/backups/<日時>-<テーマ>/に移す
+func (w *Workspace) backup() (string, error) {
+	dir := w.exerciseDir()
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(entries) == 0) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	name := time.Now().Format("20060102-150405")
+	if cur, err := w.Current(); err == nil {
+		name += "-" + cur.Theme + "-" + string(cur.Level)
+	}
+	backup := filepath.Join(w.Root, StateDir, "backups", name)
+	if err := os.MkdirAll(filepath.Dir(backup), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(dir, backup); err != nil {
+		return "", fmt.Errorf("back up %s: %w", dir, err)
+	}
+	return backup, nil
+}
+
+// Skeleton はテーマの演習問題から、要件をコメントにした雛形のmain.goを作る
+// 雛形はレベルによらず同じ（レベルは解答の保存先を決めるだけ）
+// ヒントの骨格コードは、そのままでもビルドできるよう戻り値のない関数にpanic("TODO")を補う
+func Skeleton(t *theme.Theme) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// %02d. %s - 演習問題\n", t.Number, t.Title)
+	ex := t.Exercise
+	if ex.Intro != "" {
+		b.WriteString("//\n")
+		writeComment(&b, ex.Intro, "")
+	}
+	if len(ex.Requirements) > 0 {
+		b.WriteString("//\n// 要件:\n")
+		for i, r := range ex.Requirements {
+			writeComment(&b, r, fmt.Sprintf("  %d. ", i+1))
+		}
+	}
+	if len(ex.Expected) > 0 {
+		b.WriteString("//\n// 期待される動作:\n")
+		for _, e := range ex.Expected {
+			writeComment(&b, e, "  - ")
+		}
+	}
+	b.WriteString("package main\n\n")
+
+	for _, h := range ex.Hints {
+		if h.Lang != "go" {
+			continue
+		}
+		b.WriteString(strings.TrimSpace(h.Code))
+		b.WriteString("\n\n")
+	}
+	b.WriteString("func main() {\n\t// 要件を満たしているかを確かめるコードを書く\n}\n")
+
+	src := gocheck.AddImports(b.String())
+	src, err := fillMissingReturns(src)
+	if err != nil {
+		return nil, fmt.Errorf("skeleton for theme %02d: %w", t.Number, err)
+	}
+	out, err := format.Source([]byte(src))
+	if err != nil {
+		return nil, fmt.Errorf("skeleton for theme %02d: %w", t.Number, err)
+	}
+	return out, nil
+}
+
+func writeComment(b *bytes.Buffer, text, prefix string) {
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 && prefix != "" {
+			prefix = strings.Repeat(" ", len(prefix))
+		}
+		fmt.Fprintf(b, "// %s%s\n", prefix, line)
+	}
+}
+
+// fillMissingReturns は戻り値があるのにreturnのない関数の末尾にpanic("TODO")を足す
+func fillMissingReturns(src string) (string, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", src, parser.ParseComments)
+	if err != nil {
+		return "", err
+	}
+	var offsets []int
+	for _, d := range f.Decls {
+		fn, ok := d.(*ast.FuncDecl)
+		if !ok || fn.Body == nil || fn.Type.Results == nil || len(fn.Type.Results.List) == 0 {
+			continue
+		}
+		if n := len(fn.Body.List); n > 0 && terminates(fn.Body.List[n-1]) {
+			continue
+		}
+		offsets = append(offsets, fset.Position(fn.Body.Rbrace).Offset)
+	}
+	// 後ろから挿入して、前のオフセットがずれないようにする
+	sort.Sort(sort.Reverse(sort.IntSlice(offsets)))
+	for _, off := range offsets {
+		src = src[:off] + "\tpanic(\"TODO: 実装する\")\n" + src[off:]
+	}
+	return src, nil
+}
+
+func terminates(s ast.Stmt) bool {
+	switch s := s.(type) {
+	case *ast.ReturnStmt:
+		return true
+	case *ast.ExprStmt:
+		call, ok := s.X.(*ast.CallExpr)
+		if !ok {
+			return false
+		}
+		id, ok := call.Fun.(*ast.Ident)
+		return ok && id.Name == "panic"
+	}
+	return false
+}
+
+func goFiles(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	var list []string
+	for _, f := range files {
+		if !strings.HasSuffix(f, "_test.go") {
+			list = append(list, f)
+		}
+	}
+	return list, nil
+}
+
+// packageFiles はdirの.goファイルを_test.goも含めて返す
+func packageFiles(dir string) ([]string, error) {
+	return filepath.Glob(filepath.Join(dir, "*.go"))
+}
+
+func copyFiles(files []string, dst string) error {
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dst, filepath.Base(f)), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// Package workbook は問題集（テーマのmdと解答例）を扱うツールの共通部分
+package workbook
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"workout/workbook/theme"
+)
+
+// SolutionsDir はリポジトリのルートから見たGoの解答例の置き場所
+const SolutionsDir = "solutions/phase3-backend-basic"
+
+// ModuleDir はリポジトリのルートから見たworkoutモジュールの場所
+// 解答例はworkout/lessons/...などをimportできる（Runner.Moduleを参照）
+const (
+	ModuleDir  = "environments/backend/workspace"
+	ModulePath = "workout"
+)
+
+// Level は演習の難易度
+type Level string
+
+const (
+	LevelBasic    Level = "basic"
+	LevelApplied  Level = "applied"
+	LevelAdvanced Level = "advanced"
+)
+
+// Levels は難易度を易しい順に並べたもの
+var Levels = []Level{LevelBasic, LevelApplied, LevelAdvanced}
+
+// ParseLevel は"basic"などの文字列をLevelに変換する
+func ParseLevel(s string) (Level, error) {
+	for _, l := range Levels {
+		if string(l) == s {
+			return l, nil
+		}
+	}
+	return "", fmt.Errorf("unknown level %q (want basic, applied or advanced)", s)
+}
+
+// Solution は1つの解答例ディレクトリ（main.goか_test.goを含む）
+type Solution struct {
+	Theme string // "07-custom-errors" など
+	Level Level
+	Dir   string // 絶対パス
+	// Test は解答例がテストのパッケージであること（31-table-driven-testsなど）
+	// mainを実行する代わりにgo test -vを実行し、その出力を期待出力と比べる
+	Test bool
+}
+
+// Name は"07-custom-errors/basic"の形式の名前を返す
+func (s Solution) Name() string {
+	return s.Theme + "/" + string(s.Level)
+}
+
+// FindRoot はstartから親ディレクトリを遡って、問題集のルートを探す
+// TEMPLATE.mdとsolutions/があるディレクトリをルートとみなす
+func FindRoot(start string) (string, error) {
+	dir, err := filepath.Abs(start)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if isFile(filepath.Join(dir, "TEMPLATE.md")) && isDir(filepath.Join(dir, "solutions")) {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("workbook root not found from %s (use -root)", start)
+		}
+		dir = parent
+	}
+}
+
+// DiscoverSolutions はroot配下の全ての解答例をテーマ・難易度の順に返す
+func DiscoverSolutions(root string) ([]Solution, error) {
+	themes, err := os.ReadDir(filepath.Join(root, SolutionsDir))
+	if err != nil {
+		return nil, fmt.Errorf("discover solutions: %w", err)
+	}
+	var list []Solution
+	for _, t := range themes {
+		if !t.IsDir() {
+			continue
+		}
+		for _, level := range Levels {
+			dir := filepath.Join(root, SolutionsDir, t.Name(), string(level))
+			test := hasTestFiles(dir)
+			if test || isFile(filepath.Join(dir, "main.go")) {
+				list = append(list, Solution{Theme: t.Name(), Level: level, Dir: dir, Test: test})
+			}
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Theme < list[j].Theme })
+	return list, nil
+}
+
+// ThemeFiles はroot配下の全てのフェーズのテーマファイル（phase*/NN-*.md）を返す
+func ThemeFiles(root string) ([]string, error) {
+	phases, err := filepath.Glob(filepath.Join(root, "phase*"))
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, dir := range phases {
+		if !isDir(dir) {
+			continue
+		}
+		list, err := theme.List(dir)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, list...)
+	}
+	return files, nil
+}
+
+// FilterSolutions はpatternsのいずれかに一致する解答例だけを返す（patternsが空なら全て）
+// パターンは"07"のようなテーマ番号の前方一致か、"07/basic"のようにレベルまで指定する
+func FilterSolutions(list []Solution, patterns []string) []Solution {
+	if len(patterns) == 0 {
+		return list
+	}
+	var out []Solution
+	for _, s := range list {
+		for _, p := range patterns {
+			theme, level, _ := strings.Cut(p, "/")
+			if strings.HasPrefix(s.Theme, theme) && (level == "" || Level(level) == s.Level) {
+				out = append(out, s)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func isFile(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.Mode().IsRegular()
+}
+
+func hasTestFiles(dir string) bool {
+	files, _ := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	return len(files) > 0
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
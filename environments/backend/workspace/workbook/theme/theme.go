@@ -0,0 +1,399 @@
+// Package theme はTEMPLATE.mdの形式で書かれたテーマのmdファイルを読み、構造化したモデルにする
+package theme
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TEMPLATE.mdで決められている見出し（##）
+const (
+	SectionGoals     = "🎯 このテーマで学ぶこと"
+	SectionWhy       = "📖 なぜ" // 「なぜ{テーマ}を理解する必要があるのか」の前方一致
+	SectionExamples  = "💡 コード例"
+	SectionExercise  = "🎯 演習問題"
+	SectionKeyPoints = "✅ 重要ポイント"
+
+	// HeadingBadExample は📖の中で悪い例のコードを載せる見出し（###）
+	HeadingBadExample = "こう書かないとどうなるか"
+)
+
+// Sections は必須の見出しをTEMPLATE.mdの順に並べたもの
+var Sections = []string{SectionGoals, SectionWhy, SectionExamples, SectionExercise, SectionKeyPoints}
+
+// CodeKind はコードブロックがテーマの中で果たす役割
+type CodeKind string
+
+const (
+	KindBadExample CodeKind = "bad"     // 📖「こう書かないとどうなるか」の悪い例
+	KindBasic      CodeKind = "basic"   // 💡「基本:」のコード例
+	KindApplied    CodeKind = "applied" // 💡「実践:」のコード例
+	KindHint       CodeKind = "hint"    // 🎯演習問題のヒント（骨格コード）
+	KindOther      CodeKind = "other"
+)
+
+// CodeBlock はフェンス（```）で囲まれたコード
+type CodeBlock struct {
+	Lang    string   `json:"lang"`
+	Code    string   `json:"code"`
+	Line    int      `json:"line"` // 開始フェンスの行番号（1始まり）
+	Kind    CodeKind `json:"kind"`
+	Section string   `json:"section"` // 属する##見出し
+	Heading string   `json:"heading"` // 属する###見出し（なければ空）
+	// Annotation は直前の<!-- ... -->コメントの中身（表示されない、ツール向けの注記）
+	Annotation string `json:"annotation,omitempty"`
+}
+
+// Example は💡コード例の1つ（基本・実践）
+type Example struct {
+	Kind        CodeKind   `json:"kind"` // KindBasicかKindApplied
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Code        *CodeBlock `json:"code,omitempty"`
+	Line        int        `json:"line"`
+}
+
+// Exercise は🎯演習問題
+type Exercise struct {
+	Intro        string      `json:"intro"`
+	Requirements []string    `json:"requirements"`
+	Expected     []string    `json:"expected,omitempty"` // 期待される動作
+	Hints        []CodeBlock `json:"hints,omitempty"`
+	Line         int         `json:"line"`
+}
+
+// KeyPoint は✅重要ポイントのチェックリストの1項目
+type KeyPoint struct {
+	Text    string `json:"text"`
+	Checked bool   `json:"checked"`
+}
+
+// Link は次のテーマへのリンク
+type Link struct {
+	Text   string `json:"text"`
+	Target string `json:"target"`
+	Line   int    `json:"line"`
+}
+
+// Heading はファイル中の見出し
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	Line  int    `json:"line"`
+}
+
+// Theme は1つのテーマファイルの内容
+type Theme struct {
+	Path      string      `json:"path"`
+	Number    int         `json:"number"`
+	Title     string      `json:"title"`
+	Subtitle  string      `json:"subtitle,omitempty"`
+	Goals     []string    `json:"goals"`
+	Why       string      `json:"why"` // 📖見出しの本文（悪い例のコードを除く）
+	Examples  []Example   `json:"examples"`
+	Exercise  Exercise    `json:"exercise"`
+	KeyPoints []KeyPoint  `json:"key_points"`
+	Next      *Link       `json:"next,omitempty"`
+	Code      []CodeBlock `json:"code"` // ファイル中の全てのコードブロック（出現順）
+	Headings  []Heading   `json:"headings"`
+}
+
+// CodeOf はkindのコードブロックだけを返す
+func (t *Theme) CodeOf(kind CodeKind) []CodeBlock {
+	var list []CodeBlock
+	for _, c := range t.Code {
+		if c.Kind == kind {
+			list = append(list, c)
+		}
+	}
+	return list
+}
+
+// Example はkindのコード例を返す（なければnil）
+func (t *Theme) Example(kind CodeKind) *Example {
+	for i := range t.Examples {
+		if t.Examples[i].Kind == kind {
+			return &t.Examples[i]
+		}
+	}
+	return nil
+}
+
+var (
+	titleRe    = regexp.MustCompile(`^(\d+)\.\s*(.+?)(?:\s+-\s+(.+))?$`)
+	fileRe     = regexp.MustCompile(`^(\d{2})-[a-z0-9-]+\.md$`)
+	numberedRe = regexp.MustCompile(`^\d+\.\s+(.+)$`)
+	checkRe    = regexp.MustCompile(`^- \[([ xX])\]\s+(.+)$`)
+	// 「**次のテーマ:** [02. ポインタの基本](./02-pointer-basics.md)」のような、番号付きテーマへのリンク
+	themeLinkRe  = regexp.MustCompile(`\[(\d{2}\.[^\]]*)\]\((\.?/?[^)]*\d{2}-[^)]+\.md)\)`)
+	annotationRe = regexp.MustCompile(`^<!--\s*(.*?)\s*-->$`)
+)
+
+// ParseFile はpathのテーマファイルを読む
+func ParseFile(path string) (*Theme, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(path, f)
+}
+
+// Parse はrからテーマを読む。pathはエラー表示とリンク先の確認に使う
+// 形式が崩れていてもエラーにはせず、読めた部分だけを返す（崩れはValidateで報告する）
+func Parse(path string, r io.Reader) (*Theme, error) {
+	t := &Theme{Path: path}
+	p := parser{t: t}
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		p.line++
+		p.handle(sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if p.fence != nil {
+		// 閉じていないフェンスもコードとして扱う
+		p.closeFence()
+	}
+	p.flushText()
+	return t, nil
+}
+
+// parser は1行ずつ読みながら、今どの見出しの中にいるかを追跡する
+type parser struct {
+	t    *Theme
+	line int
+
+	section string // 今の##見出し（Sectionsのいずれか、または見出しそのもの）
+	heading string // 今の###見出し
+	field   string // 演習問題の中の「**要件:**」などの小見出し
+
+	fence      *CodeBlock
+	fenceLines []string
+	annotation string // 次のコードブロックに付ける注記
+
+	text []string // 見出しの後、次の見出し・コードまでの本文
+}
+
+func (p *parser) handle(line string) {
+	if p.fence != nil {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			p.closeFence()
+			return
+		}
+		p.fenceLines = append(p.fenceLines, line)
+		return
+	}
+
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(trimmed, "```"):
+		p.flushText()
+		p.fence = &CodeBlock{
+			Lang:       strings.TrimSpace(strings.TrimPrefix(trimmed, "```")),
+			Line:       p.line,
+			Kind:       p.codeKind(),
+			Section:    p.section,
+			Heading:    p.heading,
+			Annotation: p.annotation,
+		}
+		p.annotation = ""
+		return
+	case strings.HasPrefix(line, "# "):
+		p.heading1(strings.TrimSpace(line[2:]))
+		return
+	case strings.HasPrefix(line, "## "):
+		p.heading2(strings.TrimSpace(line[3:]))
+		return
+	case strings.HasPrefix(line, "### "):
+		p.heading3(strings.TrimSpace(line[4:]))
+		return
+	}
+
+	if m := annotationRe.FindStringSubmatch(trimmed); m != nil {
+		p.annotation = m[1]
+		return
+	}
+	if trimmed != "" {
+		// 注記とコードブロックの間に本文があれば、注記はそのコード向けではない
+		p.annotation = ""
+	}
+	if m := themeLinkRe.FindStringSubmatch(line); m != nil && p.section == SectionKeyPoints {
+		p.t.Next = &Link{Text: m[1], Target: m[2], Line: p.line}
+	}
+
+	switch p.section {
+	case SectionGoals:
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok {
+			p.t.Goals = append(p.t.Goals, item)
+		}
+	case SectionExercise:
+		p.exerciseLine(trimmed)
+	case SectionKeyPoints:
+		if m := checkRe.FindStringSubmatch(trimmed); m != nil {
+			p.t.KeyPoints = append(p.t.KeyPoints, KeyPoint{Text: m[2], Checked: m[1] != " "})
+		}
+	default:
+		p.text = append(p.text, line)
+	}
+}
+
+func (p *parser) heading1(text string) {
+	p.t.Headings = append(p.t.Headings, Heading{Level: 1, Text: text, Line: p.line})
+	if m := titleRe.FindStringSubmatch(text); m != nil {
+		p.t.Number, _ = strconv.Atoi(m[1])
+		p.t.Title, p.t.Subtitle = m[2], m[3]
+	} else {
+		p.t.Title = text
+	}
+}
+
+func (p *parser) heading2(text string) {
+	p.flushText()
+	p.t.Headings = append(p.t.Headings, Heading{Level: 2, Text: text, Line: p.line})
+	p.section, p.heading, p.field, p.annotation = text, "", "", ""
+	for _, s := range Sections {
+		if strings.HasPrefix(text, s) {
+			p.section = s
+			break
+		}
+	}
+	if p.section == SectionExercise {
+		p.t.Exercise.Line = p.line
+	}
+}
+
+func (p *parser) heading3(text string) {
+	p.flushText()
+	p.t.Headings = append(p.t.Headings, Heading{Level: 3, Text: text, Line: p.line})
+	p.heading, p.annotation = text, ""
+	if p.section != SectionExamples {
+		return
+	}
+	for prefix, kind := range map[string]CodeKind{"基本:": KindBasic, "実践:": KindApplied} {
+		if title, ok := strings.CutPrefix(text, prefix); ok {
+			p.t.Examples = append(p.t.Examples, Example{Kind: kind, Title: strings.TrimSpace(title), Line: p.line})
+		}
+	}
+}
+
+// exerciseLine は演習問題の中の1行を、直前の「**要件:**」などの小見出しに応じて振り分ける
+func (p *parser) exerciseLine(line string) {
+	if strings.HasPrefix(line, "**") && strings.HasSuffix(line, ":**") {
+		p.field = strings.TrimSuffix(strings.TrimPrefix(line, "**"), ":**")
+		return
+	}
+	ex := &p.t.Exercise
+	switch p.field {
+	case "":
+		if line != "" {
+			ex.Intro = joinText(ex.Intro, line)
+		}
+	case "要件":
+		if m := numberedRe.FindStringSubmatch(line); m != nil {
+			ex.Requirements = append(ex.Requirements, m[1])
+		}
+	case "期待される動作":
+		if item, ok := strings.CutPrefix(line, "- "); ok {
+			ex.Expected = append(ex.Expected, item)
+		}
+	}
+}
+
+func (p *parser) codeKind() CodeKind {
+	switch p.section {
+	case SectionWhy:
+		if p.heading == HeadingBadExample {
+			return KindBadExample
+		}
+	case SectionExamples:
+		if n := len(p.t.Examples); n > 0 && p.heading != "" && strings.HasSuffix(p.heading, p.t.Examples[n-1].Title) {
+			return p.t.Examples[n-1].Kind
+		}
+	case SectionExercise:
+		if p.field == "ヒント" {
+			return KindHint
+		}
+	}
+	return KindOther
+}
+
+func (p *parser) closeFence() {
+	c := *p.fence
+	c.Code = strings.Join(p.fenceLines, "\n") + "\n"
+	p.fence, p.fenceLines = nil, nil
+	p.t.Code = append(p.t.Code, c)
+
+	switch c.Kind {
+	case KindBasic, KindApplied:
+		ex := &p.t.Examples[len(p.t.Examples)-1]
+		if ex.Code == nil {
+			ex.Code = &p.t.Code[len(p.t.Code)-1]
+		}
+	case KindHint:
+		p.t.Exercise.Hints = append(p.t.Exercise.Hints, c)
+	}
+}
+
+// flushText はたまった本文を今の見出しに割り当てる
+func (p *parser) flushText() {
+	text := strings.TrimSpace(strings.Join(p.text, "\n"))
+	p.text = nil
+	if text == "" {
+		return
+	}
+	switch p.section {
+	case SectionWhy:
+		p.t.Why = joinText(p.t.Why, text)
+	case SectionExamples:
+		if n := len(p.t.Examples); n > 0 && p.t.Examples[n-1].Code == nil {
+			p.t.Examples[n-1].Description = joinText(p.t.Examples[n-1].Description, text)
+		}
+	}
+}
+
+func joinText(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "\n" + b
+}
+
+// Find はdirの中から番号numberのテーマファイル（"07-xxx.md"）を探す
+func Find(dir string, number int) (string, error) {
+	matches, err := filepath.Glob(filepath.Join(dir, fmt.Sprintf("%02d-*.md", number)))
+	if err != nil {
+		return "", err
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("theme %02d not found in %s", number, dir)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("theme %02d is ambiguous: %v", number, matches)
+	}
+}
+
+// List はdirの中のテーマファイルを番号順に返す
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var list []string
+	for _, e := range entries {
+		if !e.IsDir() && fileRe.MatchString(e.Name()) {
+			list = append(list, filepath.Join(dir, e.Name()))
+		}
+	}
+	return list, nil
+}
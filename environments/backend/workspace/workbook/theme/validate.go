@@ -0,0 +1,111 @@
+package theme
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Violation はTEMPLATE.mdの形式からの逸脱
+type Violation struct {
+	Path    string `json:"path"`
+	Line    int    `json:"line"` // 特定の行でなければ0
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	if v.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", v.Path, v.Line, v.Message)
+	}
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// Validate はテーマがTEMPLATE.mdの形式に従っているかを確認し、逸脱を行順に返す
+// 次のテーマへのリンクは、リンク先のファイルが存在するかも確認する
+func (t *Theme) Validate() []Violation {
+	var vs []Violation
+	add := func(line int, format string, args ...any) {
+		vs = append(vs, Violation{Path: t.Path, Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if t.Number == 0 {
+		add(1, `title must be "# {番号}. {テーマ名} - {サブタイトル}"`)
+	} else if m := fileRe.FindStringSubmatch(filepath.Base(t.Path)); m == nil {
+		add(0, `file name must be "{番号2桁}-{kebab-case}.md"`)
+	} else if m[1] != fmt.Sprintf("%02d", t.Number) {
+		add(1, "title number %02d does not match file name %s", t.Number, filepath.Base(t.Path))
+	}
+
+	// 必須の見出しが揃っていて、TEMPLATE.mdの順に並んでいるか
+	lines := make(map[string]int)
+	prev := 0
+	for _, s := range Sections {
+		line := t.sectionLine(s)
+		lines[s] = line
+		switch {
+		case line == 0:
+			add(0, "missing section %q", "## "+s)
+		case line < prev:
+			add(line, "section %q is out of order", "## "+s)
+		default:
+			prev = line
+		}
+	}
+
+	if lines[SectionGoals] > 0 && len(t.Goals) == 0 {
+		add(lines[SectionGoals], "%q has no bullet items", SectionGoals)
+	}
+	if lines[SectionWhy] > 0 && len(t.CodeOf(KindBadExample)) == 0 {
+		add(lines[SectionWhy], "%q needs %q with a bad example code block", SectionWhy, "### "+HeadingBadExample)
+	}
+
+	if lines[SectionExamples] > 0 {
+		for _, kind := range []CodeKind{KindBasic, KindApplied} {
+			ex := t.Example(kind)
+			prefix := map[CodeKind]string{KindBasic: "基本:", KindApplied: "実践:"}[kind]
+			switch {
+			case ex == nil:
+				add(lines[SectionExamples], "missing example %q", "### "+prefix+" {タイトル}")
+			case ex.Code == nil:
+				add(ex.Line, "example %q has no code block", prefix+" "+ex.Title)
+			case strings.TrimSpace(ex.Description) == "":
+				add(ex.Line, "example %q needs an explanation before the code", prefix+" "+ex.Title)
+			}
+		}
+		if n := len(t.Examples); n > 2 {
+			add(t.Examples[2].Line, "examples must have 2 levels (基本→実践), found %d", n)
+		}
+	}
+
+	if lines[SectionExercise] > 0 && len(t.Exercise.Requirements) == 0 {
+		add(lines[SectionExercise], "%q needs a numbered %q list", SectionExercise, "**要件:**")
+	}
+	if lines[SectionKeyPoints] > 0 && len(t.KeyPoints) == 0 {
+		add(lines[SectionKeyPoints], "%q needs checklist items (- [ ])", SectionKeyPoints)
+	}
+
+	switch {
+	case t.Next == nil:
+		add(0, "missing next theme link (**次のテーマ:** [...](./NN-xxx.md))")
+	case !strings.HasPrefix(t.Next.Target, "http"):
+		target := filepath.Join(filepath.Dir(t.Path), filepath.FromSlash(t.Next.Target))
+		if _, err := os.Stat(target); err != nil {
+			add(t.Next.Line, "next theme link is broken: %s", t.Next.Target)
+		}
+	}
+
+	// 行番号のないもの（ファイル全体に対する指摘）を先頭に
+	sort.SliceStable(vs, func(i, j int) bool { return vs[i].Line < vs[j].Line })
+	return vs
+}
+
+func (t *Theme) sectionLine(section string) int {
+	for _, h := range t.Headings {
+		if h.Level == 2 && strings.HasPrefix(h.Text, section) {
+			return h.Line
+		}
+	}
+	return 0
+}
@@ -1,49 +1,15 @@
+// 解答例の型と関数はworkout/lessons/bankにある（テストもそちら）。ここではその使い方を示す
 package main
 
 import (
-	"errors"
 	"fmt"
-)
-
-type BankAccount struct {
-	Owner   string
-	Balance int
-}
-
-func (a *BankAccount) Deposit(amount int) error {
-	if amount <= 0 {
-		return errors.New("deposit amount must be positive")
-	}
-	a.Balance += amount
-	return nil
-}
-
-func (a *BankAccount) Withdraw(amount int) error {
-	if amount <= 0 {
-		return errors.New("withdrawal amount must be positive")
-	}
-	if amount > a.Balance {
-		return fmt.Errorf("insufficient funds: have %d, want %d", a.Balance, amount)
-	}
-	a.Balance -= amount
-	return nil
-}
 
-func (a *BankAccount) Transfer(to *BankAccount, amount int) error {
-	if err := a.Withdraw(amount); err != nil {
-		return fmt.Errorf("transfer failed: %w", err)
-	}
-	if err := to.Deposit(amount); err != nil {
-		// 出金を戻す
-		a.Balance += amount
-		return fmt.Errorf("transfer failed: %w", err)
-	}
-	return nil
-}
+	"workout/lessons/bank"
+)
 
 func main() {
-	alice := &BankAccount{Owner: "Alice", Balance: 1000}
-	bob := &BankAccount{Owner: "Bob", Balance: 500}
+	alice := &bank.BankAccount{Owner: "Alice", Balance: 1000}
+	bob := &bank.BankAccount{Owner: "Bob", Balance: 500}
 
 	fmt.Printf("初期: Alice=%d, Bob=%d\n", alice.Balance, bob.Balance)
 
@@ -56,14 +22,12 @@ func main() {
 	fmt.Printf("Alice出金後: %d\n", alice.Balance)
 
 	// 残高不足
-	err := alice.Withdraw(10000)
-	if err != nil {
+	if err := alice.Withdraw(10000); err != nil {
 		fmt.Println("Error:", err)
 	}
 
 	// 送金
-	err = alice.Transfer(bob, 300)
-	if err != nil {
+	if err := alice.Transfer(bob, 300); err != nil {
 		fmt.Println("Error:", err)
 	}
 	fmt.Printf("送金後: Alice=%d, Bob=%d\n", alice.Balance, bob.Balance)
@@ -1,7 +1,30 @@
+// 解答例の型と関数はworkout/lessons/notifyにある（テストもそちら）。ここではその使い方を示す
 package main
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+
+	"workout/lessons/notify"
+)
+
+// failingNotifier は常に失敗するNotifier（SendAllが残りに送り続けることを見せる）
+type failingNotifier struct{}
+
+func (failingNotifier) Notify(message string) error {
+	return errors.New("connection refused")
+}
 
 func main() {
-	fmt.Println("This is the main function. The actual implementation is in the workspace directory.")
-}
\ No newline at end of file
+	notifiers := []notify.Notifier{
+		&notify.EmailNotifier{To: "tanaka@example.com"},
+		failingNotifier{},
+		&notify.SlackNotifier{Channel: "#general"},
+	}
+
+	errs := notify.SendAll(notifiers, "デプロイが完了しました")
+	fmt.Printf("失敗: %d件\n", len(errs))
+	for _, err := range errs {
+		fmt.Println("  -", err)
+	}
+}
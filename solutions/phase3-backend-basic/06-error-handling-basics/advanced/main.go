@@ -1,57 +1,17 @@
+// 解答例の型と関数はworkout/lessons/signupにある（テストもそちら）。ここではその使い方を示す
 package main
 
 import (
 	"fmt"
-	"strings"
-)
-
-type RegisterRequest struct {
-	Username string
-	Email    string
-	Password string
-}
-
-type User struct {
-	ID       int
-	Username string
-	Email    string
-}
-
-var nextID = 1
-
-func ValidateRequest(req RegisterRequest) error {
-	if req.Username == "" {
-		return fmt.Errorf("username is required")
-	}
-	if len(req.Username) < 3 {
-		return fmt.Errorf("username must be at least 3 characters")
-	}
-	if !strings.Contains(req.Email, "@") {
-		return fmt.Errorf("email must contain @")
-	}
-	if len(req.Password) < 8 {
-		return fmt.Errorf("password must be at least 8 characters")
-	}
-	return nil
-}
-
-func RegisterUser(req RegisterRequest) (*User, error) {
-	if err := ValidateRequest(req); err != nil {
-		return nil, fmt.Errorf("validation failed: %v", err)
-	}
 
-	user := &User{
-		ID:       nextID,
-		Username: req.Username,
-		Email:    req.Email,
-	}
-	nextID++
-	return user, nil
-}
+	"workout/lessons/signup"
+)
 
 func main() {
+	var registry signup.Registry
+
 	// 正常ケース
-	user, err := RegisterUser(RegisterRequest{
+	user, err := registry.RegisterUser(signup.RegisterRequest{
 		Username: "tanaka",
 		Email:    "tanaka@example.com",
 		Password: "password123",
@@ -63,15 +23,14 @@ func main() {
 	}
 
 	// エラーケース
-	testCases := []RegisterRequest{
+	testCases := []signup.RegisterRequest{
 		{Username: "ab", Email: "test@example.com", Password: "password123"},
 		{Username: "tanaka", Email: "invalid", Password: "password123"},
 		{Username: "tanaka", Email: "test@example.com", Password: "short"},
 	}
 
 	for _, tc := range testCases {
-		_, err := RegisterUser(tc)
-		if err != nil {
+		if _, err := registry.RegisterUser(tc); err != nil {
 			fmt.Println("Error:", err)
 		}
 	}
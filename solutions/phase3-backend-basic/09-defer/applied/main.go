@@ -1,23 +1,89 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"strings"
 )
 
-// FileHandle はファイル操作のシミュレーション
+// File は開いたファイルの操作（*os.Fileが満たす）
+type File interface {
+	io.Reader
+	io.Writer
+	io.Seeker
+	io.Closer
+	Stat() (fs.FileInfo, error)
+	Sync() error
+	Chmod(mode fs.FileMode) error
+	Truncate(size int64) error
+}
+
+// FS はファイルシステムの操作
+// 本物のファイルシステム（OSFS）の代わりに、障害を起こす実装などに差し替えられる
+type FS interface {
+	OpenFile(name string, flag int, perm fs.FileMode) (File, error)
+	Stat(name string) (fs.FileInfo, error)
+	Rename(oldname, newname string) error
+	// SyncDir はディレクトリをfsyncし、renameした結果をディスクに残す
+	SyncDir(name string) error
+}
+
+// OSFS はDirを起点にした本物のファイルシステム
+type OSFS struct {
+	Dir string
+}
+
+func (o OSFS) path(name string) string { return filepath.Join(o.Dir, name) }
+
+func (o OSFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	return os.OpenFile(o.path(name), flag, perm)
+}
+
+func (o OSFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(o.path(name))
+}
+
+func (o OSFS) Rename(oldname, newname string) error {
+	return os.Rename(o.path(oldname), o.path(newname))
+}
+
+func (o OSFS) SyncDir(name string) error {
+	d, err := os.Open(o.path(name))
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// FileHandle は開いたファイル。2回Closeするとエラーを返す
 type FileHandle struct {
 	name   string
+	f      File
 	closed bool
 }
 
-func OpenFile(name string) (*FileHandle, error) {
+func OpenFile(fsys FS, name string) (*FileHandle, error) {
+	f, err := fsys.OpenFile(name, os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
 	fmt.Printf("ファイルを開く: %s\n", name)
-	return &FileHandle{name: name}, nil
+	return &FileHandle{name: name, f: f}, nil
 }
 
-func (f *FileHandle) Read() string {
-	return fmt.Sprintf("%sの内容", f.name)
+func (f *FileHandle) Read() (string, error) {
+	data, err := io.ReadAll(f.f)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", f.name, err)
+	}
+	return string(data), nil
 }
 
 func (f *FileHandle) Close() error {
@@ -26,48 +92,221 @@ func (f *FileHandle) Close() error {
 	}
 	f.closed = true
 	fmt.Printf("ファイルを閉じる: %s\n", f.name)
+	if err := f.f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", f.name, err)
+	}
 	return nil
 }
 
 // 取得直後にdeferでクリーンアップ
-func readFile(name string) (string, error) {
-	f, err := OpenFile(name)
+// Closeのエラーも捨てずに、名前付き戻り値errに合わせて返す
+func readFile(fsys FS, name string) (content string, err error) {
+	f, err := OpenFile(fsys, name)
 	if err != nil {
 		return "", fmt.Errorf("open failed: %w", err)
 	}
-	defer f.Close()
+	defer func() { err = errors.Join(err, f.Close()) }()
 
-	content := f.Read()
+	content, err = f.Read()
+	if err != nil {
+		return "", err
+	}
 	return strings.ToUpper(content), nil
 }
 
-// 複数リソースのクリーンアップ
-func copyFile(src, dst string) error {
-	srcFile, err := OpenFile(src)
+// copyFile はsrcをdstにコピーする
+//
+//   - dst.partに書いてfsyncしてからrenameするので、dstは古い内容か新しい内容のどちらかで、書きかけにならない
+//   - srcのパーミッションをdstにも付ける
+//   - 前回途中で止まったdst.partがあれば、先頭がsrcと同じかをSHA-256で確かめて続きから書く
+//   - 2つのファイルのdeferのCloseのエラーも全てまとめて返す
+func copyFile(fsys FS, src, dst string) (err error) {
+	in, err := fsys.OpenFile(src, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, closeFile(in, src)) }()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	part := dst + ".part"
+	out, err := fsys.OpenFile(part, os.O_RDWR|os.O_CREATE, 0o600)
 	if err != nil {
 		return err
 	}
-	defer srcFile.Close()
+	closed := false
+	defer func() {
+		if !closed {
+			err = errors.Join(err, closeFile(out, part))
+		}
+	}()
 
-	dstFile, err := OpenFile(dst)
+	offset, err := resumeOffset(in, out)
 	if err != nil {
 		return err
 	}
-	defer dstFile.Close()
+	if offset > 0 {
+		fmt.Printf("  再開: %sの%dバイト目から\n", part, offset)
+	}
+	n, err := io.Copy(out, in)
+	if err != nil {
+		return fmt.Errorf("copy %s: %w", src, err)
+	}
+	fmt.Printf("  書き込み: %dバイト\n", n)
 
-	content := srcFile.Read()
-	fmt.Printf("コピー: %s → %s (%s)\n", src, dst, content)
+	if err := out.Chmod(info.Mode().Perm()); err != nil {
+		return err
+	}
+	// renameする前にディスクに書き出す（電源が落ちても中身のないdstにならない）
+	if err := out.Sync(); err != nil {
+		return err
+	}
+	closed = true
+	if err := closeFile(out, part); err != nil {
+		return err
+	}
+	if err := fsys.Rename(part, dst); err != nil {
+		return err
+	}
+	return fsys.SyncDir(filepath.Dir(dst))
+}
+
+// resumeOffset はoutの内容がinの先頭と同じなら、その長さの位置に両方をシークして返す
+// 違っていればoutを空にして0を返す
+func resumeOffset(in, out File) (int64, error) {
+	info, err := out.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+	if size > 0 {
+		same, err := samePrefix(in, out, size)
+		if err != nil {
+			return 0, err
+		}
+		if same {
+			if _, err := in.Seek(size, io.SeekStart); err != nil {
+				return 0, err
+			}
+			_, err := out.Seek(size, io.SeekStart)
+			return size, err
+		}
+		fmt.Println("  途中のファイルが元のファイルと一致しないので最初から書く")
+	}
+	if _, err := in.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	if err := out.Truncate(0); err != nil {
+		return 0, err
+	}
+	_, err = out.Seek(0, io.SeekStart)
+	return 0, err
+}
+
+// samePrefix はaとbの先頭sizeバイトのSHA-256が同じかを返す
+func samePrefix(a, b File, size int64) (bool, error) {
+	sum := func(f File) ([]byte, error) {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		h := sha256.New()
+		if _, err := io.CopyN(h, f, size); err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		return h.Sum(nil), nil
+	}
+	sa, err := sum(a)
+	if err != nil {
+		return false, err
+	}
+	sb, err := sum(b)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(sa, sb), nil
+}
+
+func closeFile(f File, name string) error {
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", name, err)
+	}
 	return nil
 }
 
+// faultyFS はnamesのファイルのCloseを失敗させるFS（ディスクがいっぱいで書き出しに失敗した場合など）
+type faultyFS struct {
+	FS
+	names map[string]bool
+}
+
+func (f faultyFS) OpenFile(name string, flag int, perm fs.FileMode) (File, error) {
+	file, err := f.FS.OpenFile(name, flag, perm)
+	if err != nil || !f.names[name] {
+		return file, err
+	}
+	return faultyFile{file}, nil
+}
+
+type faultyFile struct{ File }
+
+func (f faultyFile) Close() error {
+	f.File.Close()
+	return errors.New("input/output error")
+}
+
+// show はファイルのパーミッションと内容を表示する
+func show(fsys OSFS, name string) {
+	info, err := fsys.Stat(name)
+	if err != nil {
+		fmt.Printf("  %s: %v\n", name, errors.Unwrap(err))
+		return
+	}
+	data, _ := os.ReadFile(fsys.path(name))
+	fmt.Printf("  %s %s %q\n", info.Mode().Perm(), name, data)
+}
+
 func main() {
-	content, err := readFile("test.txt")
+	dir, err := os.MkdirTemp("", "defer-applied-*")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	fsys := OSFS{Dir: dir}
+
+	os.WriteFile(filepath.Join(dir, "test.txt"), []byte("test.txtの内容"), 0o644)
+	content, err := readFile(fsys, "test.txt")
 	if err != nil {
 		fmt.Println("Error:", err)
 	} else {
 		fmt.Println("内容:", content)
 	}
+	_, err = readFile(fsys, "missing.txt")
+	fmt.Println("存在しないファイル:", errors.Is(err, fs.ErrNotExist))
+
+	fmt.Println("--- コピー")
+	src := filepath.Join(dir, "source.txt")
+	os.WriteFile(src, []byte("0123456789abcdefghij"), 0o640)
+	os.Chmod(src, 0o640)
+	fmt.Println("Error:", copyFile(fsys, "source.txt", "dest.txt"))
+	show(fsys, "dest.txt")
+	show(fsys, "dest.txt.part")
+
+	fmt.Println("--- 途中で止まったコピーの再開")
+	os.WriteFile(filepath.Join(dir, "dest.txt.part"), []byte("0123456789"), 0o600)
+	fmt.Println("Error:", copyFile(fsys, "source.txt", "dest.txt"))
+	show(fsys, "dest.txt")
+
+	fmt.Println("--- 途中のファイルが壊れている")
+	os.WriteFile(filepath.Join(dir, "dest.txt.part"), []byte("01234XXXXX"), 0o600)
+	fmt.Println("Error:", copyFile(fsys, "source.txt", "dest.txt"))
+	show(fsys, "dest.txt")
 
-	fmt.Println("---")
-	copyFile("source.txt", "dest.txt")
+	fmt.Println("--- 両方のCloseが失敗")
+	bad := faultyFS{FS: fsys, names: map[string]bool{"source.txt": true, "other.txt.part": true}}
+	err = copyFile(bad, "source.txt", "other.txt")
+	fmt.Printf("Error:\n%v\n", err)
+	show(fsys, "other.txt")
 }
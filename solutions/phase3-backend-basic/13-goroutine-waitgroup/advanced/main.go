@@ -0,0 +1,26 @@
+// 解答例の型と関数はworkout/lessons/batchにある（RunParallel、テストもそちら）。ここではその使い方を示す
+package main
+
+import (
+	"fmt"
+
+	"workout/lessons/batch"
+)
+
+func main() {
+	jobs := []batch.Job{
+		{Name: "データ取得", Fn: func() error { return nil }},
+		{Name: "データ変換", Fn: func() error { return fmt.Errorf("invalid format") }},
+		{Name: "データ保存", Fn: func() error { panic("nil pointer dereference") }},
+		{Name: "通知送信", Fn: func() error { return nil }},
+		{Name: "集計", Fn: func() error { return nil }},
+		{Name: "アーカイブ", Fn: func() error { return fmt.Errorf("disk full") }},
+	}
+
+	result := batch.RunParallel(jobs, 3)
+	fmt.Println("=== バッチ結果 ===")
+	fmt.Printf("成功: %d, 失敗: %d (うちpanic: %d)\n", result.Success, result.Failed, result.Panics)
+	for _, e := range result.Errors {
+		fmt.Println("  -", e)
+	}
+}
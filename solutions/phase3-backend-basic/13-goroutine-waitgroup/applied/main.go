@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// fetch はURLの取得のシミュレーション
+func fetch(url string) (int, error) {
+	if url == "https://example.com/broken" {
+		return 0, fmt.Errorf("fetch %s: connection refused", url)
+	}
+	return len(url) * 10, nil
+}
+
+type fetchResult struct {
+	URL  string
+	Size int
+	Err  error
+}
+
+// fetchAll はurlsを並行に取得し、渡した順に結果を返す
+func fetchAll(urls []string) []fetchResult {
+	results := make([]fetchResult, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			size, err := fetch(url)
+			results[i] = fetchResult{URL: url, Size: size, Err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+func main() {
+	urls := []string{
+		"https://example.com/users",
+		"https://example.com/broken",
+		"https://example.com/orders",
+	}
+
+	total := 0
+	for _, r := range fetchAll(urls) {
+		if r.Err != nil {
+			fmt.Println("Error:", r.Err)
+			continue
+		}
+		fmt.Printf("%s: %d bytes\n", r.URL, r.Size)
+		total += r.Size
+	}
+	fmt.Println("合計:", total, "bytes")
+}
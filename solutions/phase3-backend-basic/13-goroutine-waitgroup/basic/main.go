@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+func main() {
+	var wg sync.WaitGroup
+	results := make([]string, 3)
+
+	for i := range 3 {
+		wg.Add(1) // goroutineを起動する前にAddする
+		go func() {
+			defer wg.Done()
+			results[i] = fmt.Sprintf("worker %d 完了", i+1)
+		}()
+	}
+
+	wg.Wait() // 全てのDoneを待つ
+	// goroutineの実行順は決まらないので、各自の添字に書いた結果を順に表示する
+	for _, r := range results {
+		fmt.Println(r)
+	}
+	fmt.Println("全てのworkerが完了")
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Task はワーカーに渡す仕事
+type Task struct {
+	ID   int
+	Text string
+}
+
+// Result はワーカーが返す結果
+type Result struct {
+	TaskID int
+	Words  int
+	Err    error
+}
+
+// worker はtasksが閉じられるまで仕事を取り出して処理する
+func worker(tasks <-chan Task, results chan<- Result, wg *sync.WaitGroup) {
+	defer wg.Done()
+	for t := range tasks {
+		if strings.TrimSpace(t.Text) == "" {
+			results <- Result{TaskID: t.ID, Err: fmt.Errorf("task %d: empty text", t.ID)}
+			continue
+		}
+		results <- Result{TaskID: t.ID, Words: len(strings.Fields(t.Text))}
+	}
+}
+
+// CountWords はtextsの単語数をworkers個のワーカーで数える
+// 送信側（tasks）はこの関数が、受信側（results）は全てのワーカーの終了後にcloseする
+func CountWords(texts []string, workers int) []Result {
+	tasks := make(chan Task)
+	results := make(chan Result, len(texts))
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go worker(tasks, results, &wg)
+	}
+
+	go func() {
+		for i, text := range texts {
+			tasks <- Task{ID: i + 1, Text: text}
+		}
+		close(tasks) // これ以上仕事がないことをワーカーに伝える
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results) // 全てのワーカーが送り終わってから閉じる
+	}()
+
+	var list []Result
+	for r := range results {
+		list = append(list, r)
+	}
+	// どのワーカーがどの順で処理したかは実行ごとに変わるので、タスクの順に並べる
+	sort.Slice(list, func(i, j int) bool { return list[i].TaskID < list[j].TaskID })
+	return list
+}
+
+func main() {
+	texts := []string{
+		"Go is an open source programming language",
+		"channels connect concurrent goroutines",
+		"   ",
+		"do not communicate by sharing memory",
+		"share memory by communicating",
+	}
+
+	total := 0
+	for _, r := range CountWords(texts, 3) {
+		if r.Err != nil {
+			fmt.Println("Error:", r.Err)
+			continue
+		}
+		fmt.Printf("task %d: %d words\n", r.TaskID, r.Words)
+		total += r.Words
+	}
+	fmt.Println("合計:", total, "words")
+}
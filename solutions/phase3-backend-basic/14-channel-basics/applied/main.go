@@ -0,0 +1,46 @@
+package main
+
+import "fmt"
+
+// generate はnumsを順に送るチャネルを返す。送り終わったら送信側がcloseする
+func generate(nums ...int) <-chan int {
+	out := make(chan int)
+	go func() {
+		defer close(out)
+		for _, n := range nums {
+			out <- n
+		}
+	}()
+	return out
+}
+
+// square は受け取った値を2乗して次に送る
+func square(in <-chan int) <-chan int {
+	out := make(chan int)
+	go func() {
+		defer close(out)
+		for n := range in {
+			out <- n * n
+		}
+	}()
+	return out
+}
+
+// sum は全ての値を受け取って合計を返す
+func sum(in <-chan int) int {
+	total := 0
+	for n := range in {
+		total += n
+	}
+	return total
+}
+
+func main() {
+	// パイプライン: generate → square → 表示
+	for n := range square(generate(1, 2, 3, 4)) {
+		fmt.Println("二乗:", n)
+	}
+
+	// 段を組み替えても各段の実装は変わらない
+	fmt.Println("二乗の合計:", sum(square(generate(1, 2, 3, 4, 5))))
+}
@@ -0,0 +1,29 @@
+package main
+
+import "fmt"
+
+func main() {
+	// Unbuffered Channel: 送信は受信されるまでブロックする
+	ch := make(chan string)
+	go func() {
+		ch <- "こんにちは"
+	}()
+	fmt.Println("受信:", <-ch)
+
+	// Buffered Channel: バッファが空いていればブロックしない
+	buf := make(chan int, 3)
+	buf <- 1
+	buf <- 2
+	buf <- 3
+	fmt.Printf("len=%d cap=%d\n", len(buf), cap(buf))
+
+	// closeした後もバッファの値は受信できる。rangeは空になったら終わる
+	close(buf)
+	for v := range buf {
+		fmt.Println("値:", v)
+	}
+
+	// 閉じたチャネルからの受信はゼロ値とok=falseを返す
+	v, ok := <-buf
+	fmt.Printf("閉じた後: v=%d ok=%t\n", v, ok)
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Event はサービスから届くイベント
+type Event struct {
+	Source string
+	Seq    int
+}
+
+// produce はn個のイベントを送り終えて閉じたチャネルを返す
+// バッファに全て入れておくので、受け取る側はいつでもn個受信してから閉じたことを知る
+func produce(source string, n int) <-chan Event {
+	ch := make(chan Event, n)
+	for i := 1; i <= n; i++ {
+		ch <- Event{Source: source, Seq: i}
+	}
+	close(ch)
+	return ch
+}
+
+// stalled は止まったサービスのチャネル。何も届かず、閉じることもない
+func stalled() <-chan Event {
+	return make(chan Event)
+}
+
+// Stats は集計結果
+type Stats struct {
+	Counts  map[string]int
+	Timeout bool
+}
+
+// collect は複数のチャネルからイベントを受け取り、全て閉じるかdeadlineが来るまで集計する
+// 閉じたチャネルはnilにして、selectの対象から外す（nilチャネルの受信は永遠にブロックする）
+func collect(orders, payments <-chan Event, deadline time.Duration, stop <-chan struct{}) Stats {
+	stats := Stats{Counts: make(map[string]int)}
+	timeout := time.After(deadline)
+
+	for orders != nil || payments != nil {
+		select {
+		case ev, ok := <-orders:
+			if !ok {
+				orders = nil
+				continue
+			}
+			stats.Counts[ev.Source]++
+		case ev, ok := <-payments:
+			if !ok {
+				payments = nil
+				continue
+			}
+			stats.Counts[ev.Source]++
+		case <-stop:
+			return stats
+		case <-timeout:
+			stats.Timeout = true
+			return stats
+		}
+	}
+	return stats
+}
+
+func main() {
+	// 両方のチャネルが閉じるまで集計する
+	stats := collect(produce("orders", 3), produce("payments", 2), time.Second, nil)
+	fmt.Printf("全件受信: orders=%d payments=%d timeout=%t\n",
+		stats.Counts["orders"], stats.Counts["payments"], stats.Timeout)
+
+	// 締め切りを過ぎたら、届いていないイベントを待たずに打ち切る
+	stats = collect(produce("orders", 2), stalled(), 200*time.Millisecond, nil)
+	fmt.Printf("締め切りで打ち切り: orders=%d payments=%d timeout=%t\n",
+		stats.Counts["orders"], stats.Counts["payments"], stats.Timeout)
+
+	// stopを閉じると、待っている全てのselectに一度に停止を伝えられる
+	// （受信できるケースが複数あるとselectはランダムに選ぶので、ここでは何も届かないチャネルを使う）
+	stop := make(chan struct{})
+	close(stop)
+	stats = collect(stalled(), stalled(), time.Second, stop)
+	fmt.Printf("停止: orders=%d payments=%d timeout=%t\n",
+		stats.Counts["orders"], stats.Counts["payments"], stats.Timeout)
+}
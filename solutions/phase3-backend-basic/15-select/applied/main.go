@@ -0,0 +1,66 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrTimeout = errors.New("timeout")
+
+// callAPI はAPI呼び出しのシミュレーション。latencyだけ待ってから結果を返す
+func callAPI(name string, latency time.Duration) <-chan string {
+	ch := make(chan string, 1) // タイムアウトで誰も受信しなくてもgoroutineが終われるようバッファを持たせる
+	go func() {
+		time.Sleep(latency)
+		ch <- name + "の結果"
+	}()
+	return ch
+}
+
+// callWithTimeout はtimeoutまでに結果が届かなければErrTimeoutを返す
+func callWithTimeout(name string, latency, timeout time.Duration) (string, error) {
+	select {
+	case res := <-callAPI(name, latency):
+		return res, nil
+	case <-time.After(timeout):
+		return "", fmt.Errorf("%s: %w", name, ErrTimeout)
+	}
+}
+
+// trySend はチャネルが一杯なら待たずにfalseを返す
+func trySend(ch chan<- string, msg string) bool {
+	select {
+	case ch <- msg:
+		return true
+	default:
+		return false
+	}
+}
+
+func main() {
+	for _, c := range []struct {
+		name    string
+		latency time.Duration
+	}{
+		{"ユーザーAPI", 10 * time.Millisecond},
+		{"在庫API", 300 * time.Millisecond},
+	} {
+		res, err := callWithTimeout(c.name, c.latency, 100*time.Millisecond)
+		if errors.Is(err, ErrTimeout) {
+			fmt.Println("Error:", err)
+			continue
+		}
+		fmt.Println("成功:", res)
+	}
+
+	// バッファが一杯なら捨てる（ログの送信などで呼び出し側を止めない）
+	queue := make(chan string, 2)
+	for _, msg := range []string{"msg1", "msg2", "msg3"} {
+		if trySend(queue, msg) {
+			fmt.Println("送信:", msg)
+		} else {
+			fmt.Println("キューが一杯なので破棄:", msg)
+		}
+	}
+}
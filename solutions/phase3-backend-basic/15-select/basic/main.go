@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func main() {
+	fast := make(chan string)
+	slow := make(chan string)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		fast <- "fast"
+	}()
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		slow <- "slow"
+	}()
+
+	// select は準備のできたcaseを1つ実行する
+	select {
+	case msg := <-fast:
+		fmt.Println("先に届いた:", msg)
+	case msg := <-slow:
+		fmt.Println("先に届いた:", msg)
+	}
+
+	// タイムアウト: time.Afterのチャネルが先に受信できればタイムアウト扱い
+	select {
+	case msg := <-slow:
+		fmt.Println("受信:", msg)
+	case <-time.After(50 * time.Millisecond):
+		fmt.Println("タイムアウト: slowを待つのをやめた")
+	}
+
+	// default: どのcaseも準備できていなければブロックせずにdefaultを実行する
+	empty := make(chan int)
+	select {
+	case v := <-empty:
+		fmt.Println("受信:", v)
+	default:
+		fmt.Println("受信できる値がない")
+	}
+}
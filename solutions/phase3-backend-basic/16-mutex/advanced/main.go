@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// BankAccount は02-pointer-basicsの口座に排他制御を足したもの
+type BankAccount struct {
+	mu      sync.Mutex
+	ID      int
+	Owner   string
+	balance int
+}
+
+func (a *BankAccount) Balance() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.balance
+}
+
+func (a *BankAccount) Deposit(amount int) error {
+	if amount <= 0 {
+		return errors.New("deposit amount must be positive")
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.balance += amount
+	return nil
+}
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// Transfer はfromからtoへ送金する
+// 2つの口座を同時にロックするので、常にIDの小さい口座から順にロックしてデッドロックを防ぐ
+// （AからBとBからAの送金が同時に起きても、両方が同じ順でロックを取る）
+func Transfer(from, to *BankAccount, amount int) error {
+	if from == to {
+		return errors.New("cannot transfer to the same account")
+	}
+	if amount <= 0 {
+		return errors.New("transfer amount must be positive")
+	}
+	first, second := from, to
+	if second.ID < first.ID {
+		first, second = second, first
+	}
+	first.mu.Lock()
+	defer first.mu.Unlock()
+	second.mu.Lock()
+	defer second.mu.Unlock()
+
+	// 残高の確認と引き落としを同じロックの中で行う（確認した後に他の送金が割り込まない）
+	if from.balance < amount {
+		return fmt.Errorf("transfer %d from %s: %w", amount, from.Owner, ErrInsufficientFunds)
+	}
+	from.balance -= amount
+	to.balance += amount
+	return nil
+}
+
+func main() {
+	alice := &BankAccount{ID: 1, Owner: "Alice"}
+	bob := &BankAccount{ID: 2, Owner: "Bob"}
+	alice.Deposit(1000)
+	bob.Deposit(1000)
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed int
+	)
+	// AliceからBob、BobからAliceへの送金を同時に大量に行う
+	for i := range 200 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			from, to := alice, bob
+			if i%2 == 1 {
+				from, to = bob, alice
+			}
+			if err := Transfer(from, to, 10); err != nil {
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 送金が何件失敗しても、2人の合計は変わらない
+	fmt.Println("合計:", alice.Balance()+bob.Balance())
+	fmt.Println("失敗した送金:", failed)
+
+	err := Transfer(alice, bob, 1_000_000)
+	fmt.Println("Error:", err)
+	fmt.Println("残高不足か:", errors.Is(err, ErrInsufficientFunds))
+}
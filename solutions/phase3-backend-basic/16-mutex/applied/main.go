@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Cache は読み込みが多いキャッシュ。読み込み同士は同時に、書き込みは単独で行う
+type Cache struct {
+	mu    sync.RWMutex
+	items map[string]string
+}
+
+func NewCache() *Cache {
+	return &Cache{items: make(map[string]string)}
+}
+
+func (c *Cache) Get(key string) (string, bool) {
+	c.mu.RLock() // 複数のgoroutineが同時にRLockできる
+	defer c.mu.RUnlock()
+	v, ok := c.items[key]
+	return v, ok
+}
+
+func (c *Cache) Set(key, value string) {
+	c.mu.Lock() // Lock中は他のRLock/Lockを待たせる
+	defer c.mu.Unlock()
+	c.items[key] = value
+}
+
+// Keys はキーの一覧をソートして返す。mapをそのまま返すとロックの外で読まれてしまうのでコピーする
+func (c *Cache) Keys() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	keys := make([]string, 0, len(c.items))
+	for k := range c.items {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func main() {
+	cache := NewCache()
+	var wg sync.WaitGroup
+
+	// 書き込み
+	for i := range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.Set(fmt.Sprintf("user:%d", i), fmt.Sprintf("ユーザー%d", i))
+		}()
+	}
+	wg.Wait()
+
+	// 読み込み（同時に動く）
+	hits := make([]bool, 10)
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, hits[i] = cache.Get(fmt.Sprintf("user:%d", i))
+		}()
+	}
+	wg.Wait()
+
+	n := 0
+	for _, hit := range hits {
+		if hit {
+			n++
+		}
+	}
+	fmt.Println("キー:", cache.Keys())
+	fmt.Printf("ヒット: %d / %d\n", n, len(hits))
+}
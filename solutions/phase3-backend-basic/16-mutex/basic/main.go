@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Counter はMutexで守られたカウンター
+type Counter struct {
+	mu    sync.Mutex
+	value int
+}
+
+func (c *Counter) Increment() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value++
+}
+
+func (c *Counter) Value() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.value
+}
+
+func main() {
+	var c Counter // Mutexはゼロ値で使える（コピーしないようポインタレシーバーにする）
+	var wg sync.WaitGroup
+
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				c.Increment()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Mutexがないと c.value++ の読み書きが競合して10000にならないことがある（go run -race で検出できる）
+	fmt.Println("カウント:", c.Value())
+}
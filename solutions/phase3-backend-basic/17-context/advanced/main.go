@@ -0,0 +1,47 @@
+// 解答例の型と関数はworkout/lessons/txにある（ExecuteInTxContext、テストもそちら）。ここではその使い方を示す
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"workout/lessons/tx"
+)
+
+func main() {
+	insertUser := func(t *tx.Transaction) error {
+		if err := t.Exec("INSERT INTO users ...", 5*time.Millisecond); err != nil {
+			return err
+		}
+		return t.Exec("INSERT INTO profiles ...", 5*time.Millisecond)
+	}
+
+	fmt.Println("=== 期限内 ===")
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := tx.ExecuteInTxContext(ctx, os.Stdout, insertUser); err != nil {
+		fmt.Println("Error:", err)
+	}
+
+	fmt.Println("\n=== タイムアウト ===")
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := tx.ExecuteInTxContext(ctx, os.Stdout, func(t *tx.Transaction) error {
+		if err := t.Exec("INSERT INTO users ...", 5*time.Millisecond); err != nil {
+			return err
+		}
+		return t.Exec("UPDATE stats ... (重いクエリ)", time.Second)
+	})
+	fmt.Println("Error:", err)
+	fmt.Println("期限切れか:", errors.Is(err, context.DeadlineExceeded))
+
+	fmt.Println("\n=== キャンセル済み ===")
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	err = tx.ExecuteInTxContext(canceled, os.Stdout, insertUser)
+	fmt.Println("Error:", err)
+	fmt.Println("キャンセルか:", errors.Is(err, context.Canceled))
+}
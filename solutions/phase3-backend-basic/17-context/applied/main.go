@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"fmt"
+)
+
+// contextのキーは他のパッケージと衝突しないよう、非公開の型にする
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	userKey
+)
+
+// WithRequestID はリクエストIDを持つcontextを返す
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestID はctxのリクエストIDを返す。なければ"-"
+func RequestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey).(string); ok {
+		return id
+	}
+	return "-"
+}
+
+type User struct {
+	ID   int
+	Name string
+}
+
+func WithUser(ctx context.Context, u *User) context.Context {
+	return context.WithValue(ctx, userKey, u)
+}
+
+func UserFrom(ctx context.Context) (*User, bool) {
+	u, ok := ctx.Value(userKey).(*User)
+	return u, ok
+}
+
+// ハンドラー → サービス → リポジトリ とctxを第1引数で渡していく
+func handler(ctx context.Context) {
+	ctx = WithRequestID(ctx, "req-001")
+	ctx = WithUser(ctx, &User{ID: 1, Name: "田中太郎"})
+	service(ctx)
+}
+
+func service(ctx context.Context) {
+	u, ok := UserFrom(ctx)
+	if !ok {
+		fmt.Printf("[%s] 未ログイン\n", RequestID(ctx))
+		return
+	}
+	fmt.Printf("[%s] service: user=%s\n", RequestID(ctx), u.Name)
+	repository(ctx, u.ID)
+}
+
+func repository(ctx context.Context, userID int) {
+	fmt.Printf("[%s] repository: SELECT * FROM orders WHERE user_id = %d\n", RequestID(ctx), userID)
+}
+
+func main() {
+	handler(context.Background())
+
+	// 値のないcontext
+	service(context.Background())
+}
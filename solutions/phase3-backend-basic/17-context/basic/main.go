@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// slowOperation はdの間待つ処理。ctxがキャンセルされたら途中でやめる
+func slowOperation(ctx context.Context, d time.Duration) error {
+	select {
+	case <-time.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func main() {
+	// WithCancel: cancelを呼ぶと、ctxを受け取った全ての処理に停止を伝えられる
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	err := slowOperation(ctx, time.Second)
+	fmt.Println("WithCancel:", err)
+
+	// WithTimeout: 期限が来ると自動でキャンセルされる。cancelは必ずdeferで呼ぶ
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = slowOperation(ctx, time.Second)
+	fmt.Println("WithTimeout:", err)
+	fmt.Println("期限切れか:", errors.Is(err, context.DeadlineExceeded))
+
+	// 期限内に終われば nil
+	ctx2, cancel2 := context.WithTimeout(context.Background(), time.Second)
+	defer cancel2()
+	fmt.Println("期限内:", slowOperation(ctx2, 5*time.Millisecond))
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Graph はパッケージのimportの関係（キーのパッケージが値のパッケージをimportする）
+type Graph map[string][]string
+
+// FindCycle はimportの循環を1つ探し、見つかれば循環するパッケージの列を返す
+// Goは循環importをコンパイルエラーにするので、設計の段階で見つけて切る
+func FindCycle(g Graph) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	var stack []string
+
+	var visit func(pkg string) []string
+	visit = func(pkg string) []string {
+		state[pkg] = visiting
+		stack = append(stack, pkg)
+		for _, dep := range g[pkg] {
+			switch state[dep] {
+			case visiting:
+				// stackのdepから先が循環
+				i := slices.Index(stack, dep)
+				return append(slices.Clone(stack[i:]), dep)
+			case unvisited:
+				if c := visit(dep); c != nil {
+					return c
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[pkg] = done
+		return nil
+	}
+
+	// mapの順序に依存しないよう、名前順に探す
+	pkgs := make([]string, 0, len(g))
+	for p := range g {
+		pkgs = append(pkgs, p)
+	}
+	sort.Strings(pkgs)
+	for _, p := range pkgs {
+		if state[p] == unvisited {
+			if c := visit(p); c != nil {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// CheckInternal はinternalパッケージのルールを確かめる
+// a/b/internal/c をimportできるのは a/b 以下のパッケージだけ
+func CheckInternal(g Graph) []string {
+	var violations []string
+	for pkg, deps := range g {
+		for _, dep := range deps {
+			i := strings.LastIndex(dep, "/internal")
+			if i < 0 {
+				continue
+			}
+			parent := dep[:i]
+			if pkg != parent && !strings.HasPrefix(pkg, parent+"/") {
+				violations = append(violations, fmt.Sprintf("%s → %s", pkg, dep))
+			}
+		}
+	}
+	sort.Strings(violations)
+	return violations
+}
+
+func main() {
+	// order ⇄ user が互いをimportしている
+	cyclic := Graph{
+		"app/handler": {"app/order", "app/user"},
+		"app/order":   {"app/user"},
+		"app/user":    {"app/order"},
+	}
+	if c := FindCycle(cyclic); c != nil {
+		fmt.Println("循環import:", strings.Join(c, " → "))
+	}
+
+	// userが必要とするものをinterfaceにして、依存の向きを一方向にする
+	fixed := Graph{
+		"app/handler": {"app/order", "app/user"},
+		"app/order":   {"app/user"},
+		"app/user":    {}, // orderの型の代わりに自分で定義したinterfaceを使う
+	}
+	fmt.Println("修正後の循環:", FindCycle(fixed))
+
+	layout := Graph{
+		"app/cmd/server":         {"app/internal/db", "app/api"},
+		"app/api":                {"app/internal/db"},
+		"other/tool":             {"app/internal/db"},
+		"app/api/v2":             {"app/api/internal/codec"},
+		"app/internal/db":        {},
+		"app/api/internal/codec": {},
+	}
+	fmt.Println("internalの違反:")
+	for _, v := range CheckInternal(layout) {
+		fmt.Println("  " + v)
+	}
+}
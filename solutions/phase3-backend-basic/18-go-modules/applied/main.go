@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// GoMod はgo.modの主な項目
+type GoMod struct {
+	Module   string
+	Go       string
+	Requires []Require
+}
+
+type Require struct {
+	Path     string
+	Version  string
+	Indirect bool // 直接importしていない（依存の依存）
+}
+
+// MajorVersion はv2以上のモジュールのimportパスに付く /vN を返す（v0・v1は空）
+func (r Require) MajorVersion() string {
+	i := strings.LastIndex(r.Path, "/v")
+	if i < 0 {
+		return ""
+	}
+	suffix := r.Path[i+1:]
+	if len(suffix) < 2 || strings.Trim(suffix[1:], "0123456789") != "" {
+		return ""
+	}
+	return suffix
+}
+
+// ParseGoMod はgo.modのmodule・go・requireを読む（requireのブロックにも対応）
+func ParseGoMod(src string) (*GoMod, error) {
+	mod := &GoMod{}
+	inBlock := false
+	sc := bufio.NewScanner(strings.NewReader(src))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		indirect := strings.HasSuffix(line, "// indirect")
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			if len(fields) != 2 {
+				return nil, fmt.Errorf("go.mod:%d: want \"path version\"", n)
+			}
+			mod.Requires = append(mod.Requires, Require{fields[0], fields[1], indirect})
+		case fields[0] == "module" && len(fields) == 2:
+			mod.Module = fields[1]
+		case fields[0] == "go" && len(fields) == 2:
+			mod.Go = fields[1]
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == "require" && len(fields) == 3:
+			mod.Requires = append(mod.Requires, Require{fields[1], fields[2], indirect})
+		}
+	}
+	if mod.Module == "" {
+		return nil, fmt.Errorf("go.mod: missing module directive")
+	}
+	return mod, sc.Err()
+}
+
+const goMod = `module github.com/example/workout
+
+go 1.24
+
+require github.com/jackc/pgx/v5 v5.7.2
+
+require (
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/puddle/v2 v2.2.2 // indirect
+	golang.org/x/text v0.21.0 // indirect
+)
+`
+
+func main() {
+	mod, err := ParseGoMod(goMod)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println("module:", mod.Module)
+	fmt.Println("go:", mod.Go)
+	for _, r := range mod.Requires {
+		kind := "direct"
+		if r.Indirect {
+			kind = "indirect"
+		}
+		line := fmt.Sprintf("  %-30s %-8s %s", r.Path, r.Version, kind)
+		if v := r.MajorVersion(); v != "" {
+			line += " (メジャーバージョン " + v + " はimportパスに含める)"
+		}
+		fmt.Println(line)
+	}
+
+	// パッケージのimportパスは「モジュールパス + ディレクトリ」
+	fmt.Println("import:", mod.Module+"/domain")
+
+	_, err = ParseGoMod("go 1.24\n")
+	fmt.Println("Error:", err)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"runtime/debug"
+	"strings"
+)
+
+// 大文字で始まる名前はパッケージの外から見える（エクスポートされる）
+// 小文字で始まる名前はパッケージの中だけで使える
+type User struct {
+	Name     string // 外から読み書きできる
+	password string // 外からは見えない
+}
+
+func NewUser(name, password string) *User {
+	return &User{Name: name, password: password}
+}
+
+// CheckPassword はパスワードそのものを公開せずに照合する手段を公開する
+func (u *User) CheckPassword(p string) bool {
+	return u.password == p
+}
+
+func main() {
+	u := NewUser("tanaka", "secret")
+	fmt.Println("Name:", u.Name)
+	fmt.Println("照合:", u.CheckPassword("secret"))
+
+	// ビルドに使ったモジュールの情報はバイナリに埋め込まれている
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		fmt.Println("ビルド情報なし")
+		return
+	}
+	fmt.Println("モジュール:", info.Main.Path)
+	fmt.Println("Goのバージョン:", strings.HasPrefix(info.GoVersion, "go1."))
+	fmt.Println("依存モジュール数:", len(info.Deps))
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// newServer はタイムアウトを設定したhttp.Server
+// ListenAndServeのデフォルトはタイムアウトなしなので、遅いクライアントに接続を握られ続ける
+func newServer(handler http.Handler) *http.Server {
+	return &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+}
+
+func main() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	// /slow はシャットダウンが始まるまで終わらない処理中のリクエスト
+	// 時間を決めて待つ代わりに、チャネルで「受け付けた」「シャットダウンが始まった」を伝える
+	started := make(chan struct{})
+	release := make(chan struct{})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		fmt.Fprintln(w, "slow done")
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0") // ポート0で空いているポートを選ぶ
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	srv := newServer(mux)
+	// RegisterOnShutdownの関数はShutdownが始まると呼ばれる
+	srv.RegisterOnShutdown(func() { close(release) })
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+	base := "http://" + ln.Addr().String()
+
+	get := func(path string) {
+		resp, err := http.Get(base + path)
+		if err != nil {
+			fmt.Printf("GET %s: error\n", path)
+			return
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		fmt.Printf("GET %s → %d %s", path, resp.StatusCode, body)
+	}
+
+	get("/healthz")
+
+	// 処理中のリクエストがある状態でシャットダウンする
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		get("/slow")
+	}()
+	<-started
+
+	// Shutdownは新しい接続の受け付けをやめ、処理中のリクエストが終わるのを待つ
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		fmt.Println("Shutdown error:", err)
+	}
+	<-done
+
+	// Serveはシャットダウン後にErrServerClosedを返す（異常終了ではない）
+	err = <-serveErr
+	fmt.Println("Serveの戻り値はErrServerClosed:", errors.Is(err, http.ErrServerClosed))
+	fmt.Println("シャットダウン完了")
+}
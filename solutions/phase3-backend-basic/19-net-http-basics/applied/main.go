@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// HandlerFunc型は、関数をhttp.Handlerとして使えるようにするアダプタ
+// type HandlerFunc func(ResponseWriter, *Request)
+// func (f HandlerFunc) ServeHTTP(w ResponseWriter, r *Request) { f(w, r) }
+
+// greetHandler はクエリパラメータnameを使って挨拶する。GET以外は405を返す
+func greetHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = "ゲスト"
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprintf(w, "こんにちは、%sさん\n", name)
+}
+
+// counter は構造体にServeHTTPを実装したHandler。状態を持てる
+type counter struct {
+	n int
+}
+
+func (c *counter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.n++
+	fmt.Fprintf(w, "アクセス数: %d\n", c.n)
+}
+
+func main() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/greet", greetHandler)
+	mux.Handle("/count", &counter{})
+
+	// httptest.NewRecorderを使うと、サーバーを起動せずにハンドラーを呼べる
+	requests := []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/greet?name=田中", nil),
+		httptest.NewRequest(http.MethodGet, "/greet", nil),
+		httptest.NewRequest(http.MethodPost, "/greet", nil),
+		httptest.NewRequest(http.MethodGet, "/count", nil),
+		httptest.NewRequest(http.MethodGet, "/count", nil),
+		httptest.NewRequest(http.MethodGet, "/unknown", nil),
+	}
+	for _, req := range requests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		fmt.Printf("%s %s → %d %s\n", req.Method, req.URL, rec.Code, strings.TrimSpace(rec.Body.String()))
+	}
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+)
+
+func helloHandler(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "Hello, World!")
+}
+
+func main() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", helloHandler)
+
+	// 本番では http.ListenAndServe(":8080", mux) で起動する
+	// ここでは空いているポートで起動し、同じプログラムからリクエストを送る
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/hello")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer resp.Body.Close() // Bodyは必ず閉じる
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println("Status:", resp.Status)
+	fmt.Print("Body: ", string(body))
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// RegisterRequest は06-error-handling-basicsのユーザー登録のリクエスト
+type RegisterRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func ValidateRequest(req RegisterRequest) error {
+	if req.Username == "" {
+		return fmt.Errorf("username is required")
+	}
+	if len(req.Username) < 3 {
+		return fmt.Errorf("username must be at least 3 characters")
+	}
+	if !strings.Contains(req.Email, "@") {
+		return fmt.Errorf("email must contain @")
+	}
+	if len(req.Password) < 8 {
+		return fmt.Errorf("password must be at least 8 characters")
+	}
+	return nil
+}
+
+// maxBodyBytes はリクエストボディの上限。巨大なボディでメモリを使い切られないようにする
+const maxBodyBytes = 1 << 10
+
+// decodeJSON はボディを1つのJSONオブジェクトとしてdstに読む
+// 未知のフィールド・複数のJSON・大きすぎるボディをエラーにし、原因ごとに分かるメッセージにする
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		var maxErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &syntaxErr):
+			return fmt.Errorf("malformed JSON at position %d", syntaxErr.Offset)
+		case errors.Is(err, io.ErrUnexpectedEOF):
+			return errors.New("malformed JSON")
+		case errors.As(err, &typeErr):
+			return fmt.Errorf("field %q must be %s", typeErr.Field, typeErr.Type)
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			return fmt.Errorf("unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))
+		case errors.Is(err, io.EOF):
+			return errors.New("request body is empty")
+		case errors.As(err, &maxErr):
+			return fmt.Errorf("request body must not exceed %d bytes", maxErr.Limit)
+		default:
+			return err
+		}
+	}
+	if dec.More() {
+		return errors.New("request body must contain a single JSON object")
+	}
+	return nil
+}
+
+func registerHandler(w http.ResponseWriter, r *http.Request) {
+	var req RegisterRequest
+	if err := decodeJSON(w, r, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := ValidateRequest(req); err != nil {
+		http.Error(w, "validation failed: "+err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, "registered: %s", req.Username)
+}
+
+func main() {
+	bodies := []string{
+		`{"username": "tanaka", "email": "tanaka@example.com", "password": "password123"}`,
+		`{"username": "tanaka", "email": "invalid", "password": "password123"}`,
+		`{"username": "tanaka", "email": "tanaka@example.com", "password": 123}`,
+		`{"username": "tanaka", "role": "admin"}`,
+		`{"username": "tanaka",}`,
+		`{"username": "tanaka"`,
+		`{"username": "a"}{"username": "b"}`,
+		``,
+		`{"username": "` + strings.Repeat("x", 2000) + `"}`,
+	}
+	for _, body := range bodies {
+		req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		registerHandler(rec, req)
+		fmt.Printf("%d %s\n", rec.Code, strings.TrimSpace(rec.Body.String()))
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// CreateTodoRequest はPOST /todosのリクエストボディ（11-encoding-jsonのTodoの作成用）
+type CreateTodoRequest struct {
+	Title    string `json:"title"`
+	Priority int    `json:"priority"`
+}
+
+func createTodoHandler(w http.ResponseWriter, r *http.Request) {
+	if ct := r.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		http.Error(w, "Content-Type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	var req CreateTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Title == "" {
+		http.Error(w, "title is required", http.StatusBadRequest)
+		return
+	}
+	if req.Priority < 1 || req.Priority > 5 {
+		http.Error(w, "priority must be between 1 and 5", http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintf(w, "created: %s (priority %d)", req.Title, req.Priority)
+}
+
+func main() {
+	cases := []struct {
+		contentType string
+		body        string
+	}{
+		{"application/json", `{"title": "Go学習", "priority": 3}`},
+		{"application/json", `{"title": "", "priority": 3}`},
+		{"application/json", `{"title": "テスト", "priority": 9}`},
+		{"application/json", `{"title": "壊れたJSON"`},
+		{"text/plain", `title=Go`},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/todos", strings.NewReader(c.body))
+		req.Header.Set("Content-Type", c.contentType)
+		rec := httptest.NewRecorder()
+		createTodoHandler(rec, req)
+		fmt.Printf("%d %s\n", rec.Code, strings.TrimSpace(rec.Body.String()))
+	}
+}
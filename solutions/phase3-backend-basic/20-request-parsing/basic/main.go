@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+)
+
+func searchHandler(w http.ResponseWriter, r *http.Request) {
+	// クエリパラメータ: /search?q=go&page=2
+	q := r.URL.Query().Get("q")
+	page := 1
+	if s := r.URL.Query().Get("page"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			http.Error(w, "page must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		page = n
+	}
+	// ヘッダー（名前の大文字・小文字は区別しない）
+	ua := r.Header.Get("User-Agent")
+	fmt.Fprintf(w, "q=%s page=%d ua=%s", q, page, ua)
+}
+
+func main() {
+	for _, target := range []string{"/search?q=go&page=2", "/search?q=go", "/search?page=abc"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("user-agent", "workout-client/1.0")
+		rec := httptest.NewRecorder()
+		searchHandler(rec, req)
+		fmt.Printf("%s → %d %s\n", target, rec.Code, strings.TrimSpace(rec.Body.String()))
+	}
+}
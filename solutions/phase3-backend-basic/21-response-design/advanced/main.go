@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+)
+
+// AppError は07-custom-errorsのHTTPステータス付きエラーに、機械向けのコードを足したもの
+type AppError struct {
+	Status  int
+	Code    string
+	Message string
+	Details []string
+	Err     error // 原因。ログには出すがクライアントには返さない
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error { return e.Err }
+
+var ErrNotFound = errors.New("not found")
+
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code    string   `json:"code"`
+	Message string   `json:"message"`
+	Details []string `json:"details,omitempty"`
+}
+
+// appHandler はエラーを返せるハンドラー。レスポンスへの変換を1か所にまとめる
+type appHandler func(w http.ResponseWriter, r *http.Request) error
+
+func (h appHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := h(w, r)
+	if err == nil {
+		return
+	}
+	var appErr *AppError
+	switch {
+	case errors.As(err, &appErr):
+	case errors.Is(err, ErrNotFound):
+		appErr = &AppError{Status: http.StatusNotFound, Code: "not_found", Message: "resource not found"}
+	default:
+		// 想定外のエラーの詳細（SQLやファイルパス）はクライアントに見せない
+		appErr = &AppError{Status: http.StatusInternalServerError, Code: "internal", Message: "internal server error"}
+	}
+	fmt.Printf("  log: %s %s: %v\n", r.Method, r.URL.Path, err)
+	writeJSON(w, appErr.Status, ErrorResponse{Error: ErrorBody{Code: appErr.Code, Message: appErr.Message, Details: appErr.Details}})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	// 先にエンコードして、失敗したら200を送ってしまう前に500にする
+	data, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, `{"error":{"code":"internal","message":"internal server error"}}`, http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(data, '\n'))
+}
+
+type Product struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Price int    `json:"price"`
+}
+
+var products = map[int]Product{1: {ID: 1, Name: "Go入門書", Price: 3000}}
+
+func findProduct(id int) (Product, error) {
+	if id == 500 {
+		return Product{}, errors.New(`pq: relation "products" does not exist`)
+	}
+	p, ok := products[id]
+	if !ok {
+		return Product{}, fmt.Errorf("product id=%d: %w", id, ErrNotFound)
+	}
+	return p, nil
+}
+
+func getProduct(w http.ResponseWriter, r *http.Request) error {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return &AppError{Status: http.StatusBadRequest, Code: "invalid_id", Message: "id must be an integer", Err: err}
+	}
+	p, err := findProduct(id)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, p)
+	return nil
+}
+
+func createProduct(w http.ResponseWriter, r *http.Request) error {
+	var p Product
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		return &AppError{Status: http.StatusBadRequest, Code: "invalid_json", Message: "request body is not valid JSON", Err: err}
+	}
+	var details []string
+	if p.Name == "" {
+		details = append(details, "name: required")
+	}
+	if p.Price <= 0 {
+		details = append(details, "price: must be positive")
+	}
+	if len(details) > 0 {
+		return &AppError{Status: http.StatusUnprocessableEntity, Code: "validation_failed", Message: "request has invalid fields", Details: details}
+	}
+	p.ID = len(products) + 1
+	w.Header().Set("Location", fmt.Sprintf("/products/%d", p.ID))
+	writeJSON(w, http.StatusCreated, p)
+	return nil
+}
+
+func main() {
+	mux := http.NewServeMux()
+	mux.Handle("GET /products/{id}", appHandler(getProduct))
+	mux.Handle("POST /products", appHandler(createProduct))
+
+	requests := []struct {
+		method, path, body string
+	}{
+		{"GET", "/products/1", ""},
+		{"GET", "/products/99", ""},
+		{"GET", "/products/abc", ""},
+		{"GET", "/products/500", ""},
+		{"POST", "/products", `{"name": "Goの本", "price": 2500}`},
+		{"POST", "/products", `{"name": "", "price": 0}`},
+		{"POST", "/products", `{`},
+	}
+	for _, req := range requests {
+		fmt.Printf("%s %s\n", req.method, req.path)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(req.method, req.path, strings.NewReader(req.body)))
+		fmt.Printf("  %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+)
+
+// ErrorResponse は全てのエラーで共通のレスポンスの形
+//
+//	{"error": {"code": "not_found", "message": "..."}}
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+type ErrorBody struct {
+	Code    string   `json:"code"`              // クライアントが分岐に使う機械向けの値
+	Message string   `json:"message"`           // 人が読む説明
+	Details []string `json:"details,omitempty"` // バリデーションエラーの一覧など
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string, details ...string) {
+	writeJSON(w, status, ErrorResponse{Error: ErrorBody{Code: code, Message: message, Details: details}})
+}
+
+var products = map[int]string{1: "Go入門書"}
+
+func getProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid_id", "id must be a positive integer")
+		return
+	}
+	name, ok := products[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", fmt.Sprintf("product %d not found", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"id": id, "name": name})
+}
+
+func main() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /products/{id}", getProduct)
+
+	for _, path := range []string{"/products/1", "/products/99", "/products/abc"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		fmt.Printf("%s → %d %s", path, rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+type Product struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Price int    `json:"price"`
+}
+
+// writeJSON はvをJSONにしてstatusで返す
+// ヘッダーはWriteHeaderより前に設定する（後から設定しても送られない）
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func main() {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, Product{ID: 1, Name: "Goの本", Price: 3000})
+	}
+	created := func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Location", "/products/2")
+		writeJSON(w, http.StatusCreated, Product{ID: 2, Name: "Goの本 第2版", Price: 3500})
+	}
+	deleted := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent) // ボディなし
+	}
+
+	for _, h := range []struct {
+		name string
+		fn   http.HandlerFunc
+	}{
+		{"GET /products/1", handler},
+		{"POST /products", created},
+		{"DELETE /products/1", deleted},
+	} {
+		rec := httptest.NewRecorder()
+		h.fn(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		fmt.Printf("%s → %d %s\n", h.name, rec.Code, http.StatusText(rec.Code))
+		fmt.Printf("  Content-Type: %q Location: %q\n", rec.Header().Get("Content-Type"), rec.Header().Get("Location"))
+		fmt.Printf("  Body: %q\n", rec.Body.String())
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+)
+
+// Router はgorilla/muxのような、パスパラメータに正規表現の制約を付けられるルーター
+// 解答例は標準ライブラリだけで動かすので、同じ考え方を小さく実装する
+//
+//	r.HandleFunc("GET", "/users/{id:[0-9]+}", h)
+type Router struct {
+	prefix string
+	routes *[]route
+}
+
+type route struct {
+	method  string
+	pattern *regexp.Regexp
+	names   []string
+	handler http.HandlerFunc
+}
+
+type paramsKey struct{}
+
+func NewRouter() *Router {
+	return &Router{routes: new([]route)}
+}
+
+// PathPrefix はprefixの下にルートを登録するサブルーターを返す（ルートの一覧は共有する）
+func (r *Router) PathPrefix(prefix string) *Router {
+	return &Router{prefix: r.prefix + prefix, routes: r.routes}
+}
+
+// HandleFunc はpathのパターンを正規表現に変換して登録する。制約のない{name}は1つのセグメントに一致する
+// 制約の中にも{4}のような括弧を書けるよう、括弧の深さを数えてパラメータの終わりを探す
+func (r *Router) HandleFunc(method, path string, h http.HandlerFunc) {
+	full := r.prefix + path
+	var names []string
+	var expr strings.Builder
+	expr.WriteString("^")
+	for {
+		start := strings.IndexByte(full, '{')
+		if start < 0 {
+			break
+		}
+		end, depth := start, 0
+		for ; end < len(full); end++ {
+			if full[end] == '{' {
+				depth++
+			} else if full[end] == '}' {
+				if depth--; depth == 0 {
+					break
+				}
+			}
+		}
+		if end == len(full) {
+			panic("router: unclosed { in " + path)
+		}
+		name, constraint, ok := strings.Cut(full[start+1:end], ":")
+		if !ok {
+			constraint = "[^/]+"
+		}
+		names = append(names, name)
+		expr.WriteString(regexp.QuoteMeta(full[:start]) + "(" + constraint + ")")
+		full = full[end+1:]
+	}
+	expr.WriteString(regexp.QuoteMeta(full) + "$")
+	*r.routes = append(*r.routes, route{method, regexp.MustCompile(expr.String()), names, h})
+}
+
+// Vars はリクエストのパスパラメータを返す
+func Vars(r *http.Request) map[string]string {
+	v, _ := r.Context().Value(paramsKey{}).(map[string]string)
+	return v
+}
+
+// ServeHTTP は登録順に一致するルートを探す。パスが一致してメソッドだけ違えば405
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	var allowed []string
+	for _, rt := range *r.routes {
+		m := rt.pattern.FindStringSubmatch(req.URL.Path)
+		if m == nil {
+			continue
+		}
+		if rt.method != req.Method {
+			allowed = append(allowed, rt.method)
+			continue
+		}
+		params := make(map[string]string, len(rt.names))
+		for i, name := range rt.names {
+			params[name] = m[i+1]
+		}
+		ctx := context.WithValue(req.Context(), paramsKey{}, params)
+		rt.handler(w, req.WithContext(ctx))
+		return
+	}
+	if len(allowed) > 0 {
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	http.NotFound(w, req)
+}
+
+func main() {
+	r := NewRouter()
+	api := r.PathPrefix("/api/v1")
+
+	api.HandleFunc("GET", "/users/{id:[0-9]+}", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "user id=%s", Vars(req)["id"])
+	})
+	api.HandleFunc("PUT", "/users/{id:[0-9]+}", func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(w, "update user id=%s", Vars(req)["id"])
+	})
+	api.HandleFunc("GET", "/posts/{year:[0-9]{4}}/{slug}", func(w http.ResponseWriter, req *http.Request) {
+		v := Vars(req)
+		fmt.Fprintf(w, "post year=%s slug=%s", v["year"], v["slug"])
+	})
+
+	requests := []struct{ method, path string }{
+		{"GET", "/api/v1/users/42"},
+		{"PUT", "/api/v1/users/42"},
+		{"GET", "/api/v1/users/abc"}, // 数字の制約に合わない → 404
+		{"DELETE", "/api/v1/users/42"},
+		{"GET", "/api/v1/posts/2025/hello-go"},
+		{"GET", "/api/v1/posts/25/hello-go"},
+	}
+	for _, req := range requests {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(req.method, req.path, nil))
+		body := strings.TrimSpace(rec.Body.String())
+		if allow := rec.Header().Get("Allow"); allow != "" {
+			body += " (Allow: " + allow + ")"
+		}
+		fmt.Printf("%-6s %-28s → %d %s\n", req.method, req.path, rec.Code, body)
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+// Go 1.22からServeMuxのパターンにメソッドとパスパラメータを書ける
+// （以前はgorilla/muxなどのルーターが必要だった）
+func main() {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /users", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ユーザー一覧")
+	})
+	mux.HandleFunc("POST /users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, "ユーザー作成")
+	})
+	// {id} は1つのセグメントに一致し、r.PathValueで取り出す
+	mux.HandleFunc("GET /users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "ユーザー id=%s", r.PathValue("id"))
+	})
+	mux.HandleFunc("GET /users/{id}/orders/{orderID}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "ユーザー id=%s の注文 %s", r.PathValue("id"), r.PathValue("orderID"))
+	})
+	// より具体的なパターンが優先される（/users/me は /users/{id} より優先）
+	mux.HandleFunc("GET /users/me", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ログイン中のユーザー")
+	})
+	// {path...} は残りの全てのセグメントに一致する
+	mux.HandleFunc("GET /files/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "ファイル %s", r.PathValue("path"))
+	})
+
+	requests := []struct{ method, path string }{
+		{"GET", "/users"},
+		{"POST", "/users"},
+		{"GET", "/users/42"},
+		{"GET", "/users/me"},
+		{"GET", "/users/42/orders/7"},
+		{"GET", "/files/docs/2025/report.pdf"},
+		{"DELETE", "/users/42"}, // パスは一致するがメソッドが違う → 405
+	}
+	for _, req := range requests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(req.method, req.path, nil))
+		body := strings.TrimSpace(rec.Body.String())
+		if allow := rec.Header().Get("Allow"); allow != "" {
+			body += " (Allow: " + allow + ")"
+		}
+		fmt.Printf("%-6s %-28s → %d %s\n", req.method, req.path, rec.Code, body)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+func main() {
+	mux := http.NewServeMux()
+
+	// 末尾が/でないパターンはそのパスだけに一致する
+	mux.HandleFunc("/about", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "about")
+	})
+	// 末尾が/のパターンはその下の全てのパスに一致する（より長いパターンが優先）
+	mux.HandleFunc("/static/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "static: "+strings.TrimPrefix(r.URL.Path, "/static/"))
+	})
+	// "/" は他のどのパターンにも一致しないリクエストを受ける
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "home")
+	})
+
+	for _, path := range []string{"/", "/about", "/about/", "/static/css/app.css", "/unknown"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		fmt.Printf("%-22s → %d %s\n", path, rec.Code, strings.TrimSpace(rec.Body.String()))
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Task struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	Priority int    `json:"priority"`
+	Done     bool   `json:"done"`
+	Version  int    `json:"-"` // 更新のたびに増やし、ETagにする
+}
+
+// TaskPatch はPATCHのボディ。ポインタにして「送られなかった」と「ゼロ値を送った」を区別する
+type TaskPatch struct {
+	Title    *string `json:"title"`
+	Priority *int    `json:"priority"`
+	Done     *bool   `json:"done"`
+}
+
+type Store struct {
+	mu    sync.Mutex
+	tasks map[int]*Task
+	// 処理済みのIdempotency-Keyと、その時作ったタスクのID
+	idempotency map[string]int
+	nextID      int
+}
+
+func NewStore() *Store {
+	return &Store{tasks: make(map[int]*Task), idempotency: make(map[string]int), nextID: 1}
+}
+
+func etag(t *Task) string { return fmt.Sprintf(`"%d-%d"`, t.ID, t.Version) }
+
+func writeTask(w http.ResponseWriter, status int, t *Task) {
+	w.Header().Set("ETag", etag(t))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(t)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": msg})
+}
+
+// create はPOST /tasks。POSTは冪等ではないので、同じリクエストの再送で二重に作らないよう
+// Idempotency-Keyが同じなら前に作ったタスクを返す
+func (s *Store) create(w http.ResponseWriter, r *http.Request) {
+	var t Task
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil || t.Title == "" {
+		writeError(w, http.StatusBadRequest, "title is required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := r.Header.Get("Idempotency-Key")
+	if id, ok := s.idempotency[key]; ok && key != "" {
+		writeTask(w, http.StatusOK, s.tasks[id])
+		return
+	}
+	t.ID, t.Version = s.nextID, 1
+	s.nextID++
+	s.tasks[t.ID] = &t
+	if key != "" {
+		s.idempotency[key] = t.ID
+	}
+	w.Header().Set("Location", fmt.Sprintf("/tasks/%d", t.ID))
+	writeTask(w, http.StatusCreated, &t)
+}
+
+// lookup はタスクを探し、If-Matchがあれば今のETagと一致するか確かめる（楽観的ロック）
+func (s *Store) lookup(w http.ResponseWriter, r *http.Request) (*Task, bool) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	t, ok := s.tasks[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "task not found")
+		return nil, false
+	}
+	if m := r.Header.Get("If-Match"); m != "" && m != etag(t) {
+		writeError(w, http.StatusPreconditionFailed, "task was modified by another request")
+		return nil, false
+	}
+	return t, true
+}
+
+// replace はPUT /tasks/{id}。リソース全体を置き換えるので、何度送っても同じ結果になる（冪等）
+func (s *Store) replace(w http.ResponseWriter, r *http.Request) {
+	var in Task
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil || in.Title == "" {
+		writeError(w, http.StatusBadRequest, "title is required")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	in.ID, in.Version = t.ID, t.Version
+	if in != *t {
+		in.Version++
+	}
+	*t = in
+	writeTask(w, http.StatusOK, t)
+}
+
+// patch はPATCH /tasks/{id}。送られたフィールドだけを変える
+func (s *Store) patch(w http.ResponseWriter, r *http.Request) {
+	var p TaskPatch
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	if p.Title != nil {
+		t.Title = *p.Title
+	}
+	if p.Priority != nil {
+		t.Priority = *p.Priority
+	}
+	if p.Done != nil {
+		t.Done = *p.Done
+	}
+	t.Version++
+	writeTask(w, http.StatusOK, t)
+}
+
+func main() {
+	s := NewStore()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /tasks", s.create)
+	mux.HandleFunc("PUT /tasks/{id}", s.replace)
+	mux.HandleFunc("PATCH /tasks/{id}", s.patch)
+
+	do := func(method, path, body string, headers ...string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		fmt.Printf("%-5s %-8s %v → %d ETag=%s %s", method, path, headers, rec.Code, rec.Header().Get("ETag"), rec.Body.String())
+	}
+
+	fmt.Println("=== POSTの再送 ===")
+	do("POST", "/tasks", `{"title": "API設計", "priority": 3}`, "Idempotency-Key", "abc-123")
+	do("POST", "/tasks", `{"title": "API設計", "priority": 3}`, "Idempotency-Key", "abc-123")
+
+	fmt.Println("\n=== PUTは冪等 ===")
+	do("PUT", "/tasks/1", `{"title": "API設計", "priority": 5}`)
+	do("PUT", "/tasks/1", `{"title": "API設計", "priority": 5}`)
+
+	fmt.Println("\n=== PATCHは一部だけ変更 ===")
+	do("PATCH", "/tasks/1", `{"done": true}`)
+
+	fmt.Println("\n=== If-Matchによる楽観的ロック ===")
+	do("PATCH", "/tasks/1", `{"priority": 1}`, "If-Match", `"1-2"`) // 古いETag
+	do("PATCH", "/tasks/1", `{"priority": 1}`, "If-Match", `"1-3"`)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+type User struct {
+	FirstName string
+	LastName  string
+}
+
+var users = map[string]User{"1": {FirstName: "太郎", LastName: "田中"}}
+
+// v1のレスポンス。nameに姓名をまとめていた
+type userV1 struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// v2のレスポンス。姓と名を分けた（v1のクライアントを壊さないよう新しいバージョンにする）
+type userV2 struct {
+	ID        string `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func getUser(version int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		u, ok := users[id]
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+			return
+		}
+		if version == 1 {
+			// 廃止予定であることをヘッダーで知らせる
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Link", `</v2/users/`+id+`>; rel="successor-version"`)
+			writeJSON(w, http.StatusOK, userV1{ID: id, Name: u.LastName + " " + u.FirstName})
+			return
+		}
+		writeJSON(w, http.StatusOK, userV2{ID: id, FirstName: u.FirstName, LastName: u.LastName})
+	}
+}
+
+// acceptVersion はAcceptヘッダー（application/vnd.workout.v2+json）でバージョンを選ぶ
+func acceptVersion(w http.ResponseWriter, r *http.Request) {
+	version := 1
+	if strings.Contains(r.Header.Get("Accept"), "vnd.workout.v2+json") {
+		version = 2
+	}
+	getUser(version)(w, r)
+}
+
+func main() {
+	mux := http.NewServeMux()
+	// URLのパスでバージョンを分ける（一番よく使われる方法）
+	mux.HandleFunc("GET /v1/users/{id}", getUser(1))
+	mux.HandleFunc("GET /v2/users/{id}", getUser(2))
+	// ヘッダーでバージョンを分ける
+	mux.HandleFunc("GET /users/{id}", acceptVersion)
+
+	requests := []struct{ path, accept string }{
+		{"/v1/users/1", ""},
+		{"/v2/users/1", ""},
+		{"/users/1", "application/json"},
+		{"/users/1", "application/vnd.workout.v2+json"},
+		{"/v2/users/9", ""},
+	}
+	for _, req := range requests {
+		r := httptest.NewRequest(http.MethodGet, req.path, nil)
+		if req.accept != "" {
+			r.Header.Set("Accept", req.accept)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, r)
+		fmt.Printf("GET %s (Accept: %s) → %d %s", req.path, req.accept, rec.Code, rec.Body.String())
+		if rec.Header().Get("Deprecation") != "" {
+			fmt.Printf("  Deprecation: %s, Link: %s\n", rec.Header().Get("Deprecation"), rec.Header().Get("Link"))
+		}
+	}
+}
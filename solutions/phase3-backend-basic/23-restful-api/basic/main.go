@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Todo struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Done  bool   `json:"done"`
+}
+
+// TodoStore はメモリ上のTODOの保存先
+type TodoStore struct {
+	mu     sync.Mutex
+	todos  map[int]Todo
+	nextID int
+}
+
+func NewTodoStore() *TodoStore {
+	return &TodoStore{todos: make(map[int]Todo), nextID: 1}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// routes はリソース（名詞）のURLにHTTPメソッド（動詞）を組み合わせて操作を表す
+//
+//	GET    /todos       一覧      200
+//	POST   /todos       作成      201 + Location
+//	GET    /todos/{id}  取得      200 / 404
+//	PUT    /todos/{id}  置き換え  200 / 404
+//	DELETE /todos/{id}  削除      204 / 404
+func (s *TodoStore) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /todos", s.list)
+	mux.HandleFunc("POST /todos", s.create)
+	mux.HandleFunc("GET /todos/{id}", s.get)
+	mux.HandleFunc("PUT /todos/{id}", s.replace)
+	mux.HandleFunc("DELETE /todos/{id}", s.delete)
+	return mux
+}
+
+func (s *TodoStore) list(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]Todo, 0, len(s.todos))
+	for _, t := range s.todos {
+		list = append(list, t)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *TodoStore) create(w http.ResponseWriter, r *http.Request) {
+	var t Todo
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil || t.Title == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "title is required"})
+		return
+	}
+	s.mu.Lock()
+	t.ID = s.nextID
+	s.nextID++
+	s.todos[t.ID] = t
+	s.mu.Unlock()
+	w.Header().Set("Location", fmt.Sprintf("/todos/%d", t.ID))
+	writeJSON(w, http.StatusCreated, t)
+}
+
+func (s *TodoStore) get(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	s.mu.Lock()
+	t, ok := s.todos[id]
+	s.mu.Unlock()
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "todo not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *TodoStore) replace(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	var t Todo
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil || t.Title == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "title is required"})
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.todos[id]; !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "todo not found"})
+		return
+	}
+	t.ID = id
+	s.todos[id] = t
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *TodoStore) delete(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.todos[id]; !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "todo not found"})
+		return
+	}
+	delete(s.todos, id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func main() {
+	mux := NewTodoStore().routes()
+	requests := []struct{ method, path, body string }{
+		{"POST", "/todos", `{"title": "Go学習"}`},
+		{"POST", "/todos", `{"title": "API設計"}`},
+		{"GET", "/todos", ""},
+		{"PUT", "/todos/1", `{"title": "Go学習", "done": true}`},
+		{"DELETE", "/todos/2", ""},
+		{"GET", "/todos/2", ""},
+		{"GET", "/todos", ""},
+	}
+	for _, req := range requests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(req.method, req.path, strings.NewReader(req.body)))
+		line := fmt.Sprintf("%-6s %-9s → %d", req.method, req.path, rec.Code)
+		if loc := rec.Header().Get("Location"); loc != "" {
+			line += " Location=" + loc
+		}
+		if body := strings.TrimSpace(rec.Body.String()); body != "" {
+			line += " " + body
+		}
+		fmt.Println(line)
+	}
+}
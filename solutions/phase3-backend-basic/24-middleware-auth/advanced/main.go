@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+)
+
+// JWT（HS256）の署名と検証を標準ライブラリで実装する
+// 本番では実績のあるライブラリ（golang-jwt/jwtなど）を使う
+
+type Claims struct {
+	Subject   string `json:"sub"`
+	Role      string `json:"role"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+var (
+	ErrMalformed    = errors.New("malformed token")
+	ErrBadSignature = errors.New("invalid signature")
+	ErrExpired      = errors.New("token expired")
+)
+
+var b64 = base64.RawURLEncoding
+
+func Sign(c Claims, secret []byte) (string, error) {
+	header := b64.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	unsigned := header + "." + b64.EncodeToString(payload)
+	return unsigned + "." + b64.EncodeToString(mac(unsigned, secret)), nil
+}
+
+func mac(s string, secret []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
+
+// Verify はtokenの署名と有効期限を確かめてClaimsを返す
+func Verify(token string, secret []byte, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if h, err := b64.DecodeString(parts[0]); err != nil || json.Unmarshal(h, &header) != nil {
+		return nil, ErrMalformed
+	}
+	// alg=noneなど、想定していないアルゴリズムは受け付けない
+	if header.Alg != "HS256" {
+		return nil, fmt.Errorf("%w: unexpected alg %q", ErrMalformed, header.Alg)
+	}
+	sig, err := b64.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	// 比較にかかる時間から署名を推測されないよう、hmac.Equalで比べる
+	if !hmac.Equal(sig, mac(parts[0]+"."+parts[1], secret)) {
+		return nil, ErrBadSignature
+	}
+	payload, err := b64.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	var c Claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrMalformed
+	}
+	if now.Unix() >= c.ExpiresAt {
+		return nil, ErrExpired
+	}
+	return &c, nil
+}
+
+type claimsKey struct{}
+
+func ClaimsFrom(ctx context.Context) *Claims {
+	c, _ := ctx.Value(claimsKey{}).(*Claims)
+	return c
+}
+
+// Authenticator はJWTを検証するミドルウェアを作る
+type Authenticator struct {
+	Secret []byte
+	Now    func() time.Time // テストで時刻を固定できるようにする
+}
+
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+		c, err := Verify(token, a.Secret, a.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), claimsKey{}, c)))
+	})
+}
+
+// RequireRole は認証済みのユーザーがroleを持っているかを確かめる。持っていなければ403
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if c := ClaimsFrom(r.Context()); c == nil || c.Role != role {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func main() {
+	secret := []byte("change-me")
+	now := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	auth := &Authenticator{Secret: secret, Now: func() time.Time { return now }}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /me", auth.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c := ClaimsFrom(r.Context())
+		fmt.Fprintf(w, "hello %s (%s)", c.Subject, c.Role)
+	})))
+	mux.Handle("GET /admin", auth.Middleware(RequireRole("admin")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "admin page")
+	}))))
+
+	exp := now.Add(time.Hour).Unix()
+	userToken, _ := Sign(Claims{Subject: "tanaka", Role: "user", ExpiresAt: exp}, secret)
+	adminToken, _ := Sign(Claims{Subject: "suzuki", Role: "admin", ExpiresAt: exp}, secret)
+	expired, _ := Sign(Claims{Subject: "tanaka", Role: "user", ExpiresAt: now.Add(-time.Minute).Unix()}, secret)
+	forged, _ := Sign(Claims{Subject: "tanaka", Role: "admin", ExpiresAt: exp}, []byte("wrong-secret"))
+	noneAlg := b64.EncodeToString([]byte(`{"alg":"none"}`)) + "." + strings.Split(adminToken, ".")[1] + "."
+
+	cases := []struct{ name, path, token string }{
+		{"user", "/me", userToken},
+		{"user", "/admin", userToken},
+		{"admin", "/admin", adminToken},
+		{"期限切れ", "/me", expired},
+		{"別の鍵で署名", "/admin", forged},
+		{"alg=none", "/admin", noneAlg},
+		{"トークンなし", "/me", ""},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, c.path, nil)
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		fmt.Printf("[%s] GET %s → %d %s\n", c.name, c.path, rec.Code, strings.TrimSpace(rec.Body.String()))
+	}
+}
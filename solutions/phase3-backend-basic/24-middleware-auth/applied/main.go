@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+)
+
+type Middleware func(http.Handler) http.Handler
+
+// Chain はmiddlewaresを書いた順に外側から適用する
+func Chain(h http.Handler, middlewares ...Middleware) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// statusRecorder はハンドラーが書いたステータスコードを覚えておくResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func Logging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		fmt.Printf("  log: %s %s %d user=%s\n", r.Method, r.URL.Path, rec.status, userFrom(r.Context()))
+	})
+}
+
+type ctxKey struct{}
+
+// tokens はAPIトークンとユーザーの対応（本番ではDBなどで管理する）
+var tokens = map[string]string{"token-abc": "tanaka", "token-xyz": "suzuki"}
+
+// Auth はAuthorization: Bearer <token> を確かめ、ユーザーをcontextに入れる
+// 認証できなければ401を返し、後ろのハンドラーを呼ばない
+func Auth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		user, known := tokens[token]
+		if !ok || !known {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="workout"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), ctxKey{}, user)))
+	})
+}
+
+func userFrom(ctx context.Context) string {
+	if u, ok := ctx.Value(ctxKey{}).(string); ok {
+		return u
+	}
+	return "-"
+}
+
+func main() {
+	profile := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "profile of %s", userFrom(r.Context()))
+	})
+	// LoggingをAuthの外側に置くと、認証に失敗したリクエストもログに残る
+	// （ただしLoggingの時点ではユーザーがcontextにないので "-" になる）
+	handler := Chain(profile, Logging, Auth)
+
+	for _, auth := range []string{"Bearer token-abc", "Bearer wrong", "", "Basic dXNlcjpwYXNz"} {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		fmt.Printf("Authorization: %q\n", auth)
+		handler.ServeHTTP(rec, req)
+		fmt.Printf("  %d %s\n", rec.Code, strings.TrimSpace(rec.Body.String()))
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+// Middleware はhttp.Handlerを受け取り、前後に処理を足したhttp.Handlerを返す
+type Middleware func(http.Handler) http.Handler
+
+// Logging はリクエストの前後にログを出す
+func Logging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Printf("→ %s %s\n", r.Method, r.URL.Path)
+		next.ServeHTTP(w, r)
+		fmt.Printf("← %s %s\n", r.Method, r.URL.Path)
+	})
+}
+
+// Header は全てのレスポンスにヘッダーを付ける
+func Header(key, value string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(key, value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func main() {
+	hello := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Println("  handler実行")
+		fmt.Fprint(w, "Hello")
+	})
+
+	// 外側から順に実行される: Logging → Header → hello
+	handler := Logging(Header("X-App", "workout")(hello))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/hello", nil))
+	fmt.Printf("%d %s X-App=%s\n", rec.Code, rec.Body.String(), rec.Header().Get("X-App"))
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// bucket はトークンバケット。rateトークン/秒で補充され、最大burst個まで貯まる
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimiter はクライアント（IPアドレス）ごとにリクエストの頻度を制限する
+type RateLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*bucket
+	now     func() time.Time
+}
+
+func NewRateLimiter(rate float64, burst int, now func() time.Time) *RateLimiter {
+	if now == nil {
+		now = time.Now
+	}
+	return &RateLimiter{rate: rate, burst: float64(burst), buckets: make(map[string]*bucket), now: now}
+}
+
+// Allow はkeyのリクエストを通してよいかと、だめなら何秒後に通るかを返す
+func (l *RateLimiter) Allow(key string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	// 前回からの経過時間分だけ補充する
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	return false, wait
+}
+
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		ok, wait := l.Allow(ip)
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			http.Error(w, "too many requests", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				http.Error(w, "internal server error", http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func Chain(h http.Handler, middlewares ...func(http.Handler) http.Handler) http.Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+func main() {
+	// 時刻を手で進められる時計（実行のたびに結果が変わらないようにする）
+	clock := time.Date(2025, 4, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewRateLimiter(1, 3, func() time.Time { return clock }) // 1回/秒、最大3回まで連続
+
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/panic" {
+			panic("boom")
+		}
+		fmt.Fprint(w, "ok")
+	})
+	// Recovererを一番外側に置き、他のミドルウェアのpanicも拾う
+	handler := Chain(api, Recoverer, limiter.Middleware)
+
+	request := func(label, ip, path string) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = ip + ":12345"
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		line := fmt.Sprintf("%s %s %s → %d", label, ip, path, rec.Code)
+		if ra := rec.Header().Get("Retry-After"); ra != "" {
+			line += " Retry-After=" + ra
+		}
+		fmt.Println(line)
+	}
+
+	for i := 1; i <= 5; i++ {
+		request(fmt.Sprintf("t=0s #%d", i), "192.0.2.1", "/")
+	}
+	request("t=0s", "192.0.2.2", "/") // 別のクライアントは別のバケット
+
+	clock = clock.Add(2 * time.Second) // 2秒で2トークン補充
+	for i := 1; i <= 3; i++ {
+		request(fmt.Sprintf("t=2s #%d", i), "192.0.2.1", "/")
+	}
+
+	request("t=2s", "192.0.2.2", "/panic")
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"runtime/debug"
+	"strings"
+)
+
+// Recoverer はハンドラーのpanicをrecoverして500を返す（10-panic-recoverのRunJobと同じ考え方）
+// net/httpも接続ごとにrecoverするが、その場合クライアントには何も返らず接続が切れる
+func Recoverer(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// ErrAbortHandlerは意図的な中断なので、そのまま投げ直す
+			if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(rec)
+			}
+			// スタックトレースは調査用にログ（標準エラー）にだけ出し、クライアントには返さない
+			log.Printf("panic: %v\n%s", rec, debug.Stack())
+			fmt.Printf("  panic: %v\n", rec)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(map[string]string{"error": "internal server error"})
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+func main() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ok", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+	mux.HandleFunc("GET /nil", func(w http.ResponseWriter, r *http.Request) {
+		var m map[string]int
+		m["x"] = 1 // nil mapへの書き込みでpanic
+	})
+	mux.HandleFunc("GET /index", func(w http.ResponseWriter, r *http.Request) {
+		items := []string{"a"}
+		fmt.Fprint(w, items[len(r.URL.Query())+3])
+	})
+	handler := Recoverer(mux)
+
+	for _, path := range []string{"/ok", "/nil", "/index", "/ok"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		fmt.Printf("GET %s → %d %s\n", path, rec.Code, strings.TrimSpace(rec.Body.String()))
+	}
+}
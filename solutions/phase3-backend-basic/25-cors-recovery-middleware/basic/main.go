@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+)
+
+// CORS は許可したオリジンからのブラウザのリクエストを受け付ける
+// ブラウザは別オリジンへのPUT・DELETEやJSONのPOSTの前に、OPTIONSのプリフライトで許可を確かめる
+func CORS(allowedOrigins []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin != "" && slices.Contains(allowedOrigins, origin) {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+				w.Header().Add("Vary", "Origin") // オリジンごとに応答が変わることをキャッシュに伝える
+			}
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE")
+				w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+				w.Header().Set("Access-Control-Max-Age", "600")
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func main() {
+	api := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	})
+	handler := CORS([]string{"http://localhost:5173"})(api)
+
+	requests := []struct{ method, origin, reqMethod string }{
+		{"GET", "http://localhost:5173", ""},
+		{"GET", "https://evil.example.com", ""},
+		{"OPTIONS", "http://localhost:5173", "PUT"},
+	}
+	for _, c := range requests {
+		req := httptest.NewRequest(c.method, "/api/todos", nil)
+		req.Header.Set("Origin", c.origin)
+		if c.reqMethod != "" {
+			req.Header.Set("Access-Control-Request-Method", c.reqMethod)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		fmt.Printf("%s from %s → %d %q\n", c.method, c.origin, rec.Code, strings.TrimSpace(rec.Body.String()))
+		for _, h := range []string{"Access-Control-Allow-Origin", "Access-Control-Allow-Methods"} {
+			if v := rec.Header().Get(h); v != "" {
+				fmt.Printf("  %s: %s\n", h, v)
+			}
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Rule は1つの検証ルール。paramはタグの=の後ろ
+type Rule func(v reflect.Value, param string) bool
+
+// Validator はルールを登録できるタグベースの検証器
+// ネストした構造体とスライスの要素も検証し、フィールドの位置を items[1].name のように返す
+type Validator struct {
+	rules map[string]Rule
+}
+
+func NewValidator() *Validator {
+	v := &Validator{rules: make(map[string]Rule)}
+	v.Register("required", func(f reflect.Value, _ string) bool { return !f.IsZero() })
+	v.Register("min", func(f reflect.Value, p string) bool { return size(f) >= atoi(p) })
+	v.Register("max", func(f reflect.Value, p string) bool { return size(f) <= atoi(p) })
+	v.Register("email", func(f reflect.Value, _ string) bool {
+		addr, err := mail.ParseAddress(f.String())
+		return err == nil && addr.Address == f.String()
+	})
+	return v
+}
+
+// Register はタグで使えるルールを足す
+func (v *Validator) Register(name string, r Rule) { v.rules[name] = r }
+
+func atoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		panic("validator: bad param " + strconv.Quote(s)) // タグの誤りはプログラムの誤り
+	}
+	return n
+}
+
+// size は文字列なら文字数、スライスなら要素数、数値なら値
+func size(f reflect.Value) int {
+	switch f.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(f.String())
+	case reflect.Slice, reflect.Map:
+		return f.Len()
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return int(f.Int())
+	}
+	panic("validator: unsupported kind " + f.Kind().String())
+}
+
+// ValidationErrors はフィールドごとのエラーの一覧
+type ValidationErrors []FieldError
+
+type FieldError struct {
+	Field string `json:"field"`
+	Rule  string `json:"rule"`
+}
+
+func (e ValidationErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, fe := range e {
+		parts[i] = fe.Field + ": " + fe.Rule
+	}
+	return "validation failed: " + strings.Join(parts, ", ")
+}
+
+func (v *Validator) Struct(s any) error {
+	var errs ValidationErrors
+	v.walk(reflect.Indirect(reflect.ValueOf(s)), "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (v *Validator) walk(rv reflect.Value, prefix string, errs *ValidationErrors) {
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		f, fv := rt.Field(i), rv.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" {
+			name = f.Name
+		}
+		path := prefix + name
+
+		failed := false
+		if tag := f.Tag.Get("validate"); tag != "" {
+			for _, rule := range strings.Split(tag, ",") {
+				rname, param, _ := strings.Cut(rule, "=")
+				fn, ok := v.rules[rname]
+				if !ok {
+					panic("validator: unknown rule " + strconv.Quote(rname))
+				}
+				if !fn(fv, param) {
+					*errs = append(*errs, FieldError{Field: path, Rule: rule})
+					failed = true
+					break
+				}
+			}
+		}
+		if failed {
+			continue
+		}
+		// 中身も検証する
+		switch {
+		case fv.Kind() == reflect.Struct:
+			v.walk(fv, path+".", errs)
+		case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Struct:
+			for j := range fv.Len() {
+				v.walk(fv.Index(j), fmt.Sprintf("%s[%d].", path, j), errs)
+			}
+		}
+	}
+}
+
+// 注文のリクエスト（ネストした構造体とスライス）
+type OrderRequest struct {
+	Email   string      `json:"email" validate:"required,email"`
+	Coupon  string      `json:"coupon" validate:"coupon"`
+	Address Address     `json:"address"`
+	Items   []OrderItem `json:"items" validate:"min=1,max=10"`
+}
+
+type Address struct {
+	Zip  string `json:"zip" validate:"required,zip"`
+	City string `json:"city" validate:"required"`
+}
+
+type OrderItem struct {
+	ProductID int `json:"product_id" validate:"min=1"`
+	Quantity  int `json:"quantity" validate:"min=1,max=99"`
+}
+
+func main() {
+	v := NewValidator()
+	zipRe := regexp.MustCompile(`^\d{3}-\d{4}$`)
+	v.Register("zip", func(f reflect.Value, _ string) bool { return zipRe.MatchString(f.String()) })
+	// 空は許す（任意項目）。指定されたら大文字英数字8文字
+	couponRe := regexp.MustCompile(`^[A-Z0-9]{8}$`)
+	v.Register("coupon", func(f reflect.Value, _ string) bool { return f.String() == "" || couponRe.MatchString(f.String()) })
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req OrderRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid JSON", http.StatusBadRequest)
+			return
+		}
+		var verrs ValidationErrors
+		if err := v.Struct(req); errors.As(err, &verrs) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]any{"errors": verrs})
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "order accepted: %d items", len(req.Items))
+	})
+
+	bodies := []string{
+		`{"email": "tanaka@example.com", "address": {"zip": "100-0001", "city": "千代田区"},
+		  "items": [{"product_id": 1, "quantity": 2}]}`,
+		`{"email": "田中 <tanaka@example.com>", "coupon": "abc", "address": {"zip": "1000001"},
+		  "items": [{"product_id": 1, "quantity": 2}, {"product_id": 0, "quantity": 100}]}`,
+		`{"email": "", "address": {"zip": "100-0001", "city": "千代田区"}, "items": []}`,
+	}
+	for _, body := range bodies {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body)))
+		fmt.Printf("%d %s\n", rec.Code, strings.TrimSpace(rec.Body.String()))
+	}
+
+	// errorとしても使える
+	fmt.Println(v.Struct(OrderRequest{Email: "x@example.com", Address: Address{Zip: "100-0001", City: "東京"}}))
+}
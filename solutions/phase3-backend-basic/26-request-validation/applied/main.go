@@ -0,0 +1,128 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// 構造体タグで検証ルールを書き、reflectで読んで検証する
+// （go-playground/validatorと同じ考え方を小さく実装する）
+//
+//	Name string `validate:"required,min=3,max=20"`
+
+type CreateProductRequest struct {
+	Name     string `json:"name" validate:"required,min=3,max=20"`
+	Price    int    `json:"price" validate:"min=1,max=1000000"`
+	Stock    int    `json:"stock" validate:"min=0"`
+	Category string `json:"category" validate:"oneof=book food toy"`
+	Note     string `json:"note"` // タグがなければ検証しない
+}
+
+type FieldError struct {
+	Field string
+	Rule  string
+	Param string
+}
+
+func (e FieldError) Error() string {
+	if e.Param == "" {
+		return fmt.Sprintf("%s: failed %s", e.Field, e.Rule)
+	}
+	return fmt.Sprintf("%s: failed %s=%s", e.Field, e.Rule, e.Param)
+}
+
+// Validate はvの構造体のvalidateタグのルールを確かめる
+func Validate(v any) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: want struct, got %s", rv.Kind())
+	}
+	var errs []error
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		f := rt.Field(i)
+		tag := f.Tag.Get("validate")
+		if tag == "" {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" {
+			name = f.Name
+		}
+		for _, rule := range strings.Split(tag, ",") {
+			rule, param, _ := strings.Cut(rule, "=")
+			ok, err := check(rv.Field(i), rule, param)
+			if err != nil {
+				return fmt.Errorf("validate %s: %w", name, err)
+			}
+			if !ok {
+				errs = append(errs, FieldError{Field: name, Rule: rule, Param: param})
+				break // 1つのフィールドは最初に失敗したルールだけ報告する
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// check は1つのルールを確かめる。文字列のmin/maxは文字数、数値は値で比べる
+func check(v reflect.Value, rule, param string) (bool, error) {
+	switch rule {
+	case "required":
+		return !v.IsZero(), nil
+	case "min", "max":
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return false, fmt.Errorf("rule %s: bad param %q", rule, param)
+		}
+		var size int
+		switch v.Kind() {
+		case reflect.String:
+			size = utf8.RuneCountInString(v.String())
+		case reflect.Int, reflect.Int64, reflect.Int32:
+			size = int(v.Int())
+		default:
+			return false, fmt.Errorf("rule %s: unsupported kind %s", rule, v.Kind())
+		}
+		if rule == "min" {
+			return size >= n, nil
+		}
+		return size <= n, nil
+	case "oneof":
+		for _, s := range strings.Fields(param) {
+			if v.String() == s {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown rule %q", rule)
+}
+
+func main() {
+	requests := []CreateProductRequest{
+		{Name: "Goの本", Price: 3000, Stock: 10, Category: "book"},
+		{Name: "Go", Price: 0, Stock: -1, Category: "car"},
+		{Price: 2000000, Category: "food"},
+	}
+	for i, req := range requests {
+		if err := Validate(req); err != nil {
+			fmt.Printf("#%d NG\n%s\n", i+1, indent(err.Error()))
+			continue
+		}
+		fmt.Printf("#%d OK\n", i+1)
+	}
+
+	// タグのルールの誤りはプログラムの誤りなので、検証エラーとは別に返す
+	type bad struct {
+		N int `validate:"min=abc"`
+	}
+	fmt.Println("Error:", Validate(bad{}))
+}
+
+func indent(s string) string {
+	return "  " + strings.ReplaceAll(s, "\n", "\n  ")
+}
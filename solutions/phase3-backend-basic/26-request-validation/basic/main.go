@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"unicode/utf8"
+)
+
+type RegisterRequest struct {
+	Username string
+	Email    string
+	Password string
+	Age      int
+}
+
+// FieldError は1つのフィールドの検証エラー
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string { return e.Field + ": " + e.Message }
+
+// Validate は06-error-handling-basicsのValidateRequestと違い、最初のエラーで止めずに全ての問題を返す
+// （フォームの全ての入力欄にまとめてエラーを表示できる）
+func (r RegisterRequest) Validate() error {
+	var errs []error
+	switch n := utf8.RuneCountInString(r.Username); {
+	case n == 0:
+		errs = append(errs, &FieldError{"username", "is required"})
+	case n < 3 || n > 20:
+		errs = append(errs, &FieldError{"username", "must be 3-20 characters"})
+	}
+	if _, err := mail.ParseAddress(r.Email); err != nil {
+		errs = append(errs, &FieldError{"email", "must be a valid email address"})
+	}
+	if len(r.Password) < 8 {
+		errs = append(errs, &FieldError{"password", "must be at least 8 characters"})
+	}
+	if r.Age < 0 || r.Age > 150 {
+		errs = append(errs, &FieldError{"age", "must be between 0 and 150"})
+	}
+	return errors.Join(errs...) // errsが空ならnil
+}
+
+func main() {
+	requests := []RegisterRequest{
+		{Username: "tanaka", Email: "tanaka@example.com", Password: "password123", Age: 30},
+		{Username: "田中", Email: "tanaka@", Password: "short", Age: 200},
+		{Email: "tanaka@example.com", Password: "password123"},
+	}
+	for i, req := range requests {
+		err := req.Validate()
+		if err == nil {
+			fmt.Printf("#%d OK\n", i+1)
+			continue
+		}
+		fmt.Printf("#%d NG\n", i+1)
+		// errors.Joinしたエラーは Unwrap() []error で1つずつ取り出せる
+		for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+			fmt.Println("  -", e)
+		}
+		var fe *FieldError
+		if errors.As(err, &fe) {
+			fmt.Println("  最初のフィールド:", fe.Field)
+		}
+	}
+}
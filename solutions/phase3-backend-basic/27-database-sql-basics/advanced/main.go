@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"workout/lessons/fakesql"
+)
+
+// NewDB は接続プールを設定したsql.DBを作る
+// sql.DBは1つの接続ではなく接続プールで、アプリケーション全体で1つを共有する
+func NewDB(connector driver.Connector) *sql.DB {
+	db := sql.OpenDB(connector)
+	db.SetMaxOpenConns(2)                   // 同時に使う接続の上限（DBのmax_connectionsを超えないように）
+	db.SetMaxIdleConns(2)                   // 使い終わった接続を残しておく数
+	db.SetConnMaxLifetime(30 * time.Minute) // 古い接続を作り直す（DBやLBの接続切断に備える）
+	db.SetConnMaxIdleTime(5 * time.Minute)  // 使われない接続を閉じる
+	return db
+}
+
+func printStats(label string, db *sql.DB) {
+	s := db.Stats()
+	fmt.Printf("%s: open=%d inUse=%d idle=%d waitCount=%d\n", label, s.OpenConnections, s.InUse, s.Idle, s.WaitCount)
+}
+
+func countOrders(ctx context.Context, db *sql.DB) (int, error) {
+	var n int
+	err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM orders").Scan(&n)
+	return n, err
+}
+
+func main() {
+	db := NewDB(newOrderDB())
+	defer db.Close()
+
+	ctx := context.Background()
+	if _, err := countOrders(ctx, db); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	printStats("クエリの後", db)
+
+	// トランザクションは終わるまで接続を1つ占有する
+	tx1, _ := db.BeginTx(ctx, nil)
+	tx2, _ := db.BeginTx(ctx, nil)
+	printStats("トランザクション2つ", db)
+
+	// 空いている接続がないので待つ。待つ時間はcontextで制限する
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err := countOrders(waitCtx, db)
+	fmt.Println("Error:", err)
+	fmt.Println("期限切れか:", errors.Is(err, context.DeadlineExceeded))
+	printStats("接続待ちの後", db)
+
+	// 接続を返せば使える（Commit/Rollbackし忘れると接続が枯渇する）
+	tx1.Commit()
+	tx2.Rollback()
+	n, err := countOrders(ctx, db)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println("注文数:", n)
+	printStats("返却後", db)
+}
+
+// newOrderDB はordersテーブルを持つメモリ上のDB
+func newOrderDB() *fakesql.DB {
+	return &fakesql.DB{Queries: map[string]fakesql.QueryFunc{
+		"SELECT COUNT(*) FROM orders": func(args []driver.Value) (*fakesql.Result, error) {
+			return &fakesql.Result{Columns: []string{"count"}, Rows: [][]driver.Value{{int64(42)}}}, nil
+		},
+	}}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"workout/lessons/fakesql"
+)
+
+type Product struct {
+	ID    int64
+	Name  string
+	Price int64
+}
+
+func main() {
+	fakedb := newProductDB()
+	db := sql.OpenDB(fakedb)
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	// Prepared Statement: SQLを1回だけ準備し、値を変えて何度も実行する
+	stmt, err := db.Prepare("INSERT INTO products (name, price) VALUES (?, ?)")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer stmt.Close()
+
+	for _, p := range []Product{{Name: "Goの本", Price: 3000}, {Name: "キーボード", Price: 12000}, {Name: "マウス", Price: 4000}} {
+		res, err := stmt.Exec(p.Name, p.Price)
+		if err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+		id, _ := res.LastInsertId()
+		fmt.Printf("INSERT id=%d %s\n", id, p.Name)
+	}
+	fmt.Println("Prepareされた回数:", fakedb.Prepares())
+
+	// プレースホルダを使えば、値はSQLの一部として解釈されない（SQLインジェクションを防ぐ）
+	input := "' OR '1'='1"
+	unsafe := "SELECT id, name, price FROM products WHERE name = '" + input + "'"
+	fmt.Println("文字列連結したSQL:", unsafe) // WHEREが常に真になり全件返ってしまう
+
+	var p Product
+	err = db.QueryRow("SELECT id, name, price FROM products WHERE name = ?", input).Scan(&p.ID, &p.Name, &p.Price)
+	fmt.Println("プレースホルダ:", err)
+
+	err = db.QueryRow("SELECT id, name, price FROM products WHERE name = ?", "マウス").Scan(&p.ID, &p.Name, &p.Price)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("取得: %+v\n", p)
+}
+
+// newProductDB はproductsテーブルを持つメモリ上のDB
+func newProductDB() *fakesql.DB {
+	var products []Product
+	return &fakesql.DB{Queries: map[string]fakesql.QueryFunc{
+		"INSERT INTO products (name, price) VALUES (?, ?)": func(args []driver.Value) (*fakesql.Result, error) {
+			p := Product{ID: int64(len(products) + 1), Name: args[0].(string), Price: args[1].(int64)}
+			products = append(products, p)
+			return &fakesql.Result{Affected: 1, LastID: p.ID}, nil
+		},
+		"SELECT id, name, price FROM products WHERE name = ?": func(args []driver.Value) (*fakesql.Result, error) {
+			r := &fakesql.Result{Columns: []string{"id", "name", "price"}}
+			for _, p := range products {
+				if p.Name == args[0].(string) {
+					r.Rows = append(r.Rows, []driver.Value{p.ID, p.Name, p.Price})
+				}
+			}
+			return r, nil
+		},
+	}}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"workout/lessons/fakesql"
+)
+
+type User struct {
+	ID    int64
+	Name  string
+	Email string
+}
+
+// findUser はIDでユーザーを1件取得する。見つからなければsql.ErrNoRowsをラップして返す
+func findUser(db *sql.DB, id int64) (*User, error) {
+	var u User
+	err := db.QueryRow("SELECT id, name, email FROM users WHERE id = ?", id).Scan(&u.ID, &u.Name, &u.Email)
+	if err != nil {
+		return nil, fmt.Errorf("find user %d: %w", id, err)
+	}
+	return &u, nil
+}
+
+// listUsers は全てのユーザーを取得する
+func listUsers(db *sql.DB) ([]User, error) {
+	rows, err := db.Query("SELECT id, name, email FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // 閉じないと接続がプールに戻らない
+
+	var users []User
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Name, &u.Email); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err() // 途中で起きたエラーはループの後で確かめる
+}
+
+func main() {
+	// 本番では sql.Open("pgx", "postgres://...") で開く
+	db := sql.OpenDB(newUserDB())
+	defer db.Close()
+
+	// sql.Open/OpenDBは接続しないので、Pingで接続できるか確かめる
+	if err := db.Ping(); err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println("接続OK")
+
+	u, err := findUser(db, 1)
+	if err != nil {
+		fmt.Println("Error:", err)
+	} else {
+		fmt.Printf("取得: %+v\n", *u)
+	}
+
+	_, err = findUser(db, 99)
+	fmt.Println("Error:", err)
+	fmt.Println("ErrNoRowsか:", errors.Is(err, sql.ErrNoRows))
+
+	users, err := listUsers(db)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for _, u := range users {
+		fmt.Printf("  %d %s <%s>\n", u.ID, u.Name, u.Email)
+	}
+}
+
+// newUserDB はusersテーブルを持つメモリ上のDB
+func newUserDB() *fakesql.DB {
+	users := []User{
+		{1, "田中太郎", "tanaka@example.com"},
+		{2, "鈴木花子", "suzuki@example.com"},
+	}
+	row := func(u User) []driver.Value { return []driver.Value{u.ID, u.Name, u.Email} }
+	cols := []string{"id", "name", "email"}
+	return &fakesql.DB{Queries: map[string]fakesql.QueryFunc{
+		"SELECT id, name, email FROM users WHERE id = ?": func(args []driver.Value) (*fakesql.Result, error) {
+			r := &fakesql.Result{Columns: cols}
+			for _, u := range users {
+				if u.ID == args[0].(int64) {
+					r.Rows = append(r.Rows, row(u))
+				}
+			}
+			return r, nil
+		},
+		"SELECT id, name, email FROM users ORDER BY id": func(args []driver.Value) (*fakesql.Result, error) {
+			r := &fakesql.Result{Columns: cols}
+			for _, u := range users {
+				r.Rows = append(r.Rows, row(u))
+			}
+			return r, nil
+		},
+	}}
+}
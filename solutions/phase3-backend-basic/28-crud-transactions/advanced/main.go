@@ -0,0 +1,138 @@
+// ExecuteInTxはworkout/lessons/txのExecuteInSQLTxを使う（テストもそちら）
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+
+	"workout/lessons/fakesql"
+	"workout/lessons/tx"
+)
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// Transfer は02-pointer-basicsのTransferをトランザクションで書いたもの
+// 引き落としと入金のどちらかが失敗したら、両方をなかったことにする
+func Transfer(ctx context.Context, db *sql.DB, from, to int64, amount int64) error {
+	return tx.ExecuteInSQLTx(ctx, db, func(t *sql.Tx) error {
+		// 実際のDBでは SELECT ... FOR UPDATE で行をロックし、他の送金と競合しないようにする
+		var balance int64
+		if err := t.QueryRowContext(ctx, "SELECT balance FROM accounts WHERE id = ?", from).Scan(&balance); err != nil {
+			return fmt.Errorf("account %d: %w", from, err)
+		}
+		if _, err := t.ExecContext(ctx, "UPDATE accounts SET balance = balance - ? WHERE id = ?", amount, from); err != nil {
+			return err
+		}
+		res, err := t.ExecContext(ctx, "UPDATE accounts SET balance = balance + ? WHERE id = ?", amount, to)
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return fmt.Errorf("account %d: %w", to, sql.ErrNoRows)
+		}
+		// 引き落とした後で残高を確かめる（制約違反の代わり）
+		if balance < amount {
+			return fmt.Errorf("transfer %d from account %d: %w", amount, from, ErrInsufficientFunds)
+		}
+		return nil
+	})
+}
+
+func printBalances(ctx context.Context, db *sql.DB) {
+	rows, err := db.QueryContext(ctx, "SELECT id, owner, balance FROM accounts ORDER BY id")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	defer rows.Close()
+	var parts []string
+	for rows.Next() {
+		var id, balance int64
+		var owner string
+		if err := rows.Scan(&id, &owner, &balance); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		parts = append(parts, fmt.Sprintf("%s=%d", owner, balance))
+	}
+	fmt.Println("  残高:", strings.Join(parts, ", "))
+}
+
+func main() {
+	db := sql.OpenDB(newBankDB())
+	defer db.Close()
+	ctx := context.Background()
+
+	fmt.Println("=== 送金成功 ===")
+	fmt.Println("  Error:", Transfer(ctx, db, 1, 2, 300))
+	printBalances(ctx, db)
+
+	fmt.Println("=== 残高不足（ロールバック） ===")
+	err := Transfer(ctx, db, 1, 2, 10000)
+	fmt.Println("  Error:", err)
+	fmt.Println("  残高不足か:", errors.Is(err, ErrInsufficientFunds))
+	printBalances(ctx, db)
+
+	fmt.Println("=== 送金先がない（ロールバック） ===")
+	err = Transfer(ctx, db, 1, 99, 100)
+	fmt.Println("  Error:", err)
+	printBalances(ctx, db)
+
+	fmt.Println("=== panic（ロールバックしてから伝え直す） ===")
+	func() {
+		defer func() { fmt.Println("  recovered:", recover()) }()
+		tx.ExecuteInSQLTx(ctx, db, func(t *sql.Tx) error {
+			t.ExecContext(ctx, "UPDATE accounts SET balance = balance - ? WHERE id = ?", int64(500), int64(1))
+			panic("unexpected")
+		})
+	}()
+	printBalances(ctx, db)
+}
+
+// newBankDB はaccountsテーブルを持つメモリ上のDB。ロールバックで開始時の残高に戻す
+func newBankDB() *fakesql.DB {
+	owners := map[int64]string{1: "Alice", 2: "Bob"}
+	balances := map[int64]int64{1: 1000, 2: 500}
+	db := &fakesql.DB{
+		Snapshot: func() func() {
+			saved := maps.Clone(balances)
+			return func() { balances = saved }
+		},
+	}
+	db.Queries = map[string]fakesql.QueryFunc{
+		"SELECT balance FROM accounts WHERE id = ?": func(args []driver.Value) (*fakesql.Result, error) {
+			r := &fakesql.Result{Columns: []string{"balance"}}
+			if b, ok := balances[args[0].(int64)]; ok {
+				r.Rows = append(r.Rows, []driver.Value{b})
+			}
+			return r, nil
+		},
+		"UPDATE accounts SET balance = balance - ? WHERE id = ?": func(args []driver.Value) (*fakesql.Result, error) {
+			return addBalance(balances, args[1].(int64), -args[0].(int64)), nil
+		},
+		"UPDATE accounts SET balance = balance + ? WHERE id = ?": func(args []driver.Value) (*fakesql.Result, error) {
+			return addBalance(balances, args[1].(int64), args[0].(int64)), nil
+		},
+		"SELECT id, owner, balance FROM accounts ORDER BY id": func(args []driver.Value) (*fakesql.Result, error) {
+			r := &fakesql.Result{Columns: []string{"id", "owner", "balance"}}
+			for _, id := range []int64{1, 2} {
+				r.Rows = append(r.Rows, []driver.Value{id, owners[id], balances[id]})
+			}
+			return r, nil
+		},
+	}
+	return db
+}
+
+func addBalance(balances map[int64]int64, id, delta int64) *fakesql.Result {
+	if _, ok := balances[id]; !ok {
+		return &fakesql.Result{}
+	}
+	balances[id] += delta
+	return &fakesql.Result{Affected: 1}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"workout/lessons/fakesql"
+)
+
+// users.nickname と users.last_login_at はNULLを許すカラム
+// NULLをstringやtime.TimeにScanするとエラーになるので、sql.Null*型かポインタで受ける
+type User struct {
+	ID          int64
+	Name        string
+	Nickname    sql.NullString // Validがfalseなら NULL
+	LastLoginAt *time.Time     // nilなら NULL
+}
+
+func (u User) String() string {
+	nick := "(未設定)"
+	if u.Nickname.Valid {
+		nick = u.Nickname.String
+	}
+	login := "(未ログイン)"
+	if u.LastLoginAt != nil {
+		login = u.LastLoginAt.Format("2006-01-02 15:04")
+	}
+	return fmt.Sprintf("%d %s nickname=%s last_login=%s", u.ID, u.Name, nick, login)
+}
+
+func listUsers(ctx context.Context, db *sql.DB) ([]User, error) {
+	rows, err := db.QueryContext(ctx, "SELECT id, name, nickname, last_login_at FROM users ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var users []User
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Name, &u.Nickname, &u.LastLoginAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func main() {
+	db := sql.OpenDB(newUserDB())
+	defer db.Close()
+	ctx := context.Background()
+
+	// NULLを書き込むときもsql.Null*型かnilを渡す
+	_, err := db.ExecContext(ctx, "UPDATE users SET nickname = ? WHERE id = ?", sql.NullString{}, 1)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	_, err = db.ExecContext(ctx, "UPDATE users SET nickname = ? WHERE id = ?", sql.NullString{String: "はなちゃん", Valid: true}, 2)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+
+	users, err := listUsers(ctx, db)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	for _, u := range users {
+		fmt.Println(u)
+	}
+
+	// NULLをstringにScanするとエラー
+	var nickname string
+	err = db.QueryRowContext(ctx, "SELECT nickname FROM users WHERE id = ?", 1).Scan(&nickname)
+	fmt.Println("Error:", err)
+
+	// COALESCEでSQL側でデフォルト値に置き換える方法もある
+	err = db.QueryRowContext(ctx, "SELECT COALESCE(nickname, '') FROM users WHERE id = ?", 1).Scan(&nickname)
+	fmt.Printf("COALESCE: %q err=%v\n", nickname, err)
+}
+
+// newUserDB はNULLを含むusersテーブルを持つメモリ上のDB
+func newUserDB() *fakesql.DB {
+	login := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	type row struct {
+		id       int64
+		name     string
+		nickname driver.Value // nil か string
+		login    driver.Value // nil か time.Time
+	}
+	users := []*row{
+		{1, "田中太郎", "たなっち", login},
+		{2, "鈴木花子", nil, nil},
+	}
+	find := func(id driver.Value) *row {
+		for _, u := range users {
+			if u.id == id.(int64) {
+				return u
+			}
+		}
+		return nil
+	}
+	return &fakesql.DB{Queries: map[string]fakesql.QueryFunc{
+		"UPDATE users SET nickname = ? WHERE id = ?": func(args []driver.Value) (*fakesql.Result, error) {
+			u := find(args[1])
+			if u == nil {
+				return &fakesql.Result{}, nil
+			}
+			u.nickname = args[0]
+			return &fakesql.Result{Affected: 1}, nil
+		},
+		"SELECT id, name, nickname, last_login_at FROM users ORDER BY id": func(args []driver.Value) (*fakesql.Result, error) {
+			r := &fakesql.Result{Columns: []string{"id", "name", "nickname", "last_login_at"}}
+			for _, u := range users {
+				r.Rows = append(r.Rows, []driver.Value{u.id, u.name, u.nickname, u.login})
+			}
+			return r, nil
+		},
+		"SELECT nickname FROM users WHERE id = ?": func(args []driver.Value) (*fakesql.Result, error) {
+			return &fakesql.Result{Columns: []string{"nickname"}, Rows: [][]driver.Value{{find(args[0]).nickname}}}, nil
+		},
+		"SELECT COALESCE(nickname, '') FROM users WHERE id = ?": func(args []driver.Value) (*fakesql.Result, error) {
+			v := find(args[0]).nickname
+			if v == nil {
+				v = ""
+			}
+			return &fakesql.Result{Columns: []string{"coalesce"}, Rows: [][]driver.Value{{v}}}, nil
+		},
+	}}
+}
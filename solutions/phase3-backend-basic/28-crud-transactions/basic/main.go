@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"workout/lessons/fakesql"
+)
+
+type Todo struct {
+	ID    int64
+	Title string
+	Done  bool
+}
+
+var ErrNotFound = errors.New("todo not found")
+
+// TodoRepository はtodosテーブルのCRUD
+type TodoRepository struct {
+	db *sql.DB
+}
+
+func (r *TodoRepository) Create(ctx context.Context, title string) (int64, error) {
+	res, err := r.db.ExecContext(ctx, "INSERT INTO todos (title, done) VALUES (?, ?)", title, false)
+	if err != nil {
+		return 0, fmt.Errorf("create todo: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+func (r *TodoRepository) Get(ctx context.Context, id int64) (*Todo, error) {
+	var t Todo
+	err := r.db.QueryRowContext(ctx, "SELECT id, title, done FROM todos WHERE id = ?", id).Scan(&t.ID, &t.Title, &t.Done)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound // 呼び出し側がdatabase/sqlに依存しないよう、自分のエラーに変える
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get todo %d: %w", id, err)
+	}
+	return &t, nil
+}
+
+func (r *TodoRepository) SetDone(ctx context.Context, id int64, done bool) error {
+	res, err := r.db.ExecContext(ctx, "UPDATE todos SET done = ? WHERE id = ?", done, id)
+	if err != nil {
+		return fmt.Errorf("update todo %d: %w", id, err)
+	}
+	return checkAffected(res)
+}
+
+func (r *TodoRepository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM todos WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete todo %d: %w", id, err)
+	}
+	return checkAffected(res)
+}
+
+// checkAffected はUPDATE/DELETEが1行も変えなかったらErrNotFoundにする
+// （存在しないIDへのUPDATEはSQLとしてはエラーにならない）
+func checkAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func main() {
+	db := sql.OpenDB(newTodoDB())
+	defer db.Close()
+	repo := &TodoRepository{db: db}
+	ctx := context.Background()
+
+	id, err := repo.Create(ctx, "Go学習")
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Println("Create: id =", id)
+
+	t, _ := repo.Get(ctx, id)
+	fmt.Printf("Get: %+v\n", *t)
+
+	fmt.Println("SetDone:", repo.SetDone(ctx, id, true))
+	t, _ = repo.Get(ctx, id)
+	fmt.Printf("Get: %+v\n", *t)
+
+	fmt.Println("Delete:", repo.Delete(ctx, id))
+	_, err = repo.Get(ctx, id)
+	fmt.Println("Get after delete:", err)
+	fmt.Println("Delete again:", repo.Delete(ctx, id))
+	fmt.Println("SetDone missing:", repo.SetDone(ctx, 99, true))
+}
+
+// newTodoDB はtodosテーブルを持つメモリ上のDB
+func newTodoDB() *fakesql.DB {
+	todos := map[int64]*Todo{}
+	var nextID int64 = 1
+	return &fakesql.DB{Queries: map[string]fakesql.QueryFunc{
+		"INSERT INTO todos (title, done) VALUES (?, ?)": func(args []driver.Value) (*fakesql.Result, error) {
+			id := nextID
+			nextID++
+			todos[id] = &Todo{ID: id, Title: args[0].(string), Done: args[1].(bool)}
+			return &fakesql.Result{Affected: 1, LastID: id}, nil
+		},
+		"SELECT id, title, done FROM todos WHERE id = ?": func(args []driver.Value) (*fakesql.Result, error) {
+			r := &fakesql.Result{Columns: []string{"id", "title", "done"}}
+			if t, ok := todos[args[0].(int64)]; ok {
+				r.Rows = append(r.Rows, []driver.Value{t.ID, t.Title, t.Done})
+			}
+			return r, nil
+		},
+		"UPDATE todos SET done = ? WHERE id = ?": func(args []driver.Value) (*fakesql.Result, error) {
+			t, ok := todos[args[1].(int64)]
+			if !ok {
+				return &fakesql.Result{}, nil
+			}
+			t.Done = args[0].(bool)
+			return &fakesql.Result{Affected: 1}, nil
+		},
+		"DELETE FROM todos WHERE id = ?": func(args []driver.Value) (*fakesql.Result, error) {
+			id := args[0].(int64)
+			if _, ok := todos[id]; !ok {
+				return &fakesql.Result{}, nil
+			}
+			delete(todos, id)
+			return &fakesql.Result{Affected: 1}, nil
+		},
+	}}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type Post struct {
+	ID        int
+	Title     string
+	CreatedAt time.Time
+}
+
+// Cursor は最後に返した行の並び順のキー。(created_at, id)で一意に決まるようIDも含める
+type Cursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int       `json:"id"`
+}
+
+// Encode はクライアントに渡す不透明な文字列にする（中身に依存させない）
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+var ErrBadCursor = errors.New("invalid cursor")
+
+func DecodeCursor(s string) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrBadCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrBadCursor
+	}
+	return &c, nil
+}
+
+// PostStore はpostsテーブルの代わり。新しい順に並べて返す
+type PostStore struct {
+	posts []Post
+}
+
+// before は
+//
+//	SELECT id, title, created_at FROM posts
+//	WHERE (created_at, id) < (?, ?)
+//	ORDER BY created_at DESC, id DESC LIMIT ?
+//
+// の結果。OFFSETと違い読み飛ばす行がないので、後ろのページでも速い（(created_at, id)のインデックスを使う）
+func (s *PostStore) before(c *Cursor, limit int) []Post {
+	sorted := append([]Post(nil), s.posts...)
+	sort.Slice(sorted, func(i, j int) bool { return less(sorted[j], sorted[i]) })
+	var rows []Post
+	for _, p := range sorted {
+		if c != nil && !less(p, Post{ID: c.ID, CreatedAt: c.CreatedAt}) {
+			continue
+		}
+		if len(rows) == limit {
+			break
+		}
+		rows = append(rows, p)
+	}
+	return rows
+}
+
+func less(a, b Post) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID < b.ID
+}
+
+// offset は LIMIT ? OFFSET ? の結果（比較用）
+func (s *PostStore) offset(offset, limit int) []Post {
+	rows := s.before(nil, len(s.posts))
+	if offset >= len(rows) {
+		return nil
+	}
+	return rows[offset:min(offset+limit, len(rows))]
+}
+
+type PageResult struct {
+	Posts      []Post
+	NextCursor string // 空なら最後のページ
+}
+
+// List はcursorの次のページを返す。limit+1件取って、次のページがあるかを判定する
+func (s *PostStore) List(cursor string, limit int) (*PageResult, error) {
+	var c *Cursor
+	if cursor != "" {
+		var err error
+		if c, err = DecodeCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+	rows := s.before(c, limit+1)
+	res := &PageResult{Posts: rows}
+	if len(rows) > limit {
+		res.Posts = rows[:limit]
+		last := res.Posts[limit-1]
+		res.NextCursor = Cursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	return res, nil
+}
+
+func titles(posts []Post) []string {
+	var list []string
+	for _, p := range posts {
+		list = append(list, p.Title)
+	}
+	return list
+}
+
+func main() {
+	base := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	store := &PostStore{}
+	for i := 1; i <= 7; i++ {
+		// 投稿3と4は同じ時刻（IDで順序を決める）
+		at := base.Add(time.Duration(min(i, 3)+max(i-4, 0)) * time.Hour)
+		store.posts = append(store.posts, Post{ID: i, Title: fmt.Sprintf("投稿%d", i), CreatedAt: at})
+	}
+
+	fmt.Println("=== カーソル方式 ===")
+	page1, _ := store.List("", 3)
+	fmt.Println("1ページ目:", titles(page1.Posts))
+
+	// 1ページ目を読んだ後に新しい投稿が増えても、2ページ目はずれない
+	store.posts = append(store.posts, Post{ID: 8, Title: "投稿8(新着)", CreatedAt: base.Add(10 * time.Hour)})
+
+	page2, _ := store.List(page1.NextCursor, 3)
+	fmt.Println("2ページ目:", titles(page2.Posts))
+	page3, _ := store.List(page2.NextCursor, 3)
+	fmt.Println("3ページ目:", titles(page3.Posts), "次のページあり:", page3.NextCursor != "")
+
+	fmt.Println("\n=== OFFSET方式 ===")
+	// 同じ状況で、OFFSETは新着の分だけずれて同じ投稿をもう一度返してしまう
+	fmt.Println("2ページ目:", titles(store.offset(3, 3)))
+
+	_, err := store.List("not-a-cursor!", 3)
+	fmt.Println("\nError:", err)
+}
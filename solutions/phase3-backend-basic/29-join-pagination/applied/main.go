@@ -0,0 +1,107 @@
+package main
+
+import "fmt"
+
+// usersとordersのテーブルの代わり
+type User struct {
+	ID   int
+	Name string
+}
+
+type Order struct {
+	ID     int
+	UserID int
+	Total  int
+}
+
+var (
+	users  = []User{{1, "田中"}, {2, "鈴木"}, {3, "佐藤"}}
+	orders = []Order{{101, 1, 3000}, {102, 1, 1500}, {103, 2, 8000}}
+)
+
+var queryCount int
+
+// ordersByUser は「SELECT ... FROM orders WHERE user_id = ?」の代わり
+func ordersByUser(userID int) []Order {
+	queryCount++
+	var list []Order
+	for _, o := range orders {
+		if o.UserID == userID {
+			list = append(list, o)
+		}
+	}
+	return list
+}
+
+type UserOrder struct {
+	UserName string
+	OrderID  *int // LEFT JOINで注文がなければNULL
+	Total    *int
+}
+
+// innerJoin は
+//
+//	SELECT u.name, o.id, o.total FROM users u
+//	INNER JOIN orders o ON o.user_id = u.id ORDER BY u.id, o.id
+//
+// の結果。両方のテーブルに一致する行だけ返す（注文のないユーザーは出ない）
+func innerJoin() []UserOrder {
+	queryCount++
+	var rows []UserOrder
+	for _, u := range users {
+		for _, o := range orders {
+			if o.UserID == u.ID {
+				rows = append(rows, UserOrder{u.Name, &o.ID, &o.Total})
+			}
+		}
+	}
+	return rows
+}
+
+// leftJoin は LEFT JOIN の結果。左（users）の行は全て返し、一致しなければ右はNULL
+func leftJoin() []UserOrder {
+	queryCount++
+	var rows []UserOrder
+	for _, u := range users {
+		found := false
+		for _, o := range orders {
+			if o.UserID == u.ID {
+				rows = append(rows, UserOrder{u.Name, &o.ID, &o.Total})
+				found = true
+			}
+		}
+		if !found {
+			rows = append(rows, UserOrder{UserName: u.Name})
+		}
+	}
+	return rows
+}
+
+func (r UserOrder) String() string {
+	if r.OrderID == nil {
+		return fmt.Sprintf("%s order=NULL total=NULL", r.UserName)
+	}
+	return fmt.Sprintf("%s order=%d total=%d", r.UserName, *r.OrderID, *r.Total)
+}
+
+func main() {
+	// N+1問題: ユーザー一覧（1回）＋ユーザーごとに注文（N回）
+	queryCount = 1 // SELECT * FROM users
+	for _, u := range users {
+		n := len(ordersByUser(u.ID))
+		fmt.Printf("%s: %d件\n", u.Name, n)
+	}
+	fmt.Println("N+1のクエリ回数:", queryCount)
+
+	// JOINなら1回で取れる
+	queryCount = 0
+	fmt.Println("\n=== INNER JOIN ===")
+	for _, r := range innerJoin() {
+		fmt.Println(r)
+	}
+	fmt.Println("\n=== LEFT JOIN ===")
+	for _, r := range leftJoin() {
+		fmt.Println(r)
+	}
+	fmt.Printf("JOINのクエリ回数: %d（INNER JOINとLEFT JOINで1回ずつ）\n", queryCount)
+}
@@ -0,0 +1,64 @@
+package main
+
+import "fmt"
+
+// Page はページ番号方式（LIMIT/OFFSET）のページ指定
+type Page struct {
+	Number  int // 1から
+	PerPage int
+}
+
+const maxPerPage = 100
+
+// Normalize は不正な値をデフォルトに直す。PerPageに上限を設けて、巨大なLIMITを防ぐ
+func (p Page) Normalize() Page {
+	if p.Number < 1 {
+		p.Number = 1
+	}
+	if p.PerPage < 1 {
+		p.PerPage = 20
+	}
+	if p.PerPage > maxPerPage {
+		p.PerPage = maxPerPage
+	}
+	return p
+}
+
+func (p Page) Offset() int { return (p.Number - 1) * p.PerPage }
+
+// SQL はページ指定に対応するSQLと引数
+func (p Page) SQL() (string, []any) {
+	return "SELECT id, title FROM articles ORDER BY id LIMIT ? OFFSET ?", []any{p.PerPage, p.Offset()}
+}
+
+// TotalPages は全件数から総ページ数を計算する（件数は SELECT COUNT(*) で別に取る）
+func TotalPages(total, perPage int) int {
+	return (total + perPage - 1) / perPage
+}
+
+// articles はDBのarticlesテーブルの代わり
+var articles = func() []string {
+	var list []string
+	for i := 1; i <= 23; i++ {
+		list = append(list, fmt.Sprintf("記事%02d", i))
+	}
+	return list
+}()
+
+// query はLIMIT/OFFSETを実行した結果の代わり
+func query(limit, offset int) []string {
+	if offset >= len(articles) {
+		return nil
+	}
+	return articles[offset:min(offset+limit, len(articles))]
+}
+
+func main() {
+	for _, p := range []Page{{1, 10}, {3, 10}, {4, 10}, {0, 500}} {
+		p = p.Normalize()
+		sql, args := p.SQL()
+		rows := query(args[0].(int), args[1].(int))
+		fmt.Printf("page=%d per_page=%d → %s %v\n", p.Number, p.PerPage, sql, args)
+		fmt.Printf("  %d件 %v (全%dページ)\n", len(rows), rows, TotalPages(len(articles), p.PerPage))
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProductFilter は商品検索の条件。ゼロ値の項目は条件に含めない
+type ProductFilter struct {
+	Keyword    string
+	Categories []string
+	MinPrice   int
+	MaxPrice   int
+	Sort       string // "price" "-price" "name"
+	Limit      int
+}
+
+// SearchQuery は条件からSQLと引数を組み立てる
+// 条件の値は全てプレースホルダで渡し、SQLに埋め込むのは許可したカラム名だけにする
+// （PostgreSQLで本格的な全文検索をするなら、to_tsvectorとGINインデックスを使う）
+func SearchQuery(f ProductFilter) (string, []any, error) {
+	var (
+		where []string
+		args  []any
+	)
+	if f.Keyword != "" {
+		where = append(where, `(name LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\')`)
+		pattern := "%" + escapeLike(f.Keyword) + "%"
+		args = append(args, pattern, pattern)
+	}
+	if len(f.Categories) > 0 {
+		// INの中身は要素の数だけ ? を並べる
+		where = append(where, "category IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(f.Categories)), ", ")+")")
+		for _, c := range f.Categories {
+			args = append(args, c)
+		}
+	}
+	if f.MinPrice > 0 {
+		where = append(where, "price >= ?")
+		args = append(args, f.MinPrice)
+	}
+	if f.MaxPrice > 0 {
+		where = append(where, "price <= ?")
+		args = append(args, f.MaxPrice)
+	}
+
+	var b strings.Builder
+	b.WriteString("SELECT id, name, category, price FROM products")
+	if len(where) > 0 {
+		b.WriteString(" WHERE " + strings.Join(where, " AND "))
+	}
+	order, ok := sorts[f.Sort]
+	if !ok {
+		return "", nil, fmt.Errorf("invalid sort %q", f.Sort)
+	}
+	b.WriteString(" ORDER BY " + order)
+	limit := f.Limit
+	if limit <= 0 || limit > 50 {
+		limit = 50
+	}
+	b.WriteString(" LIMIT ?")
+	args = append(args, limit)
+	return b.String(), args, nil
+}
+
+var sorts = map[string]string{
+	"":       "id ASC",
+	"price":  "price ASC, id ASC",
+	"-price": "price DESC, id ASC",
+	"name":   "name ASC, id ASC",
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+type Product struct {
+	ID          int
+	Name        string
+	Description string
+	Category    string
+	Price       int
+}
+
+var products = []Product{
+	{1, "Goプログラミング入門", "Goの基本文法を学ぶ", "book", 3000},
+	{2, "実践Go API開発", "net/httpでREST APIを作る", "book", 3800},
+	{3, "メカニカルキーボード", "Goの書き心地が変わる", "gadget", 15000},
+	{4, "Gopherぬいぐるみ", "Goのマスコット", "toy", 2500},
+	{5, "SQL入門", "SELECTから学ぶ", "book", 2800},
+}
+
+// Search は同じ条件をメモリ上のデータに適用する（SQLの結果の代わり）
+func Search(f ProductFilter) []Product {
+	var list []Product
+	kw := strings.ToLower(f.Keyword)
+	for _, p := range products {
+		if kw != "" && !strings.Contains(strings.ToLower(p.Name), kw) && !strings.Contains(strings.ToLower(p.Description), kw) {
+			continue
+		}
+		if len(f.Categories) > 0 && !slices.Contains(f.Categories, p.Category) {
+			continue
+		}
+		if (f.MinPrice > 0 && p.Price < f.MinPrice) || (f.MaxPrice > 0 && p.Price > f.MaxPrice) {
+			continue
+		}
+		list = append(list, p)
+	}
+	slices.SortStableFunc(list, func(a, b Product) int {
+		switch f.Sort {
+		case "price":
+			return a.Price - b.Price
+		case "-price":
+			return b.Price - a.Price
+		case "name":
+			return strings.Compare(a.Name, b.Name)
+		}
+		return a.ID - b.ID
+	})
+	if f.Limit > 0 && len(list) > f.Limit {
+		list = list[:f.Limit]
+	}
+	return list
+}
+
+func main() {
+	filters := []ProductFilter{
+		{Keyword: "go", Categories: []string{"book", "toy"}, Sort: "-price"},
+		{MinPrice: 2800, MaxPrice: 4000, Sort: "price", Limit: 2},
+		{},
+		{Sort: "price; DROP TABLE products"},
+	}
+	for _, f := range filters {
+		sql, args, err := SearchQuery(f)
+		if err != nil {
+			fmt.Println("Error:", err)
+			continue
+		}
+		fmt.Println(sql)
+		fmt.Printf("  args: %v\n", args)
+		for _, p := range Search(f) {
+			fmt.Printf("  %d %s (%s) %d円\n", p.ID, p.Name, p.Category, p.Price)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sortColumns はソートに使えるキーとカラムの対応
+// カラム名はプレースホルダ（?）にできないので、許可したものだけをSQLに埋め込む
+var sortColumns = map[string]string{
+	"price":      "p.price",
+	"name":       "p.name",
+	"created_at": "p.created_at",
+}
+
+var ErrInvalidSort = errors.New("invalid sort key")
+
+// OrderBy は "price,-created_at" のようなsortパラメータ（-で降順）をORDER BY句にする
+// 同じ値の行の順序が実行ごとに変わらないよう、最後に必ずIDで並べる
+func OrderBy(param string) (string, error) {
+	var terms []string
+	seen := map[string]bool{}
+	for _, key := range strings.Split(param, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		dir := "ASC"
+		if name, ok := strings.CutPrefix(key, "-"); ok {
+			key, dir = name, "DESC"
+		}
+		col, ok := sortColumns[key]
+		if !ok {
+			return "", fmt.Errorf("%w: %q", ErrInvalidSort, key)
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		terms = append(terms, col+" "+dir)
+	}
+	terms = append(terms, "p.id ASC")
+	return "ORDER BY " + strings.Join(terms, ", "), nil
+}
+
+func main() {
+	for _, param := range []string{
+		"",
+		"price",
+		"-price,name",
+		"-created_at,-created_at",
+		"price; DROP TABLE products",
+		"password",
+	} {
+		clause, err := OrderBy(param)
+		if err != nil {
+			fmt.Printf("sort=%q → Error: %v\n", param, err)
+			continue
+		}
+		fmt.Printf("sort=%q → %s\n", param, clause)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// escapeLike はLIKEの特殊文字（% _ と エスケープ文字自身）をエスケープする
+// しないと、ユーザーが "100%" と入力したときに「100で始まる全て」に一致してしまう
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
+
+// containsQuery は部分一致検索のSQLと引数。パターンはSQLに埋め込まず引数で渡す
+func containsQuery(keyword string) (string, []any) {
+	return `SELECT id, name FROM products WHERE name LIKE ? ESCAPE '\'`, []any{"%" + escapeLike(keyword) + "%"}
+}
+
+// likeMatch はLIKEの一致判定の簡単な実装（DBの動作を確かめるため）。_は1文字に一致する
+func likeMatch(s, pattern string) bool {
+	return matchRunes([]rune(s), []rune(pattern))
+}
+
+func matchRunes(s, p []rune) bool {
+	if len(p) == 0 {
+		return len(s) == 0
+	}
+	switch p[0] {
+	case '%':
+		for i := 0; i <= len(s); i++ {
+			if matchRunes(s[i:], p[1:]) {
+				return true
+			}
+		}
+		return false
+	case '_':
+		return len(s) > 0 && matchRunes(s[1:], p[1:])
+	case '\\':
+		p = p[1:]
+		if len(p) == 0 {
+			return false
+		}
+	}
+	return len(s) > 0 && s[0] == p[0] && matchRunes(s[1:], p[1:])
+}
+
+var products = []string{"果汁100%ジュース", "果汁1000mlパック", "snake_case入門", "snake-case辞典"}
+
+func main() {
+	for _, keyword := range []string{"100%", "snake_case", "果汁"} {
+		sql, args := containsQuery(keyword)
+		fmt.Printf("検索: %q\n  %s %q\n", keyword, sql, args)
+
+		pattern := args[0].(string)
+		raw := "%" + keyword + "%" // エスケープしない場合
+		for _, p := range products {
+			fmt.Printf("  %s: escaped=%t raw=%t\n", p, likeMatch(p, pattern), likeMatch(p, raw))
+		}
+	}
+}
@@ -0,0 +1,28 @@
+// Package join はベンチマークとメモリ割り当てのテスト、ファズテストの対象
+// テストはjoin_test.goとtodo_test.goにあり、go test -v で実行する
+// ベンチマークは go test -bench . -benchmem、ファズテストは go test -fuzz FuzzTodoRoundTrip で実行する
+package join
+
+import "strings"
+
+func joinPlus(parts []string) string {
+	s := ""
+	for _, p := range parts {
+		s += p + "," // 毎回新しい文字列を作ってコピーする
+	}
+	return s
+}
+
+func joinBuilder(parts []string) string {
+	n := 0
+	for _, p := range parts {
+		n += len(p) + 1
+	}
+	var b strings.Builder
+	b.Grow(n) // 必要な大きさを先に確保して、1回の割り当てで済ませる
+	for _, p := range parts {
+		b.WriteString(p)
+		b.WriteByte(',')
+	}
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package join
+
+import (
+	"strings"
+	"testing"
+)
+
+var parts = strings.Split(strings.Repeat("item,", 100), ",")[:100]
+
+func TestJoinSameResult(t *testing.T) {
+	if got, want := joinBuilder(parts), joinPlus(parts); got != want {
+		t.Errorf("joinBuilder = %q, want %q", got, want)
+	}
+}
+
+// 1回の呼び出しあたりのメモリ割り当ての回数は実行環境によらず安定しているので、テストで固定できる
+// 時間はベンチマークで測る（環境で変わるので、テストでは比べない）
+func TestJoinAllocs(t *testing.T) {
+	plus := testing.AllocsPerRun(100, func() { joinPlus(parts) })
+	builder := testing.AllocsPerRun(100, func() { joinBuilder(parts) })
+	t.Logf("allocs/op joinPlus=%v joinBuilder=%v", plus, builder)
+	if builder != 1 {
+		t.Errorf("joinBuilder allocs/op = %v, want 1", builder)
+	}
+	if plus <= builder {
+		t.Errorf("joinPlus allocs/op = %v, want more than joinBuilder", plus)
+	}
+}
+
+func BenchmarkJoinPlus(b *testing.B) {
+	for b.Loop() {
+		joinPlus(parts)
+	}
+}
+
+func BenchmarkJoinBuilder(b *testing.B) {
+	for b.Loop() {
+		joinBuilder(parts)
+	}
+}
@@ -0,0 +1,11 @@
+package join
+
+import "time"
+
+// Todo は11-encoding-jsonのTodo
+type Todo struct {
+	ID      int        `json:"id"`
+	Title   string     `json:"title"`
+	Done    bool       `json:"done"`
+	DueDate *time.Time `json:"due_date,omitempty"`
+}
@@ -0,0 +1,70 @@
+package join
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"reflect"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+// checkRoundTrip は「Marshalした結果をUnmarshalすると元に戻る」という性質を確かめる
+func checkRoundTrip(t *testing.T, in Todo) {
+	t.Helper()
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatalf("marshal %+v: %v", in, err)
+	}
+	var out Todo
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("unmarshal %s: %v", data, err)
+	}
+	if !reflect.DeepEqual(in, out) {
+		t.Errorf("round trip %s: got %+v, want %+v", data, out, in)
+	}
+}
+
+// FuzzTodoRoundTrip はファズテスト。go test では f.Add した入力だけで実行し、
+// go test -fuzz FuzzTodoRoundTrip でランダムな入力を自動で探す（失敗した入力はtestdata/fuzz/に保存される）
+func FuzzTodoRoundTrip(f *testing.F) {
+	f.Add(1, "Go学習", false, int64(0))
+	f.Add(-5, "\"\\\n\t<>&😀", true, int64(1_700_000_000))
+	f.Fuzz(func(t *testing.T, id int, title string, done bool, due int64) {
+		if !utf8.ValidString(title) {
+			t.Skip("JSONは不正なUTF-8をU+FFFDに置き換えるので元に戻らない")
+		}
+		in := Todo{ID: id, Title: title, Done: done}
+		if due > 0 {
+			// time.TimeのMarshalJSONは9999年までしか扱えないので、その範囲に収める
+			d := time.Unix(due%maxUnix, 0).UTC()
+			in.DueDate = &d
+		}
+		checkRoundTrip(t, in)
+	})
+}
+
+// maxUnix は10000-01-01T00:00:00Zのunix時間
+const maxUnix = 253_402_300_800
+
+// TestTodoRoundTripRandom はプロパティテスト。シードを固定して、失敗しても同じ入力で再現できるようにする
+func TestTodoRoundTripRandom(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for range 1000 {
+		checkRoundTrip(t, randomTodo(r))
+	}
+}
+
+func randomTodo(r *rand.Rand) Todo {
+	runes := []rune("abcあいう漢字\"\\\n\t<>&😀")
+	title := make([]rune, r.IntN(12))
+	for i := range title {
+		title[i] = runes[r.IntN(len(runes))]
+	}
+	t := Todo{ID: r.IntN(1_000_000) - 500_000, Title: string(title), Done: r.IntN(2) == 0}
+	if r.IntN(2) == 0 {
+		due := time.Unix(r.Int64N(4_000_000_000), 0).UTC()
+		t.DueDate = &due
+	}
+	return t
+}
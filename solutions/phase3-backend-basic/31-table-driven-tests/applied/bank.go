@@ -0,0 +1,52 @@
+// Package bank は02-pointer-basicsのBankAccount（テストする対象）
+// テストはbank_test.goにあり、go test -v で実行する
+package bank
+
+import (
+	"errors"
+	"fmt"
+)
+
+type BankAccount struct {
+	Owner   string
+	Balance int
+}
+
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrSameAccount は自分自身への送金。テストの「自分自身」のケースで見つけたバグの修正
+	ErrSameAccount = errors.New("cannot transfer to the same account")
+)
+
+func (a *BankAccount) Deposit(amount int) error {
+	if amount <= 0 {
+		return errors.New("deposit amount must be positive")
+	}
+	a.Balance += amount
+	return nil
+}
+
+func (a *BankAccount) Withdraw(amount int) error {
+	if amount <= 0 {
+		return errors.New("withdrawal amount must be positive")
+	}
+	if amount > a.Balance {
+		return fmt.Errorf("have %d, want %d: %w", a.Balance, amount, ErrInsufficientFunds)
+	}
+	a.Balance -= amount
+	return nil
+}
+
+func (a *BankAccount) Transfer(to *BankAccount, amount int) error {
+	if a == to {
+		return fmt.Errorf("transfer failed: %w", ErrSameAccount)
+	}
+	if err := a.Withdraw(amount); err != nil {
+		return fmt.Errorf("transfer failed: %w", err)
+	}
+	if err := to.Deposit(amount); err != nil {
+		a.Balance += amount
+		return fmt.Errorf("transfer failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package bank
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// newAccounts はテストヘルパー。t.Helper()を呼ぶと、失敗の行番号がヘルパーではなく呼び出し元になる
+func newAccounts(t *testing.T, balances ...int) []*BankAccount {
+	t.Helper()
+	var list []*BankAccount
+	for i, b := range balances {
+		if b < 0 {
+			t.Fatalf("newAccounts: negative balance %d", b) // 準備に失敗したらテストを続けない
+		}
+		list = append(list, &BankAccount{Owner: fmt.Sprintf("user%d", i+1), Balance: b})
+	}
+	// t.Cleanupはテストの終わりに登録と逆の順で実行される（deferと同じ）
+	t.Cleanup(func() { t.Logf("cleanup: %d accounts", len(list)) })
+	return list
+}
+
+func TestTransfer(t *testing.T) {
+	tests := []struct {
+		name             string
+		from, to, amount int
+		wantFrom, wantTo int
+		wantErr          error // errors.Isで比べる（メッセージの文字列に依存しない）
+		self             bool  // 自分自身に送金する
+	}{
+		{name: "成功", from: 1000, to: 500, amount: 300, wantFrom: 700, wantTo: 800},
+		{name: "全額", from: 1000, to: 0, amount: 1000, wantFrom: 0, wantTo: 1000},
+		{name: "残高不足", from: 100, to: 0, amount: 101, wantFrom: 100, wantTo: 0, wantErr: ErrInsufficientFunds},
+		// 最初の実装は自分自身への送金をエラーにしていなかった（このケースでバグを見つけて直した）
+		{name: "自分自身", from: 1000, amount: 100, wantFrom: 1000, wantErr: ErrSameAccount, self: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			acc := newAccounts(t, tt.from, tt.to)
+			from, to := acc[0], acc[1]
+			if tt.self {
+				to = from
+			}
+			err := from.Transfer(to, tt.amount)
+			switch {
+			case tt.wantErr != nil && !errors.Is(err, tt.wantErr):
+				t.Errorf("Transfer() error = %v, want %v", err, tt.wantErr)
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("Transfer() unexpected error: %v", err)
+			}
+			if from.Balance != tt.wantFrom {
+				t.Errorf("from.Balance = %d, want %d", from.Balance, tt.wantFrom)
+			}
+			if !tt.self && to.Balance != tt.wantTo {
+				t.Errorf("to.Balance = %d, want %d", to.Balance, tt.wantTo)
+			}
+		})
+	}
+}
+
+func TestDepositRejectsNonPositive(t *testing.T) {
+	for _, amount := range []int{0, -100} {
+		t.Run(fmt.Sprint(amount), func(t *testing.T) {
+			acc := newAccounts(t, 100)[0]
+			if err := acc.Deposit(amount); err == nil {
+				t.Errorf("Deposit(%d) error = nil, want an error", amount)
+			}
+			if acc.Balance != 100 {
+				t.Errorf("Balance = %d, want 100", acc.Balance)
+			}
+		})
+	}
+}
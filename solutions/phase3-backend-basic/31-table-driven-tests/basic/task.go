@@ -0,0 +1,24 @@
+// Package task は03-methods-and-receiversのNewTask（テストする対象）
+// テストはtask_test.goにあり、go test -v で実行する
+package task
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Task struct {
+	Title    string
+	Done     bool
+	Priority int
+}
+
+func NewTask(title string, priority int) (*Task, error) {
+	if title == "" {
+		return nil, errors.New("title is required")
+	}
+	if priority < 1 || priority > 5 {
+		return nil, fmt.Errorf("priority must be 1-5, got %d", priority)
+	}
+	return &Task{Title: title, Priority: priority}, nil
+}
@@ -0,0 +1,40 @@
+package task
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewTask(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		priority int
+		wantErr  string // 空ならエラーなし
+	}{
+		{name: "正常", title: "Go学習", priority: 3},
+		{name: "優先度の下限", title: "Go学習", priority: 1},
+		{name: "優先度の上限", title: "Go学習", priority: 5},
+		{name: "タイトルなし", title: "", priority: 3, wantErr: "title is required"},
+		{name: "優先度が0", title: "Go学習", priority: 0, wantErr: "priority must be 1-5"},
+		{name: "優先度が6", title: "Go学習", priority: 6, wantErr: "priority must be 1-5"},
+	}
+	for _, tt := range tests {
+		// サブテストにすると、ケースごとに結果が出て、go test -run 'TestNewTask/優先度が0' で1つだけ実行できる
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTask(tt.title, tt.priority)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("NewTask(%q, %d) error = %v, want %q", tt.title, tt.priority, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewTask(%q, %d) unexpected error: %v", tt.title, tt.priority, err)
+			}
+			if got.Title != tt.title || got.Priority != tt.priority || got.Done {
+				t.Errorf("NewTask(%q, %d) = %+v", tt.title, tt.priority, *got)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package signup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSignup(t *testing.T) {
+	errDown := errors.New("connection reset by peer")
+
+	tests := []struct {
+		name     string
+		store    UserStore
+		existing string // 先に登録しておくメールアドレス
+		email    string
+		wantErr  error // nilでなければerrors.Isで比べる
+		anyErr   bool
+	}{
+		{name: "登録できる", store: NewMemoryStore(), email: "tanaka@example.com"},
+		{name: "二重登録はErrDuplicate", store: NewMemoryStore(), existing: "tanaka@example.com",
+			email: "tanaka@example.com", wantErr: ErrDuplicate},
+		{name: "不正なメールアドレス", store: NewMemoryStore(), email: "invalid", anyErr: true},
+		{name: "DBの障害を伝える", store: &FailingStore{UserStore: NewMemoryStore(), FailOn: "Create", Err: errDown},
+			email: "tanaka@example.com", wantErr: errDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.existing != "" {
+				mustCreate(t, tt.store, tt.existing)
+			}
+			svc := &SignupService{Store: tt.store}
+			u, err := svc.Signup(context.Background(), tt.email)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Signup(%q) error = %v, want %v", tt.email, err, tt.wantErr)
+				}
+				// DBの障害を重複と取り違えない
+				if tt.wantErr != ErrDuplicate && errors.Is(err, ErrDuplicate) {
+					t.Errorf("Signup(%q) error = %v, want it not to be ErrDuplicate", tt.email, err)
+				}
+			case tt.anyErr:
+				if err == nil {
+					t.Errorf("Signup(%q) error = nil, want an error", tt.email)
+				}
+			case err != nil:
+				t.Fatalf("Signup(%q) unexpected error: %v", tt.email, err)
+			case u.Email != tt.email:
+				t.Errorf("Signup(%q) = %+v", tt.email, u)
+			}
+		})
+	}
+}
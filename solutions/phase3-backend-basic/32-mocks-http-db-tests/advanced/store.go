@@ -0,0 +1,91 @@
+// Package signup はUserStoreに依存するSignupService（テストする対象）
+// テストはstore_test.goとsignup_test.goにあり、go test -v で実行する
+package signup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type User struct {
+	ID    int
+	Email string
+}
+
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrDuplicate = errors.New("duplicate email")
+)
+
+// UserStore はユーザーの保存先。本番はPostgreSQL、テストはメモリ上の実装を使う
+type UserStore interface {
+	Create(ctx context.Context, email string) (*User, error)
+	FindByEmail(ctx context.Context, email string) (*User, error)
+	List(ctx context.Context) ([]User, error)
+}
+
+// MemoryStore はメモリ上のUserStore（fake）。本物と同じ振る舞いをする軽い実装
+type MemoryStore struct {
+	mu     sync.Mutex
+	users  map[string]User
+	nextID int
+}
+
+func NewMemoryStore() *MemoryStore { return &MemoryStore{users: map[string]User{}, nextID: 1} }
+
+func (s *MemoryStore) Create(ctx context.Context, email string) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := strings.ToLower(email) // DBのユニーク制約（大文字小文字を区別しない）と合わせる
+	if _, ok := s.users[key]; ok {
+		return nil, ErrDuplicate
+	}
+	u := User{ID: s.nextID, Email: email}
+	s.nextID++
+	s.users[key] = u
+	return &u, nil
+}
+
+func (s *MemoryStore) FindByEmail(ctx context.Context, email string) (*User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.users[strings.ToLower(email)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &u, nil
+}
+
+func (s *MemoryStore) List(ctx context.Context) ([]User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := make([]User, 0, len(s.users))
+	for _, u := range s.users {
+		list = append(list, u)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+// SignupService はテストする対象
+type SignupService struct {
+	Store UserStore
+}
+
+func (s *SignupService) Signup(ctx context.Context, email string) (*User, error) {
+	if !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("signup: invalid email %q", email)
+	}
+	u, err := s.Store.Create(ctx, email)
+	if errors.Is(err, ErrDuplicate) {
+		return nil, fmt.Errorf("signup: %s is already registered: %w", email, err)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signup: %w", err)
+	}
+	return u, nil
+}
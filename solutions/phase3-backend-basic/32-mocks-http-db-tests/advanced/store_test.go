@@ -0,0 +1,78 @@
+package signup
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// FailingStore は指定した操作でエラーを返すラッパー（障害を注入する）
+type FailingStore struct {
+	UserStore
+	FailOn string
+	Err    error
+}
+
+func (s *FailingStore) Create(ctx context.Context, email string) (*User, error) {
+	if s.FailOn == "Create" {
+		return nil, s.Err
+	}
+	return s.UserStore.Create(ctx, email)
+}
+
+// testUserStore は契約テスト。UserStoreの全ての実装が同じテストに通ることを確かめる
+// 本物のDBの実装にも同じテストを流せば、fakeが本物とずれていないことを保証できる
+// newStoreはサブテストごとに呼ぶ（DBならテーブルを空にするかトランザクションをロールバックする）
+func testUserStore(t *testing.T, newStore func() UserStore) {
+	ctx := context.Background()
+
+	t.Run("Create", func(t *testing.T) {
+		u, err := newStore().Create(ctx, "tanaka@example.com")
+		if err != nil || u.ID != 1 {
+			t.Errorf("Create() = %+v, %v, want ID 1", u, err)
+		}
+	})
+	t.Run("大文字小文字違いの重複はErrDuplicate", func(t *testing.T) {
+		s := newStore()
+		mustCreate(t, s, "tanaka@example.com")
+		if _, err := s.Create(ctx, "TANAKA@example.com"); !errors.Is(err, ErrDuplicate) {
+			t.Errorf("Create() error = %v, want ErrDuplicate", err)
+		}
+	})
+	t.Run("FindByEmail", func(t *testing.T) {
+		s := newStore()
+		mustCreate(t, s, "tanaka@example.com")
+		got, err := s.FindByEmail(ctx, "tanaka@example.com")
+		if err != nil || got.ID != 1 {
+			t.Errorf("FindByEmail() = %+v, %v, want ID 1", got, err)
+		}
+		if _, err := s.FindByEmail(ctx, "none@example.com"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("FindByEmail(none) error = %v, want ErrNotFound", err)
+		}
+	})
+	t.Run("ListはID順", func(t *testing.T) {
+		s := newStore()
+		mustCreate(t, s, "tanaka@example.com")
+		mustCreate(t, s, "suzuki@example.com")
+		list, err := s.List(ctx)
+		if err != nil || len(list) != 2 || list[0].ID != 1 || list[1].ID != 2 {
+			t.Errorf("List() = %+v, %v", list, err)
+		}
+	})
+}
+
+func mustCreate(t *testing.T, s UserStore, email string) {
+	t.Helper()
+	if _, err := s.Create(context.Background(), email); err != nil {
+		t.Fatalf("Create(%q): %v", email, err)
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testUserStore(t, func() UserStore { return NewMemoryStore() })
+}
+
+// FailOnを指定しなければ、包んだ実装と同じ振る舞いをする
+func TestFailingStorePassesThrough(t *testing.T) {
+	testUserStore(t, func() UserStore { return &FailingStore{UserStore: NewMemoryStore()} })
+}
@@ -0,0 +1,49 @@
+// Package weather は外部の天気APIのクライアント（テストする対象）
+// テストはweather_test.goにあり、本物のAPIの代わりにhttptest.NewServerを使う
+package weather
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Client は外部の天気APIのクライアント
+type Client struct {
+	BaseURL string
+	HTTP    *http.Client
+}
+
+type Weather struct {
+	City string  `json:"city"`
+	Temp float64 `json:"temp"`
+}
+
+var ErrCityNotFound = errors.New("city not found")
+
+func (c *Client) Get(ctx context.Context, city string) (*Weather, error) {
+	u := c.BaseURL + "/weather?" + url.Values{"city": {city}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get weather: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrCityNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("get weather: unexpected status %d", resp.StatusCode)
+	}
+	var w Weather
+	if err := json.NewDecoder(resp.Body).Decode(&w); err != nil {
+		return nil, fmt.Errorf("get weather: decode: %w", err)
+	}
+	return &w, nil
+}
@@ -0,0 +1,97 @@
+package weather
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeAPI は本物のAPIの代わりに決まった応答を返すハンドラー
+// 受け取ったクエリをqueriesに記録する
+func fakeAPI(queries chan<- string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if queries != nil {
+			queries <- r.URL.RawQuery
+		}
+		switch r.URL.Query().Get("city") {
+		case "tokyo":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"city": "tokyo", "temp": 18.5}`)
+		case "broken":
+			fmt.Fprint(w, `{"city": `)
+		case "slow":
+			// クライアントが諦めて接続を切るまで応答しない（時間を決めて待たない）
+			<-r.Context().Done()
+		case "error":
+			http.Error(w, "oops", http.StatusInternalServerError)
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+func newClient(t *testing.T, h http.Handler) *Client {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	return &Client{BaseURL: srv.URL, HTTP: &http.Client{Timeout: 50 * time.Millisecond}}
+}
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		city    string
+		want    *Weather
+		wantErr error // nilでなければerrors.Isで比べる
+		anyErr  bool  // 種類を問わずエラーになる
+	}{
+		{city: "tokyo", want: &Weather{City: "tokyo", Temp: 18.5}},
+		{city: "atlantis", wantErr: ErrCityNotFound},
+		{city: "error", anyErr: true},
+		{city: "broken", anyErr: true},
+		{city: "slow", anyErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.city, func(t *testing.T) {
+			c := newClient(t, fakeAPI(nil))
+			got, err := c.Get(context.Background(), tt.city)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Get(%q) error = %v, want %v", tt.city, err, tt.wantErr)
+				}
+			case tt.anyErr:
+				if err == nil || errors.Is(err, ErrCityNotFound) {
+					t.Errorf("Get(%q) error = %v, want an error other than ErrCityNotFound", tt.city, err)
+				}
+			case err != nil:
+				t.Fatalf("Get(%q) unexpected error: %v", tt.city, err)
+			case *got != *tt.want:
+				t.Errorf("Get(%q) = %+v, want %+v", tt.city, *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestGetSendsQuery(t *testing.T) {
+	queries := make(chan string, 1)
+	c := newClient(t, fakeAPI(queries))
+	if _, err := c.Get(context.Background(), "tokyo"); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-queries; got != "city=tokyo" {
+		t.Errorf("query = %q, want city=tokyo", got)
+	}
+}
+
+// ハンドラー側はサーバーを立てずに httptest.NewRecorder でテストできる（19-net-http-basics）
+func TestFakeAPIHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	fakeAPI(nil).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/weather?city=tokyo", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+}
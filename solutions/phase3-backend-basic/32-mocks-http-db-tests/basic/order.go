@@ -0,0 +1,29 @@
+// Package order は通知に依存するOrderService（テストする対象）
+// テストはorder_test.goにあり、go test -v で実行する
+package order
+
+import "fmt"
+
+// Notifier は04-interface-basicsのNotifier。依存をinterfaceにしておくと、テストで偽物に差し替えられる
+type Notifier interface {
+	Notify(message string) error
+}
+
+// OrderService は注文を確定したら通知する
+type OrderService struct {
+	notifier Notifier
+}
+
+func NewOrderService(n Notifier) *OrderService {
+	return &OrderService{notifier: n}
+}
+
+func (s *OrderService) Confirm(orderID int) error {
+	if orderID <= 0 {
+		return fmt.Errorf("invalid order id: %d", orderID)
+	}
+	if err := s.notifier.Notify(fmt.Sprintf("注文#%dを確定しました", orderID)); err != nil {
+		return fmt.Errorf("confirm order %d: %w", orderID, err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package order
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// mockNotifier はテスト用のNotifier
+// 呼ばれた引数を記録し（spy）、返すエラーを決められる（stub）
+type mockNotifier struct {
+	messages []string
+	err      error
+}
+
+func (m *mockNotifier) Notify(message string) error {
+	m.messages = append(m.messages, message)
+	return m.err
+}
+
+func TestConfirm(t *testing.T) {
+	// 通知の失敗: 本物のメールサーバーを止めなくても、エラーの経路をテストできる
+	errSMTP := errors.New("smtp: connection refused")
+
+	tests := []struct {
+		name         string
+		orderID      int
+		notifyErr    error
+		wantErr      bool
+		wantWrapped  error // errors.Isで含まれているべきエラー
+		wantMessages []string
+	}{
+		{name: "1回だけ正しいメッセージで通知する", orderID: 42, wantMessages: []string{"注文#42を確定しました"}},
+		{name: "通知のエラーをラップして返す", orderID: 42, notifyErr: errSMTP, wantErr: true, wantWrapped: errSMTP,
+			wantMessages: []string{"注文#42を確定しました"}},
+		{name: "不正なIDでは通知しない", orderID: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &mockNotifier{err: tt.notifyErr}
+			err := NewOrderService(m).Confirm(tt.orderID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Confirm(%d) error = %v, wantErr %v", tt.orderID, err, tt.wantErr)
+			}
+			if tt.wantWrapped != nil && !errors.Is(err, tt.wantWrapped) {
+				t.Errorf("Confirm(%d) error = %v, want it to wrap %v", tt.orderID, err, tt.wantWrapped)
+			}
+			if err != nil {
+				t.Logf("error: %v", err)
+			}
+			if !slices.Equal(m.messages, tt.wantMessages) {
+				t.Errorf("messages = %q, want %q", m.messages, tt.wantMessages)
+			}
+		})
+	}
+}
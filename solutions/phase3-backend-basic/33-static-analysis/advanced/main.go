@@ -0,0 +1,312 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// golang.org/x/tools/go/analysis と同じ形で、Effective Goのイディオムのチェックを組み合わせる
+// golangci-lintも、このような独立したAnalyzerをまとめて実行している
+
+// Pass は1つのAnalyzerに渡す解析対象と報告先
+type Pass struct {
+	Fset     *token.FileSet
+	Files    []*ast.File
+	analyzer *Analyzer
+	diags    *[]Diagnostic
+}
+
+func (p *Pass) Reportf(pos token.Pos, format string, args ...any) {
+	*p.diags = append(*p.diags, Diagnostic{
+		Pos:      p.Fset.Position(pos),
+		Analyzer: p.analyzer.Name,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+type Analyzer struct {
+	Name string
+	Doc  string
+	Run  func(*Pass)
+}
+
+type Diagnostic struct {
+	Pos      token.Position
+	Analyzer string
+	Message  string
+}
+
+// exporteddoc: 公開した関数・型にはその名前で始まるドキュメントコメントを書く
+var exportedDoc = &Analyzer{
+	Name: "exporteddoc",
+	Doc:  "exported declarations should have a comment starting with their name",
+	Run: func(p *Pass) {
+		check := func(name *ast.Ident, doc *ast.CommentGroup) {
+			if !name.IsExported() {
+				return
+			}
+			if doc == nil {
+				p.Reportf(name.Pos(), "exported %s should have comment", name.Name)
+			} else if !strings.HasPrefix(doc.Text(), name.Name+" ") {
+				p.Reportf(doc.Pos(), "comment on exported %s should be of the form %q", name.Name, name.Name+" ...")
+			}
+		}
+		for _, f := range p.Files {
+			for _, d := range f.Decls {
+				switch d := d.(type) {
+				case *ast.FuncDecl:
+					if d.Recv == nil {
+						check(d.Name, d.Doc)
+					}
+				case *ast.GenDecl:
+					if d.Tok != token.TYPE {
+						continue
+					}
+					for _, s := range d.Specs {
+						ts := s.(*ast.TypeSpec)
+						doc := ts.Doc
+						if doc == nil && len(d.Specs) == 1 {
+							doc = d.Doc
+						}
+						check(ts.Name, doc)
+					}
+				}
+			}
+		}
+	},
+}
+
+// errorstrings: エラーメッセージは小文字で始め、句読点で終えない（ラップされて文の途中に来るため）
+var errorStrings = &Analyzer{
+	Name: "errorstrings",
+	Doc:  "error strings should not be capitalized or end with punctuation",
+	Run: func(p *Pass) {
+		for _, f := range p.Files {
+			ast.Inspect(f, func(n ast.Node) bool {
+				call, ok := n.(*ast.CallExpr)
+				if !ok || len(call.Args) == 0 || !(isCall(call, "errors", "New") || isCall(call, "fmt", "Errorf")) {
+					return true
+				}
+				lit, ok := call.Args[0].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					return true
+				}
+				s, _ := strconv.Unquote(lit.Value)
+				first, _ := utf8.DecodeRuneInString(s)
+				last, _ := utf8.DecodeLastRuneInString(s)
+				switch {
+				case unicode.IsUpper(first):
+					p.Reportf(lit.Pos(), "error strings should not be capitalized: %q", s)
+				case strings.ContainsRune(".!\n", last):
+					p.Reportf(lit.Pos(), "error strings should not end with punctuation: %q", s)
+				}
+				return true
+			})
+		}
+	},
+}
+
+// receivernames: レシーバーにthisやselfを使わず、型名の短い名前を一貫して使う
+var receiverNames = &Analyzer{
+	Name: "receivernames",
+	Doc:  "receiver names should be short and consistent",
+	Run: func(p *Pass) {
+		seen := map[string]string{} // 型名 → 最初に使われたレシーバー名
+		for _, f := range p.Files {
+			for _, d := range f.Decls {
+				fn, ok := d.(*ast.FuncDecl)
+				if !ok || fn.Recv == nil || len(fn.Recv.List[0].Names) == 0 {
+					continue
+				}
+				name := fn.Recv.List[0].Names[0]
+				typ := recvType(fn.Recv.List[0].Type)
+				if name.Name == "this" || name.Name == "self" {
+					p.Reportf(name.Pos(), "receiver name should not be %q", name.Name)
+				}
+				if prev, ok := seen[typ]; ok && prev != name.Name {
+					p.Reportf(name.Pos(), "receiver name %s should be consistent with previous receiver name %s for %s", name.Name, prev, typ)
+				} else if !ok {
+					seen[typ] = name.Name
+				}
+			}
+		}
+	},
+}
+
+// ifreturn: if err != nil { return err }; return nil は return err と同じ
+var ifReturn = &Analyzer{
+	Name: "ifreturn",
+	Doc:  "redundant if err != nil { return err }; return nil",
+	Run: func(p *Pass) {
+		for _, f := range p.Files {
+			ast.Inspect(f, func(n ast.Node) bool {
+				block, ok := n.(*ast.BlockStmt)
+				if !ok {
+					return true
+				}
+				for i := 0; i+1 < len(block.List); i++ {
+					ifs, ok := block.List[i].(*ast.IfStmt)
+					if !ok || ifs.Init != nil || ifs.Else != nil || len(ifs.Body.List) != 1 {
+						continue
+					}
+					cond, ok := ifs.Cond.(*ast.BinaryExpr)
+					if !ok || cond.Op != token.NEQ || !isIdent(cond.Y, "nil") {
+						continue
+					}
+					errName, ok := cond.X.(*ast.Ident)
+					if !ok {
+						continue
+					}
+					ret1, ok1 := ifs.Body.List[0].(*ast.ReturnStmt)
+					ret2, ok2 := block.List[i+1].(*ast.ReturnStmt)
+					if ok1 && ok2 && len(ret1.Results) == 1 && len(ret2.Results) == 1 &&
+						isIdent(ret1.Results[0], errName.Name) && isIdent(ret2.Results[0], "nil") {
+						p.Reportf(ifs.Pos(), "redundant if ...; return %s can be simplified", errName.Name)
+					}
+				}
+				return true
+			})
+		}
+	},
+}
+
+func isCall(call *ast.CallExpr, pkg, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	return isIdent(sel.X, pkg) && sel.Sel.Name == name
+}
+
+func isIdent(e ast.Expr, name string) bool {
+	id, ok := e.(*ast.Ident)
+	return ok && id.Name == name
+}
+
+func recvType(e ast.Expr) string {
+	if star, ok := e.(*ast.StarExpr); ok {
+		e = star.X
+	}
+	if id, ok := e.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// Run は全てのAnalyzerを実行し、位置の順に並べた結果を返す
+func Run(fset *token.FileSet, files []*ast.File, analyzers ...*Analyzer) []Diagnostic {
+	var diags []Diagnostic
+	for _, a := range analyzers {
+		a.Run(&Pass{Fset: fset, Files: files, analyzer: a, diags: &diags})
+	}
+	sort.Slice(diags, func(i, j int) bool {
+		a, b := diags[i].Pos, diags[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return diags
+}
+
+var sources = map[string]string{
+	"account.go": `package bank
+
+import "errors"
+
+var ErrInsufficientFunds = errors.New("Insufficient funds.")
+
+// Account は口座
+type Account struct {
+	ID      int
+	Balance int
+}
+
+func (a *Account) Deposit(amount int) {
+	a.Balance += amount
+}
+
+func (this *Account) Withdraw(amount int) error {
+	if amount > this.Balance {
+		return ErrInsufficientFunds
+	}
+	this.Balance -= amount
+	return nil
+}
+`,
+	"transfer.go": `package bank
+
+import "fmt"
+
+// 送金する
+func Transfer(from, to *Account, amount int) error {
+	err := from.Withdraw(amount)
+	if err != nil {
+		return err
+	}
+	to.Deposit(amount)
+	return nil
+}
+
+func Validate(amount int) error {
+	if amount <= 0 {
+		return fmt.Errorf("invalid amount: %d", amount)
+	}
+	return nil
+}
+
+func deposit(to *Account, amount int) error {
+	to.Deposit(amount)
+	err := Validate(to.Balance)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (acc Account) String() string {
+	return fmt.Sprintf("Account#%d", acc.ID)
+}
+`,
+}
+
+func main() {
+	analyzers := []*Analyzer{exportedDoc, errorStrings, receiverNames, ifReturn}
+	for _, a := range analyzers {
+		fmt.Printf("%-14s %s\n", a.Name, a.Doc)
+	}
+	fmt.Println()
+
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range names {
+		f, err := parser.ParseFile(fset, name, sources[name], parser.ParseComments)
+		if err != nil {
+			fmt.Println("parse error:", err)
+			return
+		}
+		files = append(files, f)
+	}
+
+	diags := Run(fset, files, analyzers...)
+	for _, d := range diags {
+		fmt.Printf("%s: %s (%s)\n", d.Pos, d.Message, d.Analyzer)
+	}
+	fmt.Printf("%d件の問題\n", len(diags))
+}
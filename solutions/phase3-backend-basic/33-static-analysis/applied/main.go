@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// go vetのようなチェックをgo/astで書く
+// 構文木をたどって、コンパイルは通るが誤りの可能性が高い書き方を報告する
+const src = `package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrNotFound = errors.New("not found")
+
+func find(id int) error {
+	if id == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func main() {
+	fmt.Printf("id=%d name=%s\n", 1)
+	fmt.Printf("total: %d\n", 100)
+
+	if err := find(0); err.Error() == "not found" {
+		fmt.Println("見つからない")
+	}
+
+	for _, name := range []string{"a.txt", "b.txt"} {
+		f, _ := os.Open(name)
+		defer f.Close()
+	}
+}
+`
+
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+func check(fset *token.FileSet, f *ast.File) []Diagnostic {
+	var diags []Diagnostic
+	report := func(n ast.Node, format string, args ...any) {
+		diags = append(diags, Diagnostic{Pos: fset.Position(n.Pos()), Message: fmt.Sprintf(format, args...)})
+	}
+
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			// fmt.Printfの書式の%の数と引数の数
+			if isCall(n, "fmt", "Printf") && len(n.Args) > 0 {
+				lit, ok := n.Args[0].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					break
+				}
+				format, _ := strconv.Unquote(lit.Value)
+				verbs := strings.Count(format, "%") - 2*strings.Count(format, "%%")
+				if args := len(n.Args) - 1; verbs != args {
+					report(n, "fmt.Printf format %q has %d verbs but %d args", format, verbs, args)
+				}
+			}
+		case *ast.BinaryExpr:
+			// err.Error() == "..." はメッセージが変わると壊れる（errors.Isを使う）
+			if call, ok := n.X.(*ast.CallExpr); ok && n.Op == token.EQL {
+				if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Error" && len(call.Args) == 0 {
+					report(n, "comparing error message string; use errors.Is")
+				}
+			}
+		case *ast.RangeStmt, *ast.ForStmt:
+			// ループの中のdeferは関数を抜けるまで実行されない（09-defer）
+			var body *ast.BlockStmt
+			if r, ok := n.(*ast.RangeStmt); ok {
+				body = r.Body
+			} else {
+				body = n.(*ast.ForStmt).Body
+			}
+			for _, s := range body.List {
+				if d, ok := s.(*ast.DeferStmt); ok {
+					report(d, "defer in loop runs only when the function returns")
+				}
+			}
+		}
+		return true
+	})
+	return diags
+}
+
+// isCall はcallが pkg.name(...) の呼び出しかを返す
+func isCall(call *ast.CallExpr, pkg, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	id, ok := sel.X.(*ast.Ident)
+	return ok && id.Name == pkg && sel.Sel.Name == name
+}
+
+func main() {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "main.go", src, 0)
+	if err != nil {
+		fmt.Println("parse error:", err)
+		return
+	}
+	diags := check(fset, f)
+	for _, d := range diags {
+		fmt.Printf("%s: %s\n", d.Pos, d.Message)
+	}
+	fmt.Printf("%d件の問題\n", len(diags))
+}
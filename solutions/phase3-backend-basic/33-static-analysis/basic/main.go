@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+// gofmtと同じ整形はgo/formatパッケージで呼び出せる
+// インデント・空白・importの並び順をそろえるだけで、プログラムの意味は変えない
+const messy = `package main
+import (
+"strings"
+"fmt"
+)
+type Product struct{
+ID int
+Name string
+Price int
+}
+func main(){
+p:=Product{ID:1,Name:"ノートPC",Price:120000}
+if p.Price>100000{fmt.Println(strings.ToUpper(p.Name))}
+}
+`
+
+func main() {
+	out, err := format.Source([]byte(messy))
+	if err != nil {
+		fmt.Println("format error:", err)
+		return
+	}
+	fmt.Println("=== gofmt後 ===")
+	fmt.Print(string(out))
+
+	// 整形済みのコードをもう一度整形しても変わらない（CIでは gofmt -l で差分がないかを確かめる）
+	again, _ := format.Source(out)
+	fmt.Println("=== 2回目で変化なし:", string(again) == string(out))
+
+	// 構文エラーのあるコードは整形できない
+	_, err = format.Source([]byte("package main\nfunc main() {\n"))
+	fmt.Println("=== 構文エラー:", err != nil)
+	fmt.Println(strings.Repeat("-", 20))
+	fmt.Println("go vet ./... と golangci-lint run は、整形では見つからない誤りを見つける")
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+// getEnv は環境変数がなければdefaultValueを返す
+func getEnv(key, defaultValue string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return defaultValue
+}
+
+// getEnvInt は数値の環境変数を読む。数値でなければエラーにする（黙ってデフォルトにしない）
+func getEnvInt(key string, defaultValue int) (int, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("env %s: %q is not a number", key, v)
+	}
+	return n, nil
+}
+
+func main() {
+	// 本来はシェルやDockerで設定する。ここでは例のためにプログラムの中で設定する
+	os.Setenv("APP_PORT", "9000")
+	os.Setenv("APP_DEBUG", "")
+	os.Setenv("APP_TIMEOUT", "5s")
+	os.Setenv("APP_WORKERS", "four")
+
+	// Getenvは「設定されていない」と「空文字列が設定されている」を区別できない
+	fmt.Printf("Getenv(APP_DEBUG)   = %q\n", os.Getenv("APP_DEBUG"))
+	fmt.Printf("Getenv(APP_MISSING) = %q\n", os.Getenv("APP_MISSING"))
+	_, ok1 := os.LookupEnv("APP_DEBUG")
+	_, ok2 := os.LookupEnv("APP_MISSING")
+	fmt.Printf("LookupEnv: APP_DEBUG=%v APP_MISSING=%v\n", ok1, ok2)
+
+	fmt.Println("port:", getEnv("APP_PORT", "8080"))
+	fmt.Println("host:", getEnv("APP_HOST", "localhost"))
+
+	timeout, err := time.ParseDuration(getEnv("APP_TIMEOUT", "30s"))
+	fmt.Println("timeout:", timeout, err)
+
+	if _, err := getEnvInt("APP_WORKERS", 2); err != nil {
+		fmt.Println("error:", err)
+	}
+	os.Unsetenv("APP_WORKERS")
+	workers, _ := getEnvInt("APP_WORKERS", 2)
+	fmt.Println("workers:", workers)
+}